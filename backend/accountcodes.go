@@ -15,7 +15,10 @@
 package backend
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"sort"
+	"strings"
 
 	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
@@ -30,6 +33,8 @@ import (
 // - regular: for unified accounts
 // - split: for the individual accounts split from a unified account, if the keystore does not support unified accounts, such as the BitBox01.
 // - erc20: for ERC20 token accounts
+// - multisig: for multisig accounts, identified by the cosigner xpubs
+// - imported: for watch-only accounts imported from descriptors, identified by their xpubs
 
 // regularAccountCode returns an account code based on a keystore root fingerprint, a coin code and
 // an account number.
@@ -43,6 +48,32 @@ func splitAccountCode(parentCode accountsTypes.Code, scriptType signing.ScriptTy
 	return accountsTypes.Code(fmt.Sprintf("%s-%s", parentCode, scriptType))
 }
 
+// multisigAccountCode returns an account code for a multisig account, based on the root fingerprint
+// of our keystore, a coin code and the threshold and cosigner xpubs of the multisig configuration.
+func multisigAccountCode(
+	rootFingerprint []byte, coinCode coin.Code, multisig *signing.BitcoinMultisig) accountsTypes.Code {
+	xpubs := make([]string, len(multisig.KeyInfos))
+	for index, keyInfo := range multisig.KeyInfos {
+		xpubs[index] = keyInfo.ExtendedPublicKey.String()
+	}
+	sort.Strings(xpubs)
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", multisig.Threshold, strings.Join(xpubs, ","))))
+	return accountsTypes.Code(fmt.Sprintf("v0-%x-%s-multisig-%x", rootFingerprint, coinCode, hash[:4]))
+}
+
+// importedAccountCode returns an account code for an account imported from descriptors, based on
+// the root fingerprint of the keystore, a coin code and the xpubs of the signing configurations.
+func importedAccountCode(
+	rootFingerprint []byte, coinCode coin.Code, configurations signing.Configurations) accountsTypes.Code {
+	xpubs := make([]string, len(configurations))
+	for index, configuration := range configurations {
+		xpubs[index] = configuration.ExtendedPublicKey().String()
+	}
+	sort.Strings(xpubs)
+	hash := sha256.Sum256([]byte(strings.Join(xpubs, ",")))
+	return accountsTypes.Code(fmt.Sprintf("v0-%x-%s-imported-%x", rootFingerprint, coinCode, hash[:4]))
+}
+
 // Erc20AccountCode returns the account code used for an ERC20 token.
 // It is derived from the account code of the parent ETH account and the token code.
 func Erc20AccountCode(ethereumAccountCode accountsTypes.Code, tokenCode string) accountsTypes.Code {
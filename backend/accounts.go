@@ -15,6 +15,7 @@
 package backend
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"math"
@@ -26,6 +27,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
 	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/addressbook"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/bitsurance"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
@@ -502,7 +504,8 @@ func findHiddenAccount(
 		if !account.SigningConfigurations.ContainsRootFingerprint(rootFingerprint) {
 			continue
 		}
-		if len(account.SigningConfigurations) == 0 {
+		// Multisig accounts are not part of the sequence of regular accounts.
+		if len(account.SigningConfigurations) == 0 || account.SigningConfigurations.IsMultisig() {
 			continue
 		}
 		accountNumber, err := account.SigningConfigurations[0].AccountNumber()
@@ -532,7 +535,8 @@ func nextAccountNumber(coinCode coinpkg.Code, keystore keystore.Keystore, accoun
 		if !account.SigningConfigurations.ContainsRootFingerprint(rootFingerprint) {
 			continue
 		}
-		if len(account.SigningConfigurations) == 0 {
+		// Multisig accounts are not part of the sequence of regular accounts.
+		if len(account.SigningConfigurations) == 0 || account.SigningConfigurations.IsMultisig() {
 			continue
 		}
 		accountNumber, err := account.SigningConfigurations[0].AccountNumber()
@@ -631,6 +635,88 @@ func (backend *Backend) CreateAndPersistAccountConfig(
 	return accountCode, nil
 }
 
+// CreateAndPersistMultisigAccountConfig adds a P2WSH sortedmulti multisig account for the given
+// coin, with `threshold` of the cosigners `keyInfos` required to sign. The connected keystore must be
+// one of the cosigners. If the keystore can't sign for multisig accounts, the account can still be
+// used to watch the funds and to create PSBTs to be signed by the cosigners.
+//
+// The account code of the newly created account is returned.
+func (backend *Backend) CreateAndPersistMultisigAccountConfig(
+	coinCode coinpkg.Code,
+	name string,
+	threshold int,
+	keyInfos []signing.KeyInfo,
+	keystore keystore.Keystore,
+) (accountsTypes.Code, error) {
+	coin, err := backend.Coin(coinCode)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := coin.(*btc.Coin); !ok {
+		return "", errp.Newf("Multisig accounts are not supported for %s", coinCode)
+	}
+	rootFingerprint, err := keystore.RootFingerprint()
+	if err != nil {
+		return "", err
+	}
+	ourKeyIndex := -1
+	for index, keyInfo := range keyInfos {
+		if bytes.Equal(keyInfo.RootFingerprint, rootFingerprint) {
+			ourKeyIndex = index
+			break
+		}
+	}
+	if ourKeyIndex == -1 {
+		return "", errp.New("The connected keystore is not a cosigner of the multisig account")
+	}
+	ourKeyInfo := keyInfos[ourKeyIndex]
+	extendedPublicKey, err := keystore.ExtendedPublicKey(coin, ourKeyInfo.AbsoluteKeypath)
+	if err != nil {
+		return "", err
+	}
+	// Compare the keys without the version bytes, as the xpub might be encoded as e.g. Zpub.
+	ourPublicKey, err := ourKeyInfo.ExtendedPublicKey.ECPubKey()
+	if err != nil {
+		return "", errp.WithStack(err)
+	}
+	keystorePublicKey, err := extendedPublicKey.ECPubKey()
+	if err != nil {
+		return "", errp.WithStack(err)
+	}
+	if !ourPublicKey.IsEqual(keystorePublicKey) ||
+		!bytes.Equal(ourKeyInfo.ExtendedPublicKey.ChainCode(), extendedPublicKey.ChainCode()) {
+		return "", errp.New("The xpub of the connected keystore does not match")
+	}
+	signingConfiguration, err := signing.NewBitcoinMultisigConfiguration(threshold, keyInfos, ourKeyIndex)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = fmt.Sprintf("%s %d-of-%d", coin.Name(), threshold, len(keyInfos))
+	}
+	accountCode := multisigAccountCode(rootFingerprint, coinCode, signingConfiguration.BitcoinMultisig)
+	err = backend.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
+		var accountWatch *bool
+		if accountsConfig.IsKeystoreWatchonly(rootFingerprint) {
+			t := true
+			accountWatch = &t
+		}
+		backend.log.WithField("code", accountCode).Info("persist multisig account")
+		return backend.persistAccount(config.Account{
+			Watch:                 accountWatch,
+			CoinCode:              coinCode,
+			Name:                  name,
+			Code:                  accountCode,
+			SigningConfigurations: signing.Configurations{signingConfiguration},
+		}, accountsConfig)
+	})
+	if err != nil {
+		return "", err
+	}
+	backend.ReinitializeAccounts()
+	return accountCode, nil
+}
+
 // SetAccountActive activates/deactivates an account.
 func (backend *Backend) SetAccountActive(accountCode accountsTypes.Code, active bool) error {
 	err := backend.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
@@ -863,6 +949,10 @@ func (backend *Backend) createAndAddAccount(coin coinpkg.Coin, persistedConfig *
 			}
 			if account != nil && event == accountsTypes.EventSyncDone {
 				backend.notifyNewTxs(account)
+				go backend.webhookTransactions(account)
+			}
+			if account != nil && event == accountsTypes.EventStatusChanged {
+				backend.webhookAccountStatus(account)
 			}
 		},
 		RateUpdater: backend.ratesUpdater,
@@ -872,6 +962,12 @@ func (backend *Backend) createAndAddAccount(coin coinpkg.Coin, persistedConfig *
 		GetSaveFilename:  backend.environment.GetSaveFilename,
 		UnsafeSystemOpen: backend.environment.SystemOpen,
 		BtcCurrencyUnit:  backend.config.AppConfig().Backend.BtcUnit,
+		LookupContact: func(address string) *addressbook.Contact {
+			return backend.lookupContact(coin, address)
+		},
+		FiatQuoteConfig: func() config.FiatQuoteConfig {
+			return backend.config.AppConfig().Backend.FiatQuote
+		},
 	}
 
 	switch specificCoin := coin.(type) {
@@ -1171,6 +1267,11 @@ outer:
 				switch coin.(type) {
 				case *btc.Coin:
 					for _, cfg := range account.SigningConfigurations {
+						// Multisig accounts are loaded even if the keystore can't sign for them,
+						// as the transactions are signed by the cosigners using PSBTs.
+						if cfg.BitcoinMultisig != nil {
+							continue
+						}
 						if !backend.keystore.SupportsAccount(coin, cfg.ScriptType()) {
 							continue outer
 						}
@@ -1259,6 +1360,7 @@ func (backend *Backend) maybeAddP2TR(keystore keystore.Keystore, accounts []*con
 				return err
 			}
 			if keystore.SupportsAccount(coin, signing.ScriptTypeP2TR) &&
+				!account.SigningConfigurations.IsMultisig() &&
 				account.SigningConfigurations.FindScriptType(signing.ScriptTypeP2TR) == -1 {
 				rootFingerprint, err := backend.keystore.RootFingerprint()
 				if err != nil {
@@ -1414,7 +1516,8 @@ func (backend *Backend) maybeAddHiddenUnusedAccounts() {
 			if coinCode != accountConfig.CoinCode {
 				continue
 			}
-			if !accountConfig.SigningConfigurations.ContainsRootFingerprint(rootFingerprint) {
+			if !accountConfig.SigningConfigurations.ContainsRootFingerprint(rootFingerprint) ||
+				accountConfig.SigningConfigurations.IsMultisig() {
 				continue
 			}
 			accountNumber, err := accountConfig.SigningConfigurations[0].AccountNumber()
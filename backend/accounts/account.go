@@ -20,6 +20,7 @@ import (
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/notes"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/observable"
 	"github.com/btcsuite/btcd/wire"
@@ -48,16 +49,92 @@ type PaymentRequest struct {
 	Signature     []byte
 }
 
+// Recipient is a recipient of a transaction together with the amount sent to it.
+type Recipient struct {
+	Address string
+	// Amount can be a send-all amount, in which case the recipient receives all the remaining coins
+	// after paying the other recipients and the fee.
+	Amount coin.SendAmount
+}
+
 // TxProposalArgs are the arguments needed when creating a tx proposal.
 type TxProposalArgs struct {
 	RecipientAddress string
 	Amount           coin.SendAmount
-	FeeTargetCode    FeeTargetCode
+	// Recipients, if not empty, replaces RecipientAddress and Amount to pay several recipients in a
+	// single transaction (batch payment). At most one of them can have a send-all amount. Only
+	// BTC/LTC accounts support more than one recipient.
+	Recipients    []Recipient
+	FeeTargetCode FeeTargetCode
 	// Only applies if FeeTargetCode == Custom. It is provided in sat/vB for BTC/LTC and Gwei for ETH.
-	CustomFee      string
-	SelectedUTXOs  map[wire.OutPoint]struct{}
+	CustomFee     string
+	SelectedUTXOs map[wire.OutPoint]struct{}
+	// CoinSelection is the algorithm selecting the coins to spend among the available (or selected)
+	// UTXOs. Only applies to BTC/LTC. The empty value means the default strategy.
+	CoinSelection  CoinSelectionStrategy
 	Note           string
 	PaymentRequest *PaymentRequest
+	// DryRun, if true, only validates the input and returns the result without replacing the
+	// proposal that is signed and sent by SendTx.
+	DryRun bool
+	// Fiat, if not empty, means that the amounts of the recipients are given in this fiat currency.
+	// They are converted to the coin at the latest exchange rate, which is locked in the proposal.
+	Fiat rates.Fiat
+}
+
+// AllRecipients returns the recipients of the transaction, which is either Recipients or, if
+// empty, the single recipient given by RecipientAddress and Amount.
+func (args *TxProposalArgs) AllRecipients() []Recipient {
+	if len(args.Recipients) != 0 {
+		return args.Recipients
+	}
+	return []Recipient{{Address: args.RecipientAddress, Amount: args.Amount}}
+}
+
+// ConsolidationWarningThreshold is the number of previously unlinked address clusters a tx can
+// spend from without a TxProposalWarningConsolidation warning.
+const ConsolidationWarningThreshold = 3
+
+// TxProposalWarningCode identifies a privacy issue of a tx proposal the user should be aware of
+// before signing.
+type TxProposalWarningCode string
+
+const (
+	// TxProposalWarningAddressReuse means the recipient address appears in the transaction history
+	// of the account.
+	TxProposalWarningAddressReuse TxProposalWarningCode = "addressReuse"
+	// TxProposalWarningOwnAddress means the recipient address belongs to the account.
+	TxProposalWarningOwnAddress TxProposalWarningCode = "ownAddress"
+	// TxProposalWarningConsolidation means the tx spends coins of more than
+	// ConsolidationWarningThreshold addresses that were not linked by previous transactions.
+	TxProposalWarningConsolidation TxProposalWarningCode = "consolidation"
+	// TxProposalWarningChangeScriptType means the change output has a different script type than
+	// the recipient outputs, which makes it easy to tell which output is the change.
+	TxProposalWarningChangeScriptType TxProposalWarningCode = "changeScriptType"
+)
+
+// TxProposalWarning is a privacy warning about a tx proposal.
+type TxProposalWarning struct {
+	Code TxProposalWarningCode `json:"code"`
+	// Address is the recipient address the warning is about, if any.
+	Address string `json:"address,omitempty"`
+	// NumAddresses is the number of unlinked address clusters spent from, for
+	// TxProposalWarningConsolidation.
+	NumAddresses int `json:"numAddresses,omitempty"`
+}
+
+// TxProposalResult is the result of Interface.TxProposal() to be shown to the user before signing.
+type TxProposalResult struct {
+	// Amount is the amount sent to the recipients.
+	Amount coin.Amount
+	Fee    coin.Amount
+	// Total is the amount deducted from the account, which is the amount plus the fee if the fee is
+	// paid in the same unit.
+	Total    coin.Amount
+	Warnings []TxProposalWarning
+	// FiatQuote is the exchange rate the amounts were converted with if they were given in fiat,
+	// nil otherwise.
+	FiatQuote *FiatQuote
 }
 
 // Interface is the API of a Account.
@@ -93,7 +170,12 @@ type Interface interface {
 	// available.
 	SendTx(txNote string) error
 	FeeTargets() ([]FeeTarget, FeeTargetCode)
-	TxProposal(*TxProposalArgs) (coin.Amount, coin.Amount, coin.Amount, error)
+	// TxProposal creates the tx proposal which is sent by SendTx and returns its amounts and
+	// privacy warnings.
+	TxProposal(*TxProposalArgs) (*TxProposalResult, error)
+	// SendTxProposal creates a tx proposal like TxProposal and signs and sends it right away,
+	// without replacing the active tx proposal of SendTx. The note is stored with the transaction.
+	SendTxProposal(args *TxProposalArgs, txNote string) error
 	// GetUnusedReceiveAddresses gets a list of list of receive addresses. The result can be one
 	// list of addresses, or if there are multiple types of addresses (e.g. `bc1...` vs `3...`), a
 	// list of lists.
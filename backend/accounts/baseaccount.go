@@ -25,6 +25,7 @@ import (
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/notes"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/addressbook"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/synchronizer"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
@@ -53,6 +54,12 @@ type AccountConfig struct {
 	UnsafeSystemOpen func(filename string) error
 	// BtcCurrencyUnit is the unit which should be used to format fiat amounts values expressed in BTC..
 	BtcCurrencyUnit coin.BtcUnit
+	// LookupContact returns the address book entry of a recipient address, or nil if there is
+	// none. Can be nil.
+	LookupContact func(address string) *addressbook.Contact
+	// FiatQuoteConfig returns the limits for signing transactions whose amounts were given in fiat.
+	// Can be nil, in which case the default limits apply.
+	FiatQuoteConfig func() config.FiatQuoteConfig
 }
 
 // BaseAccount is an account struct with common functionality to all coin accounts.
@@ -277,8 +284,11 @@ func (account *BaseAccount) ExportCSV(w io.Writer, transactions []*TransactionDa
 			timeString = transaction.Timestamp.Format(time.RFC3339)
 		}
 		for _, addressAndAmount := range transaction.Addresses {
+			// In a tx with several recipients, only the rows of our own addresses are sent to
+			// ourselves.
+			rowType := transactionType
 			if transactionType == "sent" && addressAndAmount.Ours {
-				transactionType = "sent_to_yourself"
+				rowType = "sent_to_yourself"
 			}
 
 			amount := addressAndAmount.Amount.BigInt().String()
@@ -290,7 +300,7 @@ func (account *BaseAccount) ExportCSV(w io.Writer, transactions []*TransactionDa
 			}
 			err := writer.Write([]string{
 				timeString,
-				transactionType,
+				rowType,
 				amount,
 				unit,
 				feeString,
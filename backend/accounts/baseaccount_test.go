@@ -248,6 +248,29 @@ func TestBaseAccount(t *testing.T) {
 
 	})
 
+	t.Run("exportCSV with BTC batch payment", func(t *testing.T) {
+		require.Equal(t,
+			header+
+				`2020-03-01T16:44:20Z,sent_to_yourself,100,satoshi,101,satoshi,own-address,batch-tx-id,
+2020-03-01T16:44:20Z,sent,200,satoshi,,,recipient-1,batch-tx-id,
+2020-03-01T16:44:20Z,sent,300,satoshi,,,recipient-2,batch-tx-id,
+`,
+			export(account, []*TransactionData{
+				{
+					Type:       TxTypeSend,
+					TxID:       "batch-tx-id",
+					InternalID: "batch-internal-tx-id",
+					Fee:        &fee,
+					Timestamp:  &timestamp,
+					Addresses: []AddressAndAmount{
+						{Address: "own-address", Amount: coin.NewAmountFromInt64(100), Ours: true},
+						{Address: "recipient-1", Amount: coin.NewAmountFromInt64(200)},
+						{Address: "recipient-2", Amount: coin.NewAmountFromInt64(300)},
+					},
+				},
+			}))
+	})
+
 	t.Run("exportCSV with ERC20", func(t *testing.T) {
 		mockCoin := &mocks.CoinMock{
 			CodeFunc: func() coin.Code {
@@ -0,0 +1,57 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accounts
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+)
+
+// CoinSelectionStrategy is the algorithm used to select the coins spent by a new BTC/LTC
+// transaction. See the constants below.
+type CoinSelectionStrategy string
+
+const (
+	// CoinSelectionLargestFirst spends the largest coins first until the amount and fee are
+	// covered. It is also the fallback of the other strategies.
+	CoinSelectionLargestFirst CoinSelectionStrategy = "largestFirst"
+
+	// CoinSelectionBranchAndBound searches for a set of coins matching the amount and fee closely
+	// enough that no change output is needed, minimizing the waste. Falls back to largest-first if
+	// there is no such set.
+	CoinSelectionBranchAndBound CoinSelectionStrategy = "branchAndBound"
+
+	// CoinSelectionPrivacy avoids linking addresses by funding the transaction from the coins of a
+	// single address, spending all of them. Falls back to largest-first if no address has enough
+	// coins.
+	CoinSelectionPrivacy CoinSelectionStrategy = "privacy"
+
+	// DefaultCoinSelection is the default coin selection strategy.
+	DefaultCoinSelection = CoinSelectionLargestFirst
+)
+
+// NewCoinSelectionStrategy checks if the strategy is valid and returns a CoinSelectionStrategy in
+// that case. The empty string results in the default strategy.
+func NewCoinSelectionStrategy(strategy string) (CoinSelectionStrategy, error) {
+	switch strategy {
+	case "":
+		return DefaultCoinSelection, nil
+	case string(CoinSelectionLargestFirst):
+	case string(CoinSelectionBranchAndBound):
+	case string(CoinSelectionPrivacy):
+	default:
+		return "", errp.Newf("Unrecognized coin selection strategy %s", strategy)
+	}
+	return CoinSelectionStrategy(strategy), nil
+}
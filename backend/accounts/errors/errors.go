@@ -41,6 +41,15 @@ var (
 	ErrFeeTooLow = TxValidationError("feeTooLow")
 	// ErrAccountNotsynced is used when the account sync has not successfully finished.
 	ErrAccountNotsynced = TxValidationError("accountNotSynced")
+	// ErrFiatRateNotAvailable is returned when an amount given in fiat cannot be converted because
+	// the exchange rate is not available.
+	ErrFiatRateNotAvailable = TxValidationError("fiatRateNotAvailable")
+	// ErrFiatQuoteExpired is returned when signing a transaction whose amount was converted from
+	// fiat with an exchange rate that has become too old.
+	ErrFiatQuoteExpired = TxValidationError("fiatQuoteExpired")
+	// ErrFiatRateChanged is returned when signing a transaction whose amount was converted from
+	// fiat, if the exchange rate has moved by more than the configured tolerance since.
+	ErrFiatRateChanged = TxValidationError("fiatRateChanged")
 
 	// ErrNotAvailable is returned if data required is not available yet. Example: the headers are
 	// not synced yet, which is a prerequisite to making a timeseries of the portfolio.
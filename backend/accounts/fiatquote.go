@@ -0,0 +1,110 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accounts
+
+import (
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+)
+
+// FiatQuote is the exchange rate with which the fiat amounts of a tx proposal were converted to the
+// coin of the account. It is locked in the proposal and checked again before signing.
+type FiatQuote struct {
+	Fiat rates.Fiat `json:"fiat"`
+	// Rate is the price of one coin in Fiat.
+	Rate float64 `json:"rate"`
+	// Timestamp is the time at which the rate was fetched.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// latestPrice returns the latest price of one coin of the account in the given fiat currency.
+func (account *BaseAccount) latestPrice(fiat rates.Fiat) (float64, error) {
+	ratesUpdater := account.config.RateUpdater
+	if ratesUpdater == nil {
+		return 0, errp.WithStack(errors.ErrFiatRateNotAvailable)
+	}
+	price, err := ratesUpdater.LatestPriceForPair(account.coin.Unit(false), string(fiat))
+	if err != nil || price <= 0 {
+		return 0, errp.WithStack(errors.ErrFiatRateNotAvailable)
+	}
+	return price, nil
+}
+
+// ConvertFiatAmounts returns a copy of the args with the amounts converted from args.Fiat to the
+// unit the account currently shows amounts in at the latest exchange rate, and the quote of that
+// rate. If args.Fiat is empty, args is returned as is and the quote is nil.
+func (account *BaseAccount) ConvertFiatAmounts(args *TxProposalArgs) (*TxProposalArgs, *FiatQuote, error) {
+	if args.Fiat == "" {
+		return args, nil, nil
+	}
+	price, err := account.latestPrice(args.Fiat)
+	if err != nil {
+		return nil, nil, err
+	}
+	quote := &FiatQuote{
+		Fiat:      args.Fiat,
+		Rate:      price,
+		Timestamp: account.config.RateUpdater.LatestPriceUpdated(),
+	}
+	priceRat := new(big.Rat).SetFloat64(price)
+	converted := *args
+	converted.Fiat = ""
+	if len(args.Recipients) == 0 {
+		converted.Amount, err = args.Amount.ConvertFromFiat(account.coin, priceRat)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &converted, quote, nil
+	}
+	converted.Recipients = make([]Recipient, len(args.Recipients))
+	for index, recipient := range args.Recipients {
+		amount, err := recipient.Amount.ConvertFromFiat(account.coin, priceRat)
+		if err != nil {
+			return nil, nil, err
+		}
+		converted.Recipients[index] = Recipient{Address: recipient.Address, Amount: amount}
+	}
+	return &converted, quote, nil
+}
+
+// CheckFiatQuote returns an error if the exchange rate of the quote has become too old to sign, or
+// if the latest exchange rate deviates from it by more than the configured tolerance. A nil quote,
+// i.e. a proposal without fiat amounts, is always valid.
+func (account *BaseAccount) CheckFiatQuote(quote *FiatQuote) error {
+	if quote == nil {
+		return nil
+	}
+	limits := config.NewDefaultAppConfig().Backend.FiatQuote
+	if account.config.FiatQuoteConfig != nil {
+		limits = account.config.FiatQuoteConfig()
+	}
+	if time.Since(quote.Timestamp) > time.Duration(limits.MaxAgeSeconds)*time.Second {
+		return errp.WithStack(errors.ErrFiatQuoteExpired)
+	}
+	price, err := account.latestPrice(quote.Fiat)
+	if err != nil {
+		return err
+	}
+	if math.Abs(price-quote.Rate)/quote.Rate*100 > limits.TolerancePercent {
+		return errp.WithStack(errors.ErrFiatRateChanged)
+	}
+	return nil
+}
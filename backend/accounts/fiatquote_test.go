@@ -0,0 +1,114 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accounts
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiatQuote(t *testing.T) {
+	// The mock rates are 21 USD/BTC.
+	ratesUpdater := rates.MockRateUpdater()
+	defer ratesUpdater.Stop()
+	limits := config.FiatQuoteConfig{MaxAgeSeconds: 60, TolerancePercent: 1}
+	cfg := &AccountConfig{
+		Config:          &config.Account{Code: "test", Name: "Test"},
+		RateUpdater:     ratesUpdater,
+		FiatQuoteConfig: func() config.FiatQuoteConfig { return limits },
+	}
+	mockCoin := &mocks.CoinMock{
+		UnitFunc:     func(isFee bool) string { return "BTC" },
+		DecimalsFunc: func(isFee bool) uint { return 8 },
+		SetAmountFunc: func(amount *big.Rat, isFee bool) coin.Amount {
+			sats, _ := new(big.Int).SetString(new(big.Rat).Mul(amount, big.NewRat(1e8, 1)).FloatString(0), 10)
+			return coin.NewAmount(sats)
+		},
+		FormatAmountFunc: func(amount coin.Amount, isFee bool) string {
+			return new(big.Rat).SetFrac(amount.BigInt(), big.NewInt(1e8)).FloatString(8)
+		},
+	}
+	account := NewBaseAccount(cfg, mockCoin, logging.Get().WithGroup("fiatquote_test"))
+
+	t.Run("no fiat", func(t *testing.T) {
+		args := &TxProposalArgs{RecipientAddress: "address", Amount: coin.NewSendAmount("1")}
+		converted, quote, err := account.ConvertFiatAmounts(args)
+		require.NoError(t, err)
+		require.Nil(t, quote)
+		require.Equal(t, args, converted)
+		require.NoError(t, account.CheckFiatQuote(nil))
+	})
+
+	t.Run("convert", func(t *testing.T) {
+		args := &TxProposalArgs{
+			RecipientAddress: "address",
+			Amount:           coin.NewSendAmount("42"),
+			Fiat:             rates.USD,
+		}
+		converted, quote, err := account.ConvertFiatAmounts(args)
+		require.NoError(t, err)
+		require.Equal(t, coin.NewSendAmount("2.00000000"), converted.Amount)
+		require.Equal(t, rates.Fiat(""), converted.Fiat)
+		require.Equal(t, rates.USD, quote.Fiat)
+		require.Equal(t, 21.0, quote.Rate)
+		require.Equal(t, ratesUpdater.LatestPriceUpdated(), quote.Timestamp)
+		// The args of the caller are not modified.
+		require.Equal(t, coin.NewSendAmount("42"), args.Amount)
+		require.NoError(t, account.CheckFiatQuote(quote))
+
+		args = &TxProposalArgs{
+			Recipients: []Recipient{
+				{Address: "address1", Amount: coin.NewSendAmount("10.5")},
+				{Address: "address2", Amount: coin.NewSendAmountAll()},
+			},
+			Fiat: rates.USD,
+		}
+		converted, _, err = account.ConvertFiatAmounts(args)
+		require.NoError(t, err)
+		require.Equal(t, []Recipient{
+			{Address: "address1", Amount: coin.NewSendAmount("0.50000000")},
+			{Address: "address2", Amount: coin.NewSendAmountAll()},
+		}, converted.Recipients)
+	})
+
+	t.Run("rate not available", func(t *testing.T) {
+		_, _, err := account.ConvertFiatAmounts(&TxProposalArgs{Amount: coin.NewSendAmount("1"), Fiat: rates.CHF})
+		require.Equal(t, errors.ErrFiatRateNotAvailable, errp.Cause(err))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		quote := &FiatQuote{Fiat: rates.USD, Rate: 21, Timestamp: time.Now().Add(-2 * time.Minute)}
+		require.Equal(t, errors.ErrFiatQuoteExpired, errp.Cause(account.CheckFiatQuote(quote)))
+	})
+
+	t.Run("rate changed", func(t *testing.T) {
+		quote := &FiatQuote{Fiat: rates.USD, Rate: 20.9, Timestamp: time.Now()}
+		require.NoError(t, account.CheckFiatQuote(quote))
+		quote.Rate = 20
+		require.Equal(t, errors.ErrFiatRateChanged, errp.Cause(account.CheckFiatQuote(quote)))
+		limits.TolerancePercent = 5
+		require.NoError(t, account.CheckFiatQuote(quote))
+	})
+}
@@ -70,12 +70,12 @@ var _ accounts.Interface = &InterfaceMock{}
 //			OfflineFunc: func() error {
 //				panic("mock out the Offline method")
 //			},
-//			ProposeTxNoteFunc: func(s string)  {
-//				panic("mock out the ProposeTxNote method")
-//			},
 //			SendTxFunc: func(txNote string) error {
 //				panic("mock out the SendTx method")
 //			},
+//			SendTxProposalFunc: func(args *accounts.TxProposalArgs, txNote string) error {
+//				panic("mock out the SendTxProposal method")
+//			},
 //			SetTxNoteFunc: func(txID string, note string) error {
 //				panic("mock out the SetTxNote method")
 //			},
@@ -88,7 +88,7 @@ var _ accounts.Interface = &InterfaceMock{}
 //			TxNoteFunc: func(txID string) string {
 //				panic("mock out the TxNote method")
 //			},
-//			TxProposalFunc: func(txProposalArgs *accounts.TxProposalArgs) (coin.Amount, coin.Amount, coin.Amount, error) {
+//			TxProposalFunc: func(txProposalArgs *accounts.TxProposalArgs) (*accounts.TxProposalResult, error) {
 //				panic("mock out the TxProposal method")
 //			},
 //			VerifyAddressFunc: func(addressID string) (bool, error) {
@@ -149,12 +149,12 @@ type InterfaceMock struct {
 	// OfflineFunc mocks the Offline method.
 	OfflineFunc func() error
 
-	// ProposeTxNoteFunc mocks the ProposeTxNote method.
-	ProposeTxNoteFunc func(s string)
-
 	// SendTxFunc mocks the SendTx method.
 	SendTxFunc func(txNote string) error
 
+	// SendTxProposalFunc mocks the SendTxProposal method.
+	SendTxProposalFunc func(args *accounts.TxProposalArgs, txNote string) error
+
 	// SetTxNoteFunc mocks the SetTxNote method.
 	SetTxNoteFunc func(txID string, note string) error
 
@@ -168,7 +168,7 @@ type InterfaceMock struct {
 	TxNoteFunc func(txID string) string
 
 	// TxProposalFunc mocks the TxProposal method.
-	TxProposalFunc func(txProposalArgs *accounts.TxProposalArgs) (coin.Amount, coin.Amount, coin.Amount, error)
+	TxProposalFunc func(txProposalArgs *accounts.TxProposalArgs) (*accounts.TxProposalResult, error)
 
 	// VerifyAddressFunc mocks the VerifyAddress method.
 	VerifyAddressFunc func(addressID string) (bool, error)
@@ -229,13 +229,17 @@ type InterfaceMock struct {
 		// Offline holds details about calls to the Offline method.
 		Offline []struct {
 		}
-		// ProposeTxNote holds details about calls to the ProposeTxNote method.
-		ProposeTxNote []struct {
-			// S is the s argument value.
-			S string
-		}
 		// SendTx holds details about calls to the SendTx method.
 		SendTx []struct {
+			// TxNote is the txNote argument value.
+			TxNote string
+		}
+		// SendTxProposal holds details about calls to the SendTxProposal method.
+		SendTxProposal []struct {
+			// Args is the args argument value.
+			Args *accounts.TxProposalArgs
+			// TxNote is the txNote argument value.
+			TxNote string
 		}
 		// SetTxNote holds details about calls to the SetTxNote method.
 		SetTxNote []struct {
@@ -282,8 +286,8 @@ type InterfaceMock struct {
 	lockNotifier                  sync.RWMutex
 	lockObserve                   sync.RWMutex
 	lockOffline                   sync.RWMutex
-	lockProposeTxNote             sync.RWMutex
 	lockSendTx                    sync.RWMutex
+	lockSendTxProposal            sync.RWMutex
 	lockSetTxNote                 sync.RWMutex
 	lockSynced                    sync.RWMutex
 	lockTransactions              sync.RWMutex
@@ -738,62 +742,71 @@ func (mock *InterfaceMock) OfflineCalls() []struct {
 	return calls
 }
 
-// ProposeTxNote calls ProposeTxNoteFunc.
-func (mock *InterfaceMock) ProposeTxNote(s string) {
-	if mock.ProposeTxNoteFunc == nil {
-		panic("InterfaceMock.ProposeTxNoteFunc: method is nil but Interface.ProposeTxNote was just called")
+// SendTx calls SendTxFunc.
+func (mock *InterfaceMock) SendTx(txNote string) error {
+	if mock.SendTxFunc == nil {
+		panic("InterfaceMock.SendTxFunc: method is nil but Interface.SendTx was just called")
 	}
 	callInfo := struct {
-		S string
+		TxNote string
 	}{
-		S: s,
+		TxNote: txNote,
 	}
-	mock.lockProposeTxNote.Lock()
-	mock.calls.ProposeTxNote = append(mock.calls.ProposeTxNote, callInfo)
-	mock.lockProposeTxNote.Unlock()
-	mock.ProposeTxNoteFunc(s)
+	mock.lockSendTx.Lock()
+	mock.calls.SendTx = append(mock.calls.SendTx, callInfo)
+	mock.lockSendTx.Unlock()
+	return mock.SendTxFunc(txNote)
 }
 
-// ProposeTxNoteCalls gets all the calls that were made to ProposeTxNote.
+// SendTxCalls gets all the calls that were made to SendTx.
 // Check the length with:
 //
-//	len(mockedInterface.ProposeTxNoteCalls())
-func (mock *InterfaceMock) ProposeTxNoteCalls() []struct {
-	S string
+//	len(mockedInterface.SendTxCalls())
+func (mock *InterfaceMock) SendTxCalls() []struct {
+	TxNote string
 } {
 	var calls []struct {
-		S string
+		TxNote string
 	}
-	mock.lockProposeTxNote.RLock()
-	calls = mock.calls.ProposeTxNote
-	mock.lockProposeTxNote.RUnlock()
+	mock.lockSendTx.RLock()
+	calls = mock.calls.SendTx
+	mock.lockSendTx.RUnlock()
 	return calls
 }
 
-// SendTx calls SendTxFunc.
-func (mock *InterfaceMock) SendTx(txNote string) error {
-	if mock.SendTxFunc == nil {
-		panic("InterfaceMock.SendTxFunc: method is nil but Interface.SendTx was just called")
+// SendTxProposal calls SendTxProposalFunc.
+func (mock *InterfaceMock) SendTxProposal(args *accounts.TxProposalArgs, txNote string) error {
+	if mock.SendTxProposalFunc == nil {
+		panic("InterfaceMock.SendTxProposalFunc: method is nil but Interface.SendTxProposal was just called")
 	}
 	callInfo := struct {
-	}{}
-	mock.lockSendTx.Lock()
-	mock.calls.SendTx = append(mock.calls.SendTx, callInfo)
-	mock.lockSendTx.Unlock()
-	return mock.SendTxFunc("")
+		Args   *accounts.TxProposalArgs
+		TxNote string
+	}{
+		Args:   args,
+		TxNote: txNote,
+	}
+	mock.lockSendTxProposal.Lock()
+	mock.calls.SendTxProposal = append(mock.calls.SendTxProposal, callInfo)
+	mock.lockSendTxProposal.Unlock()
+	return mock.SendTxProposalFunc(args, txNote)
 }
 
-// SendTxCalls gets all the calls that were made to SendTx.
+// SendTxProposalCalls gets all the calls that were made to SendTxProposal.
 // Check the length with:
 //
-//	len(mockedInterface.SendTxCalls())
-func (mock *InterfaceMock) SendTxCalls() []struct {
+//	len(mockedInterface.SendTxProposalCalls())
+func (mock *InterfaceMock) SendTxProposalCalls() []struct {
+	Args   *accounts.TxProposalArgs
+	TxNote string
 } {
 	var calls []struct {
+		Args   *accounts.TxProposalArgs
+		TxNote string
 	}
-	mock.lockSendTx.RLock()
-	calls = mock.calls.SendTx
-	mock.lockSendTx.RUnlock()
+	mock.lockSendTxProposal.RLock()
+	calls = mock.calls.SendTxProposal
+	mock.lockSendTxProposal.RUnlock()
 	return calls
 }
 
@@ -920,7 +933,7 @@ func (mock *InterfaceMock) TxNoteCalls() []struct {
 }
 
 // TxProposal calls TxProposalFunc.
-func (mock *InterfaceMock) TxProposal(txProposalArgs *accounts.TxProposalArgs) (coin.Amount, coin.Amount, coin.Amount, error) {
+func (mock *InterfaceMock) TxProposal(txProposalArgs *accounts.TxProposalArgs) (*accounts.TxProposalResult, error) {
 	if mock.TxProposalFunc == nil {
 		panic("InterfaceMock.TxProposalFunc: method is nil but Interface.TxProposal was just called")
 	}
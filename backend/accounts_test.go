@@ -18,6 +18,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -726,6 +727,77 @@ func TestCreateAndPersistAccountConfig(t *testing.T) {
 	})
 }
 
+func TestCreateAndPersistMultisigAccountConfig(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	ks := makeBitBox02Multi()
+	ks.SupportsAccountFunc = func(coin coinpkg.Coin, meta interface{}) bool {
+		switch coin.(type) {
+		case *btc.Coin:
+			scriptType := meta.(signing.ScriptType)
+			return scriptType != signing.ScriptTypeP2PKH && scriptType != signing.ScriptTypeP2WSH
+		default:
+			return true
+		}
+	}
+	b.registerKeystore(ks)
+
+	btcCoin, err := b.Coin(coinpkg.CodeBTC)
+	require.NoError(t, err)
+	keypath := mustKeypath("m/48'/0'/0'/2'")
+	keyInfo := func(rootFingerprint []byte, keystore *software.Keystore) signing.KeyInfo {
+		xpub, err := keystore.ExtendedPublicKey(btcCoin, keypath)
+		require.NoError(t, err)
+		return signing.KeyInfo{
+			RootFingerprint:   rootFingerprint,
+			AbsoluteKeypath:   keypath,
+			ExtendedPublicKey: xpub,
+		}
+	}
+	keyInfos := []signing.KeyInfo{
+		keyInfo(rootFingerprint2, keystoreHelper2()),
+		keyInfo(rootFingerprint1, keystoreHelper1()),
+	}
+
+	acctCode, err := b.CreateAndPersistMultisigAccountConfig(coinpkg.CodeBTC, "", 2, keyInfos, ks)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(acctCode), "v0-55555555-btc-multisig-"))
+	persistedAccount := b.Config().AccountsConfig().Lookup(acctCode)
+	require.NotNil(t, persistedAccount)
+	require.Equal(t, "Bitcoin 2-of-2", persistedAccount.Name)
+	require.Nil(t, persistedAccount.Watch)
+	require.Len(t, persistedAccount.SigningConfigurations, 1)
+	multisig := persistedAccount.SigningConfigurations[0].BitcoinMultisig
+	require.NotNil(t, multisig)
+	require.Equal(t, 2, multisig.Threshold)
+	require.Equal(t, 1, multisig.OurKeyIndex)
+	// The account is loaded, even if the keystore can't sign for it.
+	require.NotNil(t, b.Accounts().lookup(acctCode))
+
+	// Adding it again fails.
+	_, err = b.CreateAndPersistMultisigAccountConfig(coinpkg.CodeBTC, "", 2, keyInfos, ks)
+	require.Equal(t, errAccountAlreadyExists, errp.Cause(err))
+
+	// Regular accounts are not affected.
+	acctCode, err = b.CreateAndPersistAccountConfig(coinpkg.CodeBTC, "bitcoin 2", ks)
+	require.NoError(t, err)
+	require.Equal(t, "v0-55555555-btc-1", string(acctCode))
+
+	// The keystore is not a cosigner.
+	_, err = b.CreateAndPersistMultisigAccountConfig(coinpkg.CodeBTC, "", 1, keyInfos[:1], ks)
+	require.Error(t, err)
+
+	// The xpub does not belong to the keystore.
+	wrongKeyInfo := keyInfo(rootFingerprint1, keystoreHelper2())
+	_, err = b.CreateAndPersistMultisigAccountConfig(
+		coinpkg.CodeBTC, "", 1, []signing.KeyInfo{keyInfos[0], wrongKeyInfo}, ks)
+	require.Error(t, err)
+
+	// Not supported for Ethereum.
+	_, err = b.CreateAndPersistMultisigAccountConfig(coinpkg.CodeETH, "", 2, keyInfos, ks)
+	require.Error(t, err)
+}
+
 func TestCreateAndAddAccount(t *testing.T) {
 	b := newBackend(t, testnetDisabled, regtestDisabled)
 	defer b.Close()
@@ -0,0 +1,156 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/addressbook"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// normalizeContactAddress validates the address of a contact of the given coin and returns it in
+// the format stored in the address book, so that the same address always matches.
+func (backend *Backend) normalizeContactAddress(
+	coinCode coinpkg.Code, address string, erc20Token coinpkg.Code) (string, error) {
+	address = strings.TrimSpace(address)
+	coin, err := backend.Coin(coinCode)
+	if err != nil {
+		return "", err
+	}
+	switch specificCoin := coin.(type) {
+	case *btc.Coin:
+		if erc20Token != "" {
+			return "", errp.New("Only Ethereum contacts can have an ERC20 token")
+		}
+		return specificCoin.NormalizeAddress(address)
+	case *eth.Coin:
+		if specificCoin.ERC20Token() != nil {
+			return "", errp.New("ERC20 token contacts must be added to the Ethereum address book")
+		}
+		if erc20Token != "" && (coinCode != coinpkg.CodeETH || backend.erc20TokenByCode(erc20Token) == nil) {
+			return "", errp.Newf("Unknown ERC20 token %s", erc20Token)
+		}
+		if !eth.IsValidEthAddress(address) {
+			return "", errp.WithStack(errors.ErrInvalidAddress)
+		}
+		return ethcommon.HexToAddress(address).Hex(), nil
+	default:
+		return "", errp.Newf("Unsupported coin %s", coinCode)
+	}
+}
+
+// Contacts returns the address book entries of the given coin, sorted by name.
+func (backend *Backend) Contacts(coinCode coinpkg.Code) []*addressbook.Contact {
+	return backend.addressBook.Contacts(coinCode)
+}
+
+// AddContact adds an address to the address book of the given coin. erc20Token is optional and
+// restricts an Ethereum contact to the ERC20 token with the given code.
+func (backend *Backend) AddContact(
+	coinCode coinpkg.Code, name string, address string, erc20Token coinpkg.Code) (*addressbook.Contact, error) {
+	address, err := backend.normalizeContactAddress(coinCode, address, erc20Token)
+	if err != nil {
+		return nil, err
+	}
+	return backend.addressBook.Add(addressbook.Contact{
+		CoinCode:   coinCode,
+		Name:       strings.TrimSpace(name),
+		Address:    address,
+		ERC20Token: erc20Token,
+	})
+}
+
+// UpdateContact changes the name, address and ERC20 token of the contact with the given ID. The
+// address proof of the contact is removed if the address changes.
+func (backend *Backend) UpdateContact(
+	id string, name string, address string, erc20Token coinpkg.Code) (*addressbook.Contact, error) {
+	contact := backend.addressBook.Contact(id)
+	if contact == nil {
+		return nil, errp.Newf("Could not find contact %s", id)
+	}
+	address, err := backend.normalizeContactAddress(contact.CoinCode, address, erc20Token)
+	if err != nil {
+		return nil, err
+	}
+	contact.Name = strings.TrimSpace(name)
+	contact.Address = address
+	contact.ERC20Token = erc20Token
+	return backend.addressBook.Update(*contact)
+}
+
+// RemoveContact removes the contact with the given ID from the address book.
+func (backend *Backend) RemoveContact(id string) error {
+	return backend.addressBook.Remove(id)
+}
+
+// VerifyContact checks that the signature of the message proves control over the address of the
+// contact with the given ID, and records the proof in the address book. The signature is in the
+// format returned by `keystore.SignBTCMessage()` and `keystore.SignETHMessage()`, which is also
+// the format of AOPP proofs.
+func (backend *Backend) VerifyContact(id string, message string, signature []byte) (*addressbook.Contact, error) {
+	contact := backend.addressBook.Contact(id)
+	if contact == nil {
+		return nil, errp.Newf("Could not find contact %s", id)
+	}
+	coin, err := backend.Coin(contact.CoinCode)
+	if err != nil {
+		return nil, err
+	}
+	switch specificCoin := coin.(type) {
+	case *btc.Coin:
+		err = addressbook.VerifyBTCMessage(specificCoin.Net(), contact.Address, message, signature)
+	case *eth.Coin:
+		err = addressbook.VerifyETHMessage(contact.Address, message, signature)
+	default:
+		err = errp.Newf("Unsupported coin %s", contact.CoinCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	proof := &addressbook.Proof{
+		Message:    message,
+		Signature:  base64.StdEncoding.EncodeToString(signature),
+		VerifiedAt: time.Now().UTC(),
+	}
+	if err := backend.addressBook.SetProof(id, contact.Address, proof); err != nil {
+		return nil, err
+	}
+	contact.Proof = proof
+	return contact, nil
+}
+
+// lookupContact returns the address book entry matching the recipient address when sending the
+// given coin, or nil if there is none.
+func (backend *Backend) lookupContact(coin coinpkg.Coin, address string) *addressbook.Contact {
+	coinCode := coin.Code()
+	var erc20Token coinpkg.Code
+	if ethCoin, ok := coin.(*eth.Coin); ok && ethCoin.ERC20Token() != nil {
+		// All supported ERC20 tokens live on Ethereum mainnet.
+		coinCode, erc20Token = coinpkg.CodeETH, coin.Code()
+	}
+	address, err := backend.normalizeContactAddress(coinCode, address, erc20Token)
+	if err != nil {
+		return nil
+	}
+	return backend.addressBook.Lookup(coinCode, erc20Token, address)
+}
@@ -0,0 +1,326 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package addressbook provides functionality to retrieve and store the contacts the user sends
+// coins to.
+package addressbook
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/random"
+)
+
+// MaxNameLen is the maximum length of a contact name.
+const MaxNameLen = 256
+
+// Proof records that the owner of the address of a contact proved control over it by signing a
+// message, for example in the same format as an AOPP proof or a message signed with the BitBox02.
+type Proof struct {
+	Message string `json:"message"`
+	// Signature is the base64 encoded signature of the message.
+	Signature  string    `json:"signature"`
+	VerifiedAt time.Time `json:"verifiedAt"`
+}
+
+// Contact is an entry of the address book.
+type Contact struct {
+	ID       string       `json:"id"`
+	CoinCode coinpkg.Code `json:"coinCode"`
+	Name     string       `json:"name"`
+	Address  string       `json:"address"`
+	// ERC20Token optionally restricts an ETH contact to the ERC20 token with this coin code. If
+	// empty, the contact applies to ETH and all tokens.
+	ERC20Token coinpkg.Code `json:"erc20Token,omitempty"`
+	// Proof is set if the address was confirmed by a signed message.
+	Proof *Proof `json:"proof,omitempty"`
+}
+
+// Data is the address book JSON data serialized to disk.
+type Data struct {
+	// Contacts maps coin codes to the contacts of that coin.
+	Contacts map[coinpkg.Code][]*Contact `json:"contacts"`
+}
+
+// read deserializes the json file into the address book data. If the file does not exist yet, no
+// error is returned, and empty data is returned.
+func read(filename string) (*Data, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Data{Contacts: map[coinpkg.Code][]*Contact{}}, nil
+		}
+		return nil, errp.WithStack(err)
+	}
+	defer file.Close() //nolint:errcheck
+	var data Data
+	if err := json.NewDecoder(file).Decode(&data); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	if data.Contacts == nil {
+		data.Contacts = map[coinpkg.Code][]*Contact{}
+	}
+	return &data, nil
+}
+
+func write(data *Data, filename string) error {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return errp.WithStack(err)
+	}
+	return nil
+}
+
+// AddressBook holds the contacts of all coins. Addresses are compared as is, so they must be
+// normalized by the caller, e.g. to the checksummed format for Ethereum.
+type AddressBook struct {
+	filename string
+	data     *Data
+	dataMu   sync.RWMutex
+}
+
+// Load makes a new AddressBook instance, already pre-loading all contacts into RAM. If the file
+// does not exist, no error is returned. Returns an error for other kinds of file read errors.
+func Load(filename string) (*AddressBook, error) {
+	data, err := read(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressBook{
+		filename: filename,
+		data:     data,
+	}, nil
+}
+
+func validateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errp.New("Name cannot be empty")
+	}
+	if len(name) > MaxNameLen {
+		return errp.Newf("Length of name must be smaller than %d. Got %d", MaxNameLen, len(name))
+	}
+	return nil
+}
+
+// find returns the contact with the given ID and its index in the contacts of its coin. The
+// dataMu lock must be held.
+func (addressBook *AddressBook) find(id string) (*Contact, int) {
+	for _, contacts := range addressBook.data.Contacts {
+		for index, contact := range contacts {
+			if contact.ID == id {
+				return contact, index
+			}
+		}
+	}
+	return nil, -1
+}
+
+// lookup returns the contact with exactly the given coin code, address and token. The dataMu lock
+// must be held.
+func (addressBook *AddressBook) lookup(coinCode coinpkg.Code, address string, erc20Token coinpkg.Code) *Contact {
+	for _, contact := range addressBook.data.Contacts[coinCode] {
+		if contact.Address == address && contact.ERC20Token == erc20Token {
+			return contact
+		}
+	}
+	return nil
+}
+
+// Contacts returns copies of the contacts of the given coin, sorted by name.
+func (addressBook *AddressBook) Contacts(coinCode coinpkg.Code) []*Contact {
+	addressBook.dataMu.RLock()
+	defer addressBook.dataMu.RUnlock()
+
+	result := []*Contact{}
+	for _, contact := range addressBook.data.Contacts[coinCode] {
+		contactCopy := *contact
+		result = append(result, &contactCopy)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name)
+	})
+	return result
+}
+
+// AllContacts returns copies of the contacts of all coins, sorted by coin code and name.
+func (addressBook *AddressBook) AllContacts() []*Contact {
+	addressBook.dataMu.RLock()
+	coinCodes := make([]coinpkg.Code, 0, len(addressBook.data.Contacts))
+	for coinCode := range addressBook.data.Contacts {
+		coinCodes = append(coinCodes, coinCode)
+	}
+	addressBook.dataMu.RUnlock()
+
+	sort.Slice(coinCodes, func(i, j int) bool { return coinCodes[i] < coinCodes[j] })
+	result := []*Contact{}
+	for _, coinCode := range coinCodes {
+		result = append(result, addressBook.Contacts(coinCode)...)
+	}
+	return result
+}
+
+// Contact returns a copy of the contact with the given ID, or nil if it does not exist.
+func (addressBook *AddressBook) Contact(id string) *Contact {
+	addressBook.dataMu.RLock()
+	defer addressBook.dataMu.RUnlock()
+
+	contact, _ := addressBook.find(id)
+	if contact == nil {
+		return nil
+	}
+	contactCopy := *contact
+	return &contactCopy
+}
+
+// Lookup returns a copy of the contact matching the address when sending the given coin. For
+// ERC20 tokens, erc20Token is the token code and coinCode the code of the chain the token lives
+// on. Contacts restricted to the token take priority over contacts without token restriction.
+// Returns nil if there is no matching contact.
+func (addressBook *AddressBook) Lookup(coinCode coinpkg.Code, erc20Token coinpkg.Code, address string) *Contact {
+	addressBook.dataMu.RLock()
+	defer addressBook.dataMu.RUnlock()
+
+	contact := addressBook.lookup(coinCode, address, erc20Token)
+	if contact == nil && erc20Token != "" {
+		contact = addressBook.lookup(coinCode, address, "")
+	}
+	if contact == nil {
+		return nil
+	}
+	contactCopy := *contact
+	return &contactCopy
+}
+
+// Add adds a new contact and returns it with its newly assigned ID. The ID and proof of the given
+// contact are ignored. Returns an error if the address is already in the address book.
+func (addressBook *AddressBook) Add(contact Contact) (*Contact, error) {
+	addressBook.dataMu.Lock()
+	defer addressBook.dataMu.Unlock()
+
+	if err := validateName(contact.Name); err != nil {
+		return nil, err
+	}
+	if addressBook.lookup(contact.CoinCode, contact.Address, contact.ERC20Token) != nil {
+		return nil, errp.New("The address is already in the address book")
+	}
+	contact.ID = hex.EncodeToString(random.BytesOrPanic(16))
+	contact.Proof = nil
+	newContact := contact
+	contacts, ok := addressBook.data.Contacts[contact.CoinCode]
+	addressBook.data.Contacts[contact.CoinCode] = append(contacts, &newContact)
+	if err := write(addressBook.data, addressBook.filename); err != nil {
+		// Keep the contacts in memory in line with the file.
+		if ok {
+			addressBook.data.Contacts[contact.CoinCode] = contacts
+		} else {
+			delete(addressBook.data.Contacts, contact.CoinCode)
+		}
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// Update updates the name, address and token of the contact with the ID of the given contact.
+// The coin of a contact cannot be changed. The proof is removed if the address changes.
+func (addressBook *AddressBook) Update(contact Contact) (*Contact, error) {
+	addressBook.dataMu.Lock()
+	defer addressBook.dataMu.Unlock()
+
+	if err := validateName(contact.Name); err != nil {
+		return nil, err
+	}
+	existing, _ := addressBook.find(contact.ID)
+	if existing == nil {
+		return nil, errp.Newf("Could not find contact %s", contact.ID)
+	}
+	if existing.CoinCode != contact.CoinCode {
+		return nil, errp.New("The coin of a contact cannot be changed")
+	}
+	if other := addressBook.lookup(contact.CoinCode, contact.Address, contact.ERC20Token); other != nil && other != existing {
+		return nil, errp.New("The address is already in the address book")
+	}
+	previous := *existing
+	if existing.Address != contact.Address {
+		existing.Proof = nil
+	}
+	existing.Name = contact.Name
+	existing.Address = contact.Address
+	existing.ERC20Token = contact.ERC20Token
+	if err := write(addressBook.data, addressBook.filename); err != nil {
+		*existing = previous
+		return nil, err
+	}
+	contactCopy := *existing
+	return &contactCopy, nil
+}
+
+// Remove removes the contact with the given ID.
+func (addressBook *AddressBook) Remove(id string) error {
+	addressBook.dataMu.Lock()
+	defer addressBook.dataMu.Unlock()
+
+	contact, index := addressBook.find(id)
+	if contact == nil {
+		return errp.Newf("Could not find contact %s", id)
+	}
+	previous := addressBook.data.Contacts[contact.CoinCode]
+	contacts := append(append([]*Contact{}, previous[:index]...), previous[index+1:]...)
+	if len(contacts) == 0 {
+		delete(addressBook.data.Contacts, contact.CoinCode)
+	} else {
+		addressBook.data.Contacts[contact.CoinCode] = contacts
+	}
+	if err := write(addressBook.data, addressBook.filename); err != nil {
+		addressBook.data.Contacts[contact.CoinCode] = previous
+		return err
+	}
+	return nil
+}
+
+// SetProof stores the proof of the address of the contact with the given ID. The proof must have
+// been verified by the caller for the given address. Returns an error if the address of the contact
+// is not the given address anymore, e.g. because it was changed while the proof was verified.
+func (addressBook *AddressBook) SetProof(id string, address string, proof *Proof) error {
+	addressBook.dataMu.Lock()
+	defer addressBook.dataMu.Unlock()
+
+	contact, _ := addressBook.find(id)
+	if contact == nil {
+		return errp.Newf("Could not find contact %s", id)
+	}
+	if contact.Address != address {
+		return errp.New("The address of the contact changed while it was being verified")
+	}
+	previous := contact.Proof
+	contact.Proof = proof
+	if err := write(addressBook.data, addressBook.filename); err != nil {
+		contact.Proof = previous
+		return err
+	}
+	return nil
+}
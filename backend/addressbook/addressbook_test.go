@@ -0,0 +1,209 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package addressbook
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/test"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	ethaccounts "github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddressBook(t *testing.T) {
+	filename := test.TstTempFile("addressbook")
+	addressBook, err := Load(filename)
+	require.NoError(t, err)
+	require.Empty(t, addressBook.Contacts(coinpkg.CodeBTC))
+
+	supplier, err := addressBook.Add(Contact{
+		CoinCode: coinpkg.CodeBTC,
+		Name:     "Supplier",
+		Address:  "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, supplier.ID)
+	landlord, err := addressBook.Add(Contact{
+		CoinCode: coinpkg.CodeBTC,
+		Name:     "landlord",
+		Address:  "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+	})
+	require.NoError(t, err)
+	_, err = addressBook.Add(Contact{
+		CoinCode: coinpkg.CodeBTC,
+		Name:     "Duplicate",
+		Address:  "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh",
+	})
+	require.Error(t, err)
+	_, err = addressBook.Add(Contact{CoinCode: coinpkg.CodeBTC, Name: " ", Address: "address"})
+	require.Error(t, err)
+	_, err = addressBook.Add(Contact{
+		CoinCode: coinpkg.CodeBTC,
+		Name:     strings.Repeat("a", MaxNameLen+1),
+		Address:  "address",
+	})
+	require.Error(t, err)
+
+	// Sorted by name, case insensitive.
+	require.Equal(t, []*Contact{landlord, supplier}, addressBook.Contacts(coinpkg.CodeBTC))
+	require.Empty(t, addressBook.Contacts(coinpkg.CodeLTC))
+
+	proof := &Proof{Message: "message", Signature: "signature", VerifiedAt: time.Unix(1700000000, 0).UTC()}
+	// The proof is refused if the address changed in the meantime.
+	require.Error(t, addressBook.SetProof(supplier.ID, landlord.Address, proof))
+	require.Nil(t, addressBook.Contact(supplier.ID).Proof)
+	require.NoError(t, addressBook.SetProof(supplier.ID, supplier.Address, proof))
+	require.Equal(t, proof, addressBook.Contact(supplier.ID).Proof)
+	require.Error(t, addressBook.SetProof("unknown", supplier.Address, proof))
+
+	// Renaming keeps the proof, changing the address removes it.
+	updated, err := addressBook.Update(Contact{
+		ID:       supplier.ID,
+		CoinCode: coinpkg.CodeBTC,
+		Name:     "Supplier Inc.",
+		Address:  supplier.Address,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Supplier Inc.", updated.Name)
+	require.Equal(t, proof, updated.Proof)
+	_, err = addressBook.Update(Contact{
+		ID:       supplier.ID,
+		CoinCode: coinpkg.CodeBTC,
+		Name:     "Supplier Inc.",
+		Address:  landlord.Address,
+	})
+	require.Error(t, err)
+	_, err = addressBook.Update(Contact{
+		ID:       supplier.ID,
+		CoinCode: coinpkg.CodeLTC,
+		Name:     "Supplier Inc.",
+		Address:  supplier.Address,
+	})
+	require.Error(t, err)
+	updated, err = addressBook.Update(Contact{
+		ID:       supplier.ID,
+		CoinCode: coinpkg.CodeBTC,
+		Name:     "Supplier Inc.",
+		Address:  "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l",
+	})
+	require.NoError(t, err)
+	require.Nil(t, updated.Proof)
+
+	// Contacts are persisted.
+	reloaded, err := Load(filename)
+	require.NoError(t, err)
+	require.Equal(t, addressBook.AllContacts(), reloaded.AllContacts())
+
+	require.NoError(t, addressBook.Remove(landlord.ID))
+	require.Error(t, addressBook.Remove(landlord.ID))
+	require.Nil(t, addressBook.Contact(landlord.ID))
+	require.Equal(t, []*Contact{updated}, addressBook.Contacts(coinpkg.CodeBTC))
+}
+
+func TestAddressBookWriteError(t *testing.T) {
+	// The folder of the file does not exist, so writing it fails.
+	addressBook, err := Load(filepath.Join(t.TempDir(), "missing", "addressbook.json"))
+	require.NoError(t, err)
+
+	_, err = addressBook.Add(Contact{
+		CoinCode: coinpkg.CodeBTC,
+		Name:     "Supplier",
+		Address:  "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh",
+	})
+	require.Error(t, err)
+	require.Empty(t, addressBook.AllContacts())
+}
+
+func TestLookup(t *testing.T) {
+	addressBook, err := Load(test.TstTempFile("addressbook"))
+	require.NoError(t, err)
+
+	const address = "0x773A0c5dd7e2B5fE8fD6e7E6B1D1DB7E8Ab0f7f5"
+	anyToken, err := addressBook.Add(Contact{CoinCode: coinpkg.CodeETH, Name: "Any token", Address: address})
+	require.NoError(t, err)
+	require.Equal(t, anyToken, addressBook.Lookup(coinpkg.CodeETH, "", address))
+	require.Equal(t, anyToken, addressBook.Lookup(coinpkg.CodeETH, "eth-erc20-usdt", address))
+	require.Nil(t, addressBook.Lookup(coinpkg.CodeBTC, "", address))
+	require.Nil(t, addressBook.Lookup(coinpkg.CodeETH, "", "0x0000000000000000000000000000000000000000"))
+
+	usdt, err := addressBook.Add(Contact{
+		CoinCode:   coinpkg.CodeETH,
+		Name:       "USDT only",
+		Address:    address,
+		ERC20Token: "eth-erc20-usdt",
+	})
+	require.NoError(t, err)
+	require.Equal(t, usdt, addressBook.Lookup(coinpkg.CodeETH, "eth-erc20-usdt", address))
+	require.Equal(t, anyToken, addressBook.Lookup(coinpkg.CodeETH, "eth-erc20-usdc", address))
+	require.Equal(t, anyToken, addressBook.Lookup(coinpkg.CodeETH, "", address))
+}
+
+func TestVerifyBTCMessage(t *testing.T) {
+	net := &chaincfg.MainNetParams
+	privateKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{1}, 32))
+	pubKeyHash := btcutil.Hash160(privateKey.PubKey().SerializeCompressed())
+	p2wpkh, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, net)
+	require.NoError(t, err)
+	p2pkh, err := btcutil.NewAddressPubKeyHash(pubKeyHash, net)
+	require.NoError(t, err)
+	p2tr, err := btcutil.NewAddressTaproot(make([]byte, 32), net)
+	require.NoError(t, err)
+
+	const message = "I confirm that I own this address"
+	var buf bytes.Buffer
+	require.NoError(t, wire.WriteVarString(&buf, 0, "Bitcoin Signed Message:\n"))
+	require.NoError(t, wire.WriteVarString(&buf, 0, message))
+	signature := ecdsa.SignCompact(privateKey, chainhash.DoubleHashB(buf.Bytes()), true)
+
+	require.NoError(t, VerifyBTCMessage(net, p2wpkh.EncodeAddress(), message, signature))
+	require.NoError(t, VerifyBTCMessage(net, p2pkh.EncodeAddress(), message, signature))
+	require.Error(t, VerifyBTCMessage(net, p2wpkh.EncodeAddress(), "other message", signature))
+	require.Error(t, VerifyBTCMessage(net, p2tr.EncodeAddress(), message, signature))
+	require.Error(t, VerifyBTCMessage(net, p2wpkh.EncodeAddress(), message, signature[1:]))
+	require.Error(t, VerifyBTCMessage(net, "invalid", message, signature))
+
+	// BIP-137 header for P2WPKH.
+	bip137Signature := append([]byte{signature[0] + 8}, signature[1:]...)
+	require.NoError(t, VerifyBTCMessage(net, p2wpkh.EncodeAddress(), message, bip137Signature))
+}
+
+func TestVerifyETHMessage(t *testing.T) {
+	privateKey, err := crypto.ToECDSA(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+
+	const message = "I confirm that I own this address"
+	signature, err := crypto.Sign(ethaccounts.TextHash([]byte(message)), privateKey)
+	require.NoError(t, err)
+	signature[64] += 27
+
+	require.NoError(t, VerifyETHMessage(address, message, signature))
+	require.NoError(t, VerifyETHMessage(strings.ToLower(address), message, signature))
+	require.Error(t, VerifyETHMessage(address, "other message", signature))
+	require.Error(t, VerifyETHMessage("0x0000000000000000000000000000000000000000", message, signature))
+	require.Error(t, VerifyETHMessage("invalid", message, signature))
+}
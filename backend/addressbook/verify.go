@@ -0,0 +1,118 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package addressbook
+
+import (
+	"bytes"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	ethaccounts "github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// errInvalidSignature is returned if a signature does not prove control over an address.
+var errInvalidSignature = errp.New("The signature does not match the address")
+
+// VerifyBTCMessage verifies that the 65 byte compact signature of the message was made with the
+// key of the given P2PKH, P2WPKH or P2SH-P2WPKH address. Both the Electrum format, which is used by
+// the BitBox02 and in AOPP proofs, and the BIP-137 recovery header bytes are accepted.
+func VerifyBTCMessage(net *chaincfg.Params, address string, message string, signature []byte) error {
+	decodedAddress, err := btcutil.DecodeAddress(address, net)
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	if len(signature) != 65 {
+		return errp.New("The signature must be 65 bytes")
+	}
+	compactSignature := append([]byte{}, signature...)
+	switch header := compactSignature[0]; {
+	case header >= 35 && header <= 38:
+		// BIP-137 P2SH-P2WPKH.
+		compactSignature[0] -= 4
+	case header >= 39 && header <= 42:
+		// BIP-137 P2WPKH.
+		compactSignature[0] -= 8
+	}
+	var buf bytes.Buffer
+	if err := wire.WriteVarString(&buf, 0, "Bitcoin Signed Message:\n"); err != nil {
+		return errp.WithStack(err)
+	}
+	if err := wire.WriteVarString(&buf, 0, message); err != nil {
+		return errp.WithStack(err)
+	}
+	pubKey, compressed, err := ecdsa.RecoverCompact(compactSignature, chainhash.DoubleHashB(buf.Bytes()))
+	if err != nil {
+		return errp.WithStack(errInvalidSignature)
+	}
+	var candidates []btcutil.Address
+	if compressed {
+		pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+		p2pkh, err := btcutil.NewAddressPubKeyHash(pubKeyHash, net)
+		if err != nil {
+			return errp.WithStack(err)
+		}
+		p2wpkh, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, net)
+		if err != nil {
+			return errp.WithStack(err)
+		}
+		redeemScript := append([]byte{0x00, 0x14}, pubKeyHash...)
+		p2shP2wpkh, err := btcutil.NewAddressScriptHash(redeemScript, net)
+		if err != nil {
+			return errp.WithStack(err)
+		}
+		candidates = []btcutil.Address{p2pkh, p2wpkh, p2shP2wpkh}
+	} else {
+		p2pkh, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(pubKey.SerializeUncompressed()), net)
+		if err != nil {
+			return errp.WithStack(err)
+		}
+		candidates = []btcutil.Address{p2pkh}
+	}
+	for _, candidate := range candidates {
+		if candidate.EncodeAddress() == decodedAddress.EncodeAddress() {
+			return nil
+		}
+	}
+	return errp.WithStack(errInvalidSignature)
+}
+
+// VerifyETHMessage verifies that the 65 byte signature of the message according to EIP-191
+// (personal_sign) was made with the key of the given address.
+func VerifyETHMessage(address string, message string, signature []byte) error {
+	if !common.IsHexAddress(address) {
+		return errp.New("Invalid address")
+	}
+	if len(signature) != 65 {
+		return errp.New("The signature must be 65 bytes")
+	}
+	sig := append([]byte{}, signature...)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pubKey, err := crypto.SigToPub(ethaccounts.TextHash([]byte(message)), sig)
+	if err != nil {
+		return errp.WithStack(errInvalidSignature)
+	}
+	if crypto.PubkeyToAddress(*pubKey) != common.HexToAddress(address) {
+		return errp.WithStack(errInvalidSignature)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/random"
+)
+
+// APITokens returns the named API tokens.
+func (backend *Backend) APITokens() []config.APIToken {
+	return backend.config.AppConfig().Backend.APITokens
+}
+
+// CreateAPIToken creates and persists a named API token with the given scopes, optionally
+// restricted to some accounts and expiring at the given time. The token is returned, and can't be
+// retrieved later, as only its hash is stored.
+func (backend *Backend) CreateAPIToken(
+	name string,
+	scopes []config.APITokenScope,
+	accountCodes []accountsTypes.Code,
+	expires *time.Time,
+) (string, *config.APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, errp.New("The name of the token is missing")
+	}
+	if len(scopes) == 0 {
+		return "", nil, errp.New("The token needs at least one scope")
+	}
+	for _, scope := range scopes {
+		valid := false
+		for _, knownScope := range config.APITokenScopes {
+			valid = valid || scope == knownScope
+		}
+		if !valid {
+			return "", nil, errp.Newf("Unknown scope %q", scope)
+		}
+	}
+	for _, code := range accountCodes {
+		if backend.Accounts().lookup(code) == nil {
+			return "", nil, errp.Newf("Unknown account %q", code)
+		}
+	}
+	if expires != nil && !expires.After(time.Now()) {
+		return "", nil, errp.New("The expiry is in the past")
+	}
+	if accountCodes == nil {
+		accountCodes = []accountsTypes.Code{}
+	}
+
+	secret := hex.EncodeToString(random.BytesOrPanic(32))
+	token := config.APIToken{
+		ID:           hex.EncodeToString(random.BytesOrPanic(8)),
+		Name:         name,
+		Hash:         config.HashAPIToken(secret),
+		Scopes:       scopes,
+		AccountCodes: accountCodes,
+		Created:      time.Now(),
+		Expires:      expires,
+	}
+	err := backend.config.ModifyAppConfig(func(appConfig *config.AppConfig) error {
+		appConfig.Backend.APITokens = append(appConfig.Backend.APITokens, token)
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	backend.log.WithField("id", token.ID).WithField("scopes", scopes).Info("Created API token")
+	return secret, &token, nil
+}
+
+// RevokeAPIToken deletes the API token with the given ID, so that it can't be used anymore.
+func (backend *Backend) RevokeAPIToken(id string) error {
+	return backend.config.ModifyAppConfig(func(appConfig *config.AppConfig) error {
+		tokens := []config.APIToken{}
+		for _, token := range appConfig.Backend.APITokens {
+			if token.ID != id {
+				tokens = append(tokens, token)
+			}
+		}
+		if len(tokens) == len(appConfig.Backend.APITokens) {
+			return errp.Newf("Unknown API token %q", id)
+		}
+		appConfig.Backend.APITokens = tokens
+		backend.log.WithField("id", id).Info("Revoked API token")
+		return nil
+	})
+}
+
+// LookupAPIToken returns the API token matching the given token, or nil if there is none. Expired
+// tokens are returned as well.
+func (backend *Backend) LookupAPIToken(secret string) *config.APIToken {
+	hash := []byte(config.HashAPIToken(secret))
+	for _, token := range backend.APITokens() {
+		if subtle.ConstantTimeCompare(hash, []byte(token.Hash)) == 1 {
+			return &token
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITokens(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+
+	bitbox02LikeKeystore := makeBitBox02Multi()
+	bitbox02LikeKeystore.RootFingerprintFunc = func() ([]byte, error) {
+		return rootFingerprint1, nil
+	}
+	b.registerKeystore(bitbox02LikeKeystore)
+	const accountCode accountsTypes.Code = "v0-55555555-btc-0"
+
+	require.Empty(t, b.APITokens())
+	readOnly := []config.APITokenScope{config.APITokenScopeReadBalances, config.APITokenScopeReadTransactions}
+	past := time.Now().Add(-time.Hour)
+
+	_, _, err := b.CreateAPIToken(" ", readOnly, nil, nil)
+	require.Error(t, err)
+	_, _, err = b.CreateAPIToken("Accounting", nil, nil, nil)
+	require.Error(t, err)
+	_, _, err = b.CreateAPIToken("Accounting", []config.APITokenScope{"unknown"}, nil, nil)
+	require.Error(t, err)
+	_, _, err = b.CreateAPIToken("Accounting", readOnly, []accountsTypes.Code{"v0-55555555-btc-99"}, nil)
+	require.Error(t, err)
+	_, _, err = b.CreateAPIToken("Accounting", readOnly, nil, &past)
+	require.Error(t, err)
+	require.Empty(t, b.APITokens())
+
+	secret, token, err := b.CreateAPIToken("Accounting", readOnly, []accountsTypes.Code{accountCode}, nil)
+	require.NoError(t, err)
+	require.Len(t, secret, 64)
+	require.Equal(t, "Accounting", token.Name)
+	require.Equal(t, config.HashAPIToken(secret), token.Hash)
+	require.True(t, token.HasScope(config.APITokenScopeReadBalances))
+	require.False(t, token.HasScope(config.APITokenScopeSign))
+	require.True(t, token.AllowsAccount(accountCode))
+	require.False(t, token.AllowsAccount("v0-55555555-btc-1"))
+	require.False(t, token.Expired(time.Now().Add(365*24*time.Hour)))
+
+	future := time.Now().Add(time.Hour)
+	adminSecret, adminToken, err := b.CreateAPIToken("Admin", []config.APITokenScope{config.APITokenScopeAdmin}, nil, &future)
+	require.NoError(t, err)
+	require.True(t, adminToken.HasScope(config.APITokenScopeSign))
+	require.True(t, adminToken.AllowsAccount("v0-55555555-btc-1"))
+	require.False(t, adminToken.Expired(time.Now()))
+	require.True(t, adminToken.Expired(future))
+	require.Len(t, b.APITokens(), 2)
+
+	require.Equal(t, token.ID, b.LookupAPIToken(secret).ID)
+	require.Equal(t, adminToken.ID, b.LookupAPIToken(adminSecret).ID)
+	require.Nil(t, b.LookupAPIToken("wrong"))
+	require.Nil(t, b.LookupAPIToken(token.Hash))
+
+	tokens := b.APITokens()
+	require.Error(t, b.RevokeAPIToken("unknown"))
+	require.NoError(t, b.RevokeAPIToken(token.ID))
+	require.Nil(t, b.LookupAPIToken(secret))
+	require.Len(t, b.APITokens(), 1)
+	require.Equal(t, adminToken.ID, b.APITokens()[0].ID)
+	// The tokens returned before are not modified.
+	require.Equal(t, token.ID, tokens[0].ID)
+	require.Equal(t, adminToken.ID, tokens[1].ID)
+}
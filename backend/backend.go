@@ -16,6 +16,7 @@
 package backend
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -23,10 +24,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
 	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/addressbook"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/arguments"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/banners"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
@@ -35,6 +38,9 @@ import (
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/etherscan"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/logscan"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/node"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/rpcclient"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/ltc"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/devices/bitbox"
@@ -44,7 +50,10 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/devices/usb"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore/software"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/paymenturi"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/scheduler"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/webhooks"
 	utilConfig "github.com/BitBoxSwiss/bitbox-wallet-app/util/config"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/locker"
@@ -62,6 +71,9 @@ func init() {
 	electrum.SetClientSoftwareVersion(Version)
 }
 
+// ethNodeCheckTimeout is the timeout when checking the connection to an Ethereum node.
+const ethNodeCheckTimeout = 10 * time.Second
+
 // fixedURLWhitelist is always allowed by SystemOpen, in addition to some
 // adhoc URLs. See SystemOpen for details.
 var fixedURLWhitelist = []string{
@@ -191,6 +203,34 @@ type Backend struct {
 
 	notifier *Notifier
 
+	addressBook *addressbook.AddressBook
+
+	scheduler *scheduler.Scheduler
+	// scheduledPaymentProposals maps the IDs of due scheduled payments to the tx proposals prepared
+	// for them since the keystore connected.
+	scheduledPaymentProposals map[string]*ScheduledPaymentProposal
+	// scheduledPaymentProposalsGeneration is incremented whenever proposals are discarded, so that
+	// proposals which were being prepared at that time are not stored.
+	scheduledPaymentProposalsGeneration int
+	scheduledPaymentProposalsMu         sync.RWMutex
+	// scheduledPaymentsCheckMu prevents checking for due scheduled payments concurrently.
+	scheduledPaymentsCheckMu sync.Mutex
+	// scheduledPaymentsSendMu serializes sending and skipping scheduled payments, so that a due
+	// payment is not sent twice.
+	scheduledPaymentsSendMu sync.Mutex
+	// stopScheduledPayments stops the loop checking for due scheduled payments started in Start().
+	stopScheduledPayments context.CancelFunc
+
+	webhooks *webhooks.Dispatcher
+	// webhookTransactionsMu prevents checking the transactions of accounts for webhook events
+	// concurrently.
+	webhookTransactionsMu sync.Mutex
+	// webhookAccountStatuses are the account statuses last reported to the webhooks.
+	webhookAccountStatuses map[accountsTypes.Code]accountStatus
+	webhookAccountStatusMu sync.Mutex
+	// stopWebhooks stops delivering webhook events, started in Start().
+	stopWebhooks context.CancelFunc
+
 	devices map[string]device.Interface
 
 	usbManager *usb.Manager
@@ -204,6 +244,9 @@ type Backend struct {
 
 	aopp AOPP
 
+	// paymentURI is the last payment URI opened with the app, or nil.
+	paymentURI *PaymentURIRequest
+
 	// makeBtcAccount creates a BTC account. In production this is `btc.NewAccount`, but can be
 	// overridden in unit tests for mocking.
 	makeBtcAccount func(*accounts.AccountConfig, *btc.Coin, *types.GapLimits, *logrus.Entry) accounts.Interface
@@ -237,6 +280,23 @@ type Backend struct {
 	testing bool
 }
 
+// loadOrBackUp loads the given file. If it can't be loaded, e.g. because it is corrupt, the error is
+// logged and the file is moved to a backup file, so that it is not overwritten and can be recovered
+// manually, and it is loaded again from scratch. An error is only returned if the file can't be
+// moved away.
+func loadOrBackUp[T any](log *logrus.Entry, filename string, load func(filename string) (T, error)) (T, error) {
+	result, err := load(filename)
+	if err == nil {
+		return result, nil
+	}
+	backupFilename := fmt.Sprintf("%s.%d.bak", filename, time.Now().Unix())
+	log.WithError(err).WithField("backup", backupFilename).Error("Could not load file, moving it to a backup")
+	if renameErr := os.Rename(filename, backupFilename); renameErr != nil {
+		return result, errp.WithStack(err)
+	}
+	return load(filename)
+}
+
 // NewBackend creates a new backend with the given arguments.
 func NewBackend(arguments *arguments.Arguments, environment Environment) (*Backend, error) {
 	log := logging.Get().WithGroup("backend")
@@ -283,6 +343,32 @@ func NewBackend(arguments *arguments.Arguments, environment Environment) (*Backe
 		return nil, err
 	}
 	backend.notifier = notifier
+	addressBook, err := loadOrBackUp(
+		log, filepath.Join(arguments.MainDirectoryPath(), "addressbook.json"), addressbook.Load)
+	if err != nil {
+		return nil, err
+	}
+	backend.addressBook = addressBook
+	scheduledPayments, err := loadOrBackUp(
+		log, filepath.Join(arguments.MainDirectoryPath(), "scheduledpayments.json"), scheduler.Load)
+	if err != nil {
+		return nil, err
+	}
+	backend.scheduler = scheduledPayments
+	webhookDispatcher, err := loadOrBackUp(
+		log, filepath.Join(arguments.MainDirectoryPath(), "webhooks.json"),
+		func(filename string) (*webhooks.Dispatcher, error) {
+			return webhooks.NewDispatcher(
+				filename,
+				func() []config.Webhook { return backend.config.AppConfig().Backend.Webhooks },
+				hclient)
+		})
+	if err != nil {
+		return nil, err
+	}
+	backend.webhooks = webhookDispatcher
+	backend.webhookAccountStatuses = map[accountsTypes.Code]accountStatus{}
+	backend.scheduledPaymentProposals = map[string]*ScheduledPaymentProposal{}
 	backend.socksProxy = backendProxy
 	backend.httpClient = hclient
 	backend.etherScanHTTPClient = ratelimit.FromTransport(hclient.Transport, etherscan.CallInterval)
@@ -483,6 +569,58 @@ func (backend *Backend) DevServers() bool {
 	return backend.arguments.DevServers()
 }
 
+// ethNodeURL returns the configured JSON-RPC node URL of the given Ethereum coin, or an empty
+// string if none is configured.
+func (backend *Backend) ethNodeURL(code coinpkg.Code) string {
+	switch code {
+	case coinpkg.CodeETH:
+		return backend.config.AppConfig().Backend.ETH.NodeURL
+	case coinpkg.CodeSEPETH:
+		return backend.config.AppConfig().Backend.SEPETH.NodeURL
+	default:
+		return ""
+	}
+}
+
+// ethTransactionsSourceSetting returns the configured transactions source of the given Ethereum
+// coin.
+func (backend *Backend) ethTransactionsSourceSetting(code coinpkg.Code) config.ETHTransactionsSource {
+	switch code {
+	case coinpkg.CodeETH:
+		return backend.config.AppConfig().Backend.ETH.TransactionsSource
+	case coinpkg.CodeSEPETH:
+		return backend.config.AppConfig().Backend.SEPETH.TransactionsSource
+	default:
+		return ""
+	}
+}
+
+// ethClients returns the RPC client and the transactions source of the given Ethereum coin. If
+// the user configured an Ethereum node, it is used as the RPC client instead of `etherScan`.
+// Transactions are fetched from EtherScan unless configured otherwise, as scanning the blockchain
+// for them using the node is much slower.
+func (backend *Backend) ethClients(code coinpkg.Code, etherScan *etherscan.EtherScan) (
+	rpcclient.Interface, eth.TransactionsSource) {
+	var client rpcclient.Interface = etherScan
+	var ethNode *node.Node
+	if nodeURL := backend.ethNodeURL(code); nodeURL != "" {
+		backend.log.WithField("code", code).Info("using the configured Ethereum node")
+		ethNode = node.NewNode(nodeURL, backend.httpClient)
+		client = ethNode
+	}
+	switch backend.ethTransactionsSourceSetting(code) {
+	case config.ETHTransactionsSourceNone:
+		return client, nil
+	case config.ETHTransactionsSourceNode:
+		if ethNode != nil {
+			return client, logscan.NewScanner(ethNode, backend.log.WithField("code", code))
+		}
+		backend.log.WithField("code", code).
+			Warning("no Ethereum node configured, falling back to EtherScan for transactions")
+	}
+	return client, etherScan
+}
+
 // Coin returns the coin with the given code or an error if no such coin exists.
 func (backend *Backend) Coin(code coinpkg.Code) (coinpkg.Coin, error) {
 	defer backend.coinsLock.Lock()()
@@ -492,7 +630,7 @@ func (backend *Backend) Coin(code coinpkg.Code) (coinpkg.Coin, error) {
 	}
 	dbFolder := backend.arguments.CacheDirectoryPath()
 
-	erc20Token := erc20TokenByCode(code)
+	erc20Token := backend.erc20TokenByCode(code)
 	btcFormatUnit := backend.config.AppConfig().Backend.BtcUnit
 	switch {
 	case code == coinpkg.CodeRBTC:
@@ -516,21 +654,24 @@ func (backend *Backend) Coin(code coinpkg.Code) (coinpkg.Coin, error) {
 			"https://blockchair.com/litecoin/transaction/", backend.socksProxy)
 	case code == coinpkg.CodeETH:
 		etherScan := etherscan.NewEtherScan("https://api.etherscan.io/api", backend.etherScanHTTPClient)
-		coin = eth.NewCoin(etherScan, code, "Ethereum", "ETH", "ETH", params.MainnetChainConfig,
+		client, transactionsSource := backend.ethClients(code, etherScan)
+		coin = eth.NewCoin(client, code, "Ethereum", "ETH", "ETH", params.MainnetChainConfig,
 			"https://etherscan.io/tx/",
-			etherScan,
+			transactionsSource,
 			nil)
 	case code == coinpkg.CodeSEPETH:
 		etherScan := etherscan.NewEtherScan("https://api-sepolia.etherscan.io/api", backend.etherScanHTTPClient)
-		coin = eth.NewCoin(etherScan, code, "Ethereum Sepolia", "SEPETH", "SEPETH", params.SepoliaChainConfig,
+		client, transactionsSource := backend.ethClients(code, etherScan)
+		coin = eth.NewCoin(client, code, "Ethereum Sepolia", "SEPETH", "SEPETH", params.SepoliaChainConfig,
 			"https://sepolia.etherscan.io/tx/",
-			etherScan,
+			transactionsSource,
 			nil)
 	case erc20Token != nil:
 		etherScan := etherscan.NewEtherScan("https://api.etherscan.io/api", backend.etherScanHTTPClient)
-		coin = eth.NewCoin(etherScan, erc20Token.code, erc20Token.name, erc20Token.unit, "ETH", params.MainnetChainConfig,
+		client, transactionsSource := backend.ethClients(coinpkg.CodeETH, etherScan)
+		coin = eth.NewCoin(client, erc20Token.code, erc20Token.name, erc20Token.unit, "ETH", params.MainnetChainConfig,
 			"https://etherscan.io/tx/",
-			etherScan,
+			transactionsSource,
 			erc20Token.token,
 		)
 	default:
@@ -643,6 +784,14 @@ func (backend *Backend) Start() <-chan interface{} {
 	backend.ratesUpdater.StartCurrentRates()
 	backend.configureHistoryExchangeRates()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	backend.stopScheduledPayments = cancel
+	go backend.scheduledPaymentsLoop(ctx)
+
+	webhooksCtx, stopWebhooks := context.WithCancel(context.Background())
+	backend.stopWebhooks = stopWebhooks
+	go backend.webhooks.Run(webhooksCtx)
+
 	backend.environment.OnAuthSettingChanged(backend.config.AppConfig().Backend.Authentication)
 
 	if backend.DefaultAppConfig().Backend.StartInTestnet {
@@ -734,6 +883,7 @@ func (backend *Backend) registerKeystore(keystore keystore.Keystore) {
 	backend.connectKeystore.onConnect(backend.keystore)
 
 	go backend.maybeAddHiddenUnusedAccounts()
+	go backend.prepareScheduledPayments()
 }
 
 // DeregisterKeystore removes the registered keystore.
@@ -759,6 +909,8 @@ func (backend *Backend) DeregisterKeystore() {
 	backend.initPersistedAccounts()
 	backend.emitAccountsStatusChanged()
 	backend.connectKeystore.onDisconnect()
+	backend.clearScheduledPaymentProposals("")
+	backend.notifyScheduledPaymentsChanged()
 }
 
 // Register registers the given device at this backend.
@@ -790,6 +942,7 @@ func (backend *Backend) Register(theDevice device.Interface) error {
 		return err
 	}
 	theDevice.Observe(backend.Notify)
+	backend.webhookDevice(webhooks.EventDeviceConnected, theDevice)
 
 	// Old-school
 	select {
@@ -820,6 +973,7 @@ func (backend *Backend) Deregister(deviceID string) {
 		backend.onDeviceUninit(deviceID)
 		delete(backend.devices, deviceID)
 		backend.DeregisterKeystore()
+		backend.webhookDevice(webhooks.EventDeviceDisconnected, device)
 
 		// Old-school
 		backend.events <- backendEvent{Type: "devices", Data: "registeredChanged"}
@@ -855,6 +1009,30 @@ func (backend *Backend) CheckElectrumServer(serverInfo *config.ServerInfo) error
 		serverInfo, backend.log, backend.socksProxy.GetTCPProxyDialer())
 }
 
+// CheckETHNode checks that the Ethereum JSON-RPC node at the given URL is reachable and connected
+// to the network of the given coin.
+func (backend *Backend) CheckETHNode(code coinpkg.Code, nodeURL string) error {
+	var chainConfig *params.ChainConfig
+	switch code {
+	case coinpkg.CodeETH:
+		chainConfig = params.MainnetChainConfig
+	case coinpkg.CodeSEPETH:
+		chainConfig = params.SepoliaChainConfig
+	default:
+		return errp.Newf("unknown Ethereum coin code %s", code)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ethNodeCheckTimeout)
+	defer cancel()
+	chainID, err := node.NewNode(nodeURL, backend.httpClient).ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	if chainID.Cmp(chainConfig.ChainID) != 0 {
+		return errp.Newf("the node is connected to chain ID %s, expected %s", chainID, chainConfig.ChainID)
+	}
+	return nil
+}
+
 // RegisterTestKeystore adds a keystore derived deterministically from a PIN, for convenience in
 // devmode.
 func (backend *Backend) RegisterTestKeystore(pin string) {
@@ -896,6 +1074,12 @@ func (backend *Backend) Close() error {
 	errors := []string{}
 
 	backend.ratesUpdater.Stop()
+	if backend.stopScheduledPayments != nil {
+		backend.stopScheduledPayments()
+	}
+	if backend.stopWebhooks != nil {
+		backend.stopWebhooks()
+	}
 
 	backend.uninitAccounts(true)
 
@@ -918,9 +1102,9 @@ func (backend *Backend) Banners() *banners.Banners {
 	return backend.banners
 }
 
-// HandleURI handles an external URI click for registered protocols, e.g. 'aopp:?...' URIs.  The uri
-// param can be any string, as it is potentially passed without any validation from the calling
-// platform.
+// HandleURI handles an external URI click for registered protocols, e.g. 'aopp:?...' or
+// 'bitcoin:...' URIs. The uri param can be any string, as it is potentially passed without any
+// validation from the calling platform.
 func (backend *Backend) HandleURI(uri string) {
 	u, err := url.Parse(uri)
 	if err != nil {
@@ -930,6 +1114,8 @@ func (backend *Backend) HandleURI(uri string) {
 	switch u.Scheme {
 	case "aopp":
 		backend.handleAOPP(*u)
+	case string(paymenturi.SchemeBitcoin), string(paymenturi.SchemeLitecoin), string(paymenturi.SchemeEthereum):
+		backend.handlePaymentURI(uri)
 	default:
 		backend.log.Warningf("Unknown URI scheme: %s", uri)
 	}
@@ -18,7 +18,9 @@ import (
 	"context"
 	"math/big"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -32,13 +34,16 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
+	ethdb "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/db"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/erc20"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/rpcclient/mocks"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/devices/usb"
 	keystoremock "github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore/mocks"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore/software"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/scheduler"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/observable"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/test"
 	"github.com/ethereum/go-ethereum"
@@ -248,6 +253,7 @@ type mockTransactionsSource struct {
 }
 
 func (m *mockTransactionsSource) Transactions(
+	accountDB ethdb.Interface,
 	blockTipHeight *big.Int,
 	address common.Address, endBlock *big.Int, erc20Token *erc20.Token) (
 	[]*accounts.TransactionData, error) {
@@ -467,3 +473,62 @@ func TestRegisterKeystore(t *testing.T) {
 	require.Nil(t, b.Accounts().lookup("v0-66666666-ltc-0"))
 	require.NotNil(t, b.Accounts().lookup("v0-66666666-eth-0"))
 }
+
+func TestCheckETHNode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer server.Close()
+
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+
+	require.NoError(t, b.CheckETHNode(coinpkg.CodeETH, server.URL))
+	// Wrong network.
+	require.Error(t, b.CheckETHNode(coinpkg.CodeSEPETH, server.URL))
+	// Not an Ethereum coin.
+	require.Error(t, b.CheckETHNode(coinpkg.CodeBTC, server.URL))
+	// Unreachable.
+	require.Error(t, b.CheckETHNode(coinpkg.CodeETH, "http://127.0.0.1:1"))
+
+	require.Equal(t, "", b.ethNodeURL(coinpkg.CodeETH))
+	require.NoError(t, b.config.ModifyAppConfig(func(appConfig *config.AppConfig) error {
+		appConfig.Backend.ETH.NodeURL = server.URL
+		return nil
+	}))
+	require.Equal(t, server.URL, b.ethNodeURL(coinpkg.CodeETH))
+	require.Equal(t, "", b.ethNodeURL(coinpkg.CodeSEPETH))
+}
+
+func TestLoadOrBackUp(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "scheduledpayments.json")
+	log := logging.Get().WithGroup("backend_test")
+
+	// A corrupt file is moved away and loading starts from scratch.
+	require.NoError(t, os.WriteFile(filename, []byte("{corrupt"), 0600))
+	scheduledPayments, err := loadOrBackUp(log, filename, scheduler.Load)
+	require.NoError(t, err)
+	require.Empty(t, scheduledPayments.Payments())
+	backups, err := filepath.Glob(filename + ".*.bak")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	content, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	require.Equal(t, "{corrupt", string(content))
+
+	// Valid files are loaded as is.
+	_, err = scheduledPayments.Add(scheduler.Payment{
+		AccountCode:      "v0-55555555-btc-0",
+		Name:             "Rent",
+		RecipientAddress: "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh",
+		Amount:           "100000",
+		Cadence:          scheduler.CadenceMonthly,
+		Interval:         1,
+		Start:            time.Now(),
+	})
+	require.NoError(t, err)
+	scheduledPayments, err = loadOrBackUp(log, filename, scheduler.Load)
+	require.NoError(t, err)
+	require.Len(t, scheduledPayments.Payments(), 1)
+}
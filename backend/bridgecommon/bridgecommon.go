@@ -306,8 +306,14 @@ func Serve(
 	}
 
 	quitChan := make(chan struct{})
+	var localAPIServer *http.Server
 	globalShutdown = func() {
 		close(quitChan)
+		if localAPIServer != nil {
+			if err := localAPIServer.Close(); err != nil {
+				log.WithError(err).Error("Closing the local API server failed")
+			}
+		}
 		if err := globalBackend.Close(); err != nil {
 			log.WithError(err).Error("backend.Close failed")
 		}
@@ -318,9 +324,27 @@ func Serve(
 
 	globalToken = hex.EncodeToString(random.BytesOrPanic(16))
 
-	// the port is unused, as we bridge directly without a server.
+	// The frontend is bridged directly without a server. Other programs can use the API on
+	// localhost if enabled in the settings.
+	localAPI := globalBackend.Config().AppConfig().Backend.LocalAPI
+	port := -1
+	if localAPI.Enabled {
+		port = localAPI.Port
+	}
 	globalHandlers = handlers.NewHandlers(globalBackend,
-		handlers.NewConnectionData(-1, globalToken))
+		handlers.NewConnectionData(port, globalToken))
+	if localAPI.Enabled {
+		localAPIServer, err = globalHandlers.NewLocalAPIServer(localAPI.Port)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to create the local API server")
+		}
+		go func(server *http.Server) {
+			log.WithField("address", server.Addr).Info("Listening for local API requests")
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.WithError(err).Error("The local API server failed")
+			}
+		}(localAPIServer)
+	}
 
 	events := globalHandlers.Events()
 	go func() {
@@ -103,7 +103,10 @@ type Account struct {
 	transactions *transactions.Transactions
 
 	// if not nil, SendTx() will sign and send this transaction. Set by TxProposal().
-	activeTxProposal     *maketx.TxProposal
+	activeTxProposal *maketx.TxProposal
+	// activeFiatQuote is the exchange rate locked in activeTxProposal if its amounts were given in
+	// fiat, nil otherwise.
+	activeFiatQuote      *accounts.FiatQuote
 	activeTxProposalLock locker.Locker
 
 	// Access this only via getMinRelayFeeRate(). sat/kB.
@@ -365,6 +368,7 @@ func XPubVersionForScriptType(coin *Coin, scriptType signing.ScriptType) [4]byte
 			signing.ScriptTypeP2PKH:      {0x04, 0x88, 0xb2, 0x1e}, // xpub
 			signing.ScriptTypeP2WPKHP2SH: {0x04, 0x9d, 0x7c, 0xb2}, // ypub
 			signing.ScriptTypeP2WPKH:     {0x04, 0xb2, 0x47, 0x46}, // zpub
+			signing.ScriptTypeP2WSH:      {0x02, 0xaa, 0x7e, 0xd3}, // Zpub
 		}
 		version, ok := versions[scriptType]
 		if !ok {
@@ -396,6 +400,12 @@ func (account *Account) Info() *accounts.Info {
 		if isInsuredAccount && !isNativeSegwit {
 			continue
 		}
+		if subacc.signingConfiguration.BitcoinMultisig != nil {
+			// The cosigner xpubs are shown as-is, as they are exchanged in this format when setting
+			// up the multisig wallet.
+			signingConfigurations = append(signingConfigurations, subacc.signingConfiguration)
+			continue
+		}
 		xpub := subacc.signingConfiguration.ExtendedPublicKey()
 		if xpub.IsPrivate() {
 			panic("xpub can't be private")
@@ -745,11 +755,15 @@ func (account *Account) GetUnusedReceiveAddresses() []accounts.AddressList {
 }
 
 // VerifyAddress verifies a receive address on a keystore. Returns false, nil if no secure output
-// exists.
+// exists, which is also the case for multisig accounts, as displaying multisig addresses is not
+// supported yet.
 func (account *Account) VerifyAddress(addressID string) (bool, error) {
 	if !account.isInitialized() {
 		return false, errp.New("account must be initialized")
 	}
+	if account.Config().Config.SigningConfigurations.IsMultisig() {
+		return false, nil
+	}
 	account.Synchronizer.WaitSynchronized()
 
 	keystore, err := account.Config().ConnectKeystore()
@@ -779,7 +793,11 @@ func (account *Account) VerifyAddress(addressID string) (bool, error) {
 }
 
 // CanVerifyAddresses wraps Keystores().CanVerifyAddresses(), see that function for documentation.
+// Multisig addresses can't be verified.
 func (account *Account) CanVerifyAddresses() (bool, bool, error) {
+	if account.Config().Config.SigningConfigurations.IsMultisig() {
+		return false, false, nil
+	}
 	keystore, err := account.Config().ConnectKeystore()
 	if err != nil {
 		return false, false, err
@@ -843,6 +861,9 @@ type SpendableOutput struct {
 	OutPoint wire.OutPoint
 	Address  *addresses.AccountAddress
 	IsChange bool
+	// Frozen outputs are not spent unless explicitly selected, see SetUTXOFrozen.
+	Frozen bool
+	Label  string
 }
 
 // SpendableOutputs returns the utxo set, sorted by the value descending.
@@ -854,6 +875,11 @@ func (account *Account) SpendableOutputs() []*SpendableOutput {
 		// TODO
 		panic(err)
 	}
+	states, err := account.UTXOStates()
+	if err != nil {
+		// TODO
+		panic(err)
+	}
 	for outPoint, txOut := range utxos {
 		scriptHashHex := blockchain.NewScriptHashHex(txOut.TxOut.PkScript)
 		result = append(
@@ -863,6 +889,8 @@ func (account *Account) SpendableOutputs() []*SpendableOutput {
 				SpendableOutput: txOut,
 				Address:         account.getAddress(scriptHashHex),
 				IsChange:        account.IsChange(scriptHashHex),
+				Frozen:          states[outPoint].Frozen,
+				Label:           states[outPoint].Label,
 			})
 	}
 	return sortByAddresses(result)
@@ -125,6 +125,54 @@ func TestAccount(t *testing.T) {
 	require.Equal(t, []*SpendableOutput{}, account.SpendableOutputs())
 }
 
+func TestVerifyAddressMultisig(t *testing.T) {
+	keypath, err := signing.NewAbsoluteKeypath("m/48'/1'/0'/2'")
+	require.NoError(t, err)
+	keyInfos := make([]signing.KeyInfo, 2)
+	for i := range keyInfos {
+		seed := make([]byte, 32)
+		seed[0] = byte(i)
+		xpub, err := hdkeychain.NewMaster(seed, &chaincfg.TestNet3Params)
+		require.NoError(t, err)
+		xpub, err = xpub.Neuter()
+		require.NoError(t, err)
+		keyInfos[i] = signing.KeyInfo{
+			RootFingerprint:   []byte{byte(i), 2, 3, 4},
+			AbsoluteKeypath:   keypath,
+			ExtendedPublicKey: xpub,
+		}
+	}
+	configuration, err := signing.NewBitcoinMultisigConfiguration(2, keyInfos, 0)
+	require.NoError(t, err)
+	account := mockAccount(t, &config.Account{
+		Code:                  "accountcode",
+		Name:                  "accountname",
+		SigningConfigurations: signing.Configurations{configuration},
+	})
+	// The BitBox02 can verify addresses of single sig accounts, but not of multisig accounts.
+	account.Config().ConnectKeystore = func() (keystore.Keystore, error) {
+		return &keystoremock.KeystoreMock{
+			CanVerifyAddressFunc: func(coin.Coin) (bool, bool, error) { return true, false, nil },
+			VerifyAddressFunc: func(*signing.Configuration, coin.Coin) error {
+				require.FailNow(t, "multisig addresses can't be verified")
+				return nil
+			},
+		}, nil
+	}
+	require.NoError(t, account.Initialize())
+	require.Eventually(t, func() bool { return account.Synced() }, time.Second, time.Millisecond*200)
+
+	canVerify, optional, err := account.CanVerifyAddresses()
+	require.NoError(t, err)
+	require.False(t, canVerify)
+	require.False(t, optional)
+
+	addressID := account.GetUnusedReceiveAddresses()[0].Addresses[0].ID()
+	verified, err := account.VerifyAddress(addressID)
+	require.NoError(t, err)
+	require.False(t, verified)
+}
+
 func TestInsuredAccountAddresses(t *testing.T) {
 	net := &chaincfg.TestNet3Params
 
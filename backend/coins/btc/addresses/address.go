@@ -15,13 +15,17 @@
 package addresses
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
+	"sort"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
 	ourbtcutil "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/util"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
@@ -42,6 +46,9 @@ type AccountAddress struct {
 
 	// redeemScript stores the redeem script of a BIP16 P2SH output or nil if address type is P2PKH.
 	redeemScript []byte
+	// witnessScript stores the `sortedmulti` witness script of a P2WSH multisig output, or nil for
+	// other address types.
+	witnessScript []byte
 
 	log *logrus.Entry
 }
@@ -55,7 +62,7 @@ func NewAccountAddress(
 ) *AccountAddress {
 
 	var address btcutil.Address
-	var redeemScript []byte
+	var redeemScript, witnessScript []byte
 	configuration, err := accountConfiguration.Derive(keyPath)
 	if err != nil {
 		log.WithError(err).Panic("Failed to derive the configuration.")
@@ -98,6 +105,16 @@ func NewAccountAddress(
 		if err != nil {
 			log.WithError(err).Panic("Failed to get p2tr addr")
 		}
+	case signing.ScriptTypeP2WSH:
+		witnessScript, err = multisigWitnessScript(configuration.BitcoinMultisig, net)
+		if err != nil {
+			log.WithError(err).Panic("Failed to get multisig witness script.")
+		}
+		witnessScriptHash := sha256.Sum256(witnessScript)
+		address, err = btcutil.NewAddressWitnessScriptHash(witnessScriptHash[:], net)
+		if err != nil {
+			log.WithError(err).Panic("Failed to get p2wsh addr. from witness script.")
+		}
 	default:
 		log.Panic(fmt.Sprintf("Unrecognized script type: %s", configuration.ScriptType()))
 	}
@@ -107,10 +124,66 @@ func NewAccountAddress(
 		AccountConfiguration: accountConfiguration,
 		Configuration:        configuration,
 		redeemScript:         redeemScript,
+		witnessScript:        witnessScript,
 		log:                  log,
 	}
 }
 
+// sortedPublicKeys returns the public keys of the cosigners of a multisig configuration, sorted
+// lexicographically by their compressed serialization (BIP-67).
+func sortedPublicKeys(multisig *signing.BitcoinMultisig) ([]*btcec.PublicKey, error) {
+	publicKeys := make([]*btcec.PublicKey, len(multisig.KeyInfos))
+	for index, keyInfo := range multisig.KeyInfos {
+		publicKey, err := keyInfo.ExtendedPublicKey.ECPubKey()
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+		publicKeys[index] = publicKey
+	}
+	sort.Slice(publicKeys, func(i, j int) bool {
+		return bytes.Compare(
+			publicKeys[i].SerializeCompressed(), publicKeys[j].SerializeCompressed()) < 0
+	})
+	return publicKeys, nil
+}
+
+// multisigWitnessScript returns the `sortedmulti(threshold, keys...)` script of a multisig
+// configuration.
+func multisigWitnessScript(multisig *signing.BitcoinMultisig, net *chaincfg.Params) ([]byte, error) {
+	publicKeys, err := sortedPublicKeys(multisig)
+	if err != nil {
+		return nil, err
+	}
+	addressPublicKeys := make([]*btcutil.AddressPubKey, len(publicKeys))
+	for index, publicKey := range publicKeys {
+		addressPublicKeys[index], err = btcutil.NewAddressPubKey(publicKey.SerializeCompressed(), net)
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+	}
+	script, err := txscript.MultiSigScript(addressPublicKeys, multisig.Threshold)
+	return script, errp.WithStack(err)
+}
+
+// MultisigPublicKeys returns the public keys of a multisig address in the order in which they
+// appear in the witness script. Returns nil if the address is not a multisig address.
+func (address *AccountAddress) MultisigPublicKeys() []*btcec.PublicKey {
+	if address.Configuration.BitcoinMultisig == nil {
+		return nil
+	}
+	publicKeys, err := sortedPublicKeys(address.Configuration.BitcoinMultisig)
+	if err != nil {
+		address.log.WithError(err).Panic("Failed to get multisig public keys.")
+	}
+	return publicKeys
+}
+
+// WitnessScript returns the witness script of a P2WSH multisig address, or nil for other address
+// types.
+func (address *AccountAddress) WitnessScript() []byte {
+	return address.witnessScript
+}
+
 // ID implements accounts.Address.
 func (address *AccountAddress) ID() string {
 	return string(address.PubkeyScriptHashHex())
@@ -169,6 +242,8 @@ func (address *AccountAddress) ScriptForHashToSign() (bool, []byte) {
 		return true, address.redeemScript
 	case signing.ScriptTypeP2WPKH:
 		return true, address.PubkeyScript()
+	case signing.ScriptTypeP2WSH:
+		return true, address.witnessScript
 	default:
 		address.log.Panic("Unrecognized address type.")
 	}
@@ -217,6 +292,19 @@ func (address *AccountAddress) SignatureScript(
 			signature.SerializeCompact(),
 		}
 		return []byte{}, txWitness
+	case signing.ScriptTypeP2WSH:
+		// Our signature alone only satisfies a 1-of-n multisig. Signatures of multiple cosigners
+		// are collected and combined using PSBTs.
+		if address.Configuration.BitcoinMultisig.Threshold != 1 {
+			address.log.Panic("Multisig signature scripts need to be built from a PSBT.")
+		}
+		txWitness := wire.TxWitness{
+			// Dummy element consumed by OP_CHECKMULTISIG.
+			{},
+			append(signature.SerializeDER(), byte(txscript.SigHashAll)),
+			address.witnessScript,
+		}
+		return []byte{}, txWitness
 	default:
 		address.log.Panic("Unrecognized address type.")
 	}
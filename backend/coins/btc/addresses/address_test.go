@@ -15,18 +15,25 @@
 package addresses_test
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/hex"
+	"math/big"
 	"os"
 	"testing"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses/test"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
 	testlog "github.com/BitBoxSwiss/bitbox-wallet-app/util/test"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -116,3 +123,120 @@ func TestAddressP2TR(t *testing.T) {
 		require.Equal(t, test.expectedPkScript, hex.EncodeToString(addr.PubkeyScript()))
 	}
 }
+
+func TestAddressP2WSHMultisig(t *testing.T) {
+	keypath, err := signing.NewAbsoluteKeypath("m/48'/1'/0'/2'")
+	require.NoError(t, err)
+	xprvs := make([]*hdkeychain.ExtendedKey, 3)
+	keyInfos := make([]signing.KeyInfo, 3)
+	for i := range keyInfos {
+		seed := make([]byte, hdkeychain.RecommendedSeedLen)
+		seed[0] = byte(i)
+		master, err := hdkeychain.NewMaster(seed, net)
+		require.NoError(t, err)
+		xprvs[i], err = keypath.Derive(master)
+		require.NoError(t, err)
+		xpub, err := xprvs[i].Neuter()
+		require.NoError(t, err)
+		keyInfos[i] = signing.KeyInfo{
+			RootFingerprint:   []byte{byte(i), 0, 0, 0},
+			AbsoluteKeypath:   keypath,
+			ExtendedPublicKey: xpub,
+		}
+	}
+	relKeypath, err := signing.NewRelativeKeypath("0/3")
+	require.NoError(t, err)
+
+	// signInput creates a spending tx of the address and signs it with the given keys.
+	signInput := func(addr *addresses.AccountAddress, keys []int) (*wire.MsgTx, *wire.TxOut, [][]byte) {
+		prevOut := wire.NewTxOut(1e5, addr.PubkeyScript())
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(9e4, addr.PubkeyScript()))
+		fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+		isSegwit, script := addr.ScriptForHashToSign()
+		require.True(t, isSegwit)
+		require.Equal(t, addr.WitnessScript(), script)
+		sigHash, err := txscript.CalcWitnessSigHash(script, txscript.NewTxSigHashes(tx, fetcher),
+			txscript.SigHashAll, tx, 0, prevOut.Value)
+		require.NoError(t, err)
+		signatures := [][]byte{}
+		for _, key := range keys {
+			xprv, err := relKeypath.Derive(xprvs[key])
+			require.NoError(t, err)
+			privateKey, err := xprv.ECPrivKey()
+			require.NoError(t, err)
+			signatures = append(signatures,
+				append(ecdsa.Sign(privateKey, sigHash).Serialize(), byte(txscript.SigHashAll)))
+		}
+		return tx, prevOut, signatures
+	}
+	execute := func(tx *wire.MsgTx, prevOut *wire.TxOut) error {
+		fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+		engine, err := txscript.NewEngine(prevOut.PkScript, tx, 0, txscript.StandardVerifyFlags,
+			nil, txscript.NewTxSigHashes(tx, fetcher), prevOut.Value, fetcher)
+		require.NoError(t, err)
+		return engine.Execute()
+	}
+
+	// 2-of-3
+	configuration, err := signing.NewBitcoinMultisigConfiguration(2, keyInfos, 0)
+	require.NoError(t, err)
+	addr := addresses.NewAccountAddress(
+		configuration, relKeypath, net, logging.Get().WithGroup("addresses_test"))
+	require.Equal(t, signing.ScriptTypeP2WSH, addr.Configuration.ScriptType())
+	require.Equal(t, "m/48'/1'/0'/2'/0/3", addr.AbsoluteKeypath().Encode())
+	witnessScriptHash := sha256.Sum256(addr.WitnessScript())
+	require.Equal(t, append([]byte{txscript.OP_0, txscript.OP_DATA_32}, witnessScriptHash[:]...),
+		addr.PubkeyScript())
+
+	publicKeys := addr.MultisigPublicKeys()
+	require.Len(t, publicKeys, 3)
+	for i := 1; i < len(publicKeys); i++ {
+		require.Negative(t, bytes.Compare(
+			publicKeys[i-1].SerializeCompressed(), publicKeys[i].SerializeCompressed()))
+	}
+	scriptClass, scriptAddresses, threshold, err := txscript.ExtractPkScriptAddrs(
+		addr.WitnessScript(), net)
+	require.NoError(t, err)
+	require.Equal(t, txscript.MultiSigTy, scriptClass)
+	require.Equal(t, 2, threshold)
+	for i, scriptAddress := range scriptAddresses {
+		require.Equal(t, publicKeys[i].SerializeCompressed(), scriptAddress.ScriptAddress())
+	}
+
+	// Signatures need to be provided in the order of the public keys in the script.
+	sortedKeys := []int{}
+	for _, publicKey := range publicKeys {
+		for i, keyInfo := range keyInfos {
+			derived, err := relKeypath.Derive(keyInfo.ExtendedPublicKey)
+			require.NoError(t, err)
+			derivedPublicKey, err := derived.ECPubKey()
+			require.NoError(t, err)
+			if derivedPublicKey.IsEqual(publicKey) {
+				sortedKeys = append(sortedKeys, i)
+			}
+		}
+	}
+	tx, prevOut, signatures := signInput(addr, sortedKeys[1:])
+	tx.TxIn[0].Witness = wire.TxWitness{{}, signatures[0], signatures[1], addr.WitnessScript()}
+	require.NoError(t, execute(tx, prevOut))
+	tx.TxIn[0].Witness = wire.TxWitness{{}, signatures[1], signatures[0], addr.WitnessScript()}
+	require.Error(t, execute(tx, prevOut))
+
+	// 1-of-2, where our signature alone is sufficient.
+	configuration, err = signing.NewBitcoinMultisigConfiguration(1, keyInfos[:2], 1)
+	require.NoError(t, err)
+	addr = addresses.NewAccountAddress(
+		configuration, relKeypath, net, logging.Get().WithGroup("addresses_test"))
+	tx, prevOut, signatures = signInput(addr, []int{1})
+	signature, err := ecdsa.ParseDERSignature(signatures[0][:len(signatures[0])-1])
+	require.NoError(t, err)
+	r, s := signature.R(), signature.S()
+	rBytes, sBytes := r.Bytes(), s.Bytes()
+	tx.TxIn[0].SignatureScript, tx.TxIn[0].Witness = addr.SignatureScript(types.Signature{
+		R: new(big.Int).SetBytes(rBytes[:]),
+		S: new(big.Int).SetBytes(sBytes[:]),
+	})
+	require.NoError(t, execute(tx, prevOut))
+}
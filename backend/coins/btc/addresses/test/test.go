@@ -66,3 +66,34 @@ func GetAddress(scriptType signing.ScriptType) *addresses.AccountAddress {
 		logging.Get().WithGroup("addresses_test"),
 	)
 }
+
+// GetMultisigAddress returns a dummy P2WSH multisig address with `numKeys` cosigners, of which
+// `threshold` need to sign.
+func GetMultisigAddress(threshold, numKeys int) *addresses.AccountAddress {
+	extendedPublicKey, err := hdkeychain.NewKeyFromString(xpub)
+	if err != nil {
+		panic(err)
+	}
+	keyInfos := make([]signing.KeyInfo, numKeys)
+	for i := range keyInfos {
+		cosignerKey, err := extendedPublicKey.Derive(uint32(i))
+		if err != nil {
+			panic(err)
+		}
+		keyInfos[i] = signing.KeyInfo{
+			RootFingerprint:   []byte{1, 2, 3, byte(i)},
+			AbsoluteKeypath:   absoluteKeypath,
+			ExtendedPublicKey: cosignerKey,
+		}
+	}
+	configuration, err := signing.NewBitcoinMultisigConfiguration(threshold, keyInfos, 0)
+	if err != nil {
+		panic(err)
+	}
+	return addresses.NewAccountAddress(
+		configuration,
+		signing.NewEmptyRelativeKeypath(),
+		net,
+		logging.Get().WithGroup("addresses_test"),
+	)
+}
@@ -20,6 +20,7 @@ import (
 	"math/big"
 	"os"
 	"path"
+	"strings"
 	"sync"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
@@ -286,6 +287,19 @@ func (coin *Coin) AddressToPkScript(address string) ([]byte, error) {
 	return util.PkScriptFromAddress(addr)
 }
 
+// NormalizeAddress validates the address like AddressToPkScript and returns its canonical
+// encoding, e.g. lowercase for bech32 addresses. Silent payment addresses are accepted as well.
+func (coin *Coin) NormalizeAddress(address string) (string, error) {
+	if err := coin.ValidateSilentPaymentAddress(address); err == nil {
+		return strings.ToLower(address), nil
+	}
+	addr, err := coin.decodeAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
+
 // ValidateSilentPaymentAddress checks if the address is a valid silent payment (BIP-352) address
 // matching the account coin type.
 func (coin *Coin) ValidateSilentPaymentAddress(address string) error {
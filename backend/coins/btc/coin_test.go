@@ -147,6 +147,23 @@ func (s *testSuite) TestParseAmount() {
 	s.Require().Equal(intSatAmount, intAmount)
 }
 
+func (s *testSuite) TestConvertFromFiat() {
+	price := big.NewRat(60000, 1)
+
+	s.coin.SetFormatUnit("BTC")
+	converted, err := coin.NewSendAmount("60").ConvertFromFiat(s.coin, price)
+	s.Require().NoError(err)
+	s.Require().Equal(coin.NewSendAmount("0.00100000"), converted)
+
+	s.coin.SetFormatUnit("sat")
+	converted, err = coin.NewSendAmount("60").ConvertFromFiat(s.coin, price)
+	s.Require().NoError(err)
+	s.Require().Equal(coin.NewSendAmount("100000"), converted)
+	coinAmount, err := s.coin.ParseAmount("100000")
+	s.Require().NoError(err)
+	s.Require().Equal(int64(100000), coinAmount.BigInt().Int64())
+}
+
 func (s *testSuite) TestAddressToPkScript() {
 	type test struct {
 		address     string
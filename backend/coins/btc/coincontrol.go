@@ -0,0 +1,82 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/notes"
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// UTXOStates returns the coin control states (frozen, label) of all outputs which have one.
+func (account *Account) UTXOStates() (map[wire.OutPoint]types.UTXOState, error) {
+	if !account.isInitialized() {
+		return nil, errp.New("account not initialized")
+	}
+	return transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (map[wire.OutPoint]types.UTXOState, error) {
+		return dbTx.UTXOStates()
+	})
+}
+
+// modifyUTXOState applies `f` to the coin control state of the output. Returns true if the state
+// changed.
+func (account *Account) modifyUTXOState(outPoint wire.OutPoint, f func(*types.UTXOState)) (bool, error) {
+	if !account.isInitialized() {
+		return false, errp.New("account not initialized")
+	}
+	changed := false
+	err := transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+		state, err := dbTx.UTXOState(outPoint)
+		if err != nil {
+			return err
+		}
+		newState := state
+		f(&newState)
+		if newState == state {
+			return nil
+		}
+		changed = true
+		return dbTx.PutUTXOState(outPoint, newState)
+	})
+	if err != nil {
+		return false, err
+	}
+	if changed {
+		account.Config().OnEvent(accountsTypes.EventStatusChanged)
+	}
+	return changed, nil
+}
+
+// SetUTXOFrozen freezes or unfreezes an output. Frozen outputs are not spent by new transactions
+// unless they are explicitly selected using coin control. Returns true if the state changed.
+func (account *Account) SetUTXOFrozen(outPoint wire.OutPoint, frozen bool) (bool, error) {
+	return account.modifyUTXOState(outPoint, func(state *types.UTXOState) {
+		state.Frozen = frozen
+	})
+}
+
+// SetUTXOLabel sets the label of an output. The empty label removes it. Returns true if the label
+// changed.
+func (account *Account) SetUTXOLabel(outPoint wire.OutPoint, label string) (bool, error) {
+	if len(label) > notes.MaxNoteLen {
+		return false, errp.Newf("Length of label must be smaller than %d. Got %d", notes.MaxNoteLen, len(label))
+	}
+	return account.modifyUTXOState(outPoint, func(state *types.UTXOState) {
+		state.Label = label
+	})
+}
@@ -37,6 +37,7 @@ const (
 	bucketOutputsKey                = "outputs"
 	bucketAddressHistoriesKey       = "addressHistories"
 	bucketConfigKey                 = "config"
+	bucketUTXOStatesKey             = "utxoStates"
 )
 
 // DB is a bbolt key/value database.
@@ -382,3 +383,44 @@ func (tx *Tx) GapLimits() (types.GapLimits, error) {
 	}
 	return types.GapLimits{}, nil
 }
+
+// PutUTXOState implements transactions.DBTxInterface.
+func (tx *Tx) PutUTXOState(outPoint wire.OutPoint, state types.UTXOState) error {
+	bucketUTXOStates, err := tx.tx.CreateBucketIfNotExists([]byte(bucketUTXOStatesKey))
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	if state.IsEmpty() {
+		return errp.WithStack(bucketUTXOStates.Delete([]byte(outPoint.String())))
+	}
+	return writeJSON(bucketUTXOStates, []byte(outPoint.String()), state)
+}
+
+// UTXOState implements transactions.DBTxInterface.
+func (tx *Tx) UTXOState(outPoint wire.OutPoint) (types.UTXOState, error) {
+	var state types.UTXOState
+	_, err := readJSON(tx.tx.Bucket([]byte(bucketUTXOStatesKey)), []byte(outPoint.String()), &state)
+	return state, err
+}
+
+// UTXOStates implements transactions.DBTxInterface.
+func (tx *Tx) UTXOStates() (map[wire.OutPoint]types.UTXOState, error) {
+	states := map[wire.OutPoint]types.UTXOState{}
+	bucketUTXOStates := tx.tx.Bucket([]byte(bucketUTXOStatesKey))
+	if bucketUTXOStates == nil {
+		return states, nil
+	}
+	cursor := bucketUTXOStates.Cursor()
+	for outPointBytes, stateJSONBytes := cursor.First(); outPointBytes != nil; outPointBytes, stateJSONBytes = cursor.Next() {
+		var state types.UTXOState
+		if err := json.Unmarshal(stateJSONBytes, &state); err != nil {
+			return nil, errp.WithStack(err)
+		}
+		outPoint, err := util.ParseOutPoint(outPointBytes)
+		if err != nil {
+			return nil, err
+		}
+		states[*outPoint] = state
+	}
+	return states, nil
+}
@@ -548,3 +548,43 @@ func TestGapLimits(t *testing.T) {
 		require.Equal(t, uint16(123), limits.Change)
 	})
 }
+
+func TestUTXOStates(t *testing.T) {
+	testTx(func(tx *Tx) {
+		outPoint1 := wire.OutPoint{Hash: chainhash.HashH([]byte("tx1")), Index: 1}
+		outPoint2 := wire.OutPoint{Hash: chainhash.HashH([]byte("tx2")), Index: 0}
+
+		states, err := tx.UTXOStates()
+		require.NoError(t, err)
+		require.Empty(t, states)
+		state, err := tx.UTXOState(outPoint1)
+		require.NoError(t, err)
+		require.True(t, state.IsEmpty())
+
+		require.NoError(t, tx.PutUTXOState(outPoint1, types.UTXOState{Frozen: true, Label: "dust"}))
+		require.NoError(t, tx.PutUTXOState(outPoint2, types.UTXOState{Label: "kyc"}))
+		state, err = tx.UTXOState(outPoint1)
+		require.NoError(t, err)
+		require.Equal(t, types.UTXOState{Frozen: true, Label: "dust"}, state)
+
+		// Test actual db store against fixtures to ensure compatibility does not break
+		require.Equal(t,
+			`{"frozen":true,"label":"dust"}`,
+			string(getRawValue(tx, "utxoStates", []byte(outPoint1.String()))),
+		)
+
+		states, err = tx.UTXOStates()
+		require.NoError(t, err)
+		require.Equal(t, map[wire.OutPoint]types.UTXOState{
+			outPoint1: {Frozen: true, Label: "dust"},
+			outPoint2: {Label: "kyc"},
+		}, states)
+
+		// Storing the empty state removes it.
+		require.NoError(t, tx.PutUTXOState(outPoint2, types.UTXOState{}))
+		states, err = tx.UTXOStates()
+		require.NoError(t, err)
+		require.Len(t, states, 1)
+		require.Nil(t, getRawValue(tx, "utxoStates", []byte(outPoint2.String())))
+	})
+}
@@ -68,3 +68,18 @@ func (feeTargets FeeTargets) highest() *FeeTarget {
 	}
 	return highestFeeTarget
 }
+
+// lowest returns the feeTarget with the lowest fee.
+func (feeTargets FeeTargets) lowest() *FeeTarget {
+	var lowestFeeTarget *FeeTarget
+	for _, feeTarget := range feeTargets {
+		if feeTarget == nil || feeTarget.feeRatePerKb == nil {
+			continue
+		}
+
+		if lowestFeeTarget == nil || *feeTarget.feeRatePerKb < *lowestFeeTarget.feeRatePerKb {
+			lowestFeeTarget = feeTarget
+		}
+	}
+	return lowestFeeTarget
+}
@@ -60,6 +60,7 @@ func TestFeeTargets(t *testing.T) {
 	// empty slice
 	var feeTargets FeeTargets
 	require.Nil(t, feeTargets.highest())
+	require.Nil(t, feeTargets.lowest())
 
 	// non-empty slice, with all nil feeRates
 	feeTargets = FeeTargets{
@@ -85,6 +86,7 @@ func TestFeeTargets(t *testing.T) {
 	}
 	feeTargets = feeTargetsSlice
 	require.Equal(t, feeTargetsSlice[2], feeTargets.highest())
+	require.Equal(t, feeTargetsSlice[0], feeTargets.lowest())
 
 	// non-empty slice, with unsorted not-nil feeRates
 	feeTargetsSlice = []*FeeTarget{
@@ -94,5 +96,6 @@ func TestFeeTargets(t *testing.T) {
 	}
 	feeTargets = feeTargetsSlice
 	require.Equal(t, feeTargetsSlice[1], feeTargets.highest())
+	require.Equal(t, feeTargetsSlice[0], feeTargets.lowest())
 
 }
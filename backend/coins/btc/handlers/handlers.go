@@ -31,12 +31,16 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/addressbook"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/sweep"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/util"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/etherscan"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/paymenturi"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/config"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
@@ -65,11 +69,14 @@ func NewHandlers(
 	handleFunc("/export", handlers.ensureAccountInitialized(handlers.postExportTransactions)).Methods("POST")
 	handleFunc("/info", handlers.ensureAccountInitialized(handlers.getAccountInfo)).Methods("GET")
 	handleFunc("/utxos", handlers.ensureAccountInitialized(handlers.getUTXOs)).Methods("GET")
+	handleFunc("/utxos/freeze", handlers.ensureAccountInitialized(handlers.postSetUTXOFrozen)).Methods("POST")
+	handleFunc("/utxos/label", handlers.ensureAccountInitialized(handlers.postSetUTXOLabel)).Methods("POST")
 	handleFunc("/balance", handlers.ensureAccountInitialized(handlers.getAccountBalance)).Methods("GET")
 	handleFunc("/sendtx", handlers.ensureAccountInitialized(handlers.postAccountSendTx)).Methods("POST")
 	handleFunc("/fee-targets", handlers.ensureAccountInitialized(handlers.getAccountFeeTargets)).Methods("GET")
 	handleFunc("/tx-proposal", handlers.ensureAccountInitialized(handlers.postAccountTxProposal)).Methods("POST")
 	handleFunc("/receive-addresses", handlers.ensureAccountInitialized(handlers.getReceiveAddresses)).Methods("GET")
+	handleFunc("/receive-uri", handlers.ensureAccountInitialized(handlers.postReceiveURI)).Methods("POST")
 	handleFunc("/verify-address", handlers.ensureAccountInitialized(handlers.postVerifyAddress)).Methods("POST")
 	handleFunc("/verify-extended-public-key", handlers.ensureAccountInitialized(handlers.postVerifyExtendedPublicKey)).Methods("POST")
 	handleFunc("/sign-address", handlers.ensureAccountInitialized(handlers.postSignBTCAddress)).Methods("POST")
@@ -80,6 +87,15 @@ func NewHandlers(
 	handleFunc("/eth-sign-msg", handlers.ensureAccountInitialized(handlers.postEthSignMsg)).Methods("POST")
 	handleFunc("/eth-sign-typed-msg", handlers.ensureAccountInitialized(handlers.postEthSignTypedMsg)).Methods("POST")
 	handleFunc("/eth-sign-wallet-connect-tx", handlers.ensureAccountInitialized(handlers.postEthSignWalletConnectTx)).Methods("POST")
+	handleFunc("/psbt/export", handlers.ensureAccountInitialized(handlers.postExportPSBT)).Methods("POST")
+	handleFunc("/psbt/broadcast", handlers.ensureAccountInitialized(handlers.postBroadcastPSBT)).Methods("POST")
+	handleFunc("/psbt/sign", handlers.ensureAccountInitialized(handlers.postSignPSBT)).Methods("POST")
+	handleFunc("/bump-fee", handlers.ensureAccountInitialized(handlers.postBumpFee)).Methods("POST")
+	handleFunc("/cpfp", handlers.ensureAccountInitialized(handlers.postCPFP)).Methods("POST")
+	handleFunc("/sweep/proposal", handlers.ensureAccountInitialized(handlers.postSweepProposal)).Methods("POST")
+	handleFunc("/sweep/send", handlers.ensureAccountInitialized(handlers.postSweepSend)).Methods("POST")
+	handleFunc("/eth-speed-up-tx", handlers.ensureAccountInitialized(handlers.postEthSpeedUpTx)).Methods("POST")
+	handleFunc("/eth-cancel-tx", handlers.ensureAccountInitialized(handlers.postEthCancelTx)).Methods("POST")
 	return handlers
 }
 
@@ -362,12 +378,56 @@ func (handlers *Handlers) getUTXOs(*http.Request) (interface{}, error) {
 				"note":          handlers.account.TxNote(output.OutPoint.Hash.String()),
 				"addressReused": addressReused,
 				"isChange":      output.IsChange,
+				"frozen":        output.Frozen,
+				"label":         output.Label,
 			})
 	}
 
 	return result, nil
 }
 
+// postSetUTXOFrozen freezes or unfreezes an output of a BTC based account.
+func (handlers *Handlers) postSetUTXOFrozen(r *http.Request) (interface{}, error) {
+	var args struct {
+		OutPoint string `json:"outPoint"`
+		Frozen   bool   `json:"frozen"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("Interface must be of type btc.Account")
+	}
+	outPoint, err := util.ParseOutPoint([]byte(args.OutPoint))
+	if err != nil {
+		return nil, err
+	}
+	_, err = btcAccount.SetUTXOFrozen(*outPoint, args.Frozen)
+	return nil, err
+}
+
+// postSetUTXOLabel sets the label of an output of a BTC based account.
+func (handlers *Handlers) postSetUTXOLabel(r *http.Request) (interface{}, error) {
+	var args struct {
+		OutPoint string `json:"outPoint"`
+		Label    string `json:"label"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	btcAccount, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return nil, errp.New("Interface must be of type btc.Account")
+	}
+	outPoint, err := util.ParseOutPoint([]byte(args.OutPoint))
+	if err != nil {
+		return nil, err
+	}
+	_, err = btcAccount.SetUTXOLabel(*outPoint, args.Label)
+	return nil, err
+}
+
 func (handlers *Handlers) getAccountBalance(*http.Request) (interface{}, error) {
 	balance, err := handlers.account.Balance()
 	if err != nil {
@@ -439,9 +499,19 @@ func (input *sendTxInput) UnmarshalJSON(jsonBytes []byte) error {
 		CustomFee      string         `json:"customFee"`
 		Amount         string         `json:"amount"`
 		SelectedUTXOS  []string       `json:"selectedUTXOS"`
+		CoinSelection  string         `json:"coinSelection"`
 		Note           string         `json:"note"`
 		Counter        int            `json:"counter"`
 		PaymentRequest *slip24Request `json:"paymentRequest"`
+		// Recipients, if not empty, replaces address, amount and sendAll to pay several recipients
+		// in one transaction.
+		Recipients []struct {
+			Address string `json:"address"`
+			Amount  string `json:"amount"`
+			SendAll string `json:"sendAll"`
+		} `json:"recipients"`
+		// Fiat, if not empty, denominates amount and the recipient amounts in this fiat currency.
+		Fiat string `json:"fiat"`
 	}{}
 	if err := json.Unmarshal(jsonBytes, &jsonBody); err != nil {
 		return errp.WithStack(err)
@@ -460,6 +530,20 @@ func (input *sendTxInput) UnmarshalJSON(jsonBytes []byte) error {
 	} else {
 		input.Amount = coin.NewSendAmount(jsonBody.Amount)
 	}
+	for _, recipient := range jsonBody.Recipients {
+		amount := coin.NewSendAmount(recipient.Amount)
+		if recipient.SendAll == "yes" {
+			amount = coin.NewSendAmountAll()
+		}
+		input.Recipients = append(input.Recipients, accounts.Recipient{
+			Address: recipient.Address,
+			Amount:  amount,
+		})
+	}
+	input.CoinSelection, err = accounts.NewCoinSelectionStrategy(jsonBody.CoinSelection)
+	if err != nil {
+		return err
+	}
 	input.SelectedUTXOs = map[wire.OutPoint]struct{}{}
 	for _, outPointString := range jsonBody.SelectedUTXOS {
 		outPoint, err := util.ParseOutPoint([]byte(outPointString))
@@ -468,6 +552,7 @@ func (input *sendTxInput) UnmarshalJSON(jsonBytes []byte) error {
 		}
 		input.SelectedUTXOs[*outPoint] = struct{}{}
 	}
+	input.Fiat = rates.Fiat(jsonBody.Fiat)
 	input.Note = jsonBody.Note
 	if jsonBody.PaymentRequest != nil {
 		paymentRequest, err := jsonBody.PaymentRequest.toPaymentRequest()
@@ -497,6 +582,9 @@ func (handlers *Handlers) postAccountSendTx(r *http.Request) (interface{}, error
 		if strings.Contains(err.Error(), etherscan.ERC20GasErr) {
 			result["errorCode"] = errors.ERC20InsufficientGasFunds.Error()
 		}
+		if validationErr, ok := errp.Cause(err).(errors.TxValidationError); ok {
+			result["errorCode"] = validationErr.Error()
+		}
 		return result, nil
 	}
 	return map[string]interface{}{"success": true}, nil
@@ -517,15 +605,28 @@ func (handlers *Handlers) postAccountTxProposal(r *http.Request) (interface{}, e
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		return txProposalError(errp.WithStack(err))
 	}
-	outputAmount, fee, total, err := handlers.account.TxProposal(&input.TxProposalArgs)
+	txProposal, err := handlers.account.TxProposal(&input.TxProposalArgs)
 	if err != nil {
 		return txProposalError(err)
 	}
+	// Recipient addresses which are in the address book, mapped to their contact.
+	contacts := map[string]*addressbook.Contact{}
+	if lookupContact := handlers.account.Config().LookupContact; lookupContact != nil {
+		for _, recipient := range input.AllRecipients() {
+			if contact := lookupContact(recipient.Address); contact != nil {
+				contacts[recipient.Address] = contact
+			}
+		}
+	}
 	return map[string]interface{}{
-		"success": true,
-		"amount":  handlers.formatAmountAsJSON(outputAmount, false),
-		"fee":     handlers.formatAmountAsJSON(fee, true),
-		"total":   handlers.formatAmountAsJSON(total, false),
+		"success":  true,
+		"amount":   handlers.formatAmountAsJSON(txProposal.Amount, false),
+		"fee":      handlers.formatAmountAsJSON(txProposal.Fee, true),
+		"total":    handlers.formatAmountAsJSON(txProposal.Total, false),
+		"warnings": txProposal.Warnings,
+		"contacts": contacts,
+		// The exchange rate locked for the fiat amounts, nil if the amounts were not in fiat.
+		"fiatQuote": txProposal.FiatQuote,
 	}, nil
 }
 
@@ -612,6 +713,51 @@ func (handlers *Handlers) getReceiveAddresses(*http.Request) (interface{}, error
 	return addressList, nil
 }
 
+// postReceiveURI returns a payment URI (BIP-21 or EIP-681) for one of the unused receive
+// addresses, optionally requesting an amount and with a label and message.
+func (handlers *Handlers) postReceiveURI(r *http.Request) (interface{}, error) {
+	type response struct {
+		Success      bool   `json:"success"`
+		URI          string `json:"uri,omitempty"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+	var request struct {
+		AddressID string `json:"addressID"`
+		// Amount is in the unit of the account. Empty if no amount is requested.
+		Amount  string `json:"amount"`
+		Label   string `json:"label"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	var address accounts.Address
+	for _, addresses := range handlers.account.GetUnusedReceiveAddresses() {
+		for _, addr := range addresses.Addresses {
+			if addr.ID() == request.AddressID {
+				address = addr
+			}
+		}
+	}
+	if address == nil {
+		return response{Success: false, ErrorMessage: "Unknown address"}, nil
+	}
+	uri, err := paymenturi.New(handlers.account.Coin(), address.EncodeForHumans())
+	if err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	if request.Amount != "" {
+		amount, err := handlers.account.Coin().ParseAmount(request.Amount)
+		if err != nil || amount.BigInt().Sign() <= 0 {
+			return response{Success: false, ErrorMessage: "Invalid amount"}, nil
+		}
+		uri.Amount = amount.BigInt()
+	}
+	uri.Label = request.Label
+	uri.Message = request.Message
+	return response{Success: true, URI: uri.String()}, nil
+}
+
 func (handlers *Handlers) postVerifyAddress(r *http.Request) (interface{}, error) {
 	var addressID string
 	if err := json.NewDecoder(r.Body).Decode(&addressID); err != nil {
@@ -852,3 +998,278 @@ func (handlers *Handlers) getHasPaymentRequest(r *http.Request) (interface{}, er
 
 	return response{Success: true}, nil
 }
+
+// postExportPSBT exports the active tx proposal, created with /tx-proposal, as a base64 encoded
+// PSBT.
+func (handlers *Handlers) postExportPSBT(*http.Request) (interface{}, error) {
+	type response struct {
+		Success      bool   `json:"success"`
+		PSBT         string `json:"psbt,omitempty"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+	account, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return response{
+			Success:      false,
+			ErrorMessage: "An account must be BTC based to support PSBTs.",
+		}, nil
+	}
+	encoded, err := account.ExportPSBT()
+	if err != nil {
+		handlers.log.WithError(err).Error("Failed to export PSBT")
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	return response{Success: true, PSBT: encoded}, nil
+}
+
+// postBroadcastPSBT finalizes and broadcasts a fully signed PSBT (base64 or hex encoded).
+func (handlers *Handlers) postBroadcastPSBT(r *http.Request) (interface{}, error) {
+	type response struct {
+		Success      bool   `json:"success"`
+		TxID         string `json:"txID,omitempty"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+	var encoded string
+	if err := json.NewDecoder(r.Body).Decode(&encoded); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	account, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return response{
+			Success:      false,
+			ErrorMessage: "An account must be BTC based to support PSBTs.",
+		}, nil
+	}
+	txID, err := account.BroadcastPSBT(encoded)
+	if err != nil {
+		handlers.log.WithError(err).Error("Failed to broadcast PSBT")
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	return response{Success: true, TxID: txID}, nil
+}
+
+// postSignPSBT adds the signatures of the account keystore to a PSBT (base64 or hex encoded), e.g. to
+// collect the signatures of the cosigners of a multisig account.
+func (handlers *Handlers) postSignPSBT(r *http.Request) (interface{}, error) {
+	type response struct {
+		Success      bool   `json:"success"`
+		Aborted      bool   `json:"aborted,omitempty"`
+		PSBT         string `json:"psbt,omitempty"`
+		Complete     bool   `json:"complete"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+	var encoded string
+	if err := json.NewDecoder(r.Body).Decode(&encoded); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	account, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return response{
+			Success:      false,
+			ErrorMessage: "An account must be BTC based to support PSBTs.",
+		}, nil
+	}
+	signed, complete, err := account.SignPSBT(encoded)
+	if errp.Cause(err) == keystore.ErrSigningAborted || errp.Cause(err) == errp.ErrUserAbort {
+		return response{Success: false, Aborted: true}, nil
+	}
+	if err != nil {
+		handlers.log.WithError(err).Error("Failed to sign PSBT")
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	return response{Success: true, PSBT: signed, Complete: complete}, nil
+}
+
+// accelerateTx handles requests to speed up a pending transaction, given by its ID and a new fee
+// target. The actual acceleration method (RBF, CPFP) is performed by `accelerate`.
+func (handlers *Handlers) accelerateTx(
+	r *http.Request,
+	accelerate func(*btc.Account, string, accounts.FeeTargetCode, string) (string, coin.Amount, error),
+) (interface{}, error) {
+	type response struct {
+		Success      bool             `json:"success"`
+		Aborted      bool             `json:"aborted,omitempty"`
+		TxID         string           `json:"txID,omitempty"`
+		Fee          *FormattedAmount `json:"fee,omitempty"`
+		ErrorCode    string           `json:"errorCode,omitempty"`
+		ErrorMessage string           `json:"errorMessage,omitempty"`
+	}
+	var jsonBody struct {
+		TxID      string `json:"txID"`
+		FeeTarget string `json:"feeTarget"`
+		// Provided in Sat/vByte.
+		CustomFee string `json:"customFee"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	feeTargetCode, err := accounts.NewFeeTargetCode(jsonBody.FeeTarget)
+	if err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	account, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return response{
+			Success:      false,
+			ErrorMessage: "An account must be BTC based to accelerate a transaction.",
+		}, nil
+	}
+	txID, fee, err := accelerate(account, jsonBody.TxID, feeTargetCode, jsonBody.CustomFee)
+	if errp.Cause(err) == keystore.ErrSigningAborted || errp.Cause(err) == errp.ErrUserAbort {
+		return response{Success: false, Aborted: true}, nil
+	}
+	if err != nil {
+		handlers.log.WithError(err).Error("Failed to accelerate transaction")
+		if validationErr, ok := errp.Cause(err).(errors.TxValidationError); ok {
+			return response{Success: false, ErrorCode: validationErr.Error()}, nil
+		}
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	formattedFee := handlers.formatAmountAsJSON(fee, true)
+	return response{Success: true, TxID: txID, Fee: &formattedFee}, nil
+}
+
+// postBumpFee replaces a pending outgoing transaction by one paying a higher fee (RBF).
+func (handlers *Handlers) postBumpFee(r *http.Request) (interface{}, error) {
+	return handlers.accelerateTx(r, (*btc.Account).BumpFee)
+}
+
+// postCPFP accelerates a pending transaction by spending its outputs in a child transaction paying
+// a higher fee (CPFP).
+func (handlers *Handlers) postCPFP(r *http.Request) (interface{}, error) {
+	return handlers.accelerateTx(r, (*btc.Account).ChildPaysForParent)
+}
+
+// sweepKey handles requests to sweep the coins of a private key (WIF or xprv), e.g. of a paper
+// wallet, into the account. The proposal is returned, and if `send` is true, the transaction is
+// signed with the key and broadcasted. As the proposal is made again when sending, the amount and
+// fee returned for the proposal must be passed when sending, and nothing is sent if they changed.
+func (handlers *Handlers) sweepKey(r *http.Request, send bool) (interface{}, error) {
+	type response struct {
+		Success      bool             `json:"success"`
+		Amount       *FormattedAmount `json:"amount,omitempty"`
+		Fee          *FormattedAmount `json:"fee,omitempty"`
+		TxID         string           `json:"txID,omitempty"`
+		ErrorCode    string           `json:"errorCode,omitempty"`
+		ErrorMessage string           `json:"errorMessage,omitempty"`
+	}
+	var jsonBody struct {
+		Key       string `json:"key"`
+		FeeTarget string `json:"feeTarget"`
+		// Provided in Sat/vByte.
+		CustomFee string `json:"customFee"`
+		// Amount and Fee are the formatted amount and fee of the confirmed proposal, when sending.
+		Amount string `json:"amount"`
+		Fee    string `json:"fee"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	feeTargetCode, err := accounts.NewFeeTargetCode(jsonBody.FeeTarget)
+	if err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	account, ok := handlers.account.(*btc.Account)
+	if !ok {
+		return response{
+			Success:      false,
+			ErrorMessage: "An account must be BTC based to sweep a private key into it.",
+		}, nil
+	}
+	sweepError := func(err error) (interface{}, error) {
+		handlers.log.WithError(err).Error("Failed to sweep private key")
+		if validationErr, ok := errp.Cause(err).(errors.TxValidationError); ok {
+			return response{Success: false, ErrorCode: validationErr.Error()}, nil
+		}
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	keySweep, err := sweep.New(account, jsonBody.Key)
+	if err != nil {
+		return sweepError(err)
+	}
+	txProposal, err := keySweep.TxProposal(feeTargetCode, jsonBody.CustomFee)
+	if err != nil {
+		return sweepError(err)
+	}
+	amount := handlers.formatAmountAsJSON(coin.NewAmountFromInt64(int64(txProposal.Amount)), false)
+	fee := handlers.formatAmountAsJSON(coin.NewAmountFromInt64(int64(txProposal.Fee)), true)
+	if !send {
+		return response{Success: true, Amount: &amount, Fee: &fee}, nil
+	}
+	if amount.Amount != jsonBody.Amount || fee.Amount != jsonBody.Fee {
+		return sweepError(errp.WithStack(sweep.ErrProposalChanged))
+	}
+	txID, err := keySweep.Send(txProposal)
+	if err != nil {
+		return sweepError(err)
+	}
+	return response{Success: true, Amount: &amount, Fee: &fee, TxID: txID}, nil
+}
+
+// postSweepProposal returns the amount and fee of sweeping a private key into the account.
+func (handlers *Handlers) postSweepProposal(r *http.Request) (interface{}, error) {
+	return handlers.sweepKey(r, false)
+}
+
+// postSweepSend sweeps a private key into the account.
+func (handlers *Handlers) postSweepSend(r *http.Request) (interface{}, error) {
+	return handlers.sweepKey(r, true)
+}
+
+// replaceEthTx handles requests to replace a pending outgoing Ethereum transaction, given by its
+// ID and a new fee target. The actual replacement (speed up, cancel) is performed by `replace`.
+func (handlers *Handlers) replaceEthTx(
+	r *http.Request,
+	replace func(*eth.Account, string, accounts.FeeTargetCode, string) (string, coin.Amount, error),
+) (interface{}, error) {
+	type response struct {
+		Success      bool             `json:"success"`
+		Aborted      bool             `json:"aborted,omitempty"`
+		TxID         string           `json:"txID,omitempty"`
+		Fee          *FormattedAmount `json:"fee,omitempty"`
+		ErrorCode    string           `json:"errorCode,omitempty"`
+		ErrorMessage string           `json:"errorMessage,omitempty"`
+	}
+	var jsonBody struct {
+		TxID      string `json:"txID"`
+		FeeTarget string `json:"feeTarget"`
+		// Provided in Gwei.
+		CustomFee string `json:"customFee"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	feeTargetCode, err := accounts.NewFeeTargetCode(jsonBody.FeeTarget)
+	if err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	ethAccount, ok := handlers.account.(*eth.Account)
+	if !ok {
+		return response{Success: false, ErrorMessage: "Must be an ETH based account"}, nil
+	}
+	txID, fee, err := replace(ethAccount, jsonBody.TxID, feeTargetCode, jsonBody.CustomFee)
+	if errp.Cause(err) == keystore.ErrSigningAborted || errp.Cause(err) == errp.ErrUserAbort {
+		return response{Success: false, Aborted: true}, nil
+	}
+	if err != nil {
+		handlers.log.WithError(err).Error("Failed to replace transaction")
+		if validationErr, ok := errp.Cause(err).(errors.TxValidationError); ok {
+			return response{Success: false, ErrorCode: validationErr.Error()}, nil
+		}
+		return response{Success: false, ErrorMessage: err.Error()}, nil
+	}
+	formattedFee := handlers.formatAmountAsJSON(fee, true)
+	return response{Success: true, TxID: txID, Fee: &formattedFee}, nil
+}
+
+// postEthSpeedUpTx replaces a pending outgoing Ethereum transaction by the same transaction paying
+// higher fees.
+func (handlers *Handlers) postEthSpeedUpTx(r *http.Request) (interface{}, error) {
+	return handlers.replaceEthTx(r, (*eth.Account).SpeedUpTx)
+}
+
+// postEthCancelTx replaces a pending outgoing Ethereum transaction by a zero-value transfer to
+// ourselves paying higher fees.
+func (handlers *Handlers) postEthCancelTx(r *http.Request) (interface{}, error) {
+	return handlers.replaceEthTx(r, (*eth.Account).CancelTx)
+}
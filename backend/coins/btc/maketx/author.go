@@ -8,6 +8,7 @@ package maketx
 import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/sirupsen/logrus"
 )
 
@@ -47,3 +48,16 @@ func isDustAmount(
 	// (output size + input size) is greater than 1/3 of the relay fee.
 	return int64(amount)*1000/(3*int64(totalSize)) < int64(relayFeePerKb)
 }
+
+// isDustOutput is like isDustAmount, for an output to a recipient, whose configuration is not
+// known. Like Bitcoin Core's dust threshold, the input redeeming it is assumed to have a 107 byte
+// signature script, or the equivalent witness for witness programs.
+func isDustOutput(amount btcutil.Amount, outputInfo *OutputInfo, relayFeePerKb btcutil.Amount) bool {
+	sigScriptSize := 107
+	// Silent payments result in Taproot outputs.
+	if outputInfo.silentPaymentAddress != "" || txscript.IsWitnessProgram(outputInfo.pkScript) {
+		sigScriptSize /= 4
+	}
+	totalSize := outputSize(outputInfo.pkScriptLen()) + calcInputSize(sigScriptSize)
+	return int64(amount)*1000/(3*int64(totalSize)) < int64(relayFeePerKb)
+}
@@ -0,0 +1,248 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx
+
+import (
+	"math"
+	"sort"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// bnbMaxTries limits the number of steps of the branch-and-bound search.
+const bnbMaxTries = 100000
+
+// CoinSelection configures how the coins spent by a new transaction are selected.
+type CoinSelection struct {
+	Strategy accounts.CoinSelectionStrategy
+	// LongTermFeePerKb is the fee rate expected to be needed to spend coins in the long run. If the
+	// current fee rate is higher, spending fewer coins now is cheaper, and vice versa.
+	LongTermFeePerKb btcutil.Amount
+}
+
+// selectionParams contains what is needed to evaluate coin selections for a transaction paying
+// a fixed amount to the recipients.
+type selectionParams struct {
+	utxos               map[wire.OutPoint]UTXO
+	amount              btcutil.Amount
+	outputPkScriptSizes []int
+	changePkScriptSize  int
+	changeConfiguration *signing.Configuration
+	feePerKb            btcutil.Amount
+	longTermFeePerKb    btcutil.Amount
+}
+
+// feeForVSize is the fee for the given virtual size, rounded up.
+func feeForVSize(feePerKb btcutil.Amount, vsize int) btcutil.Amount {
+	return btcutil.Amount(math.Ceil(float64(feePerKb) * float64(vsize) / 1000))
+}
+
+// inputVSize is the virtual size an input spending a coin of the given configuration adds to a
+// transaction, rounded up.
+func inputVSize(configuration *signing.Configuration) int {
+	sigScriptSize, witnessSize := sigScriptWitnessSize(configuration)
+	weight := 4*calcInputSize(sigScriptSize) + witnessSize
+	return (weight + 3) / 4
+}
+
+// inputWaste is the fee paid for spending the coin now instead of at the long-term fee rate.
+// Negative if the current fee rate is below the long-term fee rate.
+func (params *selectionParams) inputWaste(utxo UTXO) btcutil.Amount {
+	vsize := inputVSize(utxo.Address.Configuration)
+	return feeForVSize(params.feePerKb, vsize) - feeForVSize(params.longTermFeePerKb, vsize)
+}
+
+// effectiveValue is the value of the coin minus the fee for spending it.
+func (params *selectionParams) effectiveValue(utxo UTXO) btcutil.Amount {
+	return btcutil.Amount(utxo.TxOut.Value) -
+		feeForVSize(params.feePerKb, inputVSize(utxo.Address.Configuration))
+}
+
+// target is the amount the effective values of the selected coins need to cover in a transaction
+// without change: the amount sent plus the fee for everything but the inputs.
+func (params *selectionParams) target() btcutil.Amount {
+	// The additional vbyte accounts for the segwit marker and flag.
+	return params.amount + feeForVSize(
+		params.feePerKb, estimateTxSizeOutputs(nil, params.outputPkScriptSizes)+1)
+}
+
+// costOfChange is the cost of adding a change output now and spending it later.
+func (params *selectionParams) costOfChange() btcutil.Amount {
+	return feeForVSize(params.feePerKb, outputSize(params.changePkScriptSize)) +
+		feeForVSize(params.longTermFeePerKb, inputVSize(params.changeConfiguration))
+}
+
+// waste computes the waste metric of a selection: the fee paid for the inputs beyond what they
+// would cost at the long-term fee rate, plus the cost of creating change, or the excess given up
+// to the fee if there is no change. Lower is better.
+func (params *selectionParams) waste(
+	outPoints []wire.OutPoint, changeless bool, excess btcutil.Amount) btcutil.Amount {
+	waste := btcutil.Amount(0)
+	for _, outPoint := range outPoints {
+		waste += params.inputWaste(params.utxos[outPoint])
+	}
+	if changeless {
+		return waste + excess
+	}
+	return waste + params.costOfChange()
+}
+
+// branchAndBound searches for a selection of coins covering the amount and fee without needing a
+// change output, i.e. with an excess smaller than the cost of change. Among the solutions found,
+// the one with the least waste is returned. Returns nil if there is no such selection.
+//
+// This follows the algorithm of Bitcoin Core, see https://murch.one/erhardt2016coinselection.pdf.
+func (params *selectionParams) branchAndBound() []wire.OutPoint {
+	type candidate struct {
+		outPoint       wire.OutPoint
+		effectiveValue btcutil.Amount
+		waste          btcutil.Amount
+	}
+	candidates := []candidate{}
+	available := btcutil.Amount(0)
+	for outPoint, utxo := range params.utxos {
+		effectiveValue := params.effectiveValue(utxo)
+		if effectiveValue <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{
+			outPoint:       outPoint,
+			effectiveValue: effectiveValue,
+			waste:          params.inputWaste(utxo),
+		})
+		available += effectiveValue
+	}
+	// Largest first, with a secondary sort to make the search deterministic.
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].effectiveValue == candidates[j].effectiveValue {
+			return candidates[i].outPoint.String() < candidates[j].outPoint.String()
+		}
+		return candidates[i].effectiveValue > candidates[j].effectiveValue
+	})
+
+	target := params.target()
+	costOfChange := params.costOfChange()
+	feeAboveLongTerm := params.feePerKb > params.longTermFeePerKb
+
+	// selection[i] tells whether candidates[i] is included. Its length is the search depth.
+	selection := []bool{}
+	var bestSelection []bool
+	bestWaste := btcutil.Amount(math.MaxInt64)
+	value := btcutil.Amount(0)
+	waste := btcutil.Amount(0)
+	for try := 0; try < bnbMaxTries; try++ {
+		backtrack := false
+		switch {
+		case value+available < target,
+			value > target+costOfChange,
+			// Adding more inputs only increases the waste if the fee rate is above the long-term
+			// fee rate.
+			waste > bestWaste && feeAboveLongTerm:
+			backtrack = true
+		case value >= target:
+			if waste+value-target <= bestWaste {
+				bestSelection = append([]bool{}, selection...)
+				bestWaste = waste + value - target
+			}
+			backtrack = true
+		}
+
+		if backtrack {
+			// Walk back to the last included candidate, restoring the skipped candidates.
+			for len(selection) > 0 && !selection[len(selection)-1] {
+				selection = selection[:len(selection)-1]
+				available += candidates[len(selection)].effectiveValue
+			}
+			if len(selection) == 0 {
+				// The whole tree was searched.
+				break
+			}
+			// Try the branch excluding the last included candidate.
+			last := len(selection) - 1
+			selection[last] = false
+			value -= candidates[last].effectiveValue
+			waste -= candidates[last].waste
+			continue
+		}
+
+		depth := len(selection)
+		current := candidates[depth]
+		available -= current.effectiveValue
+		// Including a candidate equivalent to a previously excluded one results in a selection
+		// which was already explored.
+		if depth > 0 && !selection[depth-1] &&
+			current.effectiveValue == candidates[depth-1].effectiveValue &&
+			current.waste == candidates[depth-1].waste {
+			selection = append(selection, false)
+		} else {
+			selection = append(selection, true)
+			value += current.effectiveValue
+			waste += current.waste
+		}
+	}
+
+	if bestSelection == nil {
+		return nil
+	}
+	outPoints := []wire.OutPoint{}
+	for i, included := range bestSelection {
+		if included {
+			outPoints = append(outPoints, candidates[i].outPoint)
+		}
+	}
+	return outPoints
+}
+
+// privacy returns all the coins of the single address which funds the transaction (amount and fee
+// including change) with the least waste. Spending all coins of an address at once and not
+// combining coins of different addresses avoids linking addresses together, and leaving coins
+// behind on an address whose other coins were already spent. Returns nil if no address has enough
+// coins.
+func (params *selectionParams) privacy() []wire.OutPoint {
+	clusters := map[string][]wire.OutPoint{}
+	for outPoint, utxo := range params.utxos {
+		key := string(utxo.TxOut.PkScript)
+		clusters[key] = append(clusters[key], outPoint)
+	}
+	var best []wire.OutPoint
+	var bestWaste btcutil.Amount
+	var bestKey string
+	for key, outPoints := range clusters {
+		sum := btcutil.Amount(0)
+		for _, outPoint := range outPoints {
+			sum += btcutil.Amount(params.utxos[outPoint].TxOut.Value)
+		}
+		outputPkScriptSizes := append(
+			params.outputPkScriptSizes[:len(params.outputPkScriptSizes):len(params.outputPkScriptSizes)],
+			params.changePkScriptSize)
+		fee := feeForVSize(params.feePerKb, estimateTxSizeOutputs(
+			toInputConfigurations(params.utxos, outPoints), outputPkScriptSizes))
+		if sum < params.amount+fee {
+			continue
+		}
+		changeAmount := sum - params.amount - fee
+		changeless := isDustAmount(
+			changeAmount, params.changePkScriptSize, params.changeConfiguration, params.feePerKb)
+		waste := params.waste(outPoints, changeless, changeAmount)
+		// Ties are broken by the address to make the selection deterministic.
+		if best == nil || waste < bestWaste || (waste == bestWaste && key < bestKey) {
+			best, bestWaste, bestKey = outPoints, waste, key
+		}
+	}
+	return best
+}
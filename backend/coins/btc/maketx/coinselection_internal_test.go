@@ -0,0 +1,132 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	addressesTest "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses/test"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// coinSelectionFixture creates P2PKH coins with the given values. values[i] is a list of coin values
+// received on the i-th address. The outpoints are named by the order of the values.
+func coinSelectionFixture(t *testing.T, values ...[]int64) (
+	map[wire.OutPoint]UTXO, []wire.OutPoint, *addresses.AccountAddress) {
+	t.Helper()
+	_, addressChain := addressesTest.NewAddressChain(
+		func(*addresses.AccountAddress) (bool, error) { return false, nil })
+	someAddresses, err := addressChain.EnsureAddresses()
+	require.NoError(t, err)
+	utxos := map[wire.OutPoint]UTXO{}
+	outPoints := []wire.OutPoint{}
+	for addressIndex, addressValues := range values {
+		address := someAddresses[addressIndex+1]
+		for _, value := range addressValues {
+			outPoint := wire.OutPoint{
+				Hash:  chainhash.HashH([]byte("coin-selection")),
+				Index: uint32(len(outPoints)),
+			}
+			outPoints = append(outPoints, outPoint)
+			utxos[outPoint] = UTXO{TxOut: wire.NewTxOut(value, address.PubkeyScript()), Address: address}
+		}
+	}
+	return utxos, outPoints, someAddresses[0]
+}
+
+func sortedOutPoints(outPoints []wire.OutPoint) []wire.OutPoint {
+	sort.Slice(outPoints, func(i, j int) bool { return outPoints[i].Index < outPoints[j].Index })
+	return outPoints
+}
+
+func TestInputVSize(t *testing.T) {
+	configuration, _ := addressesTest.NewAddressChain(
+		func(*addresses.AccountAddress) (bool, error) { return false, nil })
+	require.Equal(t, 148, inputVSize(configuration))
+}
+
+func TestBranchAndBound(t *testing.T) {
+	// All coins are P2PKH, spending one costs 148 vbytes. With 1 sat/vB, the effective values are
+	// 6000, 4050, 20000 and 10100.
+	utxos, outPoints, changeAddress := coinSelectionFixture(t, []int64{6148, 4198, 20148, 10248})
+	params := &selectionParams{
+		utxos:               utxos,
+		amount:              10000,
+		outputPkScriptSizes: []int{25},
+		changePkScriptSize:  25,
+		changeConfiguration: changeAddress.Configuration,
+		feePerKb:            1000,
+		longTermFeePerKb:    500,
+	}
+	// 10 vbytes overhead, 34 vbytes output and one vbyte for the segwit marker.
+	require.Equal(t, btcutil.Amount(10045), params.target())
+	// 34 vbytes change output at 1 sat/vB and 148 vbytes input at 0.5 sat/vB.
+	require.Equal(t, btcutil.Amount(108), params.costOfChange())
+
+	// Both {6000, 4050} and {10100} match without change. The single input wastes less, as the
+	// current fee rate is above the long-term fee rate.
+	selected := params.branchAndBound()
+	require.Equal(t, []wire.OutPoint{outPoints[3]}, selected)
+	require.Equal(t, btcutil.Amount(74+55), params.waste(selected, true, 55))
+
+	// If fees are expected to rise, consolidating more coins now wastes less.
+	params.longTermFeePerKb = 3000
+	require.Equal(t,
+		[]wire.OutPoint{outPoints[0], outPoints[1]},
+		sortedOutPoints(params.branchAndBound()))
+
+	// No changeless match.
+	params.amount = 15000
+	require.Nil(t, params.branchAndBound())
+
+	// No coins.
+	params.utxos = map[wire.OutPoint]UTXO{}
+	require.Nil(t, params.branchAndBound())
+}
+
+func TestPrivacyCoinSelection(t *testing.T) {
+	utxos, outPoints, changeAddress := coinSelectionFixture(t, []int64{3000, 4000}, []int64{9000})
+	params := &selectionParams{
+		utxos:               utxos,
+		amount:              6000,
+		outputPkScriptSizes: []int{25},
+		changePkScriptSize:  25,
+		changeConfiguration: changeAddress.Configuration,
+		feePerKb:            1000,
+		longTermFeePerKb:    500,
+	}
+	// Both addresses can fund the tx. Spending one coin wastes less.
+	require.Equal(t, []wire.OutPoint{outPoints[2]}, params.privacy())
+
+	// If fees are expected to rise, consolidating both coins of the first address wastes less.
+	params.longTermFeePerKb = 3000
+	require.Equal(t,
+		[]wire.OutPoint{outPoints[0], outPoints[1]},
+		sortedOutPoints(params.privacy()))
+
+	// Only the second address has enough coins. The coins of the first address are never combined
+	// with it.
+	params.amount = 8500
+	require.Equal(t, []wire.OutPoint{outPoints[2]}, params.privacy())
+
+	// No single address has enough coins.
+	params.amount = 12000
+	require.Nil(t, params.privacy())
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// NewTxCPFP creates a child transaction accelerating an unconfirmed parent transaction
+// (child-pays-for-parent). The child spends all given outputs of the parent to a single output to
+// `changeAddress`, which must belong to the wallet.
+//
+// The child fee is chosen so that the parent and child together pay `feePerKb`:
+//
+//	childFee = feePerKb * (parentVSize + childVSize) - parentFee
+//
+// parentOutputs: the outputs of the parent transaction owned by the wallet.
+// parentVSize: the virtual size of the parent transaction.
+// parentFee: the fee paid by the parent transaction.
+func NewTxCPFP(
+	coin coinpkg.Coin,
+	parentOutputs map[wire.OutPoint]UTXO,
+	parentVSize int64,
+	parentFee btcutil.Amount,
+	feePerKb btcutil.Amount,
+	changeAddress *addresses.AccountAddress,
+	log *logrus.Entry,
+) (*TxProposal, error) {
+	if len(parentOutputs) == 0 {
+		return nil, errp.New("The parent transaction has no spendable outputs")
+	}
+	selectedOutPoints := []wire.OutPoint{}
+	inputs := []*wire.TxIn{}
+	outputsSum := btcutil.Amount(0)
+	for outPoint, output := range parentOutputs {
+		selectedOutPoints = append(selectedOutPoints, outPoint)
+		outputsSum += btcutil.Amount(output.TxOut.Value)
+		inputs = append(inputs, wire.NewTxIn(&outPoint, nil, nil))
+	}
+	changePkScript := changeAddress.PubkeyScript()
+	childVSize := estimateTxSize(
+		toInputConfigurations(parentOutputs, selectedOutPoints),
+		len(changePkScript),
+		0)
+	packageFee := feeForSerializeSize(feePerKb, int(parentVSize)+childVSize, log)
+	childFee := packageFee - parentFee
+	if childFee <= feeForSerializeSize(feePerKb, childVSize, log) {
+		// The parent already pays at least the target fee rate.
+		return nil, errp.WithStack(errors.ErrFeeTooLow)
+	}
+	if outputsSum < childFee {
+		return nil, errp.WithStack(errors.ErrInsufficientFunds)
+	}
+	childAmount := outputsSum - childFee
+	if isDustAmount(childAmount, len(changePkScript), changeAddress.Configuration, feePerKb) {
+		return nil, errp.WithStack(errors.ErrInsufficientFunds)
+	}
+	unsignedTransaction := &wire.MsgTx{
+		Version:  wire.TxVersion,
+		TxIn:     inputs,
+		TxOut:    []*wire.TxOut{wire.NewTxOut(int64(childAmount), changePkScript)},
+		LockTime: 0,
+	}
+
+	log.WithFields(logrus.Fields{"parentFee": parentFee, "fee": childFee}).
+		Debug("Preparing child-pays-for-parent transaction")
+
+	setRBF(coin, unsignedTransaction)
+	return &TxProposal{
+		Coin:            coin,
+		Amount:          childAmount,
+		Fee:             childFee,
+		Transaction:     unsignedTransaction,
+		ChangeAddress:   changeAddress,
+		PreviousOutputs: parentOutputs,
+		OutIndex:        0,
+	}, nil
+}
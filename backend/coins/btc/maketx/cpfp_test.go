@@ -0,0 +1,86 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx_test
+
+import (
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	addressesTest "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses/test"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTxCPFP(t *testing.T) {
+	log := logging.Get().WithGroup("cpfp_test")
+	_, addressChain := addressesTest.NewAddressChain(
+		func(*addresses.AccountAddress) (bool, error) { return false, nil })
+	someAddresses, err := addressChain.EnsureAddresses()
+	require.NoError(t, err)
+	changeAddress := someAddresses[0]
+
+	parentHash := chainhash.HashH([]byte("parent"))
+	parentOutputs := map[wire.OutPoint]maketx.UTXO{
+		{Hash: parentHash, Index: 1}: {
+			TxOut:   wire.NewTxOut(100000, someAddresses[1].PubkeyScript()),
+			Address: someAddresses[1],
+		},
+	}
+	// Parent of 200 vbytes paying 1 sat/vB.
+	const parentVSize = 200
+	const parentFee = btcutil.Amount(200)
+
+	for _, c := range []struct {
+		name string
+		tx   func() (*maketx.TxProposal, error)
+	}{
+		{"tbtc", func() (*maketx.TxProposal, error) {
+			return maketx.NewTxCPFP(tbtc, parentOutputs, parentVSize, parentFee, 10000, changeAddress, log)
+		}},
+		{"tltc", func() (*maketx.TxProposal, error) {
+			return maketx.NewTxCPFP(tltc, parentOutputs, parentVSize, parentFee, 10000, changeAddress, log)
+		}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			txProposal, err := c.tx()
+			require.NoError(t, err)
+			tx := txProposal.Transaction
+			require.Len(t, tx.TxIn, 1)
+			require.Equal(t, wire.OutPoint{Hash: parentHash, Index: 1}, tx.TxIn[0].PreviousOutPoint)
+			require.Len(t, tx.TxOut, 1)
+			require.Equal(t, changeAddress.PubkeyScript(), tx.TxOut[0].PkScript)
+			require.Equal(t, changeAddress, txProposal.ChangeAddress)
+			require.Equal(t, int64(100000), tx.TxOut[0].Value+int64(txProposal.Fee))
+
+			// P2PKH, one input and one output: 192 vbytes.
+			const childVSize = 192
+			require.Equal(t, btcutil.Amount(10*(parentVSize+childVSize))-parentFee, txProposal.Fee)
+		})
+	}
+
+	// The parent already pays the target fee rate.
+	_, err = maketx.NewTxCPFP(tbtc, parentOutputs, parentVSize, parentFee, 1000, changeAddress, log)
+	require.Equal(t, errors.ErrFeeTooLow, errp.Cause(err))
+
+	// The parent outputs are not enough to pay for the package.
+	_, err = maketx.NewTxCPFP(tbtc, parentOutputs, parentVSize, parentFee, 300000, changeAddress, log)
+	require.Equal(t, errors.ErrInsufficientFunds, errp.Cause(err))
+}
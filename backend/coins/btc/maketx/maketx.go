@@ -60,6 +60,8 @@ type TxProposal struct {
 	SilentPaymentAddress string
 	// OutIndex is the index of the output we send to.
 	OutIndex int
+	// CoinSelection is the strategy which selected the spent coins. Empty if all coins are spent.
+	CoinSelection accounts.CoinSelectionStrategy
 }
 
 // SigHashes computes the hashes cache to speed up per-input sighash computations.
@@ -76,6 +78,20 @@ func (txProposal *TxProposal) Total() btcutil.Amount {
 type UTXO struct {
 	TxOut   *wire.TxOut
 	Address *addresses.AccountAddress
+	// Frozen coins are excluded from new transactions. The caller unsets it for frozen coins the
+	// user explicitly selected.
+	Frozen bool
+}
+
+// withoutFrozen returns the coins which are not frozen.
+func withoutFrozen(utxos map[wire.OutPoint]UTXO) map[wire.OutPoint]UTXO {
+	result := make(map[wire.OutPoint]UTXO, len(utxos))
+	for outPoint, utxo := range utxos {
+		if !utxo.Frozen {
+			result[outPoint] = utxo
+		}
+	}
+	return result
 }
 
 type byValue struct {
@@ -93,7 +109,8 @@ func (p *byValue) Less(i, j int) bool {
 }
 func (p *byValue) Swap(i, j int) { p.outPoints[i], p.outPoints[j] = p.outPoints[j], p.outPoints[i] }
 
-func coinSelection(
+// largestFirstCoinSelection selects the largest coins until their sum covers minAmount.
+func largestFirstCoinSelection(
 	minAmount btcutil.Amount,
 	outputs map[wire.OutPoint]UTXO,
 ) (btcutil.Amount, []wire.OutPoint, error) {
@@ -171,6 +188,12 @@ func NewOutputInfo(pkScript []byte) *OutputInfo {
 	return &OutputInfo{pkScript: pkScript}
 }
 
+// Recipient is a recipient of a transaction paid with a fixed amount.
+type Recipient struct {
+	OutputInfo *OutputInfo
+	Amount     btcutil.Amount
+}
+
 // NewTxSpendAll creates a transaction which spends all available unspent outputs.
 func NewTxSpendAll(
 	coin coinpkg.Coin,
@@ -179,46 +202,7 @@ func NewTxSpendAll(
 	feePerKb btcutil.Amount,
 	log *logrus.Entry,
 ) (*TxProposal, error) {
-	selectedOutPoints := []wire.OutPoint{}
-	inputs := []*wire.TxIn{}
-	outputsSum := btcutil.Amount(0)
-	for outPoint, output := range spendableOutputs {
-		selectedOutPoints = append(selectedOutPoints, outPoint)
-		outputsSum += btcutil.Amount(output.TxOut.Value)
-		inputs = append(inputs, wire.NewTxIn(&outPoint, nil, nil))
-	}
-	txSize := estimateTxSize(
-		toInputConfigurations(spendableOutputs, selectedOutPoints),
-		outputInfo.pkScriptLen(),
-		0)
-	maxRequiredFee := feeForSerializeSize(feePerKb, txSize, log)
-	if outputsSum < maxRequiredFee {
-		return nil, errp.WithStack(errors.ErrInsufficientFunds)
-	}
-	output := wire.NewTxOut(int64(outputsSum-maxRequiredFee), outputInfo.pkScript)
-	unsignedTransaction := &wire.MsgTx{
-		Version:  wire.TxVersion,
-		TxIn:     inputs,
-		TxOut:    []*wire.TxOut{output},
-		LockTime: 0,
-	}
-
-	secureRand := mrand.New(mrand.NewSource(secureSeed()))
-	shuffleTxInputsAndOutputs(unsignedTransaction, secureRand)
-
-	log.WithField("fee", maxRequiredFee).Debug("Preparing transaction to spend all outputs")
-
-	setRBF(coin, unsignedTransaction)
-	return &TxProposal{
-		Coin:                 coin,
-		Amount:               btcutil.Amount(output.Value),
-		Fee:                  maxRequiredFee,
-		Transaction:          unsignedTransaction,
-		PreviousOutputs:      spendableOutputs,
-		SilentPaymentAddress: outputInfo.silentPaymentAddress,
-		// Only one output in send-all
-		OutIndex: 0,
-	}, nil
+	return NewTxRecipients(coin, spendableOutputs, nil, outputInfo, feePerKb, nil, nil, log)
 }
 
 // NewTx creates a transaction from a set of unspent outputs, targeting an output value. A subset of
@@ -234,70 +218,102 @@ func NewTx(
 	changeAddress *addresses.AccountAddress,
 	log *logrus.Entry,
 ) (*TxProposal, error) {
-	output := wire.NewTxOut(outputAmount, outputInfo.pkScript)
-
-	targetAmount := btcutil.Amount(output.Value)
-	if targetAmount <= 0 {
+	if outputAmount <= 0 {
 		panic("amount must be positive")
 	}
-	outputs := []*wire.TxOut{output}
-	changePKScript := changeAddress.PubkeyScript()
-
-	targetFee := btcutil.Amount(0)
-	for {
-		selectedOutputsSum, selectedOutPoints, err := coinSelection(
-			targetAmount+targetFee,
-			spendableOutputs,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		txSize := estimateTxSize(
-			toInputConfigurations(spendableOutputs, selectedOutPoints),
-			outputInfo.pkScriptLen(),
-			len(changePKScript))
-		maxRequiredFee := feeForSerializeSize(feePerKb, txSize, log)
-		if selectedOutputsSum-targetAmount < maxRequiredFee {
-			targetFee = maxRequiredFee
-			continue
-		}
+	return NewTxRecipients(
+		coin,
+		spendableOutputs,
+		[]*Recipient{{OutputInfo: outputInfo, Amount: btcutil.Amount(outputAmount)}},
+		nil,
+		feePerKb,
+		changeAddress,
+		nil,
+		log,
+	)
+}
 
-		inputs := make([]*wire.TxIn, len(selectedOutPoints))
-		previousOutputs := make(PreviousOutputs, len(selectedOutPoints))
-		for i, outPoint := range selectedOutPoints {
-			inputs[i] = wire.NewTxIn(&outPoint, nil, nil)
-			previousOutputs[outPoint] = spendableOutputs[outPoint]
+// NewTxRecipients creates a transaction paying several recipients at once (batch payment).
+//
+// recipients: the recipients receiving a fixed amount each.
+//
+// Frozen coins in spendableOutputs are never spent.
+//
+// remainingRecipient: if not nil, all unspent outputs are spent and this recipient receives what
+// remains after paying the other recipients and the fee. No change output is added in this case.
+//
+// changeAddress: a change output to this address is added if needed. Unused if remainingRecipient
+// is set.
+//
+// coinSelection: the strategy selecting the coins to spend. Largest-first is used if nil.
+//
+// At most one recipient can be a silent payment recipient. The OutIndex of the returned proposal
+// points to the silent payment output if there is one, and to the first recipient otherwise.
+func NewTxRecipients(
+	coin coinpkg.Coin,
+	spendableOutputs map[wire.OutPoint]UTXO,
+	recipients []*Recipient,
+	remainingRecipient *OutputInfo,
+	feePerKb btcutil.Amount,
+	changeAddress *addresses.AccountAddress,
+	coinSelection *CoinSelection,
+	log *logrus.Entry,
+) (*TxProposal, error) {
+	if len(recipients) == 0 && remainingRecipient == nil {
+		return nil, errp.New("A transaction needs at least one recipient")
+	}
+	spendableOutputs = withoutFrozen(spendableOutputs)
+	outputs := []*wire.TxOut{}
+	outputPkScriptSizes := []int{}
+	var silentPaymentAddress string
+	var silentPaymentOutput *wire.TxOut
+	addOutput := func(outputInfo *OutputInfo, amount btcutil.Amount) (*wire.TxOut, error) {
+		output := wire.NewTxOut(int64(amount), outputInfo.pkScript)
+		if outputInfo.silentPaymentAddress != "" {
+			if silentPaymentOutput != nil {
+				return nil, errp.New("Only one silent payment recipient is supported per transaction")
+			}
+			silentPaymentAddress = outputInfo.silentPaymentAddress
+			silentPaymentOutput = output
 		}
-		unsignedTransaction := &wire.MsgTx{
-			Version:  wire.TxVersion,
-			TxIn:     inputs,
-			TxOut:    outputs,
-			LockTime: 0,
+		outputs = append(outputs, output)
+		outputPkScriptSizes = append(outputPkScriptSizes, outputInfo.pkScriptLen())
+		return output, nil
+	}
+	recipientsSum := btcutil.Amount(0)
+	for _, recipient := range recipients {
+		if recipient.Amount <= 0 {
+			return nil, errp.WithStack(errors.ErrInvalidAmount)
 		}
-		changeAmount := selectedOutputsSum - targetAmount - maxRequiredFee
-		changeIsDust := isDustAmount(
-			changeAmount, len(changePKScript), changeAddress.Configuration, feePerKb)
-		finalFee := maxRequiredFee
-		if changeIsDust {
-			log.Info("change is dust")
-			finalFee = selectedOutputsSum - targetAmount
+		if _, err := addOutput(recipient.OutputInfo, recipient.Amount); err != nil {
+			return nil, err
 		}
-		if changeAmount != 0 && !changeIsDust {
-			unsignedTransaction.TxOut = append(unsignedTransaction.TxOut,
-				wire.NewTxOut(int64(changeAmount), changePKScript))
-		} else {
-			changeAddress = nil
+		recipientsSum += recipient.Amount
+	}
+
+	finish := func(
+		unsignedTransaction *wire.MsgTx,
+		amount btcutil.Amount,
+		fee btcutil.Amount,
+		changeAddress *addresses.AccountAddress,
+		previousOutputs PreviousOutputs,
+		strategy accounts.CoinSelectionStrategy,
+	) (*TxProposal, error) {
+		// The output identified by OutIndex, tracked by pointer across the shuffle.
+		mainOutput := outputs[0]
+		if silentPaymentOutput != nil {
+			mainOutput = silentPaymentOutput
 		}
 
 		secureRand := mrand.New(mrand.NewSource(secureSeed()))
 		shuffleTxInputsAndOutputs(unsignedTransaction, secureRand)
 
-		log.WithField("fee", finalFee).Debug("Preparing transaction")
+		log.WithFields(logrus.Fields{"fee": fee, "recipients": len(outputs)}).
+			Debug("Preparing transaction")
 
 		outIndex := -1
 		for i, txOut := range unsignedTransaction.TxOut {
-			if txOut == output {
+			if txOut == mainOutput {
 				outIndex = i
 				break
 			}
@@ -309,15 +325,166 @@ func NewTx(
 		setRBF(coin, unsignedTransaction)
 		return &TxProposal{
 			Coin:                 coin,
-			Amount:               targetAmount,
-			Fee:                  finalFee,
+			Amount:               amount,
+			Fee:                  fee,
 			Transaction:          unsignedTransaction,
 			ChangeAddress:        changeAddress,
 			PreviousOutputs:      previousOutputs,
-			SilentPaymentAddress: outputInfo.silentPaymentAddress,
+			SilentPaymentAddress: silentPaymentAddress,
 			OutIndex:             outIndex,
+			CoinSelection:        strategy,
 		}, nil
 	}
+
+	if remainingRecipient != nil {
+		remainingOutput, err := addOutput(remainingRecipient, 0)
+		if err != nil {
+			return nil, err
+		}
+		selectedOutPoints := []wire.OutPoint{}
+		inputs := []*wire.TxIn{}
+		outputsSum := btcutil.Amount(0)
+		for outPoint, output := range spendableOutputs {
+			selectedOutPoints = append(selectedOutPoints, outPoint)
+			outputsSum += btcutil.Amount(output.TxOut.Value)
+			inputs = append(inputs, wire.NewTxIn(&outPoint, nil, nil))
+		}
+		txSize := estimateTxSizeOutputs(
+			toInputConfigurations(spendableOutputs, selectedOutPoints),
+			outputPkScriptSizes)
+		maxRequiredFee := feeForSerializeSize(feePerKb, txSize, log)
+		if outputsSum < recipientsSum+maxRequiredFee {
+			return nil, errp.WithStack(errors.ErrInsufficientFunds)
+		}
+		remainingAmount := outputsSum - recipientsSum - maxRequiredFee
+		if remainingAmount == 0 || isDustOutput(remainingAmount, remainingRecipient, feePerKb) {
+			return nil, errp.WithStack(errors.ErrInsufficientFunds)
+		}
+		remainingOutput.Value = int64(remainingAmount)
+		unsignedTransaction := &wire.MsgTx{
+			Version:  wire.TxVersion,
+			TxIn:     inputs,
+			TxOut:    outputs,
+			LockTime: 0,
+		}
+		return finish(
+			unsignedTransaction,
+			outputsSum-maxRequiredFee,
+			maxRequiredFee,
+			nil,
+			spendableOutputs,
+			"",
+		)
+	}
+
+	changePKScript := changeAddress.PubkeyScript()
+	outputPkScriptSizesWithChange := append(
+		outputPkScriptSizes[:len(outputPkScriptSizes):len(outputPkScriptSizes)],
+		len(changePKScript))
+	// build makes the transaction spending the selected coins. Unless changeless is true, a change
+	// output is added if the change is not dust.
+	build := func(
+		selectedOutPoints []wire.OutPoint,
+		selectedOutputsSum btcutil.Amount,
+		changeless bool,
+		strategy accounts.CoinSelectionStrategy,
+	) (*TxProposal, error) {
+		inputs := make([]*wire.TxIn, len(selectedOutPoints))
+		previousOutputs := make(PreviousOutputs, len(selectedOutPoints))
+		for i, outPoint := range selectedOutPoints {
+			inputs[i] = wire.NewTxIn(&outPoint, nil, nil)
+			previousOutputs[outPoint] = spendableOutputs[outPoint]
+		}
+		unsignedTransaction := &wire.MsgTx{
+			Version:  wire.TxVersion,
+			TxIn:     inputs,
+			TxOut:    outputs,
+			LockTime: 0,
+		}
+		finalFee := selectedOutputsSum - recipientsSum
+		changeAddress := changeAddress
+		if changeless {
+			changeAddress = nil
+		} else {
+			maxRequiredFee := feeForSerializeSize(feePerKb, estimateTxSizeOutputs(
+				toInputConfigurations(spendableOutputs, selectedOutPoints),
+				outputPkScriptSizesWithChange), log)
+			changeAmount := selectedOutputsSum - recipientsSum - maxRequiredFee
+			changeIsDust := isDustAmount(
+				changeAmount, len(changePKScript), changeAddress.Configuration, feePerKb)
+			if changeIsDust {
+				log.Info("change is dust")
+			} else {
+				finalFee = maxRequiredFee
+			}
+			if changeAmount != 0 && !changeIsDust {
+				unsignedTransaction.TxOut = append(unsignedTransaction.TxOut,
+					wire.NewTxOut(int64(changeAmount), changePKScript))
+			} else {
+				changeAddress = nil
+			}
+		}
+		return finish(unsignedTransaction, recipientsSum, finalFee, changeAddress, previousOutputs, strategy)
+	}
+
+	if coinSelection != nil && coinSelection.Strategy != accounts.CoinSelectionLargestFirst {
+		params := &selectionParams{
+			utxos:               spendableOutputs,
+			amount:              recipientsSum,
+			outputPkScriptSizes: outputPkScriptSizes,
+			changePkScriptSize:  len(changePKScript),
+			changeConfiguration: changeAddress.Configuration,
+			feePerKb:            feePerKb,
+			longTermFeePerKb:    coinSelection.LongTermFeePerKb,
+		}
+		var selectedOutPoints []wire.OutPoint
+		changeless := false
+		switch coinSelection.Strategy {
+		case accounts.CoinSelectionBranchAndBound:
+			selectedOutPoints = params.branchAndBound()
+			changeless = true
+		case accounts.CoinSelectionPrivacy:
+			selectedOutPoints = params.privacy()
+		default:
+			return nil, errp.Newf("Unknown coin selection strategy %s", coinSelection.Strategy)
+		}
+		selectedOutputsSum := btcutil.Amount(0)
+		for _, outPoint := range selectedOutPoints {
+			selectedOutputsSum += btcutil.Amount(spendableOutputs[outPoint].TxOut.Value)
+		}
+		requiredSizes := outputPkScriptSizes
+		if !changeless {
+			requiredSizes = outputPkScriptSizesWithChange
+		}
+		requiredFee := feeForSerializeSize(feePerKb, estimateTxSizeOutputs(
+			toInputConfigurations(spendableOutputs, selectedOutPoints), requiredSizes), log)
+		if selectedOutPoints != nil && selectedOutputsSum-recipientsSum >= requiredFee {
+			return build(selectedOutPoints, selectedOutputsSum, changeless, coinSelection.Strategy)
+		}
+		log.WithField("strategy", coinSelection.Strategy).
+			Info("No coin selection found, falling back to largest-first")
+	}
+
+	targetFee := btcutil.Amount(0)
+	for {
+		selectedOutputsSum, selectedOutPoints, err := largestFirstCoinSelection(
+			recipientsSum+targetFee,
+			spendableOutputs,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		txSize := estimateTxSizeOutputs(
+			toInputConfigurations(spendableOutputs, selectedOutPoints),
+			outputPkScriptSizesWithChange)
+		maxRequiredFee := feeForSerializeSize(feePerKb, txSize, log)
+		if selectedOutputsSum-recipientsSum < maxRequiredFee {
+			targetFee = maxRequiredFee
+			continue
+		}
+		return build(selectedOutPoints, selectedOutputsSum, false, accounts.CoinSelectionLargestFirst)
+	}
 }
 
 // shuffleTxInputsAndOutputs shuffles both the TxIn and TxOut slices of a wire.MsgTx.
@@ -19,6 +19,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
@@ -304,6 +305,18 @@ func (s *newTxSuite) TestNewTxInsufficientFunds() {
 	_, err = s.newTxSpendAll(feePerKb, s.buildUTXO())
 	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
 
+	// Spending all coins fails if the remaining amount after the fee is dust.
+	txProposal, err := s.newTxSpendAll(feePerKb, s.buildUTXO(mBTC))
+	s.Require().NoError(err)
+	fee := txProposal.Fee
+	_, err = s.newTxSpendAll(feePerKb, s.buildUTXO(int64(fee)))
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+	_, err = s.newTxSpendAll(feePerKb, s.buildUTXO(int64(fee)+1))
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+	txProposal, err = s.newTxSpendAll(feePerKb, s.buildUTXO(int64(fee)+1000))
+	s.Require().NoError(err)
+	s.Require().Equal(btcutil.Amount(1000), txProposal.Amount)
+
 	// Using one coin.
 	_, err = s.newTx(amount, feePerKb, s.buildUTXO(mBTC))
 	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
@@ -347,3 +360,201 @@ func (s *newTxSuite) TestNewTxCoinSelection() {
 	s.check(true, btcutil.Amount(100299738), feePerKb, s.buildUTXO(mBTC, 2*mBTC, 1000*mBTC+txSizeOneInput), s.change(0), noDust, s.selectCoins(0, 1, 2))
 
 }
+
+// recipients returns n recipients, paying 1000, 2000, ... satoshi to distinct addresses.
+func (s *newTxSuite) recipients(n int) []*maketx.Recipient {
+	recipients := make([]*maketx.Recipient, n)
+	for i := range recipients {
+		recipients[i] = &maketx.Recipient{
+			OutputInfo: maketx.NewOutputInfo(s.someAddresses[i+1].PubkeyScript()),
+			Amount:     btcutil.Amount(1000 * (i + 1)),
+		}
+	}
+	return recipients
+}
+
+// requireOutput checks that the tx has exactly one output to pkScript and returns its index.
+func (s *newTxSuite) requireOutput(tx *wire.MsgTx, pkScript []byte, value int64) int {
+	index := -1
+	for i, txOut := range tx.TxOut {
+		if bytes.Equal(pkScript, txOut.PkScript) {
+			s.Require().Equal(-1, index)
+			index = i
+		}
+	}
+	s.Require().GreaterOrEqual(index, 0)
+	s.Require().Equal(value, tx.TxOut[index].Value)
+	return index
+}
+
+func (s *newTxSuite) TestNewTxRecipients() {
+	const mBTC = 100000
+	feePerKb := btcutil.Amount(1000) // 1 sat / vbyte
+	recipients := s.recipients(3)
+
+	txProposal, err := maketx.NewTxRecipients(
+		s.coin, s.buildUTXO(mBTC, 2*mBTC), recipients, nil, feePerKb, s.changeAddress, nil, s.log)
+	s.Require().NoError(err)
+	tx := txProposal.Transaction
+	// The larger coin covers all recipients.
+	s.Require().Len(tx.TxIn, 1)
+	s.Require().Equal(s.outpoint(1), tx.TxIn[0].PreviousOutPoint)
+	s.Require().Len(tx.TxOut, 4)
+	for i, recipient := range recipients {
+		index := s.requireOutput(tx, s.someAddresses[i+1].PubkeyScript(), int64(recipient.Amount))
+		if i == 0 {
+			s.Require().Equal(index, txProposal.OutIndex)
+		}
+	}
+	s.Require().Equal(btcutil.Amount(6000), txProposal.Amount)
+	s.Require().Equal(s.changeAddress, txProposal.ChangeAddress)
+
+	pkScriptSize := len(s.changeAddress.PubkeyScript())
+	expectedFee := maketx.TstFeeForSerializeSize(
+		feePerKb,
+		maketx.TstEstimateTxSizeOutputs(
+			[]*signing.Configuration{s.inputConfiguration},
+			[]int{pkScriptSize, pkScriptSize, pkScriptSize, pkScriptSize}),
+		s.log)
+	s.Require().Equal(expectedFee, txProposal.Fee)
+	s.requireOutput(tx, s.changeAddress.PubkeyScript(), int64(2*mBTC-6000-expectedFee))
+	for _, txIn := range tx.TxIn {
+		if s.coin == tbtc {
+			s.Require().Equal(wire.MaxTxInSequenceNum-2, txIn.Sequence)
+		}
+	}
+
+	// Not enough coins for all recipients.
+	_, err = maketx.NewTxRecipients(
+		s.coin, s.buildUTXO(6000), recipients, nil, feePerKb, s.changeAddress, nil, s.log)
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+
+	// Invalid amount.
+	_, err = maketx.NewTxRecipients(
+		s.coin, s.buildUTXO(mBTC),
+		[]*maketx.Recipient{recipients[0], {OutputInfo: recipients[1].OutputInfo}},
+		nil, feePerKb, s.changeAddress, nil, s.log)
+	s.Require().Equal(errors.ErrInvalidAmount, errp.Cause(err))
+
+	// No recipients.
+	_, err = maketx.NewTxRecipients(
+		s.coin, s.buildUTXO(mBTC), nil, nil, feePerKb, s.changeAddress, nil, s.log)
+	s.Require().Error(err)
+
+	// Only one silent payment recipient per transaction.
+	_, err = maketx.NewTxRecipients(
+		s.coin, s.buildUTXO(mBTC),
+		[]*maketx.Recipient{
+			{OutputInfo: maketx.NewOutputInfoSilentPayment("sp1a"), Amount: 1000},
+			{OutputInfo: maketx.NewOutputInfoSilentPayment("sp1b"), Amount: 1000},
+		},
+		nil, feePerKb, s.changeAddress, nil, s.log)
+	s.Require().Error(err)
+}
+
+func (s *newTxSuite) TestNewTxRecipientsSendRemaining() {
+	const mBTC = 100000
+	feePerKb := btcutil.Amount(1000) // 1 sat / vbyte
+	recipients := s.recipients(2)
+	remainingPkScript := s.someAddresses[3].PubkeyScript()
+
+	txProposal, err := maketx.NewTxRecipients(
+		s.coin, s.buildUTXO(mBTC, 2*mBTC), recipients,
+		maketx.NewOutputInfo(remainingPkScript), feePerKb, s.changeAddress, nil, s.log)
+	s.Require().NoError(err)
+	tx := txProposal.Transaction
+	// All coins are spent and there is no change.
+	s.Require().Len(tx.TxIn, 2)
+	s.Require().Len(tx.TxOut, 3)
+	s.Require().Nil(txProposal.ChangeAddress)
+	index := s.requireOutput(tx, s.someAddresses[1].PubkeyScript(), 1000)
+	s.Require().Equal(index, txProposal.OutIndex)
+	s.requireOutput(tx, s.someAddresses[2].PubkeyScript(), 2000)
+
+	pkScriptSize := len(remainingPkScript)
+	expectedFee := maketx.TstFeeForSerializeSize(
+		feePerKb,
+		maketx.TstEstimateTxSizeOutputs(
+			[]*signing.Configuration{s.inputConfiguration, s.inputConfiguration},
+			[]int{pkScriptSize, pkScriptSize, pkScriptSize}),
+		s.log)
+	s.Require().Equal(expectedFee, txProposal.Fee)
+	s.requireOutput(tx, remainingPkScript, int64(3*mBTC-3000-expectedFee))
+	s.Require().Equal(btcutil.Amount(3*mBTC)-expectedFee, txProposal.Amount)
+
+	// The fixed recipients and the fee exceed the available coins.
+	_, err = maketx.NewTxRecipients(
+		s.coin, s.buildUTXO(3000), recipients,
+		maketx.NewOutputInfo(remainingPkScript), feePerKb, s.changeAddress, nil, s.log)
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+}
+
+func (s *newTxSuite) TestNewTxRecipientsCoinSelection() {
+	feePerKb := btcutil.Amount(1000) // 1 sat / vbyte
+	recipients := []*maketx.Recipient{
+		{OutputInfo: maketx.NewOutputInfo(s.outputPkScript), Amount: 10000},
+	}
+	branchAndBound := &maketx.CoinSelection{
+		Strategy:         accounts.CoinSelectionBranchAndBound,
+		LongTermFeePerKb: 500,
+	}
+	// Largest-first spends the largest coin and creates change, while 10000 + 192 sat fee for one
+	// input and one output matches the second coin closely enough to not need change.
+	utxo := s.buildUTXO(50000, 10200)
+	txProposal, err := maketx.NewTxRecipients(
+		s.coin, utxo, recipients, nil, feePerKb, s.changeAddress, nil, s.log)
+	s.Require().NoError(err)
+	s.Require().Equal(accounts.CoinSelectionLargestFirst, txProposal.CoinSelection)
+	s.Require().Equal(s.outpoint(0), txProposal.Transaction.TxIn[0].PreviousOutPoint)
+	s.Require().Len(txProposal.Transaction.TxOut, 2)
+
+	txProposal, err = maketx.NewTxRecipients(
+		s.coin, utxo, recipients, nil, feePerKb, s.changeAddress, branchAndBound, s.log)
+	s.Require().NoError(err)
+	s.Require().Equal(accounts.CoinSelectionBranchAndBound, txProposal.CoinSelection)
+	s.Require().Len(txProposal.Transaction.TxIn, 1)
+	s.Require().Equal(s.outpoint(1), txProposal.Transaction.TxIn[0].PreviousOutPoint)
+	s.Require().Len(txProposal.Transaction.TxOut, 1)
+	s.Require().Nil(txProposal.ChangeAddress)
+	s.Require().Equal(btcutil.Amount(200), txProposal.Fee)
+
+	// No changeless match, falling back to largest-first.
+	txProposal, err = maketx.NewTxRecipients(
+		s.coin, s.buildUTXO(50000, 20000), recipients, nil, feePerKb, s.changeAddress,
+		branchAndBound, s.log)
+	s.Require().NoError(err)
+	s.Require().Equal(accounts.CoinSelectionLargestFirst, txProposal.CoinSelection)
+	s.Require().Equal(s.outpoint(0), txProposal.Transaction.TxIn[0].PreviousOutPoint)
+	s.Require().Len(txProposal.Transaction.TxOut, 2)
+
+	// All coins are on the same address, so the privacy strategy spends all of them.
+	txProposal, err = maketx.NewTxRecipients(
+		s.coin, s.buildUTXO(50000, 20000), recipients, nil, feePerKb, s.changeAddress,
+		&maketx.CoinSelection{Strategy: accounts.CoinSelectionPrivacy, LongTermFeePerKb: 500}, s.log)
+	s.Require().NoError(err)
+	s.Require().Equal(accounts.CoinSelectionPrivacy, txProposal.CoinSelection)
+	s.Require().Len(txProposal.Transaction.TxIn, 2)
+	s.Require().Equal(s.changeAddress, txProposal.ChangeAddress)
+}
+
+func (s *newTxSuite) TestNewTxFrozen() {
+	feePerKb := btcutil.Amount(1000) // 1 sat / vbyte
+	utxo := s.buildUTXO(50000, 20000)
+	frozen := utxo[s.outpoint(0)]
+	frozen.Frozen = true
+	utxo[s.outpoint(0)] = frozen
+
+	// The largest coin is frozen and not spent.
+	txProposal, err := s.newTx(10000, feePerKb, utxo)
+	s.Require().NoError(err)
+	s.Require().Len(txProposal.Transaction.TxIn, 1)
+	s.Require().Equal(s.outpoint(1), txProposal.Transaction.TxIn[0].PreviousOutPoint)
+
+	txProposal, err = s.newTxSpendAll(feePerKb, utxo)
+	s.Require().NoError(err)
+	s.Require().Len(txProposal.Transaction.TxIn, 1)
+	s.Require().Equal(s.outpoint(1), txProposal.Transaction.TxIn[0].PreviousOutPoint)
+
+	_, err = s.newTx(30000, feePerKb, utxo)
+	s.Require().Equal(errors.ErrInsufficientFunds, errp.Cause(err))
+}
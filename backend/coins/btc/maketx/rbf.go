@@ -0,0 +1,224 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx
+
+import (
+	mrand "math/rand"
+	"sort"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// SignalsRBF returns true if the transaction signals replaceability as defined in BIP-125, i.e. if
+// at least one of its inputs has a sequence number smaller than 0xfffffffe.
+func SignalsRBF(tx *wire.MsgTx) bool {
+	for _, txIn := range tx.TxIn {
+		if txIn.Sequence < wire.MaxTxInSequenceNum-1 {
+			return true
+		}
+	}
+	return false
+}
+
+// NewTxBumpFee creates a transaction replacing `tx` according to BIP-125, paying a fee of
+// `feePerKb`. All inputs of the original transaction are kept, so that the replacement conflicts
+// with the original. All outputs except for the change output are kept as well.
+//
+// The additional fee is taken from the change output. If the change output is not large enough,
+// additional inputs from `spendableOutputs` are added, except for frozen ones. These must be
+// confirmed, as BIP-125 does not allow new unconfirmed inputs in a replacement.
+//
+// previousOutputs: the outputs spent by `tx`.
+// changeIndex: the index of the change output in `tx`, or -1 if there is none.
+// changeAddress: the address of the change output, or a new change address if there is none. It is
+// used in case a change output needs to be added.
+// minRelayFeePerKb: the incremental relay fee. The replacement needs to pay at least this rate on top
+// of the fee of the original transaction.
+func NewTxBumpFee(
+	coin coinpkg.Coin,
+	tx *wire.MsgTx,
+	previousOutputs PreviousOutputs,
+	changeIndex int,
+	changeAddress *addresses.AccountAddress,
+	spendableOutputs map[wire.OutPoint]UTXO,
+	feePerKb btcutil.Amount,
+	minRelayFeePerKb btcutil.Amount,
+	log *logrus.Entry,
+) (*TxProposal, error) {
+	if !SignalsRBF(tx) {
+		return nil, errp.New("The transaction does not signal replaceability (BIP-125)")
+	}
+	if changeIndex >= len(tx.TxOut) {
+		return nil, errp.New("invalid change output index")
+	}
+	spendableOutputs = withoutFrozen(spendableOutputs)
+
+	inputs := []*wire.TxIn{}
+	inputConfigurations := []*signing.Configuration{}
+	newPreviousOutputs := PreviousOutputs{}
+	inputsSum := btcutil.Amount(0)
+	addInput := func(outPoint wire.OutPoint, utxo UTXO) {
+		inputs = append(inputs, wire.NewTxIn(&outPoint, nil, nil))
+		inputConfigurations = append(inputConfigurations, utxo.Address.Configuration)
+		newPreviousOutputs[outPoint] = utxo
+		inputsSum += btcutil.Amount(utxo.TxOut.Value)
+	}
+	for _, txIn := range tx.TxIn {
+		utxo, ok := previousOutputs[txIn.PreviousOutPoint]
+		if !ok || utxo.Address == nil {
+			return nil, errp.New("Only transactions spending our own coins can be replaced")
+		}
+		addInput(txIn.PreviousOutPoint, utxo)
+	}
+
+	recipientOutputs := []*wire.TxOut{}
+	outputPkScriptSizes := []int{}
+	outputsSum := btcutil.Amount(0)
+	for index, txOut := range tx.TxOut {
+		outputsSum += btcutil.Amount(txOut.Value)
+		if index == changeIndex {
+			continue
+		}
+		recipientOutputs = append(recipientOutputs, txOut)
+		outputPkScriptSizes = append(outputPkScriptSizes, len(txOut.PkScript))
+	}
+	if len(recipientOutputs) == 0 {
+		return nil, errp.New("The transaction has no recipient output")
+	}
+	recipientsSum := outputsSum
+	if changeIndex >= 0 {
+		recipientsSum -= btcutil.Amount(tx.TxOut[changeIndex].Value)
+	}
+	originalFee := inputsSum - outputsSum
+
+	// The replacement must pay a higher fee rate than the original (BIP-125 rule 6).
+	originalOutputPkScriptSizes := outputPkScriptSizes
+	if changeIndex >= 0 {
+		originalOutputPkScriptSizes = append(
+			append([]int{}, outputPkScriptSizes...), len(tx.TxOut[changeIndex].PkScript))
+	}
+	originalSize := estimateTxSizeOutputs(inputConfigurations, originalOutputPkScriptSizes)
+	if feePerKb <= originalFee*1000/btcutil.Amount(originalSize) {
+		return nil, errp.WithStack(errors.ErrFeeTooLow)
+	}
+
+	// Candidates for additional inputs, largest first.
+	candidates := []wire.OutPoint{}
+	for outPoint := range spendableOutputs {
+		if _, ok := newPreviousOutputs[outPoint]; !ok {
+			candidates = append(candidates, outPoint)
+		}
+	}
+	sort.Sort(sort.Reverse(&byValue{candidates, spendableOutputs}))
+
+	changePkScript := changeAddress.PubkeyScript()
+	// requiredFee computes the fee of the replacement, which must be at least the fee of the original
+	// plus the incremental relay fee for the replacement (BIP-125 rules 3 and 4).
+	requiredFee := func(txSize int) btcutil.Amount {
+		fee := feeForSerializeSize(feePerKb, txSize, log)
+		minFee := originalFee + feeForSerializeSize(minRelayFeePerKb, txSize, log)
+		if fee < minFee {
+			return minFee
+		}
+		return fee
+	}
+	for {
+		txSizeWithChange := estimateTxSizeOutputs(
+			inputConfigurations, append(append([]int{}, outputPkScriptSizes...), len(changePkScript)))
+		feeWithChange := requiredFee(txSizeWithChange)
+		txSizeWithoutChange := estimateTxSizeOutputs(inputConfigurations, outputPkScriptSizes)
+		feeWithoutChange := requiredFee(txSizeWithoutChange)
+
+		var changeOutput *wire.TxOut
+		var finalFee btcutil.Amount
+		if inputsSum >= recipientsSum+feeWithChange {
+			changeAmount := inputsSum - recipientsSum - feeWithChange
+			changeIsDust := isDustAmount(
+				changeAmount, len(changePkScript), changeAddress.Configuration, feePerKb)
+			if !changeIsDust {
+				changeOutput = wire.NewTxOut(int64(changeAmount), changePkScript)
+				finalFee = feeWithChange
+			} else {
+				log.Info("change is dust")
+				finalFee = inputsSum - recipientsSum
+			}
+		} else if inputsSum >= recipientsSum+feeWithoutChange {
+			finalFee = inputsSum - recipientsSum
+		} else {
+			if len(candidates) == 0 {
+				return nil, errp.WithStack(errors.ErrInsufficientFunds)
+			}
+			addInput(candidates[0], spendableOutputs[candidates[0]])
+			candidates = candidates[1:]
+			continue
+		}
+
+		// Keep the order of the original outputs. The change output stays at its position, or is
+		// inserted at a random position if it is new.
+		outputs := []*wire.TxOut{}
+		for index, txOut := range tx.TxOut {
+			if index == changeIndex {
+				if changeOutput != nil {
+					outputs = append(outputs, changeOutput)
+				}
+				continue
+			}
+			outputs = append(outputs, wire.NewTxOut(txOut.Value, txOut.PkScript))
+		}
+		if changeIndex < 0 && changeOutput != nil {
+			secureRand := mrand.New(mrand.NewSource(secureSeed()))
+			position := secureRand.Intn(len(outputs) + 1)
+			outputs = append(outputs[:position], append([]*wire.TxOut{changeOutput}, outputs[position:]...)...)
+		}
+		if changeOutput == nil {
+			changeAddress = nil
+		}
+
+		outIndex := -1
+		for index, txOut := range outputs {
+			if txOut != changeOutput {
+				outIndex = index
+				break
+			}
+		}
+
+		unsignedTransaction := &wire.MsgTx{
+			Version:  tx.Version,
+			TxIn:     inputs,
+			TxOut:    outputs,
+			LockTime: tx.LockTime,
+		}
+		setRBF(coin, unsignedTransaction)
+
+		log.WithFields(logrus.Fields{"originalFee": originalFee, "fee": finalFee}).
+			Debug("Preparing replacement transaction")
+		return &TxProposal{
+			Coin:            coin,
+			Amount:          recipientsSum,
+			Fee:             finalFee,
+			Transaction:     unsignedTransaction,
+			ChangeAddress:   changeAddress,
+			PreviousOutputs: newPreviousOutputs,
+			OutIndex:        outIndex,
+		}, nil
+	}
+}
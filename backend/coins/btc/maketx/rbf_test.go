@@ -0,0 +1,172 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maketx_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	addressesTest "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses/test"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+type bumpFeeFixture struct {
+	utxos          map[wire.OutPoint]maketx.UTXO
+	original       *maketx.TxProposal
+	changeIndex    int
+	recipientIndex int
+	freshChange    *addresses.AccountAddress
+}
+
+// newBumpFeeFixture creates an original transaction sending 50'000 sat at 1 sat/vB from the first
+// of the given UTXO values.
+func newBumpFeeFixture(t *testing.T, values ...int64) *bumpFeeFixture {
+	t.Helper()
+	_, addressChain := addressesTest.NewAddressChain(
+		func(*addresses.AccountAddress) (bool, error) { return false, nil })
+	someAddresses, err := addressChain.EnsureAddresses()
+	require.NoError(t, err)
+	recipient, changeAddress, freshChange := someAddresses[0], someAddresses[1], someAddresses[2]
+
+	utxos := map[wire.OutPoint]maketx.UTXO{}
+	for index, value := range values {
+		utxos[wire.OutPoint{Hash: chainhash.HashH([]byte("bump-fee")), Index: uint32(index)}] = maketx.UTXO{
+			TxOut:   wire.NewTxOut(value, someAddresses[3].PubkeyScript()),
+			Address: someAddresses[3],
+		}
+	}
+	firstOutPoint := wire.OutPoint{Hash: chainhash.HashH([]byte("bump-fee")), Index: 0}
+	original, err := maketx.NewTx(
+		tbtc,
+		map[wire.OutPoint]maketx.UTXO{firstOutPoint: utxos[firstOutPoint]},
+		maketx.NewOutputInfo(recipient.PubkeyScript()),
+		50000,
+		1000,
+		changeAddress,
+		logging.Get().WithGroup("rbf_test"),
+	)
+	require.NoError(t, err)
+	fixture := &bumpFeeFixture{
+		utxos:       utxos,
+		original:    original,
+		changeIndex: -1,
+		freshChange: freshChange,
+	}
+	for index, txOut := range original.Transaction.TxOut {
+		if original.ChangeAddress != nil && bytes.Equal(txOut.PkScript, original.ChangeAddress.PubkeyScript()) {
+			fixture.changeIndex = index
+		} else {
+			fixture.recipientIndex = index
+		}
+	}
+	return fixture
+}
+
+func (fixture *bumpFeeFixture) bumpFee(feePerKb btcutil.Amount) (*maketx.TxProposal, error) {
+	changeAddress := fixture.freshChange
+	if fixture.changeIndex >= 0 {
+		changeAddress = fixture.original.ChangeAddress
+	}
+	extra := map[wire.OutPoint]maketx.UTXO{}
+	for outPoint, utxo := range fixture.utxos {
+		if _, ok := fixture.original.PreviousOutputs[outPoint]; !ok {
+			extra[outPoint] = utxo
+		}
+	}
+	return maketx.NewTxBumpFee(
+		tbtc,
+		fixture.original.Transaction,
+		fixture.original.PreviousOutputs,
+		fixture.changeIndex,
+		changeAddress,
+		extra,
+		feePerKb,
+		1000,
+		logging.Get().WithGroup("rbf_test"),
+	)
+}
+
+func TestNewTxBumpFeeReducesChange(t *testing.T) {
+	fixture := newBumpFeeFixture(t, 100000)
+	require.GreaterOrEqual(t, fixture.changeIndex, 0)
+	originalTx := fixture.original.Transaction
+
+	replacement, err := fixture.bumpFee(10000)
+	require.NoError(t, err)
+	tx := replacement.Transaction
+	require.True(t, maketx.SignalsRBF(tx))
+	require.Len(t, tx.TxIn, 1)
+	require.Equal(t, originalTx.TxIn[0].PreviousOutPoint, tx.TxIn[0].PreviousOutPoint)
+	require.Len(t, tx.TxOut, 2)
+	// The recipient output is unchanged and stays in place.
+	require.Equal(t, originalTx.TxOut[fixture.recipientIndex], tx.TxOut[fixture.recipientIndex])
+	require.Equal(t, fixture.recipientIndex, replacement.OutIndex)
+	require.Equal(t, btcutil.Amount(50000), replacement.Amount)
+	require.Greater(t, replacement.Fee, fixture.original.Fee*9)
+	require.Equal(t,
+		originalTx.TxOut[fixture.changeIndex].Value-int64(replacement.Fee-fixture.original.Fee),
+		tx.TxOut[fixture.changeIndex].Value)
+	require.Equal(t, fixture.original.ChangeAddress, replacement.ChangeAddress)
+}
+
+func TestNewTxBumpFeeAddsInputs(t *testing.T) {
+	fixture := newBumpFeeFixture(t, 50240, 30000, 20000)
+	// Change was dust in the original tx.
+	require.Equal(t, -1, fixture.changeIndex)
+
+	replacement, err := fixture.bumpFee(5000)
+	require.NoError(t, err)
+	tx := replacement.Transaction
+	// The largest additional coin is added.
+	require.Len(t, tx.TxIn, 2)
+	require.Equal(t, fixture.original.Transaction.TxIn[0].PreviousOutPoint, tx.TxIn[0].PreviousOutPoint)
+	require.Equal(t, int64(30000), replacement.PreviousOutputs[tx.TxIn[1].PreviousOutPoint].TxOut.Value)
+	// A new change output is added.
+	require.Len(t, tx.TxOut, 2)
+	require.Equal(t, fixture.freshChange, replacement.ChangeAddress)
+	require.Equal(t, fixture.original.Transaction.TxOut[0], tx.TxOut[replacement.OutIndex])
+	var outputsSum int64
+	for _, txOut := range tx.TxOut {
+		outputsSum += txOut.Value
+	}
+	require.Equal(t, int64(50240+30000)-outputsSum, int64(replacement.Fee))
+}
+
+func TestNewTxBumpFeeErrors(t *testing.T) {
+	fixture := newBumpFeeFixture(t, 51000)
+
+	// Not higher than the original fee rate.
+	_, err := fixture.bumpFee(1000)
+	require.Equal(t, errors.ErrFeeTooLow, errp.Cause(err))
+
+	// No additional coins to pay for the fee.
+	_, err = fixture.bumpFee(20000)
+	require.Equal(t, errors.ErrInsufficientFunds, errp.Cause(err))
+
+	// No RBF signaled.
+	for _, txIn := range fixture.original.Transaction.TxIn {
+		txIn.Sequence = wire.MaxTxInSequenceNum
+	}
+	_, err = fixture.bumpFee(5000)
+	require.Error(t, err)
+}
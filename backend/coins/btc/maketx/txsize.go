@@ -65,6 +65,17 @@ func sigScriptWitnessSize(configuration *signing.Configuration) (int, int) {
 	case signing.ScriptTypeP2TR:
 		// Taproot key spend: <64 byte sig>
 		return 0, wire.VarIntSerializeSize(1) + wire.VarIntSerializeSize(64) + 64
+	case signing.ScriptTypeP2WSH:
+		multisig := configuration.BitcoinMultisig
+		threshold, numKeys := multisig.Threshold, len(multisig.KeyInfos)
+		// OP_m <pubkey1> ... <pubkeyN> OP_n OP_CHECKMULTISIG
+		witnessScriptSize := 1 + numKeys*(1+pubkeySize) + 1 + 1
+		// <empty> <sig1> ... <sigM> <witnessScript>
+		// The empty item is needed due to the off-by-one bug in OP_CHECKMULTISIG.
+		return 0, wire.VarIntSerializeSize(uint64(threshold+2)) +
+			wire.VarIntSerializeSize(0) +
+			threshold*(wire.VarIntSerializeSize(signatureSize)+signatureSize) +
+			wire.VarIntSerializeSize(uint64(witnessScriptSize)) + witnessScriptSize
 	default:
 		panic("unknown address type")
 	}
@@ -85,11 +96,19 @@ func estimateTxSize(
 	inputConfigurations []*signing.Configuration,
 	outputPkScriptSize int,
 	changePkScriptSize int) int {
-	outputCount := 2 // 1 output + 1 change output
-	if changePkScriptSize == 0 {
-		outputCount = 1
+	outputPkScriptSizes := []int{outputPkScriptSize}
+	if changePkScriptSize != 0 {
+		outputPkScriptSizes = append(outputPkScriptSizes, changePkScriptSize)
 	}
+	return estimateTxSizeOutputs(inputConfigurations, outputPkScriptSizes)
+}
 
+// estimateTxSizeOutputs is like estimateTxSize, but for an arbitrary number of outputs.
+// outputPkScriptSizes contains the pkScript size of each output, including the change output if
+// there is one.
+func estimateTxSizeOutputs(
+	inputConfigurations []*signing.Configuration,
+	outputPkScriptSizes []int) int {
 	const (
 		versionSize  = 4
 		lockTimeSize = 4
@@ -98,9 +117,10 @@ func estimateTxSize(
 	)
 
 	txWeight := nonWitness * (versionSize + lockTimeSize + wire.VarIntSerializeSize(uint64(len(inputConfigurations))) +
-		wire.VarIntSerializeSize(uint64(outputCount)) +
-		outputSize(outputPkScriptSize) +
-		outputSize(changePkScriptSize))
+		wire.VarIntSerializeSize(uint64(len(outputPkScriptSizes))))
+	for _, pkScriptSize := range outputPkScriptSizes {
+		txWeight += nonWitness * outputSize(pkScriptSize)
+	}
 
 	isSegwitTx := false
 	for _, inputConfiguration := range inputConfigurations {
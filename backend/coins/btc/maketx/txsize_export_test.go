@@ -25,3 +25,9 @@ func TstEstimateTxSize(
 		outputPkScriptSize,
 		changePkScriptSize)
 }
+
+func TstEstimateTxSizeOutputs(
+	inputConfigurations []*signing.Configuration,
+	outputPkScriptSizes []int) int {
+	return estimateTxSizeOutputs(inputConfigurations, outputPkScriptSizes)
+}
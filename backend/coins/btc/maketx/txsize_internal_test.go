@@ -26,6 +26,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/stretchr/testify/require"
 )
@@ -131,6 +132,21 @@ func TestSigScriptWitnessSize(t *testing.T) {
 			}
 		})
 	}
+
+	// Test multisig configurations.
+	for _, c := range []struct{ threshold, numKeys int }{{1, 1}, {2, 3}, {3, 5}, {15, 15}} {
+		address := test.GetMultisigAddress(c.threshold, c.numKeys)
+		t.Run(address.Configuration.String(), func(t *testing.T) {
+			sigScriptSize, witnessSize := sigScriptWitnessSize(address.Configuration)
+			require.Equal(t, 0, sigScriptSize)
+			witness := wire.TxWitness{{}}
+			for i := 0; i < c.threshold; i++ {
+				witness = append(witness, append(sig.SerializeDER(), byte(txscript.SigHashAll)))
+			}
+			witness = append(witness, address.WitnessScript())
+			require.Equal(t, witness.SerializeSize(), witnessSize)
+		})
+	}
 }
 
 func TestEstimateTxSize(t *testing.T) {
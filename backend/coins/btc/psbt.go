@@ -0,0 +1,470 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// rootFingerprintUint32 converts a 4 byte root fingerprint to the integer representation used by
+// the psbt package, which serializes it in little endian.
+func rootFingerprintUint32(rootFingerprint []byte) (uint32, error) {
+	if len(rootFingerprint) != 4 {
+		return 0, errp.Newf("invalid root fingerprint length: %d", len(rootFingerprint))
+	}
+	return binary.LittleEndian.Uint32(rootFingerprint), nil
+}
+
+// psbtDerivations returns the BIP-32 derivations of the keys of an address. For multisig addresses,
+// the derivations of all cosigner keys are returned. For Taproot addresses, the derivation is
+// returned as a Taproot key path derivation, with the x-only internal key.
+func psbtDerivations(address *addresses.AccountAddress) (
+	[]*psbt.Bip32Derivation, *psbt.TaprootBip32Derivation, error) {
+	configuration := address.Configuration
+	if configuration.BitcoinMultisig != nil {
+		derivations := make([]*psbt.Bip32Derivation, len(configuration.BitcoinMultisig.KeyInfos))
+		for index, keyInfo := range configuration.BitcoinMultisig.KeyInfos {
+			fingerprint, err := rootFingerprintUint32(keyInfo.RootFingerprint)
+			if err != nil {
+				return nil, nil, err
+			}
+			publicKey, err := keyInfo.ExtendedPublicKey.ECPubKey()
+			if err != nil {
+				return nil, nil, errp.WithStack(err)
+			}
+			derivations[index] = &psbt.Bip32Derivation{
+				PubKey:               publicKey.SerializeCompressed(),
+				MasterKeyFingerprint: fingerprint,
+				Bip32Path:            keyInfo.AbsoluteKeypath.ToUInt32(),
+			}
+		}
+		return derivations, nil, nil
+	}
+	fingerprint, err := rootFingerprintUint32(configuration.BitcoinSimple.KeyInfo.RootFingerprint)
+	if err != nil {
+		return nil, nil, err
+	}
+	keypath := configuration.AbsoluteKeypath().ToUInt32()
+	publicKey := configuration.PublicKey()
+	if configuration.ScriptType() == signing.ScriptTypeP2TR {
+		return nil, &psbt.TaprootBip32Derivation{
+			XOnlyPubKey:          schnorr.SerializePubKey(publicKey),
+			LeafHashes:           [][]byte{},
+			MasterKeyFingerprint: fingerprint,
+			Bip32Path:            keypath,
+		}, nil
+	}
+	return []*psbt.Bip32Derivation{{
+		PubKey:               publicKey.SerializeCompressed(),
+		MasterKeyFingerprint: fingerprint,
+		Bip32Path:            keypath,
+	}}, nil, nil
+}
+
+// psbtXPubs returns the account level xpubs of a signing configuration, one per cosigner for
+// multisig configurations.
+func psbtXPubs(configuration *signing.Configuration) ([]psbt.XPub, error) {
+	var keyInfos []signing.KeyInfo
+	if configuration.BitcoinMultisig != nil {
+		keyInfos = configuration.BitcoinMultisig.KeyInfos
+	} else {
+		keyInfos = append(keyInfos, configuration.BitcoinSimple.KeyInfo)
+	}
+	xpubs := make([]psbt.XPub, len(keyInfos))
+	for index, keyInfo := range keyInfos {
+		fingerprint, err := rootFingerprintUint32(keyInfo.RootFingerprint)
+		if err != nil {
+			return nil, err
+		}
+		xpubs[index] = psbt.XPub{
+			ExtendedKey:          psbt.EncodeExtendedKey(keyInfo.ExtendedPublicKey),
+			MasterKeyFingerprint: fingerprint,
+			Bip32Path:            keyInfo.AbsoluteKeypath.ToUInt32(),
+		}
+	}
+	return xpubs, nil
+}
+
+// NewPSBT converts a transaction proposal to a BIP-174 PSBT (version 0). All inputs are annotated
+// with the previous outputs they spend, the redeem script for wrapped segwit inputs, the witness
+// script for multisig inputs, and the BIP-32 key origins, so that any PSBT compatible signer can sign it. The change output, if present, is
+// annotated as well so that signers can recognize it.
+//
+// getPrevTx is used to fetch the full previous transactions of non-Taproot inputs, which are
+// required by many signers to verify the input amounts.
+func NewPSBT(
+	txProposal *maketx.TxProposal,
+	getPrevTx func(chainhash.Hash) (*wire.MsgTx, error),
+) (*psbt.Packet, error) {
+	if txProposal.SilentPaymentAddress != "" {
+		return nil, errp.New("transactions to silent payment addresses can't be exported to a PSBT")
+	}
+	packet, err := psbt.NewFromUnsignedTx(txProposal.Transaction.Copy())
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+
+	xpubs := map[string]psbt.XPub{}
+	for index, txIn := range packet.UnsignedTx.TxIn {
+		spentOutput, ok := txProposal.PreviousOutputs[txIn.PreviousOutPoint]
+		if !ok || spentOutput.Address == nil {
+			return nil, errp.New("There needs to be exactly one output being spent per input!")
+		}
+		address := spentOutput.Address
+		input := &packet.Inputs[index]
+		scriptType := address.Configuration.ScriptType()
+		if scriptType != signing.ScriptTypeP2PKH {
+			input.WitnessUtxo = spentOutput.TxOut
+		}
+		switch scriptType {
+		case signing.ScriptTypeP2WPKHP2SH:
+			_, input.RedeemScript = address.ScriptForHashToSign()
+		case signing.ScriptTypeP2WSH:
+			input.WitnessScript = address.WitnessScript()
+		}
+		if scriptType != signing.ScriptTypeP2TR {
+			// Taproot inputs are signed with SIGHASH_DEFAULT, which is encoded by leaving the field
+			// empty.
+			input.SighashType = txscript.SigHashAll
+			prevTx, err := getPrevTx(txIn.PreviousOutPoint.Hash)
+			if err != nil {
+				return nil, err
+			}
+			input.NonWitnessUtxo = prevTx
+		}
+
+		derivations, taprootDerivation, err := psbtDerivations(address)
+		if err != nil {
+			return nil, err
+		}
+		if taprootDerivation != nil {
+			input.TaprootBip32Derivation = []*psbt.TaprootBip32Derivation{taprootDerivation}
+			input.TaprootInternalKey = taprootDerivation.XOnlyPubKey
+		} else {
+			input.Bip32Derivation = derivations
+		}
+
+		accountXPubs, err := psbtXPubs(address.AccountConfiguration)
+		if err != nil {
+			return nil, err
+		}
+		for _, xpub := range accountXPubs {
+			xpubs[string(xpub.ExtendedKey)] = xpub
+		}
+	}
+	for _, xpub := range xpubs {
+		packet.XPubs = append(packet.XPubs, xpub)
+	}
+
+	if txProposal.ChangeAddress != nil {
+		changePkScript := txProposal.ChangeAddress.PubkeyScript()
+		for index, txOut := range packet.UnsignedTx.TxOut {
+			if !bytes.Equal(txOut.PkScript, changePkScript) {
+				continue
+			}
+			derivations, taprootDerivation, err := psbtDerivations(txProposal.ChangeAddress)
+			if err != nil {
+				return nil, err
+			}
+			output := &packet.Outputs[index]
+			if taprootDerivation != nil {
+				output.TaprootBip32Derivation = []*psbt.TaprootBip32Derivation{taprootDerivation}
+				output.TaprootInternalKey = taprootDerivation.XOnlyPubKey
+			} else {
+				output.Bip32Derivation = derivations
+				switch txProposal.ChangeAddress.Configuration.ScriptType() {
+				case signing.ScriptTypeP2WPKHP2SH:
+					_, output.RedeemScript = txProposal.ChangeAddress.ScriptForHashToSign()
+				case signing.ScriptTypeP2WSH:
+					output.WitnessScript = txProposal.ChangeAddress.WitnessScript()
+				}
+			}
+		}
+	}
+	return packet, nil
+}
+
+// ParsePSBT decodes a PSBT given in base64 or hex encoding.
+func ParsePSBT(encoded string) (*psbt.Packet, error) {
+	encoded = strings.TrimSpace(encoded)
+	if rawBytes, err := hex.DecodeString(encoded); err == nil {
+		packet, err := psbt.NewFromRawBytes(bytes.NewReader(rawBytes), false)
+		return packet, errp.WithStack(err)
+	}
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(encoded), true)
+	return packet, errp.WithStack(err)
+}
+
+// psbtPreviousOutputs collects the outputs spent by the PSBT inputs. The witness UTXO is used if
+// available, otherwise the output is looked up in the full previous transaction.
+func psbtPreviousOutputs(packet *psbt.Packet) (maketx.PreviousOutputs, error) {
+	previousOutputs := make(maketx.PreviousOutputs, len(packet.UnsignedTx.TxIn))
+	for index, txIn := range packet.UnsignedTx.TxIn {
+		input := packet.Inputs[index]
+		outPoint := txIn.PreviousOutPoint
+		switch {
+		case input.WitnessUtxo != nil:
+			previousOutputs[outPoint] = maketx.UTXO{TxOut: input.WitnessUtxo}
+		case input.NonWitnessUtxo != nil:
+			if input.NonWitnessUtxo.TxHash() != outPoint.Hash ||
+				int(outPoint.Index) >= len(input.NonWitnessUtxo.TxOut) {
+				return nil, errp.Newf("previous transaction of input %d does not match", index)
+			}
+			previousOutputs[outPoint] = maketx.UTXO{TxOut: input.NonWitnessUtxo.TxOut[outPoint.Index]}
+		default:
+			return nil, errp.Newf("input %d is missing the previous output", index)
+		}
+	}
+	return previousOutputs, nil
+}
+
+// FinalizePSBT finalizes all inputs of a fully signed PSBT and extracts the network serialized
+// transaction. The signatures are checked against the spent outputs before returning.
+func FinalizePSBT(packet *psbt.Packet) (*wire.MsgTx, error) {
+	previousOutputs, err := psbtPreviousOutputs(packet)
+	if err != nil {
+		return nil, err
+	}
+	if err := psbt.MaybeFinalizeAll(packet); err != nil {
+		return nil, errp.WithMessage(err, "PSBT could not be finalized, is it fully signed?")
+	}
+	transaction, err := psbt.Extract(packet)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	sigHashes := txscript.NewTxSigHashes(transaction, previousOutputs)
+	if err := TxValidityCheck(transaction, previousOutputs, sigHashes); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// addPSBTSignatures adds the given signatures, one per input, to the PSBT as partial signatures, or
+// as the key spend signature for Taproot inputs. Inputs without a signature are skipped.
+// previousOutputs must contain the spent outputs, including the address they belong to.
+func addPSBTSignatures(
+	packet *psbt.Packet,
+	previousOutputs maketx.PreviousOutputs,
+	signatures []*types.Signature,
+) error {
+	if len(signatures) != len(packet.UnsignedTx.TxIn) {
+		return errp.New("there needs to be one signature per input")
+	}
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	for index, txIn := range packet.UnsignedTx.TxIn {
+		signature := signatures[index]
+		if signature == nil {
+			continue
+		}
+		address := previousOutputs[txIn.PreviousOutPoint].Address
+		if address == nil {
+			return errp.Newf("input %d does not belong to the account", index)
+		}
+		input := &packet.Inputs[index]
+		if address.Configuration.ScriptType() == signing.ScriptTypeP2TR {
+			input.TaprootKeySpendSig = signature.SerializeCompact()
+			continue
+		}
+		publicKey := address.Configuration.PublicKey().SerializeCompressed()
+		alreadySigned := false
+		for _, partialSig := range input.PartialSigs {
+			if bytes.Equal(partialSig.PubKey, publicKey) {
+				alreadySigned = true
+			}
+		}
+		if alreadySigned {
+			continue
+		}
+		var redeemScript, witnessScript []byte
+		switch address.Configuration.ScriptType() {
+		case signing.ScriptTypeP2WPKHP2SH:
+			_, redeemScript = address.ScriptForHashToSign()
+		case signing.ScriptTypeP2WSH:
+			witnessScript = address.WitnessScript()
+		}
+		outcome, err := updater.Sign(
+			index,
+			append(signature.SerializeDER(), byte(txscript.SigHashAll)),
+			publicKey,
+			redeemScript,
+			witnessScript,
+		)
+		if err != nil {
+			return errp.WithStack(err)
+		}
+		if outcome != psbt.SignSuccesful && outcome != psbt.SignFinalized {
+			return errp.Newf("could not add the signature of input %d", index)
+		}
+	}
+	return nil
+}
+
+// isPSBTComplete returns true if the PSBT contains enough signatures to be finalized.
+func isPSBTComplete(packet *psbt.Packet) bool {
+	var serialized bytes.Buffer
+	if err := packet.Serialize(&serialized); err != nil {
+		return false
+	}
+	packetCopy, err := psbt.NewFromRawBytes(&serialized, false)
+	if err != nil {
+		return false
+	}
+	_, err = FinalizePSBT(packetCopy)
+	return err == nil
+}
+
+// ExportPSBT exports the active tx proposal, set by TxProposal(), as a base64 encoded PSBT.
+func (account *Account) ExportPSBT() (string, error) {
+	unlock := account.activeTxProposalLock.RLock()
+	txProposal := account.activeTxProposal
+	fiatQuote := account.activeFiatQuote
+	unlock()
+	if txProposal == nil {
+		return "", errp.New("No active tx proposal")
+	}
+	if err := account.CheckFiatQuote(fiatQuote); err != nil {
+		return "", err
+	}
+	packet, err := NewPSBT(txProposal, account.coin.Blockchain().TransactionGet)
+	if err != nil {
+		return "", err
+	}
+	return packet.B64Encode()
+}
+
+// BroadcastPSBT finalizes a fully signed PSBT and broadcasts the resulting transaction. All inputs
+// must spend outputs of this account. The transaction ID of the broadcasted transaction is
+// returned.
+func (account *Account) BroadcastPSBT(encoded string) (string, error) {
+	packet, err := ParsePSBT(encoded)
+	if err != nil {
+		return "", err
+	}
+	previousOutputs, err := psbtPreviousOutputs(packet)
+	if err != nil {
+		return "", err
+	}
+	for _, spentOutput := range previousOutputs {
+		scriptHashHex := blockchain.NewScriptHashHex(spentOutput.TxOut.PkScript)
+		if account.getAddress(scriptHashHex) == nil {
+			return "", errp.New("The PSBT spends coins which do not belong to this account")
+		}
+	}
+	transaction, err := FinalizePSBT(packet)
+	if err != nil {
+		return "", err
+	}
+	account.log.Info("Broadcasting transaction from PSBT")
+	if err := account.coin.Blockchain().TransactionBroadcast(transaction); err != nil {
+		return "", err
+	}
+	return transaction.TxHash().String(), nil
+}
+
+// SignPSBT adds the signatures of the keystore of this account to a PSBT, e.g. one created by a
+// cosigner of a multisig account. All inputs must spend outputs of this account. The signed PSBT is
+// returned base64 encoded, together with a flag indicating whether it contains all the signatures
+// needed for broadcasting.
+func (account *Account) SignPSBT(encoded string) (string, bool, error) {
+	packet, err := ParsePSBT(encoded)
+	if err != nil {
+		return "", false, err
+	}
+	previousOutputs, err := psbtPreviousOutputs(packet)
+	if err != nil {
+		return "", false, err
+	}
+	inputsSum := btcutil.Amount(0)
+	for outPoint, spentOutput := range previousOutputs {
+		spentOutput.Address = account.getAddress(blockchain.NewScriptHashHex(spentOutput.TxOut.PkScript))
+		if spentOutput.Address == nil {
+			return "", false, errp.New("The PSBT spends coins which do not belong to this account")
+		}
+		previousOutputs[outPoint] = spentOutput
+		inputsSum += btcutil.Amount(spentOutput.TxOut.Value)
+	}
+
+	txProposal := &maketx.TxProposal{
+		Coin:            account.coin,
+		Transaction:     packet.UnsignedTx.Copy(),
+		PreviousOutputs: previousOutputs,
+		OutIndex:        -1,
+	}
+	outputsSum := btcutil.Amount(0)
+	for index, txOut := range txProposal.Transaction.TxOut {
+		outputsSum += btcutil.Amount(txOut.Value)
+		scriptHashHex := blockchain.NewScriptHashHex(txOut.PkScript)
+		if txProposal.ChangeAddress == nil && account.IsChange(scriptHashHex) {
+			txProposal.ChangeAddress = account.getAddress(scriptHashHex)
+			continue
+		}
+		txProposal.Amount += btcutil.Amount(txOut.Value)
+		if txProposal.OutIndex == -1 {
+			txProposal.OutIndex = index
+		}
+	}
+	if txProposal.OutIndex == -1 {
+		return "", false, errp.New("The PSBT has no recipient output")
+	}
+	if outputsSum > inputsSum {
+		return "", false, errp.New("The PSBT outputs exceed the inputs")
+	}
+	txProposal.Fee = inputsSum - outputsSum
+
+	// Use the previous transactions contained in the PSBT if available.
+	getPrevTx := func(hash chainhash.Hash) (*wire.MsgTx, error) {
+		for _, input := range packet.Inputs {
+			if input.NonWitnessUtxo != nil && input.NonWitnessUtxo.TxHash() == hash {
+				return input.NonWitnessUtxo, nil
+			}
+		}
+		return account.coin.Blockchain().TransactionGet(hash)
+	}
+	proposedTransaction := account.newProposedTransaction(txProposal, getPrevTx)
+	keystore, err := account.Config().ConnectKeystore()
+	if err != nil {
+		return "", false, err
+	}
+	if err := keystore.SignTransaction(proposedTransaction); err != nil {
+		return "", false, err
+	}
+	if err := addPSBTSignatures(packet, previousOutputs, proposedTransaction.Signatures); err != nil {
+		return "", false, err
+	}
+	signed, err := packet.B64Encode()
+	if err != nil {
+		return "", false, errp.WithStack(err)
+	}
+	return signed, isPSBTComplete(packet), nil
+}
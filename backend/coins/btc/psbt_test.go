@@ -0,0 +1,335 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/socksproxy"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func newPSBTTestAddress(
+	t *testing.T,
+	master *hdkeychain.ExtendedKey,
+	scriptType signing.ScriptType,
+	accountKeypath string,
+	relativeKeypath string,
+) *addresses.AccountAddress {
+	t.Helper()
+	keypath, err := signing.NewAbsoluteKeypath(accountKeypath)
+	require.NoError(t, err)
+	xprv, err := keypath.Derive(master)
+	require.NoError(t, err)
+	xpub, err := xprv.Neuter()
+	require.NoError(t, err)
+	relative, err := signing.NewRelativeKeypath(relativeKeypath)
+	require.NoError(t, err)
+	return addresses.NewAccountAddress(
+		signing.NewBitcoinConfiguration(scriptType, []byte{0xf4, 0x2a, 0x61, 0x13}, keypath, xpub),
+		relative,
+		&chaincfg.TestNet3Params,
+		logging.Get().WithGroup("psbt_test"),
+	)
+}
+
+// signPSBT signs all inputs of the packet with the keys derived from master, filling in the partial
+// signatures like an external signer would.
+func signPSBT(t *testing.T, master *hdkeychain.ExtendedKey, packet *psbt.Packet) {
+	t.Helper()
+	previousOutputs, err := psbtPreviousOutputs(packet)
+	require.NoError(t, err)
+	sigHashes := txscript.NewTxSigHashes(packet.UnsignedTx, previousOutputs)
+	for index := range packet.Inputs {
+		input := &packet.Inputs[index]
+		prevOut := previousOutputs[packet.UnsignedTx.TxIn[index].PreviousOutPoint].TxOut
+		if len(input.TaprootBip32Derivation) == 1 {
+			derivation := input.TaprootBip32Derivation[0]
+			xprv, err := signing.NewAbsoluteKeypathFromUint32(derivation.Bip32Path...).Derive(master)
+			require.NoError(t, err)
+			privateKey, err := xprv.ECPrivKey()
+			require.NoError(t, err)
+			signature, err := txscript.RawTxInTaprootSignature(
+				packet.UnsignedTx, sigHashes, index, prevOut.Value, prevOut.PkScript,
+				nil, txscript.SigHashDefault, privateKey)
+			require.NoError(t, err)
+			input.TaprootKeySpendSig = signature
+			continue
+		}
+		require.Len(t, input.Bip32Derivation, 1)
+		derivation := input.Bip32Derivation[0]
+		xprv, err := signing.NewAbsoluteKeypathFromUint32(derivation.Bip32Path...).Derive(master)
+		require.NoError(t, err)
+		privateKey, err := xprv.ECPrivKey()
+		require.NoError(t, err)
+		var signature []byte
+		if input.WitnessUtxo != nil {
+			subScript := prevOut.PkScript
+			if input.RedeemScript != nil {
+				subScript = input.RedeemScript
+			}
+			signature, err = txscript.RawTxInWitnessSignature(
+				packet.UnsignedTx, sigHashes, index, prevOut.Value, subScript,
+				txscript.SigHashAll, privateKey)
+		} else {
+			signature, err = txscript.RawTxInSignature(
+				packet.UnsignedTx, index, prevOut.PkScript, txscript.SigHashAll, privateKey)
+		}
+		require.NoError(t, err)
+		input.PartialSigs = []*psbt.PartialSig{{PubKey: derivation.PubKey, Signature: signature}}
+	}
+}
+
+func TestPSBTRoundtrip(t *testing.T) {
+	master, err := hdkeychain.NewMaster(make([]byte, hdkeychain.RecommendedSeedLen), &chaincfg.TestNet3Params)
+	require.NoError(t, err)
+
+	inputAddresses := []*addresses.AccountAddress{
+		newPSBTTestAddress(t, master, signing.ScriptTypeP2PKH, "m/44'/1'/0'", "0/0"),
+		newPSBTTestAddress(t, master, signing.ScriptTypeP2WPKHP2SH, "m/49'/1'/0'", "0/1"),
+		newPSBTTestAddress(t, master, signing.ScriptTypeP2WPKH, "m/84'/1'/0'", "0/2"),
+		newPSBTTestAddress(t, master, signing.ScriptTypeP2TR, "m/86'/1'/0'", "0/3"),
+	}
+	changeAddress := newPSBTTestAddress(t, master, signing.ScriptTypeP2WPKH, "m/84'/1'/0'", "1/0")
+	recipient := newPSBTTestAddress(t, master, signing.ScriptTypeP2WPKH, "m/84'/1'/1'", "0/0")
+
+	// Create one previous transaction per input, funding the input addresses.
+	prevTxs := map[chainhash.Hash]*wire.MsgTx{}
+	utxos := map[wire.OutPoint]maketx.UTXO{}
+	for index, address := range inputAddresses {
+		prevTx := wire.NewMsgTx(wire.TxVersion)
+		prevTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: uint32(index)}, nil, nil))
+		prevTx.AddTxOut(wire.NewTxOut(1e6, address.PubkeyScript()))
+		prevTxs[prevTx.TxHash()] = prevTx
+		utxos[wire.OutPoint{Hash: prevTx.TxHash(), Index: 0}] = maketx.UTXO{
+			TxOut:   prevTx.TxOut[0],
+			Address: address,
+		}
+	}
+	getPrevTx := func(hash chainhash.Hash) (*wire.MsgTx, error) {
+		return prevTxs[hash], nil
+	}
+
+	tbtc := NewCoin(coin.CodeTBTC, "Bitcoin Testnet", "TBTC", coin.BtcUnitDefault,
+		&chaincfg.TestNet3Params, ".", nil, explorer, socksproxy.NewSocksProxy(false, ""))
+	txProposal, err := maketx.NewTx(
+		tbtc,
+		utxos,
+		maketx.NewOutputInfo(recipient.PubkeyScript()),
+		3500000,
+		1000,
+		changeAddress,
+		logging.Get().WithGroup("psbt_test"),
+	)
+	require.NoError(t, err)
+	require.Len(t, txProposal.Transaction.TxIn, 4)
+	require.NotNil(t, txProposal.ChangeAddress)
+
+	packet, err := NewPSBT(txProposal, getPrevTx)
+	require.NoError(t, err)
+	encoded, err := packet.B64Encode()
+	require.NoError(t, err)
+
+	decoded, err := ParsePSBT(encoded)
+	require.NoError(t, err)
+	require.Equal(t, txProposal.Transaction.TxHash(), decoded.UnsignedTx.TxHash())
+	require.Len(t, decoded.XPubs, 4)
+
+	for index, txIn := range decoded.UnsignedTx.TxIn {
+		input := decoded.Inputs[index]
+		address := utxos[txIn.PreviousOutPoint].Address
+		expectedKeypath := address.Configuration.AbsoluteKeypath().ToUInt32()
+		switch address.Configuration.ScriptType() {
+		case signing.ScriptTypeP2TR:
+			require.Nil(t, input.NonWitnessUtxo)
+			require.NotNil(t, input.WitnessUtxo)
+			require.Len(t, input.TaprootBip32Derivation, 1)
+			require.Equal(t, expectedKeypath, input.TaprootBip32Derivation[0].Bip32Path)
+			require.Equal(t, input.TaprootInternalKey, input.TaprootBip32Derivation[0].XOnlyPubKey)
+			require.Equal(t,
+				"f42a6113",
+				hex.EncodeToString(psbt.SerializeBIP32Derivation(
+					input.TaprootBip32Derivation[0].MasterKeyFingerprint, nil)))
+		case signing.ScriptTypeP2PKH:
+			require.NotNil(t, input.NonWitnessUtxo)
+			require.Nil(t, input.WitnessUtxo)
+			require.Len(t, input.Bip32Derivation, 1)
+			require.Equal(t, expectedKeypath, input.Bip32Derivation[0].Bip32Path)
+		case signing.ScriptTypeP2WPKHP2SH:
+			require.NotNil(t, input.RedeemScript)
+			fallthrough
+		default:
+			require.NotNil(t, input.NonWitnessUtxo)
+			require.NotNil(t, input.WitnessUtxo)
+			require.Len(t, input.Bip32Derivation, 1)
+			require.Equal(t, expectedKeypath, input.Bip32Derivation[0].Bip32Path)
+		}
+	}
+
+	// The change output is annotated, the recipient output is not.
+	annotatedOutputs := 0
+	for index, txOut := range decoded.UnsignedTx.TxOut {
+		if len(decoded.Outputs[index].Bip32Derivation) == 0 {
+			require.Equal(t, recipient.PubkeyScript(), txOut.PkScript)
+			continue
+		}
+		annotatedOutputs++
+		require.Equal(t, changeAddress.PubkeyScript(), txOut.PkScript)
+		require.Equal(t,
+			changeAddress.Configuration.AbsoluteKeypath().ToUInt32(),
+			decoded.Outputs[index].Bip32Derivation[0].Bip32Path)
+	}
+	require.Equal(t, 1, annotatedOutputs)
+
+	// Not signed yet.
+	_, err = FinalizePSBT(decoded)
+	require.Error(t, err)
+
+	signPSBT(t, master, decoded)
+	// Hex encoding is accepted as well.
+	var serialized bytes.Buffer
+	require.NoError(t, decoded.Serialize(&serialized))
+	signed, err := ParsePSBT(hex.EncodeToString(serialized.Bytes()))
+	require.NoError(t, err)
+	transaction, err := FinalizePSBT(signed)
+	require.NoError(t, err)
+	require.NoError(t, psbt.VerifyInputPrevOutpointsEqual(txProposal.Transaction.TxIn, transaction.TxIn))
+	require.NoError(t, psbt.VerifyOutputsEqual(txProposal.Transaction.TxOut, transaction.TxOut))
+}
+
+func TestNewPSBTSilentPayment(t *testing.T) {
+	_, err := NewPSBT(
+		&maketx.TxProposal{SilentPaymentAddress: "sp1..."},
+		func(chainhash.Hash) (*wire.MsgTx, error) { return nil, nil },
+	)
+	require.Error(t, err)
+}
+
+func TestPSBTMultisig(t *testing.T) {
+	net := &chaincfg.TestNet3Params
+	keypath, err := signing.NewAbsoluteKeypath("m/48'/1'/0'/2'")
+	require.NoError(t, err)
+	masters := make([]*hdkeychain.ExtendedKey, 3)
+	keyInfos := make([]signing.KeyInfo, 3)
+	for i := range masters {
+		seed := make([]byte, hdkeychain.RecommendedSeedLen)
+		seed[0] = byte(i + 1)
+		masters[i], err = hdkeychain.NewMaster(seed, net)
+		require.NoError(t, err)
+		xprv, err := keypath.Derive(masters[i])
+		require.NoError(t, err)
+		xpub, err := xprv.Neuter()
+		require.NoError(t, err)
+		keyInfos[i] = signing.KeyInfo{
+			RootFingerprint:   []byte{byte(i + 1), 0, 0, 0},
+			AbsoluteKeypath:   keypath,
+			ExtendedPublicKey: xpub,
+		}
+	}
+	// cosignerAddress returns the address as seen by the given cosigner.
+	cosignerAddress := func(cosigner int, relativeKeypath string) *addresses.AccountAddress {
+		configuration, err := signing.NewBitcoinMultisigConfiguration(2, keyInfos, cosigner)
+		require.NoError(t, err)
+		relative, err := signing.NewRelativeKeypath(relativeKeypath)
+		require.NoError(t, err)
+		return addresses.NewAccountAddress(configuration, relative, net, logging.Get().WithGroup("psbt_test"))
+	}
+	inputAddress := cosignerAddress(0, "0/0")
+	changeAddress := cosignerAddress(0, "1/0")
+	recipient := newPSBTTestAddress(t, masters[0], signing.ScriptTypeP2WPKH, "m/84'/1'/0'", "0/0")
+
+	prevTx := wire.NewMsgTx(wire.TxVersion)
+	prevTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	prevTx.AddTxOut(wire.NewTxOut(1e6, inputAddress.PubkeyScript()))
+	outPoint := wire.OutPoint{Hash: prevTx.TxHash(), Index: 0}
+	utxos := map[wire.OutPoint]maketx.UTXO{outPoint: {TxOut: prevTx.TxOut[0], Address: inputAddress}}
+
+	tbtc := NewCoin(coin.CodeTBTC, "Bitcoin Testnet", "TBTC", coin.BtcUnitDefault,
+		net, ".", nil, explorer, socksproxy.NewSocksProxy(false, ""))
+	txProposal, err := maketx.NewTx(
+		tbtc,
+		utxos,
+		maketx.NewOutputInfo(recipient.PubkeyScript()),
+		500000,
+		1000,
+		changeAddress,
+		logging.Get().WithGroup("psbt_test"),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, txProposal.ChangeAddress)
+
+	packet, err := NewPSBT(txProposal, func(chainhash.Hash) (*wire.MsgTx, error) { return prevTx, nil })
+	require.NoError(t, err)
+	require.Len(t, packet.XPubs, 3)
+	require.Equal(t, inputAddress.WitnessScript(), packet.Inputs[0].WitnessScript)
+	require.Len(t, packet.Inputs[0].Bip32Derivation, 3)
+	for index, txOut := range packet.UnsignedTx.TxOut {
+		if bytes.Equal(txOut.PkScript, changeAddress.PubkeyScript()) {
+			require.Equal(t, changeAddress.WitnessScript(), packet.Outputs[index].WitnessScript)
+			require.Len(t, packet.Outputs[index].Bip32Derivation, 3)
+		}
+	}
+
+	// sign adds the signature of the given cosigner, like SignPSBT does with the signatures of the
+	// keystore.
+	sign := func(cosigner int) {
+		address := cosignerAddress(cosigner, "0/0")
+		previousOutputs := maketx.PreviousOutputs{outPoint: {TxOut: prevTx.TxOut[0], Address: address}}
+		sigHash, err := txscript.CalcWitnessSigHash(address.WitnessScript(),
+			txscript.NewTxSigHashes(packet.UnsignedTx, previousOutputs),
+			txscript.SigHashAll, packet.UnsignedTx, 0, prevTx.TxOut[0].Value)
+		require.NoError(t, err)
+		xprv, err := address.Configuration.AbsoluteKeypath().Derive(masters[cosigner])
+		require.NoError(t, err)
+		privateKey, err := xprv.ECPrivKey()
+		require.NoError(t, err)
+		signature := ecdsa.Sign(privateKey, sigHash)
+		r, s := signature.R(), signature.S()
+		rBytes, sBytes := r.Bytes(), s.Bytes()
+		require.NoError(t, addPSBTSignatures(packet, previousOutputs, []*types.Signature{{
+			R: new(big.Int).SetBytes(rBytes[:]),
+			S: new(big.Int).SetBytes(sBytes[:]),
+		}}))
+	}
+
+	sign(2)
+	require.Len(t, packet.Inputs[0].PartialSigs, 1)
+	require.False(t, isPSBTComplete(packet))
+	// Adding the same signature again is a no-op.
+	sign(2)
+	require.Len(t, packet.Inputs[0].PartialSigs, 1)
+
+	sign(0)
+	require.True(t, isPSBTComplete(packet))
+	transaction, err := FinalizePSBT(packet)
+	require.NoError(t, err)
+	require.Len(t, transaction.TxIn[0].Witness, 4)
+}
@@ -15,6 +15,8 @@
 package btc
 
 import (
+	"errors"
+
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
@@ -27,6 +29,11 @@ import (
 	"github.com/btcsuite/btcd/wire"
 )
 
+// ErrMultisigPSBTRequired is returned when trying to sign and send a transaction of a multisig
+// account requiring more than one signature. Such transactions need to be exported as a PSBT and
+// signed by the cosigners.
+var ErrMultisigPSBTRequired = errors.New("multisig transactions need to be signed by the cosigners using a PSBT")
+
 // ProposedTransaction contains all the info needed to sign a btc transaction.
 type ProposedTransaction struct {
 	TXProposal *maketx.TxProposal
@@ -53,19 +60,16 @@ func (p *ProposedTransaction) Finalize() error {
 	return nil
 }
 
-// signTransaction signs all inputs. It assumes all outputs spent belong to this
-// wallet. previousOutputs must contain all outputs which are spent by the transaction.
-func (account *Account) signTransaction(
+// newProposedTransaction prepares a transaction proposal for signing with the keystore.
+func (account *Account) newProposedTransaction(
 	txProposal *maketx.TxProposal,
 	getPrevTx func(chainhash.Hash) (*wire.MsgTx, error),
-) error {
+) *ProposedTransaction {
 	signingConfigs := make([]*signing.Configuration, len(account.subaccounts))
 	for i, subacc := range account.subaccounts {
 		signingConfigs[i] = subacc.signingConfiguration
 	}
-	previousOutputs := txProposal.PreviousOutputs
-
-	proposedTransaction := &ProposedTransaction{
+	return &ProposedTransaction{
 		TXProposal:                   txProposal,
 		AccountSigningConfigurations: signingConfigs,
 		GetAccountAddress:            account.getAddress,
@@ -73,6 +77,22 @@ func (account *Account) signTransaction(
 		Signatures:                   make([]*types.Signature, len(txProposal.Transaction.TxIn)),
 		FormatUnit:                   account.coin.formatUnit,
 	}
+}
+
+// signTransaction signs all inputs. It assumes all outputs spent belong to this
+// wallet. previousOutputs must contain all outputs which are spent by the transaction.
+func (account *Account) signTransaction(
+	txProposal *maketx.TxProposal,
+	getPrevTx func(chainhash.Hash) (*wire.MsgTx, error),
+) error {
+	for _, subacc := range account.subaccounts {
+		multisig := subacc.signingConfiguration.BitcoinMultisig
+		if multisig != nil && multisig.Threshold > 1 {
+			return errp.WithStack(ErrMultisigPSBTRequired)
+		}
+	}
+	previousOutputs := txProposal.PreviousOutputs
+	proposedTransaction := account.newProposedTransaction(txProposal, getPrevTx)
 
 	keystore, err := account.Config().ConnectKeystore()
 	if err != nil {
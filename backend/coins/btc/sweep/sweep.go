@@ -0,0 +1,361 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sweep moves all coins controlled by a private key that is not part of the wallet, e.g. the
+// key of a paper wallet, into an account. The key is only held in memory while sweeping.
+package sweep
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore/software"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// gapLimit is the number of consecutive unused addresses after which the scan of an address chain
+// of an extended private key stops.
+const gapLimit = 20
+
+var (
+	// ErrInvalidKey is returned if the key is neither a WIF private key nor an extended private key.
+	ErrInvalidKey = errors.New("the key is not a valid WIF or extended private key")
+	// ErrWrongNetwork is returned if the key belongs to a different network than the account.
+	ErrWrongNetwork = errors.New("the key belongs to a different network")
+	// ErrUncompressedKey is returned for WIF keys of uncompressed public keys, which cannot be swept.
+	ErrUncompressedKey = errors.New("keys of uncompressed public keys are not supported")
+	// ErrNoFunds is returned if no unspent coins were found for the key.
+	ErrNoFunds = errors.New("no coins found for this key")
+	// ErrProposalChanged is returned if the amount or fee of the sweep changed since the user
+	// confirmed it, e.g. because the fee rates or the coins of the key changed.
+	ErrProposalChanged = errors.New("the amount or fee changed, please review the sweep again")
+)
+
+// scriptTypes are the script types for which the addresses of a key are scanned.
+var scriptTypes = []signing.ScriptType{
+	signing.ScriptTypeP2PKH,
+	signing.ScriptTypeP2WPKHP2SH,
+	signing.ScriptTypeP2WPKH,
+	signing.ScriptTypeP2TR,
+}
+
+// bip44Purposes are the BIP44 purposes of the standard account keypaths of the script types.
+var bip44Purposes = map[signing.ScriptType]uint32{
+	signing.ScriptTypeP2PKH:      44,
+	signing.ScriptTypeP2WPKHP2SH: 49,
+	signing.ScriptTypeP2WPKH:     84,
+	signing.ScriptTypeP2TR:       86,
+}
+
+// Key is a private key to sweep, together with the configurations of the addresses it controls.
+type Key struct {
+	// master is the key all addresses are derived from. A WIF key is wrapped in an extended key
+	// without chain code, so it can be used by the software keystore.
+	master *hdkeychain.ExtendedKey
+	// configurations are the configurations whose addresses are scanned for coins.
+	configurations []*signing.Configuration
+	// chains is true if the receive and change chains of the configurations are scanned, and false
+	// if the configurations are the addresses themselves, as for a WIF key.
+	chains bool
+}
+
+// ParseKey parses a WIF private key or an extended private key (xprv/tprv) of the given network.
+//
+// The addresses of a WIF key are its P2PKH, P2WPKH-P2SH, P2WPKH and P2TR addresses. An extended key
+// is treated as an account key, scanning the receive and change chains for each script type. If it
+// is a master key, the standard BIP44/49/84/86 keypaths of the first account are scanned as well.
+func ParseKey(key string, net *chaincfg.Params) (*Key, error) {
+	key = strings.TrimSpace(key)
+	if wif, err := btcutil.DecodeWIF(key); err == nil {
+		if !wif.IsForNet(net) {
+			return nil, errp.WithStack(ErrWrongNetwork)
+		}
+		if !wif.CompressPubKey {
+			return nil, errp.WithStack(ErrUncompressedKey)
+		}
+		master := hdkeychain.NewExtendedKey(
+			net.HDPrivateKeyID[:], wif.PrivKey.Serialize(), make([]byte, 32), []byte{0, 0, 0, 0}, 0, 0, true)
+		configurations, err := newConfigurations(master, signing.NewEmptyAbsoluteKeypath())
+		if err != nil {
+			return nil, err
+		}
+		return &Key{master: master, configurations: configurations, chains: false}, nil
+	}
+
+	master, err := hdkeychain.NewKeyFromString(key)
+	if err != nil || !master.IsPrivate() {
+		return nil, errp.WithStack(ErrInvalidKey)
+	}
+	if !master.IsForNet(net) {
+		return nil, errp.WithStack(ErrWrongNetwork)
+	}
+	configurations, err := newConfigurations(master, signing.NewEmptyAbsoluteKeypath())
+	if err != nil {
+		return nil, err
+	}
+	if master.Depth() == 0 {
+		for _, scriptType := range scriptTypes {
+			keypath := signing.NewAbsoluteKeypathFromUint32(
+				bip44Purposes[scriptType]+hdkeychain.HardenedKeyStart,
+				net.HDCoinType+hdkeychain.HardenedKeyStart,
+				hdkeychain.HardenedKeyStart,
+			)
+			accountConfigurations, err := newConfigurations(master, keypath)
+			if err != nil {
+				return nil, err
+			}
+			for _, configuration := range accountConfigurations {
+				if configuration.ScriptType() == scriptType {
+					configurations = append(configurations, configuration)
+				}
+			}
+		}
+	}
+	return &Key{master: master, configurations: configurations, chains: true}, nil
+}
+
+// newConfigurations returns the configurations of all script types at the keypath of the master key.
+func newConfigurations(
+	master *hdkeychain.ExtendedKey, keypath signing.AbsoluteKeypath) ([]*signing.Configuration, error) {
+	rootFingerprint, err := software.NewKeystore(master).RootFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	xprv, err := keypath.Derive(master)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	xpub, err := xprv.Neuter()
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	configurations := make([]*signing.Configuration, len(scriptTypes))
+	for index, scriptType := range scriptTypes {
+		configurations[index] = signing.NewBitcoinConfiguration(scriptType, rootFingerprint, keypath, xpub)
+	}
+	return configurations, nil
+}
+
+// funds are the unspent coins controlled by a key.
+type funds struct {
+	utxos map[wire.OutPoint]maketx.UTXO
+	// addresses are the used addresses of the key, by the hash of their pubkey script.
+	addresses map[blockchain.ScriptHashHex]*addresses.AccountAddress
+}
+
+// scan finds the unspent coins of the addresses controlled by the key.
+func scan(
+	chain blockchain.Interface, key *Key, net *chaincfg.Params, log *logrus.Entry,
+) (*funds, error) {
+	usedAddresses := map[blockchain.ScriptHashHex]*addresses.AccountAddress{}
+	txHashes := map[chainhash.Hash]struct{}{}
+	// isUsed adds the transactions of the address, returning false if the address has none.
+	isUsed := func(configuration *signing.Configuration, keypath signing.RelativeKeypath) (bool, error) {
+		address := addresses.NewAccountAddress(configuration, keypath, net, log)
+		history, err := chain.ScriptHashGetHistory(address.PubkeyScriptHashHex())
+		if err != nil {
+			return false, err
+		}
+		if len(history) == 0 {
+			return false, nil
+		}
+		usedAddresses[address.PubkeyScriptHashHex()] = address
+		for _, txInfo := range history {
+			txHashes[txInfo.TXHash.Hash()] = struct{}{}
+		}
+		return true, nil
+	}
+	for _, configuration := range key.configurations {
+		if !key.chains {
+			if _, err := isUsed(configuration, signing.NewEmptyRelativeKeypath()); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, chainIndex := range []uint32{0, 1} {
+			chainKeypath := signing.NewEmptyRelativeKeypath().Child(chainIndex, false)
+			for addressIndex, unused := uint32(0), 0; unused < gapLimit; addressIndex++ {
+				used, err := isUsed(configuration, chainKeypath.Child(addressIndex, false))
+				if err != nil {
+					return nil, err
+				}
+				if used {
+					unused = 0
+				} else {
+					unused++
+				}
+			}
+		}
+	}
+
+	// Outputs to our addresses which are not spent by any of the transactions of our addresses.
+	outputs := map[wire.OutPoint]maketx.UTXO{}
+	spent := map[wire.OutPoint]struct{}{}
+	for txHash := range txHashes {
+		tx, err := chain.TransactionGet(txHash)
+		if err != nil {
+			return nil, err
+		}
+		for _, txIn := range tx.TxIn {
+			spent[txIn.PreviousOutPoint] = struct{}{}
+		}
+		for index, txOut := range tx.TxOut {
+			address, ok := usedAddresses[blockchain.NewScriptHashHex(txOut.PkScript)]
+			if !ok {
+				continue
+			}
+			outputs[*wire.NewOutPoint(&txHash, uint32(index))] = maketx.UTXO{TxOut: txOut, Address: address}
+		}
+	}
+	for outPoint := range spent {
+		delete(outputs, outPoint)
+	}
+	log.WithField("utxos", len(outputs)).Info("Scanned the addresses of the key to sweep")
+	return &funds{utxos: outputs, addresses: usedAddresses}, nil
+}
+
+// sign signs all inputs of the transaction, which must only spend coins of the key, using the
+// software keystore.
+func sign(
+	key *Key,
+	funds *funds,
+	txProposal *maketx.TxProposal,
+	getPrevTx func(chainhash.Hash) (*wire.MsgTx, error),
+) error {
+	proposedTransaction := &btc.ProposedTransaction{
+		TXProposal: txProposal,
+		GetAccountAddress: func(scriptHashHex blockchain.ScriptHashHex) *addresses.AccountAddress {
+			return funds.addresses[scriptHashHex]
+		},
+		GetPrevTx:  getPrevTx,
+		Signatures: make([]*types.Signature, len(txProposal.Transaction.TxIn)),
+	}
+	if err := software.NewKeystore(key.master).SignTransaction(proposedTransaction); err != nil {
+		return err
+	}
+	if err := proposedTransaction.Finalize(); err != nil {
+		return err
+	}
+	return btc.TxValidityCheck(txProposal.Transaction, txProposal.PreviousOutputs, txProposal.SigHashes())
+}
+
+// Sweep sweeps the coins of a key into an account.
+type Sweep struct {
+	account *btc.Account
+	coin    *btc.Coin
+	key     *Key
+	funds   *funds
+	log     *logrus.Entry
+}
+
+// New parses the key and finds its coins. The account must be initialized.
+func New(account *btc.Account, key string) (*Sweep, error) {
+	coin, ok := account.Coin().(*btc.Coin)
+	if !ok {
+		return nil, errp.New("Only Bitcoin-based accounts can be swept into")
+	}
+	parsedKey, err := ParseKey(key, coin.Net())
+	if err != nil {
+		return nil, err
+	}
+	log := logging.Get().WithGroup("sweep").WithField("code", account.Config().Config.Code)
+	funds, err := scan(coin.Blockchain(), parsedKey, coin.Net(), log)
+	if err != nil {
+		return nil, err
+	}
+	if len(funds.utxos) == 0 {
+		return nil, errp.WithStack(ErrNoFunds)
+	}
+	return &Sweep{account: account, coin: coin, key: parsedKey, funds: funds, log: log}, nil
+}
+
+// Balance returns the sum of the coins of the key.
+func (sweep *Sweep) Balance() btcutil.Amount {
+	var balance btcutil.Amount
+	for _, utxo := range sweep.funds.utxos {
+		balance += btcutil.Amount(utxo.TxOut.Value)
+	}
+	return balance
+}
+
+// TxProposal creates the transaction paying all coins of the key to an unused receive address of
+// the account. The fee rate is deduced from the fee target as in a regular transaction.
+func (sweep *Sweep) TxProposal(
+	feeTargetCode accounts.FeeTargetCode, customFee string) (*maketx.TxProposal, error) {
+	feePerKb, err := sweep.account.FeeRatePerKb(feeTargetCode, customFee)
+	if err != nil {
+		return nil, err
+	}
+	receiveAddress, err := sweep.receiveAddress()
+	if err != nil {
+		return nil, err
+	}
+	return maketx.NewTxSpendAll(
+		sweep.coin,
+		sweep.funds.utxos,
+		maketx.NewOutputInfo(receiveAddress.PubkeyScript()),
+		feePerKb,
+		sweep.log,
+	)
+}
+
+// receiveAddress returns the first unused receive address of the account, preferring native segwit
+// like the receive screen.
+func (sweep *Sweep) receiveAddress() (*addresses.AccountAddress, error) {
+	var receiveAddress *addresses.AccountAddress
+	for _, addressList := range sweep.account.GetUnusedReceiveAddresses() {
+		if len(addressList.Addresses) == 0 {
+			continue
+		}
+		address, ok := addressList.Addresses[0].(*addresses.AccountAddress)
+		if !ok {
+			continue
+		}
+		if receiveAddress == nil ||
+			(addressList.ScriptType != nil && *addressList.ScriptType == signing.ScriptTypeP2WPKH) {
+			receiveAddress = address
+		}
+	}
+	if receiveAddress == nil {
+		return nil, errp.New("The account has no receive address")
+	}
+	return receiveAddress, nil
+}
+
+// Send signs the transaction with the key and broadcasts it. It returns the transaction ID.
+func (sweep *Sweep) Send(txProposal *maketx.TxProposal) (string, error) {
+	if err := sign(sweep.key, sweep.funds, txProposal, sweep.coin.Blockchain().TransactionGet); err != nil {
+		return "", errp.WithMessage(err, "Failed to sign the sweep transaction")
+	}
+	if err := sweep.coin.Blockchain().TransactionBroadcast(txProposal.Transaction); err != nil {
+		return "", err
+	}
+	txID := txProposal.Transaction.TxHash().String()
+	sweep.log.WithField("txID", txID).Info("Broadcasted the sweep transaction")
+	return txID, nil
+}
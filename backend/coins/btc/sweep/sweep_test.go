@@ -0,0 +1,218 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/socksproxy"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+var net = &chaincfg.TestNet3Params
+
+// testChain is a blockchain index serving the history of the transactions added to it.
+type testChain struct {
+	txs     map[chainhash.Hash]*wire.MsgTx
+	history map[blockchain.ScriptHashHex]blockchain.TxHistory
+}
+
+func newTestChain() *testChain {
+	return &testChain{
+		txs:     map[chainhash.Hash]*wire.MsgTx{},
+		history: map[blockchain.ScriptHashHex]blockchain.TxHistory{},
+	}
+}
+
+// pay adds a transaction paying the amount to each of the addresses, and spending the given outputs.
+func (chain *testChain) pay(
+	spend []wire.OutPoint, amount int64, payTo ...*addresses.AccountAddress) *wire.MsgTx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	scriptHashes := map[blockchain.ScriptHashHex]struct{}{}
+	for _, outPoint := range spend {
+		tx.AddTxIn(wire.NewTxIn(&outPoint, nil, nil))
+		prevOut := chain.txs[outPoint.Hash].TxOut[outPoint.Index]
+		scriptHashes[blockchain.NewScriptHashHex(prevOut.PkScript)] = struct{}{}
+	}
+	if len(spend) == 0 {
+		// Make the funding transactions unique.
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: uint32(len(chain.txs))}, nil, nil))
+	}
+	for _, address := range payTo {
+		tx.AddTxOut(wire.NewTxOut(amount, address.PubkeyScript()))
+		scriptHashes[address.PubkeyScriptHashHex()] = struct{}{}
+	}
+	txHash := tx.TxHash()
+	chain.txs[txHash] = tx
+	for scriptHash := range scriptHashes {
+		chain.history[scriptHash] = append(chain.history[scriptHash],
+			&blockchain.TxInfo{Height: 10, TXHash: blockchain.TXHash(txHash)})
+	}
+	return tx
+}
+
+func (chain *testChain) blockchain() blockchain.Interface {
+	return &mocks.BlockchainMock{
+		MockScriptHashGetHistory: func(scriptHash blockchain.ScriptHashHex) (blockchain.TxHistory, error) {
+			return chain.history[scriptHash], nil
+		},
+		MockTransactionGet: func(txHash chainhash.Hash) (*wire.MsgTx, error) {
+			return chain.txs[txHash], nil
+		},
+	}
+}
+
+func testAddress(
+	t *testing.T, key *Key, scriptType signing.ScriptType, accountKeypath string, keypath string,
+) *addresses.AccountAddress {
+	t.Helper()
+	absoluteKeypath, err := signing.NewAbsoluteKeypath(accountKeypath)
+	require.NoError(t, err)
+	for _, configuration := range key.configurations {
+		if configuration.ScriptType() != scriptType ||
+			configuration.AbsoluteKeypath().Encode() != absoluteKeypath.Encode() {
+			continue
+		}
+		relativeKeypath, err := signing.NewRelativeKeypath(keypath)
+		require.NoError(t, err)
+		return addresses.NewAccountAddress(configuration, relativeKeypath, net, logging.Get().WithGroup("sweep_test"))
+	}
+	require.FailNow(t, "configuration not found")
+	return nil
+}
+
+// sweepAndCheck scans the chain for the coins of the key, and signs a transaction spending them.
+func sweepAndCheck(t *testing.T, chain *testChain, key *Key) (*funds, *maketx.TxProposal) {
+	t.Helper()
+	log := logging.Get().WithGroup("sweep_test")
+	funds, err := scan(chain.blockchain(), key, net, log)
+	require.NoError(t, err)
+
+	tbtc := btc.NewCoin(coin.CodeTBTC, "Bitcoin Testnet", "TBTC", coin.BtcUnitDefault,
+		net, ".", nil, "", socksproxy.NewSocksProxy(false, ""))
+	master, err := hdkeychain.NewMaster(bytes.Repeat([]byte{0x42}, hdkeychain.RecommendedSeedLen), net)
+	require.NoError(t, err)
+	recipient := testAddress(t, &Key{configurations: mustConfigurations(t, master)},
+		signing.ScriptTypeP2WPKH, "m/", "0/0")
+	txProposal, err := maketx.NewTxSpendAll(
+		tbtc, funds.utxos, maketx.NewOutputInfo(recipient.PubkeyScript()), 1000, log)
+	require.NoError(t, err)
+	require.Len(t, txProposal.Transaction.TxOut, 1)
+	require.NoError(t, sign(key, funds, txProposal, chain.blockchain().TransactionGet))
+	return funds, txProposal
+}
+
+func mustConfigurations(t *testing.T, master *hdkeychain.ExtendedKey) []*signing.Configuration {
+	t.Helper()
+	configurations, err := newConfigurations(master, signing.NewEmptyAbsoluteKeypath())
+	require.NoError(t, err)
+	return configurations
+}
+
+func TestParseKey(t *testing.T) {
+	privateKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x01}, 32))
+
+	wif, err := btcutil.NewWIF(privateKey, net, true)
+	require.NoError(t, err)
+	key, err := ParseKey(" "+wif.String()+"\n", net)
+	require.NoError(t, err)
+	require.False(t, key.chains)
+	require.Len(t, key.configurations, len(scriptTypes))
+	require.Equal(t, privateKey.PubKey(), key.configurations[0].PublicKey())
+
+	_, err = ParseKey(wif.String(), &chaincfg.MainNetParams)
+	require.Equal(t, ErrWrongNetwork, errp.Cause(err))
+
+	uncompressed, err := btcutil.NewWIF(privateKey, net, false)
+	require.NoError(t, err)
+	_, err = ParseKey(uncompressed.String(), net)
+	require.Equal(t, ErrUncompressedKey, errp.Cause(err))
+
+	master, err := hdkeychain.NewMaster(bytes.Repeat([]byte{0x01}, hdkeychain.RecommendedSeedLen), net)
+	require.NoError(t, err)
+	key, err = ParseKey(master.String(), net)
+	require.NoError(t, err)
+	require.True(t, key.chains)
+	// The key itself and the standard account keypaths.
+	require.Len(t, key.configurations, 2*len(scriptTypes))
+	require.Equal(t, "m/86'/1'/0'", key.configurations[len(key.configurations)-1].AbsoluteKeypath().Encode())
+
+	xpub, err := master.Neuter()
+	require.NoError(t, err)
+	_, err = ParseKey(xpub.String(), net)
+	require.Equal(t, ErrInvalidKey, errp.Cause(err))
+	_, err = ParseKey("not a key", net)
+	require.Equal(t, ErrInvalidKey, errp.Cause(err))
+}
+
+func TestSweepWIF(t *testing.T) {
+	privateKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x02}, 32))
+	wif, err := btcutil.NewWIF(privateKey, net, true)
+	require.NoError(t, err)
+	key, err := ParseKey(wif.String(), net)
+	require.NoError(t, err)
+
+	chain := newTestChain()
+	var funding []*wire.MsgTx
+	for _, scriptType := range scriptTypes {
+		funding = append(funding, chain.pay(nil, 100000, testAddress(t, key, scriptType, "m/", "")))
+	}
+	// The P2PKH coins were spent already.
+	other, err := hdkeychain.NewMaster(bytes.Repeat([]byte{0x03}, hdkeychain.RecommendedSeedLen), net)
+	require.NoError(t, err)
+	chain.pay([]wire.OutPoint{{Hash: funding[0].TxHash(), Index: 0}}, 90000,
+		testAddress(t, &Key{configurations: mustConfigurations(t, other)}, signing.ScriptTypeP2WPKH, "m/", "0/0"))
+
+	funds, txProposal := sweepAndCheck(t, chain, key)
+	require.Len(t, funds.utxos, 3)
+	require.Len(t, txProposal.Transaction.TxIn, 3)
+	require.Equal(t, btcutil.Amount(300000), txProposal.Amount+txProposal.Fee)
+}
+
+func TestSweepExtendedKey(t *testing.T) {
+	master, err := hdkeychain.NewMaster(bytes.Repeat([]byte{0x04}, hdkeychain.RecommendedSeedLen), net)
+	require.NoError(t, err)
+	key, err := ParseKey(master.String(), net)
+	require.NoError(t, err)
+
+	chain := newTestChain()
+	chain.pay(nil, 100000, testAddress(t, key, signing.ScriptTypeP2WPKH, "m/84'/1'/0'", "0/0"))
+	// Found as the address is within the gap limit of the previous used address.
+	chain.pay(nil, 100000, testAddress(t, key, signing.ScriptTypeP2WPKH, "m/84'/1'/0'", "0/15"))
+	chain.pay(nil, 100000, testAddress(t, key, signing.ScriptTypeP2TR, "m/86'/1'/0'", "1/3"))
+	chain.pay(nil, 100000, testAddress(t, key, signing.ScriptTypeP2WPKHP2SH, "m/", "0/1"))
+	// Not found as it is beyond the gap limit.
+	chain.pay(nil, 100000, testAddress(t, key, signing.ScriptTypeP2PKH, "m/44'/1'/0'", "0/25"))
+
+	funds, txProposal := sweepAndCheck(t, chain, key)
+	require.Len(t, funds.utxos, 4)
+	require.Equal(t, btcutil.Amount(400000), txProposal.Amount+txProposal.Fee)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 )
 
@@ -76,6 +77,17 @@ func (account *Account) getFeePerKb(args *accounts.TxProposalArgs) (btcutil.Amou
 	return *feeTarget.feeRatePerKb, nil
 }
 
+// FeeRatePerKb returns the fee rate deduced from the fee target, or the custom fee rate (in
+// sat/vB) if the fee target is `FeeTargetCodeCustom`. It is used for transactions not created from
+// the coins of the account, e.g. when sweeping a private key into the account.
+func (account *Account) FeeRatePerKb(
+	feeTargetCode accounts.FeeTargetCode, customFee string) (btcutil.Amount, error) {
+	return account.getFeePerKb(&accounts.TxProposalArgs{
+		FeeTargetCode: feeTargetCode,
+		CustomFee:     customFee,
+	})
+}
+
 // pickChangeAddress returns a suitable unused change address to be used when making a transaction.
 // If the account is a unified account with multiple subaccounts (script/address types), we choose
 // the change address type like this:
@@ -129,8 +141,21 @@ func (account *Account) pickChangeAddress(utxos map[wire.OutPoint]maketx.UTXO) (
 	return unusedAddresses[0], nil
 }
 
-// newTx creates a new tx to the given recipient address. It also returns a set of used account
-// outputs, which contains all outputs that spent in the tx. Those are needed to be able to sign the
+// outputInfo returns the output info for sending to the given recipient address, which can also be
+// a silent payment address.
+func (account *Account) outputInfo(address string) (*maketx.OutputInfo, error) {
+	if err := account.coin.ValidateSilentPaymentAddress(address); err == nil {
+		return maketx.NewOutputInfoSilentPayment(address), nil
+	}
+	pkScript, err := account.coin.AddressToPkScript(address)
+	if err != nil {
+		return nil, err
+	}
+	return maketx.NewOutputInfo(pkScript), nil
+}
+
+// newTx creates a new tx to the given recipients. It also returns a set of used account outputs,
+// which contains all outputs that spent in the tx. Those are needed to be able to sign the
 // transaction. selectedUTXOs restricts the available coins; if empty, no restriction is applied and
 // all unspent coins can be used.
 func (account *Account) newTx(args *accounts.TxProposalArgs) (
@@ -138,20 +163,50 @@ func (account *Account) newTx(args *accounts.TxProposalArgs) (
 
 	account.log.Debug("Prepare new transaction")
 
-	var outputInfo *maketx.OutputInfo
-	if err := account.coin.ValidateSilentPaymentAddress(args.RecipientAddress); err == nil {
-		outputInfo = maketx.NewOutputInfoSilentPayment(args.RecipientAddress)
-	} else {
-		pkScript, err := account.coin.AddressToPkScript(args.RecipientAddress)
+	allRecipients := args.AllRecipients()
+	if args.PaymentRequest != nil && len(allRecipients) != 1 {
+		return nil, nil, errp.New("Payment Requests do not allow multiple recipients")
+	}
+	unit := int64(unitSatoshi)
+	if account.coin.formatUnit == coin.BtcUnitSats {
+		unit = 1
+	}
+	var recipients []*maketx.Recipient
+	var remainingRecipient *maketx.OutputInfo
+	for _, recipient := range allRecipients {
+		outputInfo, err := account.outputInfo(recipient.Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		if recipient.Amount.SendAll() {
+			if remainingRecipient != nil {
+				return nil, nil, errp.New("Only one recipient can receive the remaining coins")
+			}
+			remainingRecipient = outputInfo
+			continue
+		}
+		allowZero := false
+		parsedAmount, err := recipient.Amount.Amount(big.NewInt(unit), allowZero)
 		if err != nil {
 			return nil, nil, err
 		}
-		outputInfo = maketx.NewOutputInfo(pkScript)
+		parsedAmountInt64, err := parsedAmount.Int64()
+		if err != nil {
+			return nil, nil, errp.WithStack(errors.ErrInvalidAmount)
+		}
+		recipients = append(recipients, &maketx.Recipient{
+			OutputInfo: outputInfo,
+			Amount:     btcutil.Amount(parsedAmountInt64),
+		})
 	}
 	utxo, err := account.transactions.SpendableOutputs()
 	if err != nil {
 		return nil, nil, err
 	}
+	utxoStates, err := account.UTXOStates()
+	if err != nil {
+		return nil, nil, err
+	}
 	wireUTXO := make(map[wire.OutPoint]maketx.UTXO, len(utxo))
 	for outPoint, txOut := range utxo {
 		// Apply coin control.
@@ -164,6 +219,8 @@ func (account *Account) newTx(args *accounts.TxProposalArgs) (
 			TxOut: txOut.TxOut,
 			Address: account.getAddress(
 				blockchain.NewScriptHashHex(txOut.TxOut.PkScript)),
+			// Explicitly selected coins are spent even if they are frozen.
+			Frozen: utxoStates[outPoint].Frozen && len(args.SelectedUTXOs) == 0,
 		}
 	}
 	feeRatePerKb, err := account.getFeePerKb(args)
@@ -171,64 +228,68 @@ func (account *Account) newTx(args *accounts.TxProposalArgs) (
 		return nil, nil, err
 	}
 
-	var txProposal *maketx.TxProposal
-	if args.Amount.SendAll() {
+	var changeAddress *addresses.AccountAddress
+	if remainingRecipient != nil {
 		if args.PaymentRequest != nil {
 			return nil, nil, errp.New("Payment Requests do not allow send-all transaction proposals")
 		}
-		txProposal, err = maketx.NewTxSpendAll(
-			account.coin,
-			wireUTXO,
-			outputInfo,
-			feeRatePerKb,
-			account.log,
-		)
-		if err != nil {
-			return nil, nil, err
-		}
 	} else {
-		allowZero := false
-
-		unit := int64(unitSatoshi)
-		if account.coin.formatUnit == coin.BtcUnitSats {
-			unit = 1
-		}
-		parsedAmount, err := args.Amount.Amount(big.NewInt(unit), allowZero)
-		if err != nil {
-			return nil, nil, err
-		}
-		parsedAmountInt64, err := parsedAmount.Int64()
-		if err != nil {
-			return nil, nil, errp.WithStack(errors.ErrInvalidAmount)
-		}
-		changeAddress, err := account.pickChangeAddress(wireUTXO)
+		changeAddress, err = account.pickChangeAddress(wireUTXO)
 		if err != nil {
 			return nil, nil, err
 		}
 		account.log.Infof("Change address script type: %s", changeAddress.Configuration.ScriptType())
-		txProposal, err = maketx.NewTx(
-			account.coin,
-			wireUTXO,
-			outputInfo,
-			parsedAmountInt64,
-			feeRatePerKb,
-			changeAddress,
-			account.log,
-		)
-		if err != nil {
-			return nil, nil, err
-		}
-
-		if args.PaymentRequest != nil {
-			account.log.Info("Payment request tx proposal")
-			txProposal.PaymentRequest = args.PaymentRequest
-		}
 	}
-	account.log.Debugf("creating tx with %d inputs, %d outputs",
-		len(txProposal.Transaction.TxIn), len(txProposal.Transaction.TxOut))
+	coinSelection, err := account.coinSelection(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	txProposal, err := maketx.NewTxRecipients(
+		account.coin,
+		wireUTXO,
+		recipients,
+		remainingRecipient,
+		feeRatePerKb,
+		changeAddress,
+		coinSelection,
+		account.log,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if args.PaymentRequest != nil {
+		account.log.Info("Payment request tx proposal")
+		txProposal.PaymentRequest = args.PaymentRequest
+	}
+	account.log.Debugf("creating tx with %d inputs, %d outputs, coin selection: %s",
+		len(txProposal.Transaction.TxIn), len(txProposal.Transaction.TxOut), txProposal.CoinSelection)
 	return utxo, txProposal, nil
 }
 
+// coinSelection returns the coin selection configuration for a new tx. The long-term fee rate used
+// to weigh the selections is the lowest fee target, or the min relay fee if no fee target is
+// available.
+func (account *Account) coinSelection(args *accounts.TxProposalArgs) (*maketx.CoinSelection, error) {
+	strategy := args.CoinSelection
+	if strategy == "" {
+		strategy = accounts.DefaultCoinSelection
+	}
+	if strategy == accounts.CoinSelectionLargestFirst {
+		return &maketx.CoinSelection{Strategy: strategy}, nil
+	}
+	if feeTarget := account.feeTargets().lowest(); feeTarget != nil {
+		return &maketx.CoinSelection{
+			Strategy:         strategy,
+			LongTermFeePerKb: *feeTarget.feeRatePerKb,
+		}, nil
+	}
+	minRelayFeeRate, err := account.getMinRelayFeeRate()
+	if err != nil {
+		return nil, err
+	}
+	return &maketx.CoinSelection{Strategy: strategy, LongTermFeePerKb: minRelayFeeRate}, nil
+}
+
 // getAddress returns the address in the account with the given `scriptHashHex`. Returns nil if the
 // address does not exist in the account.
 func (account *Account) getAddress(scriptHashHex blockchain.ScriptHashHex) *addresses.AccountAddress {
@@ -247,10 +308,36 @@ func (account *Account) getAddress(scriptHashHex blockchain.ScriptHashHex) *addr
 func (account *Account) SendTx(txNote string) error {
 	unlock := account.activeTxProposalLock.RLock()
 	txProposal := account.activeTxProposal
+	fiatQuote := account.activeFiatQuote
 	unlock()
 	if txProposal == nil {
 		return errp.New("No active tx proposal")
 	}
+	return account.sendTx(txProposal, fiatQuote, txNote)
+}
+
+// SendTxProposal implements accounts.Interface.
+func (account *Account) SendTxProposal(args *accounts.TxProposalArgs, txNote string) error {
+	unlock := account.activeTxProposalLock.RLock()
+	args, fiatQuote, err := account.ConvertFiatAmounts(args)
+	if err != nil {
+		unlock()
+		return err
+	}
+	_, txProposal, err := account.newTx(args)
+	unlock()
+	if err != nil {
+		return err
+	}
+	return account.sendTx(txProposal, fiatQuote, txNote)
+}
+
+// sendTx signs and broadcasts the tx proposal, and stores the note of the transaction.
+func (account *Account) sendTx(
+	txProposal *maketx.TxProposal, fiatQuote *accounts.FiatQuote, txNote string) error {
+	if err := account.CheckFiatQuote(fiatQuote); err != nil {
+		return err
+	}
 
 	account.log.Info("Signing and sending transaction")
 	if err := account.signTransaction(txProposal, account.coin.Blockchain().TransactionGet); err != nil {
@@ -270,24 +357,293 @@ func (account *Account) SendTx(txNote string) error {
 }
 
 // TxProposal creates a tx from the relevant input and returns information about it for display in
-// the UI (the output amount and the fee). At the same time, it validates the input. The proposal is
-// stored internally and can be signed and sent with SendTx().
+// the UI (the output amount, the fee and privacy warnings). At the same time, it validates the
+// input. Unless args.DryRun is set, the proposal is stored internally and can be signed and sent
+// with SendTx().
 func (account *Account) TxProposal(
 	args *accounts.TxProposalArgs,
-) (
-	coin.Amount, coin.Amount, coin.Amount, error) {
+) (*accounts.TxProposalResult, error) {
 	defer account.activeTxProposalLock.Lock()()
 
 	account.log.Debug("Proposing transaction")
+	args, fiatQuote, err := account.ConvertFiatAmounts(args)
+	if err != nil {
+		return nil, err
+	}
 	_, txProposal, err := account.newTx(args)
 	if err != nil {
-		return coin.Amount{}, coin.Amount{}, coin.Amount{}, err
+		return nil, err
+	}
+	warnings, err := account.txProposalWarnings(args, txProposal)
+	if err != nil {
+		return nil, err
 	}
 
-	account.activeTxProposal = txProposal
+	if !args.DryRun {
+		account.activeTxProposal = txProposal
+		account.activeFiatQuote = fiatQuote
+	}
 
 	account.log.WithField("fee", txProposal.Fee).Debug("Returning fee")
-	return coin.NewAmountFromInt64(int64(txProposal.Amount)),
-		coin.NewAmountFromInt64(int64(txProposal.Fee)),
-		coin.NewAmountFromInt64(int64(txProposal.Total())), nil
+	return &accounts.TxProposalResult{
+		Amount:    coin.NewAmountFromInt64(int64(txProposal.Amount)),
+		Fee:       coin.NewAmountFromInt64(int64(txProposal.Fee)),
+		Total:     coin.NewAmountFromInt64(int64(txProposal.Total())),
+		Warnings:  warnings,
+		FiatQuote: fiatQuote,
+	}, nil
+}
+
+// BumpFee replaces the pending outgoing transaction with the given ID by a transaction paying a
+// higher fee, deduced from the fee target like in TxProposal(). The replacement spends the same
+// inputs (BIP-125) and pays the same recipients. The fee increase is deducted from the change
+// output, and more coins are added if needed.
+//
+// The replacement is signed and broadcasted. Its transaction ID and fee are returned. The note of
+// the original transaction is carried over.
+func (account *Account) BumpFee(
+	txID string, feeTargetCode accounts.FeeTargetCode, customFee string) (string, coin.Amount, error) {
+	txHash, err := chainhash.NewHashFromStr(txID)
+	if err != nil {
+		return "", coin.Amount{}, errp.WithStack(err)
+	}
+	txs, err := account.Transactions()
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	var originalTx *accounts.TransactionData
+	// New inputs of the replacement must be confirmed.
+	unconfirmedTxs := map[string]struct{}{}
+	for _, tx := range txs {
+		if tx.Height <= 0 {
+			unconfirmedTxs[tx.TxID] = struct{}{}
+		}
+		if tx.TxID == txID {
+			originalTx = tx
+		}
+	}
+	if originalTx == nil {
+		return "", coin.Amount{}, errp.Newf("Transaction %s not found", txID)
+	}
+	if originalTx.Type != accounts.TxTypeSend || originalTx.Height > 0 {
+		return "", coin.Amount{}, errp.New("Only pending outgoing transactions can be replaced")
+	}
+
+	tx, err := account.coin.Blockchain().TransactionGet(*txHash)
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	previousOutputs := maketx.PreviousOutputs{}
+	for _, txIn := range tx.TxIn {
+		outPoint := txIn.PreviousOutPoint
+		prevTx, err := account.coin.Blockchain().TransactionGet(outPoint.Hash)
+		if err != nil {
+			return "", coin.Amount{}, err
+		}
+		if int(outPoint.Index) >= len(prevTx.TxOut) {
+			return "", coin.Amount{}, errp.New("Invalid previous output")
+		}
+		txOut := prevTx.TxOut[outPoint.Index]
+		address := account.getAddress(blockchain.NewScriptHashHex(txOut.PkScript))
+		if address == nil {
+			return "", coin.Amount{}, errp.New("Only transactions spending our own coins can be replaced")
+		}
+		previousOutputs[outPoint] = maketx.UTXO{TxOut: txOut, Address: address}
+	}
+
+	changeIndex := -1
+	var changeAddress *addresses.AccountAddress
+	for index, txOut := range tx.TxOut {
+		scriptHashHex := blockchain.NewScriptHashHex(txOut.PkScript)
+		if account.IsChange(scriptHashHex) {
+			changeIndex = index
+			changeAddress = account.getAddress(scriptHashHex)
+			break
+		}
+	}
+	if changeAddress == nil {
+		changeAddress, err = account.pickChangeAddress(previousOutputs)
+		if err != nil {
+			return "", coin.Amount{}, err
+		}
+	}
+
+	utxo, err := account.transactions.SpendableOutputs()
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	utxoStates, err := account.UTXOStates()
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	wireUTXO := make(map[wire.OutPoint]maketx.UTXO, len(utxo))
+	for outPoint, txOut := range utxo {
+		if _, ok := unconfirmedTxs[outPoint.Hash.String()]; ok {
+			continue
+		}
+		wireUTXO[outPoint] = maketx.UTXO{
+			TxOut: txOut.TxOut,
+			Address: account.getAddress(
+				blockchain.NewScriptHashHex(txOut.TxOut.PkScript)),
+			Frozen: utxoStates[outPoint].Frozen,
+		}
+	}
+
+	feeRatePerKb, err := account.getFeePerKb(&accounts.TxProposalArgs{
+		FeeTargetCode: feeTargetCode,
+		CustomFee:     customFee,
+	})
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	minRelayFeeRate, err := account.getMinRelayFeeRate()
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	txProposal, err := maketx.NewTxBumpFee(
+		account.coin,
+		tx,
+		previousOutputs,
+		changeIndex,
+		changeAddress,
+		wireUTXO,
+		feeRatePerKb,
+		minRelayFeeRate,
+		account.log,
+	)
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+
+	account.log.WithField("fee", txProposal.Fee).Info("Signing and sending replacement transaction")
+	if err := account.signTransaction(txProposal, account.coin.Blockchain().TransactionGet); err != nil {
+		return "", coin.Amount{}, errp.WithMessage(err, "Failed to sign transaction")
+	}
+	if err := account.coin.Blockchain().TransactionBroadcast(txProposal.Transaction); err != nil {
+		return "", coin.Amount{}, err
+	}
+
+	replacementTxID := txProposal.Transaction.TxHash().String()
+	if note := account.TxNote(txID); note != "" {
+		if err := account.SetTxNote(replacementTxID, note); err != nil {
+			// Not critical.
+			account.log.WithError(err).Error("Failed to save transaction note of the replacement tx")
+		}
+	}
+	return replacementTxID, coin.NewAmountFromInt64(int64(txProposal.Fee)), nil
+}
+
+// ChildPaysForParent accelerates the unconfirmed transaction with the given ID by spending its
+// outputs belonging to this account in a child transaction (CPFP). The child pays a fee such that
+// parent and child together pay the fee rate deduced from the fee target like in TxProposal().
+// This works for incoming transactions, and for coins or senders that do not support RBF.
+//
+// The child is signed and broadcasted. Its transaction ID and fee are returned.
+func (account *Account) ChildPaysForParent(
+	txID string, feeTargetCode accounts.FeeTargetCode, customFee string) (string, coin.Amount, error) {
+	txHash, err := chainhash.NewHashFromStr(txID)
+	if err != nil {
+		return "", coin.Amount{}, errp.WithStack(err)
+	}
+	txs, err := account.Transactions()
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	var parentTx *accounts.TransactionData
+	for _, tx := range txs {
+		if tx.TxID == txID {
+			parentTx = tx
+			break
+		}
+	}
+	if parentTx == nil {
+		return "", coin.Amount{}, errp.Newf("Transaction %s not found", txID)
+	}
+	if parentTx.Height > 0 {
+		return "", coin.Amount{}, errp.New("Only pending transactions can be accelerated")
+	}
+
+	var parentFee btcutil.Amount
+	if parentTx.Fee != nil {
+		fee, err := parentTx.Fee.Int64()
+		if err != nil {
+			return "", coin.Amount{}, err
+		}
+		parentFee = btcutil.Amount(fee)
+	} else {
+		// Incoming transaction, the spent outputs are not ours and need to be fetched.
+		parentFee, err = account.transactionFee(*txHash)
+		if err != nil {
+			return "", coin.Amount{}, err
+		}
+	}
+
+	utxo, err := account.transactions.UnspentOutputsOfTx(*txHash)
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	parentOutputs := make(map[wire.OutPoint]maketx.UTXO, len(utxo))
+	for outPoint, txOut := range utxo {
+		parentOutputs[outPoint] = maketx.UTXO{
+			TxOut: txOut.TxOut,
+			Address: account.getAddress(
+				blockchain.NewScriptHashHex(txOut.TxOut.PkScript)),
+		}
+	}
+	feeRatePerKb, err := account.getFeePerKb(&accounts.TxProposalArgs{
+		FeeTargetCode: feeTargetCode,
+		CustomFee:     customFee,
+	})
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	changeAddress, err := account.pickChangeAddress(parentOutputs)
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	txProposal, err := maketx.NewTxCPFP(
+		account.coin,
+		parentOutputs,
+		parentTx.VSize,
+		parentFee,
+		feeRatePerKb,
+		changeAddress,
+		account.log,
+	)
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+
+	account.log.WithField("fee", txProposal.Fee).Info("Signing and sending child-pays-for-parent transaction")
+	if err := account.signTransaction(txProposal, account.coin.Blockchain().TransactionGet); err != nil {
+		return "", coin.Amount{}, errp.WithMessage(err, "Failed to sign transaction")
+	}
+	if err := account.coin.Blockchain().TransactionBroadcast(txProposal.Transaction); err != nil {
+		return "", coin.Amount{}, err
+	}
+	return txProposal.Transaction.TxHash().String(), coin.NewAmountFromInt64(int64(txProposal.Fee)), nil
+}
+
+// transactionFee computes the fee of a transaction by fetching the outputs it spends.
+func (account *Account) transactionFee(txHash chainhash.Hash) (btcutil.Amount, error) {
+	tx, err := account.coin.Blockchain().TransactionGet(txHash)
+	if err != nil {
+		return 0, err
+	}
+	fee := btcutil.Amount(0)
+	for _, txIn := range tx.TxIn {
+		outPoint := txIn.PreviousOutPoint
+		prevTx, err := account.coin.Blockchain().TransactionGet(outPoint.Hash)
+		if err != nil {
+			return 0, err
+		}
+		if int(outPoint.Index) >= len(prevTx.TxOut) {
+			return 0, errp.New("Invalid previous output")
+		}
+		fee += btcutil.Amount(prevTx.TxOut[outPoint.Index].Value)
+	}
+	for _, txOut := range tx.TxOut {
+		fee -= btcutil.Amount(txOut.Value)
+	}
+	return fee, nil
 }
@@ -105,6 +105,16 @@ type DBTxInterface interface {
 	// GapLimits returns the gap limit for receive and change addresses.
 	// If none have been stored before, the default zero value is returned.
 	GapLimits() (types.GapLimits, error)
+
+	// PutUTXOState stores the coin control state of an output. An empty state is removed.
+	PutUTXOState(wire.OutPoint, types.UTXOState) error
+
+	// UTXOState returns the coin control state of an output. If none is stored, the empty state is
+	// returned.
+	UTXOState(wire.OutPoint) (types.UTXOState, error)
+
+	// UTXOStates returns all stored coin control states.
+	UTXOStates() (map[wire.OutPoint]types.UTXOState, error)
 }
 
 // DBInterface can be implemented by database backends to open database transactions.
@@ -221,6 +221,37 @@ func (transactions *Transactions) SpendableOutputs() (map[wire.OutPoint]*Spendab
 	})
 }
 
+// UnspentOutputsOfTx returns the unspent outputs of the wallet created by the given transaction.
+// Unlike SpendableOutputs(), the outputs are returned even if the transaction is an unconfirmed
+// incoming transaction. This allows to accelerate such a transaction by spending its outputs
+// (child-pays-for-parent).
+func (transactions *Transactions) UnspentOutputsOfTx(txHash chainhash.Hash) (
+	map[wire.OutPoint]*SpendableOutput, error) {
+	transactions.synchronizer.WaitSynchronized()
+	return DBView(transactions.db, func(dbTx DBTxInterface) (map[wire.OutPoint]*SpendableOutput, error) {
+		txInfo, err := dbTx.TxInfo(txHash)
+		if err != nil {
+			return nil, err
+		}
+		if txInfo.Tx == nil {
+			return nil, errp.Newf("transaction %s not found", txHash)
+		}
+		result := map[wire.OutPoint]*SpendableOutput{}
+		for index := range txInfo.Tx.TxOut {
+			outPoint := wire.OutPoint{Hash: txHash, Index: uint32(index)}
+			txOut, err := dbTx.Output(outPoint)
+			if err != nil {
+				return nil, err
+			}
+			if txOut == nil || transactions.isInputSpent(dbTx, outPoint) {
+				continue
+			}
+			result[outPoint] = &SpendableOutput{TxOut: txOut}
+		}
+		return result, nil
+	})
+}
+
 func (transactions *Transactions) isInputSpent(dbTx DBTxInterface, outPoint wire.OutPoint) bool {
 	input, err := dbTx.Input(outPoint)
 	if err != nil {
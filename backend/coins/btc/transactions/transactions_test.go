@@ -257,6 +257,42 @@ func (s *transactionsSuite) TestSpendableOutputs() {
 	s.Require().Contains(spendableOutputs, wire.OutPoint{Hash: tx22Spend.TxHash(), Index: 0})
 }
 
+// TestUnspentOutputsOfTx checks that unconfirmed incoming outputs are returned, as long as they are
+// unspent.
+func (s *transactionsSuite) TestUnspentOutputsOfTx() {
+	addresses, err := s.addressChain.EnsureAddresses()
+	s.Require().NoError(err)
+	address := addresses[0]
+	otherAddress := addresses[1]
+	tx1 := newTx(chainhash.HashH(nil), 0, address, 1000)
+	tx1.AddTxOut(wire.NewTxOut(2000, otherAddress.PubkeyScript()))
+	s.blockchainMock.RegisterTxs(tx1)
+	s.updateAddressHistory(address, []*blockchainpkg.TxInfo{
+		{TXHash: blockchainpkg.TXHash(tx1.TxHash()), Height: 0},
+	})
+	spendableOutputs, err := s.transactions.SpendableOutputs()
+	s.Require().NoError(err)
+	s.Require().Empty(spendableOutputs)
+	unspentOutputs, err := s.transactions.UnspentOutputsOfTx(tx1.TxHash())
+	s.Require().NoError(err)
+	s.Require().Equal(
+		map[wire.OutPoint]*transactions.SpendableOutput{
+			{Hash: tx1.TxHash(), Index: 0}: {TxOut: wire.NewTxOut(1000, address.PubkeyScript())},
+		},
+		unspentOutputs,
+	)
+
+	tx1Spend := newTx(tx1.TxHash(), 0, otherAddress, 900)
+	s.blockchainMock.RegisterTxs(tx1Spend)
+	s.updateAddressHistory(address, []*blockchainpkg.TxInfo{
+		{TXHash: blockchainpkg.TXHash(tx1.TxHash()), Height: 0},
+		{TXHash: blockchainpkg.TXHash(tx1Spend.TxHash()), Height: 0},
+	})
+	unspentOutputs, err = s.transactions.UnspentOutputsOfTx(tx1.TxHash())
+	s.Require().NoError(err)
+	s.Require().Empty(unspentOutputs)
+}
+
 func (s *transactionsSuite) TestBalance() {
 	balance, err := s.transactions.Balance()
 	s.Require().NoError(err)
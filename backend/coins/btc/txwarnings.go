@@ -0,0 +1,151 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/blockchain"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// addressClusters is a union-find of the addresses of the account which are linked on-chain by
+// being spent together or by receiving change from each other.
+type addressClusters map[blockchain.ScriptHashHex]blockchain.ScriptHashHex
+
+func (clusters addressClusters) find(address blockchain.ScriptHashHex) blockchain.ScriptHashHex {
+	parent, ok := clusters[address]
+	if !ok || parent == address {
+		return address
+	}
+	root := clusters.find(parent)
+	clusters[address] = root
+	return root
+}
+
+func (clusters addressClusters) union(address1, address2 blockchain.ScriptHashHex) {
+	root1, root2 := clusters.find(address1), clusters.find(address2)
+	if root1 != root2 {
+		clusters[root1] = root2
+	}
+}
+
+// txHistoryInfo holds what is needed from the transaction history of the account to compute the
+// privacy warnings.
+type txHistoryInfo struct {
+	// pkScripts contains all output scripts of all transactions in the history.
+	pkScripts map[string]struct{}
+	clusters  addressClusters
+}
+
+func (account *Account) txHistoryInfo() (*txHistoryInfo, error) {
+	return transactions.DBView(account.db, func(dbTx transactions.DBTxInterface) (*txHistoryInfo, error) {
+		info := &txHistoryInfo{
+			pkScripts: map[string]struct{}{},
+			clusters:  addressClusters{},
+		}
+		txHashes, err := dbTx.Transactions()
+		if err != nil {
+			return nil, err
+		}
+		for _, txHash := range txHashes {
+			txInfo, err := dbTx.TxInfo(txHash)
+			if err != nil {
+				return nil, err
+			}
+			var linked []blockchain.ScriptHashHex
+			for _, txIn := range txInfo.Tx.TxIn {
+				prevOut, err := dbTx.Output(txIn.PreviousOutPoint)
+				if err != nil {
+					return nil, err
+				}
+				if prevOut != nil {
+					linked = append(linked, blockchain.NewScriptHashHex(prevOut.PkScript))
+				}
+			}
+			for _, txOut := range txInfo.Tx.TxOut {
+				info.pkScripts[string(txOut.PkScript)] = struct{}{}
+				scriptHashHex := blockchain.NewScriptHashHex(txOut.PkScript)
+				// Our outputs of a tx spending our coins are change or sends to self.
+				if len(linked) != 0 && account.getAddress(scriptHashHex) != nil {
+					linked = append(linked, scriptHashHex)
+				}
+			}
+			for _, scriptHashHex := range linked {
+				info.clusters.union(linked[0], scriptHashHex)
+			}
+		}
+		return info, nil
+	})
+}
+
+// txProposalWarnings returns the privacy warnings of a new tx proposal: sending to an address
+// which was used before or to an address of this account, spending from many unlinked addresses
+// at once and creating change which is easy to identify by its script type.
+func (account *Account) txProposalWarnings(
+	args *accounts.TxProposalArgs, txProposal *maketx.TxProposal) ([]accounts.TxProposalWarning, error) {
+	historyInfo, err := account.txHistoryInfo()
+	if err != nil {
+		return nil, err
+	}
+	warnings := []accounts.TxProposalWarning{}
+	recipientScriptClasses := map[txscript.ScriptClass]struct{}{}
+	for _, recipient := range args.AllRecipients() {
+		if account.coin.ValidateSilentPaymentAddress(recipient.Address) == nil {
+			// Silent payments always create a fresh taproot output.
+			recipientScriptClasses[txscript.WitnessV1TaprootTy] = struct{}{}
+			continue
+		}
+		pkScript, err := account.coin.AddressToPkScript(recipient.Address)
+		if err != nil {
+			return nil, err
+		}
+		recipientScriptClasses[txscript.GetScriptClass(pkScript)] = struct{}{}
+		if account.getAddress(blockchain.NewScriptHashHex(pkScript)) != nil {
+			warnings = append(warnings, accounts.TxProposalWarning{
+				Code:    accounts.TxProposalWarningOwnAddress,
+				Address: recipient.Address,
+			})
+		} else if _, ok := historyInfo.pkScripts[string(pkScript)]; ok {
+			warnings = append(warnings, accounts.TxProposalWarning{
+				Code:    accounts.TxProposalWarningAddressReuse,
+				Address: recipient.Address,
+			})
+		}
+	}
+
+	clusters := map[blockchain.ScriptHashHex]struct{}{}
+	for _, txIn := range txProposal.Transaction.TxIn {
+		utxo := txProposal.PreviousOutputs[txIn.PreviousOutPoint]
+		clusters[historyInfo.clusters.find(blockchain.NewScriptHashHex(utxo.TxOut.PkScript))] = struct{}{}
+	}
+	if len(clusters) > accounts.ConsolidationWarningThreshold {
+		warnings = append(warnings, accounts.TxProposalWarning{
+			Code:         accounts.TxProposalWarningConsolidation,
+			NumAddresses: len(clusters),
+		})
+	}
+
+	if txProposal.ChangeAddress != nil {
+		changeScriptClass := txscript.GetScriptClass(txProposal.ChangeAddress.PubkeyScript())
+		if _, ok := recipientScriptClasses[changeScriptClass]; !ok {
+			warnings = append(warnings, accounts.TxProposalWarning{
+				Code: accounts.TxProposalWarningChangeScriptType,
+			})
+		}
+	}
+	return warnings, nil
+}
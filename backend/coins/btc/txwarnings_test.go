@@ -0,0 +1,185 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package btc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/addresses"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/maketx"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/transactions"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxProposalWarnings(t *testing.T) {
+	account := mockAccount(t, nil)
+	require.NoError(t, account.Initialize())
+	defer account.Close()
+	require.Eventually(t, account.Synced, time.Second, 10*time.Millisecond)
+
+	receiveAddresses, err := account.subaccounts[0].receiveAddresses.GetUnused()
+	require.NoError(t, err)
+	changeAddresses, err := account.subaccounts[0].changeAddresses.GetUnused()
+	require.NoError(t, err)
+
+	net := &chaincfg.TestNet3Params
+	foreignP2WPKH, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), net)
+	require.NoError(t, err)
+	freshP2WPKH, err := btcutil.NewAddressWitnessPubKeyHash(append(make([]byte, 19), 1), net)
+	require.NoError(t, err)
+	freshP2TR, err := btcutil.NewAddressTaproot(make([]byte, 32), net)
+	require.NoError(t, err)
+	foreignPkScript, err := account.coin.AddressToPkScript(foreignP2WPKH.EncodeAddress())
+	require.NoError(t, err)
+
+	// tx1 pays receiveAddresses[0] and a foreign address. tx2 and tx3 pay receiveAddresses[1] and
+	// receiveAddresses[2], which are then spent together in tx4 with change to changeAddresses[0].
+	newTx := func(prevHash chainhash.Hash, outputs ...*addresses.AccountAddress) *wire.MsgTx {
+		tx := wire.NewMsgTx(2)
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: prevHash}, nil, nil))
+		for _, output := range outputs {
+			tx.AddTxOut(wire.NewTxOut(1000, output.PubkeyScript()))
+		}
+		return tx
+	}
+	tx1 := newTx(chainhash.Hash{1}, receiveAddresses[0])
+	tx1.AddTxOut(wire.NewTxOut(1000, foreignPkScript))
+	tx2 := newTx(chainhash.Hash{2}, receiveAddresses[1])
+	tx3 := newTx(chainhash.Hash{3}, receiveAddresses[2])
+	tx4 := newTx(tx2.TxHash(), changeAddresses[0])
+	tx4.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: tx3.TxHash()}, nil, nil))
+	require.NoError(t, transactions.DBUpdate(account.db, func(dbTx transactions.DBTxInterface) error {
+		for _, tx := range []*wire.MsgTx{tx1, tx2, tx3, tx4} {
+			if err := dbTx.PutTx(tx.TxHash(), tx, 10); err != nil {
+				return err
+			}
+			for index, txOut := range tx.TxOut {
+				if string(txOut.PkScript) == string(foreignPkScript) {
+					continue
+				}
+				if err := dbTx.PutOutput(wire.OutPoint{Hash: tx.TxHash(), Index: uint32(index)}, txOut); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}))
+
+	// Spends from receiveAddresses[0], the cluster of tx4 and the given fresh addresses.
+	txProposal := func(freshAddresses ...*addresses.AccountAddress) *maketx.TxProposal {
+		spent := []wire.OutPoint{
+			{Hash: tx1.TxHash(), Index: 0},
+			{Hash: tx4.TxHash(), Index: 0},
+		}
+		previousOutputs := maketx.PreviousOutputs{
+			spent[0]: {TxOut: tx1.TxOut[0], Address: receiveAddresses[0]},
+			spent[1]: {TxOut: tx4.TxOut[0], Address: changeAddresses[0]},
+		}
+		for i, address := range freshAddresses {
+			outPoint := wire.OutPoint{Hash: chainhash.Hash{10}, Index: uint32(i)}
+			spent = append(spent, outPoint)
+			previousOutputs[outPoint] = maketx.UTXO{
+				TxOut:   wire.NewTxOut(1000, address.PubkeyScript()),
+				Address: address,
+			}
+		}
+		tx := wire.NewMsgTx(2)
+		for i := range spent {
+			tx.AddTxIn(wire.NewTxIn(&spent[i], nil, nil))
+		}
+		return &maketx.TxProposal{
+			Transaction:     tx,
+			ChangeAddress:   changeAddresses[1],
+			PreviousOutputs: previousOutputs,
+		}
+	}
+
+	t.Run("none", func(t *testing.T) {
+		warnings, err := account.txProposalWarnings(
+			&accounts.TxProposalArgs{RecipientAddress: freshP2WPKH.EncodeAddress()},
+			txProposal(receiveAddresses[3]))
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+	})
+
+	t.Run("address-reuse", func(t *testing.T) {
+		warnings, err := account.txProposalWarnings(
+			&accounts.TxProposalArgs{RecipientAddress: foreignP2WPKH.EncodeAddress()},
+			txProposal())
+		require.NoError(t, err)
+		require.Equal(t, []accounts.TxProposalWarning{{
+			Code:    accounts.TxProposalWarningAddressReuse,
+			Address: foreignP2WPKH.EncodeAddress(),
+		}}, warnings)
+	})
+
+	t.Run("own-address", func(t *testing.T) {
+		ownAddress := receiveAddresses[0].EncodeForHumans()
+		warnings, err := account.txProposalWarnings(
+			&accounts.TxProposalArgs{RecipientAddress: ownAddress},
+			txProposal())
+		require.NoError(t, err)
+		require.Equal(t, []accounts.TxProposalWarning{{
+			Code:    accounts.TxProposalWarningOwnAddress,
+			Address: ownAddress,
+		}}, warnings)
+	})
+
+	t.Run("consolidation", func(t *testing.T) {
+		warnings, err := account.txProposalWarnings(
+			&accounts.TxProposalArgs{RecipientAddress: freshP2WPKH.EncodeAddress()},
+			txProposal(receiveAddresses[3], receiveAddresses[4]))
+		require.NoError(t, err)
+		require.Equal(t, []accounts.TxProposalWarning{{
+			Code:         accounts.TxProposalWarningConsolidation,
+			NumAddresses: 4,
+		}}, warnings)
+	})
+
+	t.Run("change-script-type", func(t *testing.T) {
+		warnings, err := account.txProposalWarnings(
+			&accounts.TxProposalArgs{RecipientAddress: freshP2TR.EncodeAddress()},
+			txProposal())
+		require.NoError(t, err)
+		require.Equal(t, []accounts.TxProposalWarning{{
+			Code: accounts.TxProposalWarningChangeScriptType,
+		}}, warnings)
+
+		// No warning if one of the recipients has the same script type as the change.
+		warnings, err = account.txProposalWarnings(
+			&accounts.TxProposalArgs{Recipients: []accounts.Recipient{
+				{Address: freshP2TR.EncodeAddress()},
+				{Address: freshP2WPKH.EncodeAddress()},
+			}},
+			txProposal())
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+
+		// No warning without change.
+		proposal := txProposal()
+		proposal.ChangeAddress = nil
+		warnings, err = account.txProposalWarnings(
+			&accounts.TxProposalArgs{RecipientAddress: freshP2TR.EncodeAddress()},
+			proposal)
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+	})
+}
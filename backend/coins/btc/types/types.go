@@ -29,6 +29,19 @@ type GapLimits struct {
 	Change uint16
 }
 
+// UTXOState holds the coin control state of an output, set by the user.
+type UTXOState struct {
+	// Frozen outputs are not spent unless explicitly selected.
+	Frozen bool `json:"frozen"`
+	// Label is a free-text label of the output.
+	Label string `json:"label"`
+}
+
+// IsEmpty returns true if no state is set.
+func (s UTXOState) IsEmpty() bool {
+	return !s.Frozen && s.Label == ""
+}
+
 // Signature is a type represending an ECDSA signature, or a BIP-340 Schnorr signature.
 type Signature struct {
 	R *big.Int
@@ -112,6 +112,25 @@ func (sendAmount SendAmount) Amount(unit *big.Int, allowZero bool) (Amount, erro
 	return amount, nil
 }
 
+// ConvertFromFiat interprets the concrete amount as an amount in a fiat currency and converts it to
+// the unit the coin currently formats and parses amounts in (e.g. sats if the user chose to show
+// sats), given the price of one coin in that fiat currency. Send-all amounts are returned
+// unchanged.
+func (sendAmount SendAmount) ConvertFromFiat(coin Coin, price *big.Rat) (SendAmount, error) {
+	if sendAmount.sendAll {
+		return sendAmount, nil
+	}
+	fiatAmount, ok := new(big.Rat).SetString(sendAmount.amount)
+	if !ok {
+		return SendAmount{}, errp.WithStack(errors.ErrInvalidAmount)
+	}
+	if price.Sign() <= 0 {
+		return SendAmount{}, errp.New("The price must be positive")
+	}
+	amount := coin.SetAmount(new(big.Rat).Quo(fiatAmount, price), false)
+	return NewSendAmount(coin.FormatAmount(amount, false)), nil
+}
+
 // SendAll returns if this represents a send-all input.
 func (sendAmount *SendAmount) SendAll() bool {
 	return sendAmount.sendAll
@@ -22,6 +22,7 @@ import (
 	"testing/quick"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin/mocks"
 	"github.com/stretchr/testify/require"
 )
 
@@ -101,3 +102,47 @@ func TestSendAmount(t *testing.T) {
 	require.Equal(t, int64(0), amount.BigInt().Int64())
 
 }
+
+func TestSendAmountConvertFromFiat(t *testing.T) {
+	btcLike := &mocks.CoinMock{
+		DecimalsFunc: func(isFee bool) uint { return 8 },
+		SetAmountFunc: func(amount *big.Rat, isFee bool) coin.Amount {
+			sats, _ := new(big.Int).SetString(new(big.Rat).Mul(amount, big.NewRat(1e8, 1)).FloatString(0), 10)
+			return coin.NewAmount(sats)
+		},
+		FormatAmountFunc: func(amount coin.Amount, isFee bool) string {
+			return new(big.Rat).SetFrac(amount.BigInt(), big.NewInt(1e8)).FloatString(8)
+		},
+	}
+	price := big.NewRat(60000, 1)
+
+	converted, err := coin.NewSendAmount("1500").ConvertFromFiat(btcLike, price)
+	require.NoError(t, err)
+	require.Equal(t, coin.NewSendAmount("0.02500000"), converted)
+
+	// Rounded to the smallest unit.
+	converted, err = coin.NewSendAmount("100").ConvertFromFiat(btcLike, price)
+	require.NoError(t, err)
+	require.Equal(t, coin.NewSendAmount("0.00166667"), converted)
+
+	// The result is in the unit the coin currently formats amounts in, so it parses back correctly.
+	satsLike := &mocks.CoinMock{
+		DecimalsFunc:  btcLike.DecimalsFunc,
+		SetAmountFunc: btcLike.SetAmountFunc,
+		FormatAmountFunc: func(amount coin.Amount, isFee bool) string {
+			return amount.BigInt().String()
+		},
+	}
+	converted, err = coin.NewSendAmount("1500").ConvertFromFiat(satsLike, price)
+	require.NoError(t, err)
+	require.Equal(t, coin.NewSendAmount("2500000"), converted)
+
+	converted, err = coin.NewSendAmountAll().ConvertFromFiat(btcLike, price)
+	require.NoError(t, err)
+	require.True(t, converted.SendAll())
+
+	_, err = coin.NewSendAmount("abc").ConvertFromFiat(btcLike, price)
+	require.Error(t, err)
+	_, err = coin.NewSendAmount("100").ConvertFromFiat(btcLike, new(big.Rat))
+	require.Error(t, err)
+}
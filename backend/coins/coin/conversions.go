@@ -39,8 +39,13 @@ func FormatAsPlainCurrency(amount *big.Rat, currency string) string {
 // FormatAsCurrency handles formatting for currencies.
 func FormatAsCurrency(amount *big.Rat, currency string) string {
 	formatted := FormatAsPlainCurrency(amount, currency)
+	// Don't put a separator between the minus sign and the digits.
+	start := 0
+	if strings.HasPrefix(formatted, "-") {
+		start = 1
+	}
 	position := strings.Index(formatted, ".") - 3
-	for position > 0 {
+	for position > start {
 		formatted = formatted[:position] + "'" + formatted[position:]
 		position -= 3
 	}
@@ -31,3 +31,11 @@ func TestBtc2Sat(t *testing.T) {
 	require.Equal(t, "123456789", coin.Btc2Sat(new(big.Rat).SetFloat64(1.23456789)).FloatString(0))
 	require.Equal(t, "12345", coin.Btc2Sat(new(big.Rat).SetFloat64(0.00012345)).FloatString(0))
 }
+
+func TestFormatAsCurrency(t *testing.T) {
+	require.Equal(t, "1'234'567.89", coin.FormatAsCurrency(big.NewRat(123456789, 100), "USD"))
+	require.Equal(t, "123.45", coin.FormatAsCurrency(big.NewRat(12345, 100), "USD"))
+	require.Equal(t, "-123.45", coin.FormatAsCurrency(big.NewRat(-12345, 100), "USD"))
+	require.Equal(t, "-1'234.50", coin.FormatAsCurrency(big.NewRat(-12345, 10), "USD"))
+	require.Equal(t, "0.00012345", coin.FormatAsCurrency(big.NewRat(12345, 100000000), "BTC"))
+}
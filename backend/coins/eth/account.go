@@ -43,6 +43,7 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/sirupsen/logrus"
 )
@@ -98,6 +99,9 @@ type Account struct {
 
 	// if not nil, SendTx() will sign and send this transaction. Set by TxProposal().
 	activeTxProposal *TxProposal
+	// activeFiatQuote is the exchange rate locked in activeTxProposal if its amounts were given in
+	// fiat, nil otherwise.
+	activeFiatQuote *accounts.FiatQuote
 
 	// quitChan is used to send a quit signal to the accounts long running routines that
 	// should listen to it.
@@ -274,6 +278,10 @@ func (account *Account) updateOutgoingTransactions(tipHeight uint64) {
 		txLog := account.log.WithField("idx", idx)
 		remoteTx, err := account.coin.client.TransactionReceiptWithBlockNumber(context.TODO(), tx.Transaction.Hash())
 		if remoteTx == nil || err != nil {
+			if tx.ReplacedBy != "" {
+				// Replaced transactions are not rebroadcast, as this would undo the replacement.
+				continue
+			}
 			// Transaction not found. This usually happens for pending transactions.
 			// In this case, check if the node actually knows about the transaction, and if not, re-broadcast.
 			// We do this because it seems that sometimes, a transaction that was broadcast without error still ends up lost.
@@ -293,6 +301,24 @@ func (account *Account) updateOutgoingTransactions(tipHeight uint64) {
 			}
 			continue
 		}
+		if tx.ReplacedBy != "" {
+			// The replaced transaction was mined before its replacement, so the replacement can never
+			// be mined.
+			txLog.Infof("replaced tx with nonce %d was mined", tx.Transaction.Nonce())
+			tx.ReplacedBy = ""
+			for _, otherTx := range outgoingTransactions {
+				if otherTx != tx && otherTx.Transaction.Nonce() == tx.Transaction.Nonce() {
+					otherTx.ReplacedBy = tx.TxID()
+					if err := dbTx.PutOutgoingTransaction(otherTx); err != nil {
+						txLog.WithError(err).Error("could not update outgoing tx")
+					}
+				}
+			}
+			if err := dbTx.PutOutgoingTransaction(tx); err != nil {
+				txLog.WithError(err).Error("could not update outgoing tx")
+				continue
+			}
+		}
 		success := remoteTx.Status == types.ReceiptStatusSuccessful
 		if tx.Height == 0 || (tipHeight-remoteTx.BlockNumber) < ethtypes.NumConfirmationsComplete || tx.Success != success {
 			tx.Height = remoteTx.BlockNumber
@@ -311,7 +337,8 @@ func (account *Account) updateOutgoingTransactions(tipHeight uint64) {
 }
 
 // outgoingTransactions gets all locally stored outgoing transactions. It filters out the ones also
-// present from the transactions source.
+// present from the transactions source, and the ones that were replaced by another transaction
+// with the same nonce.
 func (account *Account) outgoingTransactions(allTxs []*accounts.TransactionData) (
 	[]*ethtypes.TransactionWithMetadata, error) {
 	dbTx, err := account.db.Begin()
@@ -335,6 +362,10 @@ func (account *Account) outgoingTransactions(allTxs []*accounts.TransactionData)
 		if _, ok := allTxHashes[tx.TxID()]; ok {
 			continue
 		}
+		// Skip replaced txs, which would otherwise be counted twice together with their replacement.
+		if tx.ReplacedBy != "" {
+			continue
+		}
 		transactions = append(transactions, tx)
 	}
 	return transactions, nil
@@ -359,6 +390,7 @@ func (account *Account) update() error {
 	if transactionsSource != nil {
 		var err error
 		confirmedTansactions, err = transactionsSource.Transactions(
+			account.db,
 			account.blockNumber,
 			account.address.Address, account.blockNumber, account.coin.erc20Token)
 		if err != nil {
@@ -502,6 +534,16 @@ type TxProposal struct {
 }
 
 func (account *Account) newTx(args *accounts.TxProposalArgs) (*TxProposal, error) {
+	recipients := args.AllRecipients()
+	if len(recipients) != 1 {
+		return nil, errp.New("Ethereum transactions can only have one recipient")
+	}
+	argsCopy := *args
+	args = &argsCopy
+	args.RecipientAddress = recipients[0].Address
+	args.Amount = recipients[0].Amount
+	args.Recipients = nil
+
 	if !IsValidEthAddress(args.RecipientAddress) {
 		return nil, errp.WithStack(errors.ErrInvalidAddress)
 	}
@@ -678,10 +720,35 @@ func (account *Account) storePendingOutgoingTransaction(transaction *types.Trans
 func (account *Account) SendTx(txNote string) error {
 	unlock := account.updateLock.RLock()
 	txProposal := account.activeTxProposal
+	fiatQuote := account.activeFiatQuote
 	unlock()
 	if txProposal == nil {
 		return errp.New("No active tx proposal")
 	}
+	return account.sendTx(txProposal, fiatQuote, txNote)
+}
+
+// SendTxProposal implements accounts.Interface.
+func (account *Account) SendTxProposal(args *accounts.TxProposalArgs, txNote string) error {
+	unlock := account.updateLock.RLock()
+	args, fiatQuote, err := account.ConvertFiatAmounts(args)
+	if err != nil {
+		unlock()
+		return err
+	}
+	txProposal, err := account.newTx(args)
+	unlock()
+	if err != nil {
+		return err
+	}
+	return account.sendTx(txProposal, fiatQuote, txNote)
+}
+
+// sendTx signs and broadcasts the tx proposal, and stores the note of the transaction.
+func (account *Account) sendTx(txProposal *TxProposal, fiatQuote *accounts.FiatQuote, txNote string) error {
+	if err := account.CheckFiatQuote(fiatQuote); err != nil {
+		return err
+	}
 
 	keystore, err := account.Config().ConnectKeystore()
 	if err != nil {
@@ -710,6 +777,259 @@ func (account *Account) SendTx(txNote string) error {
 	return nil
 }
 
+// replacementFeeBumpPercent is the minimum increase of the fees required by nodes to accept a
+// transaction replacing a pending transaction with the same nonce.
+const replacementFeeBumpPercent = 10
+
+// minReplacementFee returns the lowest fee value that is high enough to replace a pending
+// transaction paying `fee`.
+func minReplacementFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+replacementFeeBumpPercent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func maxBigInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// pendingOutgoingTransaction returns the stored outgoing transaction with the given ID. An error
+// is returned if it is not pending or if it was already replaced.
+func (account *Account) pendingOutgoingTransaction(txID string) (*ethtypes.TransactionWithMetadata, error) {
+	dbTx, err := account.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+	outgoingTransactions, err := dbTx.OutgoingTransactions()
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range outgoingTransactions {
+		if tx.TxID() != txID {
+			continue
+		}
+		if tx.Height > 0 {
+			return nil, errp.New("Only pending outgoing transactions can be replaced")
+		}
+		if tx.ReplacedBy != "" {
+			return nil, errp.Newf("Transaction %s was already replaced", txID)
+		}
+		return tx, nil
+	}
+	return nil, errp.Newf("Transaction %s not found", txID)
+}
+
+// replacementTx contains the contents of a transaction replacing a pending transaction.
+type replacementTx struct {
+	to    ethcommon.Address
+	value *big.Int
+	data  []byte
+	gas   uint64
+	// shownValue and shownRecipient are the amount and the recipient shown to the user when
+	// signing. They differ from value and to for ERC20 transfers.
+	shownValue     *big.Int
+	shownRecipient string
+}
+
+// replaceTx signs and broadcasts a transaction replacing the pending outgoing transaction with
+// the given ID. The replacement has the same nonce and pays higher fees, deduced from the fee
+// target like in TxProposal(), but at least the minimum increase required by the nodes.
+//
+// `makeTx` returns the contents of the replacement given the original transaction.
+//
+// The replaced transaction is marked as such in the database. The note of the original
+// transaction is carried over. The ID and the fee of the replacement are returned.
+func (account *Account) replaceTx(
+	txID string,
+	feeTargetCode accounts.FeeTargetCode,
+	customFee string,
+	makeTx func(original *types.Transaction) (*replacementTx, error),
+) (string, coin.Amount, error) {
+	original, err := account.pendingOutgoingTransaction(txID)
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	originalTx := original.Transaction
+	if originalTx.To() == nil {
+		return "", coin.Amount{}, errp.New("contract creation not supported")
+	}
+
+	suggestedGasFeeCap, suggestedGasTipCap, err := account.gasFees(&accounts.TxProposalArgs{
+		FeeTargetCode: feeTargetCode,
+		CustomFee:     customFee,
+	})
+	if err != nil {
+		if _, ok := errp.Cause(err).(errors.TxValidationError); ok {
+			return "", coin.Amount{}, err
+		}
+		account.log.WithError(err).Error("error getting the gas price")
+		return "", coin.Amount{}, errp.WithStack(errors.ErrFeesNotAvailable)
+	}
+
+	replacement, err := makeTx(originalTx)
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+
+	var tx *types.Transaction
+	var gasFeeCap *big.Int
+	switch originalTx.Type() {
+	case types.DynamicFeeTxType:
+		gasFeeCap = maxBigInt(suggestedGasFeeCap, minReplacementFee(originalTx.GasFeeCap()))
+		gasTipCap := maxBigInt(suggestedGasTipCap, minReplacementFee(originalTx.GasTipCap()))
+		if gasTipCap.Cmp(gasFeeCap) > 0 {
+			gasFeeCap = gasTipCap
+		}
+		tx = types.NewTx(&types.DynamicFeeTx{
+			Nonce:     originalTx.Nonce(),
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+			Gas:       replacement.gas,
+			To:        &replacement.to,
+			Value:     replacement.value,
+			Data:      replacement.data,
+		})
+	case types.LegacyTxType:
+		gasFeeCap = maxBigInt(suggestedGasFeeCap, minReplacementFee(originalTx.GasPrice()))
+		tx = types.NewTransaction(
+			originalTx.Nonce(), replacement.to, replacement.value, replacement.gas, gasFeeCap, replacement.data)
+	default:
+		return "", coin.Amount{}, errp.New("unsupported transaction type")
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(replacement.gas), gasFeeCap)
+	if account.coin.erc20Token == nil {
+		total := new(big.Int).Add(replacement.value, fee)
+		// The balance already excludes the value and fee of the original transaction.
+		unlock := account.updateLock.RLock()
+		available := new(big.Int).Add(account.balance.BigInt(), originalTx.Value())
+		unlock()
+		available.Add(available, new(big.Int).Mul(new(big.Int).SetUint64(originalTx.Gas()), originalTx.GasPrice()))
+		if total.Cmp(available) > 0 {
+			return "", coin.Amount{}, errp.WithStack(errors.ErrInsufficientFunds)
+		}
+	}
+
+	txProposal := &TxProposal{
+		Coin:             account.coin,
+		Tx:               tx,
+		Fee:              fee,
+		Value:            replacement.shownValue,
+		Signer:           types.NewLondonSigner(account.coin.net.ChainID),
+		Keypath:          account.signingConfiguration.AbsoluteKeypath(),
+		RecipientAddress: replacement.shownRecipient,
+	}
+	keystore, err := account.Config().ConnectKeystore()
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	account.log.WithField("nonce", tx.Nonce()).Info("Signing and sending replacement transaction")
+	if err := keystore.SignTransaction(txProposal); err != nil {
+		return "", coin.Amount{}, err
+	}
+	if err := account.coin.client.SendTransaction(context.TODO(), txProposal.Tx); err != nil {
+		return "", coin.Amount{}, errp.WithStack(err)
+	}
+
+	replacementTxID := txProposal.Tx.Hash().Hex()
+	dbTx, err := account.db.Begin()
+	if err != nil {
+		return "", coin.Amount{}, err
+	}
+	defer dbTx.Rollback()
+	original.ReplacedBy = replacementTxID
+	if err := dbTx.PutOutgoingTransaction(original); err != nil {
+		return "", coin.Amount{}, err
+	}
+	if err := dbTx.PutOutgoingTransaction(
+		&ethtypes.TransactionWithMetadata{
+			Transaction:       txProposal.Tx,
+			BroadcastAttempts: 1,
+		}); err != nil {
+		return "", coin.Amount{}, err
+	}
+	if err := dbTx.Commit(); err != nil {
+		return "", coin.Amount{}, err
+	}
+
+	if note := account.TxNote(txID); note != "" {
+		if err := account.SetTxNote(replacementTxID, note); err != nil {
+			// Not critical.
+			account.log.WithError(err).Error("Failed to save transaction note of the replacement tx")
+		}
+	}
+	account.enqueueUpdateCh <- struct{}{}
+	return replacementTxID, coin.NewAmount(fee), nil
+}
+
+// SpeedUpTx replaces the pending outgoing transaction with the given ID by the same transaction
+// paying higher fees. See replaceTx() for details.
+func (account *Account) SpeedUpTx(
+	txID string, feeTargetCode accounts.FeeTargetCode, customFee string) (string, coin.Amount, error) {
+	return account.replaceTx(txID, feeTargetCode, customFee, func(original *types.Transaction) (*replacementTx, error) {
+		replacement := &replacementTx{
+			to:             *original.To(),
+			value:          original.Value(),
+			data:           original.Data(),
+			gas:            original.Gas(),
+			shownValue:     original.Value(),
+			shownRecipient: original.To().Hex(),
+		}
+		if account.coin.erc20Token != nil {
+			// See ethtypes.TransactionWithMetadata.TransactionData() for the layout of the data.
+			data := original.Data()
+			if len(data) != 68 {
+				return nil, errp.New("invalid erc20 tx")
+			}
+			replacement.shownValue = new(big.Int).SetBytes(data[len(data)-32:])
+			replacement.shownRecipient = ethcommon.BytesToAddress(data[4+32-ethcommon.AddressLength : 4+32]).Hex()
+		}
+		return replacement, nil
+	})
+}
+
+// CancelTx replaces the pending outgoing transaction with the given ID by a transaction with the
+// same nonce transferring nothing to ourselves, paying higher fees. See replaceTx() for details.
+//
+// For ERC20 accounts, the cancellation is a transfer of zero tokens to ourselves, so that it can
+// be shown in the transactions of the token account like the transaction it replaces.
+func (account *Account) CancelTx(
+	txID string, feeTargetCode accounts.FeeTargetCode, customFee string) (string, coin.Amount, error) {
+	return account.replaceTx(txID, feeTargetCode, customFee, func(original *types.Transaction) (*replacementTx, error) {
+		self := account.address.Address
+		if account.coin.erc20Token != nil {
+			parsed, err := abi.JSON(strings.NewReader(erc20.IERC20ABI))
+			if err != nil {
+				panic(errp.WithStack(err))
+			}
+			data, err := parsed.Pack("transfer", self, big.NewInt(0))
+			if err != nil {
+				panic(errp.WithStack(err))
+			}
+			// A transfer of zero tokens to ourselves does not use more gas than the original
+			// transfer.
+			return &replacementTx{
+				to:             *original.To(),
+				value:          big.NewInt(0),
+				data:           data,
+				gas:            original.Gas(),
+				shownValue:     big.NewInt(0),
+				shownRecipient: self.Hex(),
+			}, nil
+		}
+		return &replacementTx{
+			to:             self,
+			value:          big.NewInt(0),
+			gas:            params.TxGas,
+			shownValue:     big.NewInt(0),
+			shownRecipient: self.Hex(),
+		}, nil
+	})
+}
+
 // feeTargets returns three priorities with fee targets estimated by Etherscan
 // https://docs.etherscan.io/api-endpoints/gas-tracker#get-gas-oracle
 // If the service should not be reachable, we fallback to only one priority, estimated by
@@ -773,13 +1093,20 @@ func (account *Account) gasFees(args *accounts.TxProposalArgs) (*big.Int, *big.I
 // TxProposal implements accounts.Interface.
 func (account *Account) TxProposal(
 	args *accounts.TxProposalArgs,
-) (coin.Amount, coin.Amount, coin.Amount, error) {
+) (*accounts.TxProposalResult, error) {
 	defer account.updateLock.Lock()()
+	args, fiatQuote, err := account.ConvertFiatAmounts(args)
+	if err != nil {
+		return nil, err
+	}
 	txProposal, err := account.newTx(args)
 	if err != nil {
-		return coin.Amount{}, coin.Amount{}, coin.Amount{}, err
+		return nil, err
+	}
+	if !args.DryRun {
+		account.activeTxProposal = txProposal
+		account.activeFiatQuote = fiatQuote
 	}
-	account.activeTxProposal = txProposal
 
 	var total *big.Int
 	if account.coin.erc20Token != nil {
@@ -787,7 +1114,36 @@ func (account *Account) TxProposal(
 	} else {
 		total = new(big.Int).Add(txProposal.Value, txProposal.Fee)
 	}
-	return coin.NewAmount(txProposal.Value), coin.NewAmount(txProposal.Fee), coin.NewAmount(total), nil
+	return &accounts.TxProposalResult{
+		Amount:    coin.NewAmount(txProposal.Value),
+		Fee:       coin.NewAmount(txProposal.Fee),
+		Total:     coin.NewAmount(total),
+		Warnings:  account.txProposalWarnings(args.AllRecipients()[0].Address),
+		FiatQuote: fiatQuote,
+	}, nil
+}
+
+// txProposalWarnings returns the privacy warnings of sending to the given recipient. As all funds
+// of the account are on the same address, spending never links addresses and there is no change.
+func (account *Account) txProposalWarnings(recipientAddress string) []accounts.TxProposalWarning {
+	recipient := ethcommon.HexToAddress(recipientAddress)
+	if recipient == account.address.Address {
+		return []accounts.TxProposalWarning{{
+			Code:    accounts.TxProposalWarningOwnAddress,
+			Address: recipientAddress,
+		}}
+	}
+	for _, transaction := range account.transactions {
+		for _, address := range transaction.Addresses {
+			if ethcommon.IsHexAddress(address.Address) && ethcommon.HexToAddress(address.Address) == recipient {
+				return []accounts.TxProposalWarning{{
+					Code:    accounts.TxProposalWarningAddressReuse,
+					Address: recipientAddress,
+				}}
+			}
+		}
+	}
+	return []accounts.TxProposalWarning{}
 }
 
 // GetUnusedReceiveAddresses implements accounts.Interface.
@@ -20,15 +20,19 @@ import (
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
 	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/rpcclient"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/rpcclient/mocks"
+	ethtypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
 	keystoremock "github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
@@ -37,6 +41,7 @@ import (
 	"github.com/btcsuite/btcd/chaincfg"
 	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -47,6 +52,13 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
+type nopNotifier struct{}
+
+func (nopNotifier) Put([]byte) error              { return nil }
+func (nopNotifier) Delete([]byte) error           { return nil }
+func (nopNotifier) UnnotifiedCount() (int, error) { return 0, nil }
+func (nopNotifier) MarkAllNotified() error        { return nil }
+
 func newAccount(t *testing.T) *Account {
 	t.Helper()
 	log := logging.Get().WithGroup("account_test")
@@ -81,6 +93,15 @@ func newAccount(t *testing.T) *Account {
 		PendingNonceAtFunc: func(ctx context.Context, account common.Address) (uint64, error) {
 			return 0, nil
 		},
+		TransactionReceiptWithBlockNumberFunc: func(ctx context.Context, hash common.Hash) (*rpcclient.RPCTransactionReceipt, error) {
+			return nil, nil
+		},
+		TransactionByHashFunc: func(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+			return nil, true, nil
+		},
+		SendTransactionFunc: func(ctx context.Context, tx *types.Transaction) error {
+			return nil
+		},
 	}
 	coin := NewCoin(client, coin.CodeSEPETH, "Sepolia", "SEPETH", "SEPETH", params.SepoliaChainConfig, "", nil, nil)
 	acct := NewAccount(
@@ -91,15 +112,19 @@ func newAccount(t *testing.T) *Account {
 				SigningConfigurations: signingConfigurations,
 			},
 			DBFolder:        dbFolder,
+			NotesFolder:     t.TempDir(),
 			OnEvent:         func(accountsTypes.Event) {},
 			RateUpdater:     nil,
-			GetNotifier:     func(signing.Configurations) accounts.Notifier { return nil },
+			GetNotifier:     func(signing.Configurations) accounts.Notifier { return nopNotifier{} },
 			GetSaveFilename: func(suggestedFilename string) string { return suggestedFilename },
 			ConnectKeystore: func() (keystore.Keystore, error) {
 				ks := &keystoremock.KeystoreMock{
 					SupportsEIP1559Func: func() bool {
 						return true
 					},
+					SignTransactionFunc: func(interface{}) error {
+						return nil
+					},
 				}
 				return ks, nil
 			},
@@ -118,19 +143,33 @@ func TestTxProposal(t *testing.T) {
 	acct.Synchronizer.WaitSynchronized()
 
 	t.Run("valid", func(t *testing.T) {
-		value, fee, total, err := acct.TxProposal(&accounts.TxProposalArgs{
+		txProposal, err := acct.TxProposal(&accounts.TxProposalArgs{
 			RecipientAddress: "0xa29163852021BF4C139D03Dff59ae763AC73e84e",
 			Amount:           coin.NewSendAmount("0.1"),
 			FeeTargetCode:    accounts.FeeTargetCodeCustom,
 			CustomFee:        "20",
 		})
 		require.NoError(t, err)
-		require.Equal(t, coin.NewAmountFromInt64(100000000000000000), value)
-		require.Equal(t, coin.NewAmountFromInt64(420000000000000), fee)
-		require.Equal(t, coin.NewAmountFromInt64(100420000000000000), total)
+		require.Equal(t, coin.NewAmountFromInt64(100000000000000000), txProposal.Amount)
+		require.Equal(t, coin.NewAmountFromInt64(420000000000000), txProposal.Fee)
+		require.Equal(t, coin.NewAmountFromInt64(100420000000000000), txProposal.Total)
+		require.Empty(t, txProposal.Warnings)
+	})
+	t.Run("own-address", func(t *testing.T) {
+		txProposal, err := acct.TxProposal(&accounts.TxProposalArgs{
+			RecipientAddress: acct.address.Address.Hex(),
+			Amount:           coin.NewSendAmount("0.1"),
+			FeeTargetCode:    accounts.FeeTargetCodeCustom,
+			CustomFee:        "20",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []accounts.TxProposalWarning{{
+			Code:    accounts.TxProposalWarningOwnAddress,
+			Address: acct.address.Address.Hex(),
+		}}, txProposal.Warnings)
 	})
 	t.Run("valid-address-lowercase", func(t *testing.T) {
-		_, _, _, err := acct.TxProposal(&accounts.TxProposalArgs{
+		_, err := acct.TxProposal(&accounts.TxProposalArgs{
 			RecipientAddress: "0xa29163852021bf4c139d03dff59ae763ac73e84e",
 			Amount:           coin.NewSendAmount("0.1"),
 			FeeTargetCode:    accounts.FeeTargetCodeCustom,
@@ -139,7 +178,7 @@ func TestTxProposal(t *testing.T) {
 		require.NoError(t, err)
 	})
 	t.Run("valid-address-uppercase", func(t *testing.T) {
-		_, _, _, err := acct.TxProposal(&accounts.TxProposalArgs{
+		_, err := acct.TxProposal(&accounts.TxProposalArgs{
 			RecipientAddress: "0XA29163852021BF4C139D03DFF59AE763AC73E84E",
 			Amount:           coin.NewSendAmount("0.1"),
 			FeeTargetCode:    accounts.FeeTargetCodeCustom,
@@ -149,7 +188,7 @@ func TestTxProposal(t *testing.T) {
 	})
 	t.Run("invalid-address-checksum", func(t *testing.T) {
 		// EIP-55 checksum wrong
-		_, _, _, err := acct.TxProposal(&accounts.TxProposalArgs{
+		_, err := acct.TxProposal(&accounts.TxProposalArgs{
 			RecipientAddress: "0xA29163852021BF4C139D03Dff59ae763AC73e84e",
 			Amount:           coin.NewSendAmount("0.1"),
 			FeeTargetCode:    accounts.FeeTargetCodeCustom,
@@ -159,7 +198,7 @@ func TestTxProposal(t *testing.T) {
 	})
 
 	t.Run("invalid-address", func(t *testing.T) {
-		_, _, _, err := acct.TxProposal(&accounts.TxProposalArgs{
+		_, err := acct.TxProposal(&accounts.TxProposalArgs{
 			RecipientAddress: "0xa29163852021BF4C1",
 			Amount:           coin.NewSendAmount("0.1"),
 			FeeTargetCode:    accounts.FeeTargetCodeCustom,
@@ -169,6 +208,117 @@ func TestTxProposal(t *testing.T) {
 	})
 }
 
+func TestTxProposalFiat(t *testing.T) {
+	acct := newAccount(t)
+	defer acct.Close()
+	acct.Synchronizer.WaitSynchronized()
+
+	// No exchange rates are available in the test account.
+	_, err := acct.TxProposal(&accounts.TxProposalArgs{
+		RecipientAddress: "0xa29163852021BF4C139D03Dff59ae763AC73e84e",
+		Amount:           coin.NewSendAmount("100"),
+		Fiat:             rates.USD,
+		FeeTargetCode:    accounts.FeeTargetCodeCustom,
+		CustomFee:        "20",
+	})
+	require.Equal(t, errors.ErrFiatRateNotAvailable, errp.Cause(err))
+
+	// Signing is refused if the locked exchange rate is too old.
+	txProposal, err := acct.TxProposal(&accounts.TxProposalArgs{
+		RecipientAddress: "0xa29163852021BF4C139D03Dff59ae763AC73e84e",
+		Amount:           coin.NewSendAmount("0.1"),
+		FeeTargetCode:    accounts.FeeTargetCodeCustom,
+		CustomFee:        "20",
+	})
+	require.NoError(t, err)
+	require.Nil(t, txProposal.FiatQuote)
+	acct.activeFiatQuote = &accounts.FiatQuote{
+		Fiat:      rates.USD,
+		Rate:      3000,
+		Timestamp: time.Now().Add(-time.Hour),
+	}
+	require.Equal(t, errors.ErrFiatQuoteExpired, errp.Cause(acct.SendTx("")))
+}
+
+func TestReplaceTx(t *testing.T) {
+	acct := newAccount(t)
+	defer acct.Close()
+	acct.Synchronizer.WaitSynchronized()
+
+	recipient := common.HexToAddress("0xa29163852021BF4C139D03Dff59ae763AC73e84e")
+	original := types.NewTx(&types.DynamicFeeTx{
+		Nonce:     0,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(20e9),
+		Gas:       21000,
+		To:        &recipient,
+		Value:     big.NewInt(1e17),
+	})
+	require.NoError(t, acct.storePendingOutgoingTransaction(original))
+	require.NoError(t, acct.SetTxNote(original.Hash().Hex(), "note"))
+
+	outgoingTransactions := func() []*ethtypes.TransactionWithMetadata {
+		dbTx, err := acct.db.Begin()
+		require.NoError(t, err)
+		defer dbTx.Rollback()
+		txs, err := dbTx.OutgoingTransactions()
+		require.NoError(t, err)
+		return txs
+	}
+	client := acct.coin.client.(*mocks.InterfaceMock)
+
+	// The custom fee is lower than the original fee, so the minimum increase is applied to the
+	// fee cap.
+	speedUpTxID, fee, err := acct.SpeedUpTx(original.Hash().Hex(), accounts.FeeTargetCodeCustom, "10")
+	require.NoError(t, err)
+	require.Len(t, client.SendTransactionCalls(), 1)
+	speedUpTx := client.SendTransactionCalls()[0].Tx
+	require.Equal(t, speedUpTxID, speedUpTx.Hash().Hex())
+	require.Equal(t, uint64(0), speedUpTx.Nonce())
+	require.Equal(t, recipient, *speedUpTx.To())
+	require.Equal(t, original.Value(), speedUpTx.Value())
+	require.Equal(t, big.NewInt(22e9), speedUpTx.GasFeeCap())
+	require.Equal(t, big.NewInt(10e9), speedUpTx.GasTipCap())
+	require.Equal(t, coin.NewAmountFromInt64(21000*22e9), fee)
+	require.Equal(t, "note", acct.TxNote(speedUpTxID))
+	txs := outgoingTransactions()
+	require.Len(t, txs, 2)
+	for _, tx := range txs {
+		if tx.TxID() == original.Hash().Hex() {
+			require.Equal(t, speedUpTxID, tx.ReplacedBy)
+		} else {
+			require.Empty(t, tx.ReplacedBy)
+		}
+	}
+
+	// The original was already replaced.
+	_, _, err = acct.SpeedUpTx(original.Hash().Hex(), accounts.FeeTargetCodeCustom, "30")
+	require.Error(t, err)
+	_, _, err = acct.CancelTx("0x1234", accounts.FeeTargetCodeCustom, "30")
+	require.Error(t, err)
+
+	cancelTxID, fee, err := acct.CancelTx(speedUpTxID, accounts.FeeTargetCodeCustom, "30")
+	require.NoError(t, err)
+	require.Len(t, client.SendTransactionCalls(), 2)
+	cancelTx := client.SendTransactionCalls()[1].Tx
+	require.Equal(t, cancelTxID, cancelTx.Hash().Hex())
+	require.Equal(t, uint64(0), cancelTx.Nonce())
+	require.Equal(t, acct.address.Address, *cancelTx.To())
+	require.Equal(t, big.NewInt(0), cancelTx.Value())
+	require.Equal(t, big.NewInt(30e9), cancelTx.GasFeeCap())
+	require.Equal(t, coin.NewAmountFromInt64(21000*30e9), fee)
+
+	// Only the cancellation is pending, and only its fee is deducted from the balance.
+	pending, err := acct.outgoingTransactions(nil)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, cancelTxID, pending[0].TxID())
+	pendingData := []*accounts.TransactionData{
+		pending[0].TransactionData(100, nil, acct.address.Address.Hex()),
+	}
+	require.Equal(t, big.NewInt(21000*30e9), pendingTxsAmount(pendingData, false))
+}
+
 func TestMatchesAddress(t *testing.T) {
 	acct := newAccount(t)
 	defer acct.Close()
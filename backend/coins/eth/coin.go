@@ -16,17 +16,20 @@
 package eth
 
 import (
+	"context"
 	"math/big"
 	"strings"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/db"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/erc20"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/rpcclient"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/observable"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/sirupsen/logrus"
@@ -34,8 +37,12 @@ import (
 
 // TransactionsSource source of Ethereum transactions. An additional source for this is needed as a
 // normal ETH full node does not expose an API endpoint to get transactions per address.
+//
+// accountDB is the database of the account, which the source can use to persist state between
+// calls.
 type TransactionsSource interface {
 	Transactions(
+		accountDB db.Interface,
 		blockTipHeight *big.Int,
 		address common.Address, endBlock *big.Int, erc20Token *erc20.Token) (
 		[]*accounts.TransactionData, error)
@@ -101,6 +108,40 @@ func (coin *Coin) TstSetTransactionsSource(ts TransactionsSource) {
 	coin.transactionsSource = ts
 }
 
+// ERC20TokenInfo contains the metadata of an ERC20 token contract.
+type ERC20TokenInfo struct {
+	Name     string
+	Symbol   string
+	Decimals uint8
+}
+
+// ERC20TokenInfo fetches the name, symbol and decimals of the ERC20 token deployed at the given
+// contract address.
+func (coin *Coin) ERC20TokenInfo(contractAddress common.Address) (*ERC20TokenInfo, error) {
+	caller, err := erc20.NewIERC20Caller(contractAddress, coin.client)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	opts := &bind.CallOpts{Context: context.TODO()}
+	name, err := caller.Name(opts)
+	if err != nil {
+		return nil, errp.WithMessage(err, "could not get the token name")
+	}
+	symbol, err := caller.Symbol(opts)
+	if err != nil {
+		return nil, errp.WithMessage(err, "could not get the token symbol")
+	}
+	decimals, err := caller.Decimals(opts)
+	if err != nil {
+		return nil, errp.WithMessage(err, "could not get the token decimals")
+	}
+	return &ERC20TokenInfo{
+		Name:     name,
+		Symbol:   symbol,
+		Decimals: decimals,
+	}, nil
+}
+
 // Net returns the network (mainnet, testnet, etc.).
 func (coin *Coin) Net() *params.ChainConfig { return coin.net }
 
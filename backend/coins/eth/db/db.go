@@ -16,6 +16,7 @@ package db
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"sort"
 
@@ -27,6 +28,10 @@ import (
 
 const (
 	bucketOutgoingTransactions = "pendingTransactions"
+	bucketScannedTransactions  = "scannedTransactions"
+	bucketScan                 = "scan"
+
+	keyScanCursor = "cursor"
 )
 
 // DB is a bbolt key/value database.
@@ -53,9 +58,19 @@ func (db *DB) Begin() (TxInterface, error) {
 	if err != nil {
 		return nil, err
 	}
+	bucketScannedTransactions, err := tx.CreateBucketIfNotExists([]byte(bucketScannedTransactions))
+	if err != nil {
+		return nil, err
+	}
+	bucketScan, err := tx.CreateBucketIfNotExists([]byte(bucketScan))
+	if err != nil {
+		return nil, err
+	}
 	return &Tx{
 		tx:                         tx,
 		bucketOutgoingTransactions: bucketOutgoingTransactions,
+		bucketScannedTransactions:  bucketScannedTransactions,
+		bucketScan:                 bucketScan,
 	}, nil
 }
 
@@ -69,6 +84,8 @@ type Tx struct {
 	tx *bbolt.Tx
 
 	bucketOutgoingTransactions *bbolt.Bucket
+	bucketScannedTransactions  *bbolt.Bucket
+	bucketScan                 *bbolt.Bucket
 }
 
 // Rollback implements DBTxInterface.
@@ -114,3 +131,64 @@ func (tx *Tx) OutgoingTransactions() ([]*types.TransactionWithMetadata, error) {
 	sort.Sort(sort.Reverse(byNonce(transactions)))
 	return transactions, nil
 }
+
+// scannedTransactionKey orders scanned transactions by block number, so that the ones from a range
+// of blocks can be removed efficiently.
+func scannedTransactionKey(transaction *types.ScannedTransaction) []byte {
+	key := make([]byte, 8, 8+len(transaction.TxHash)+4)
+	binary.BigEndian.PutUint64(key, transaction.BlockNumber)
+	key = append(key, transaction.TxHash.Bytes()...)
+	// Native transactions come before the ERC20 transfers of the same transaction.
+	var logIndex uint32
+	if transaction.LogIndex != nil {
+		logIndex = uint32(*transaction.LogIndex) + 1
+	}
+	return binary.BigEndian.AppendUint32(key, logIndex)
+}
+
+// PutScannedTransaction implements DBTxInterface.
+func (tx *Tx) PutScannedTransaction(transaction *types.ScannedTransaction) error {
+	return tx.bucketScannedTransactions.Put(
+		scannedTransactionKey(transaction),
+		jsonp.MustMarshal(transaction))
+}
+
+// ScannedTransactions implements DBTxInterface.
+func (tx *Tx) ScannedTransactions() ([]*types.ScannedTransaction, error) {
+	transactions := []*types.ScannedTransaction{}
+	cursor := tx.bucketScannedTransactions.Cursor()
+	for _, txSerialized := cursor.Last(); txSerialized != nil; _, txSerialized = cursor.Prev() {
+		transaction := new(types.ScannedTransaction)
+		if err := json.Unmarshal(txSerialized, transaction); err != nil {
+			return nil, errp.WithStack(err)
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, nil
+}
+
+// DeleteScannedTransactionsFrom implements DBTxInterface.
+func (tx *Tx) DeleteScannedTransactionsFrom(blockNumber uint64) error {
+	start := binary.BigEndian.AppendUint64(nil, blockNumber)
+	cursor := tx.bucketScannedTransactions.Cursor()
+	for key, _ := cursor.Seek(start); key != nil; key, _ = cursor.Seek(start) {
+		if err := cursor.Delete(); err != nil {
+			return errp.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// ScanCursor implements DBTxInterface.
+func (tx *Tx) ScanCursor() (uint64, bool) {
+	value := tx.bucketScan.Get([]byte(keyScanCursor))
+	if len(value) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(value), true
+}
+
+// PutScanCursor implements DBTxInterface.
+func (tx *Tx) PutScanCursor(blockNumber uint64) error {
+	return tx.bucketScan.Put([]byte(keyScanCursor), binary.BigEndian.AppendUint64(nil, blockNumber))
+}
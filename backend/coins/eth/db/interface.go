@@ -30,6 +30,24 @@ type TxInterface interface {
 	// OutgoingTransactions returns the stored list of outgoing transactions, sorted descending by
 	// the transaction nonce.
 	OutgoingTransactions() ([]*types.TransactionWithMetadata, error)
+
+	// PutScannedTransaction stores a transaction found by scanning the blockchain.
+	PutScannedTransaction(*types.ScannedTransaction) error
+
+	// ScannedTransactions returns the stored scanned transactions, sorted descending by block
+	// number.
+	ScannedTransactions() ([]*types.ScannedTransaction, error)
+
+	// DeleteScannedTransactionsFrom removes the scanned transactions in blocks at or above the given
+	// block number, e.g. because they need to be scanned again in case of a reorg.
+	DeleteScannedTransactionsFrom(blockNumber uint64) error
+
+	// ScanCursor returns the next block to be scanned. The second return value is false if no
+	// block has been scanned yet.
+	ScanCursor() (uint64, bool)
+
+	// PutScanCursor stores the next block to be scanned.
+	PutScanCursor(blockNumber uint64) error
 }
 
 // Interface can be implemented by database backends to open database transactions.
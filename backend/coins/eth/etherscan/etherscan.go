@@ -27,6 +27,7 @@ import (
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/db"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/erc20"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/rpcclient"
 	ethtypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/types"
@@ -302,6 +303,7 @@ func prepareTransactions(
 // Transactions queries EtherScan for transactions for the given account, until endBlock.
 // Provide erc20Token to filter for those. If nil, standard etheruem transactions will be fetched.
 func (etherScan *EtherScan) Transactions(
+	_ db.Interface,
 	blockTipHeight *big.Int,
 	address common.Address, endBlock *big.Int, erc20Token *erc20.Token) (
 	[]*accounts.TransactionData, error) {
@@ -491,6 +493,23 @@ func (etherScan *EtherScan) CallContract(ctx context.Context, msg ethereum.CallM
 	return result, nil
 }
 
+// CodeAt implements rpc.Interface.
+func (etherScan *EtherScan) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	params := url.Values{}
+	params.Set("action", "eth_getCode")
+	params.Set("address", contract.Hex())
+	if blockNumber == nil {
+		params.Set("tag", "latest")
+	} else {
+		panic("not implemented")
+	}
+	var result hexutil.Bytes
+	if err := etherScan.rpcCall(params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func callMsgParams(params *url.Values, msg ethereum.CallMsg) {
 	params.Set("from", msg.From.Hex())
 	params.Set("to", msg.To.Hex())
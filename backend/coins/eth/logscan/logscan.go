@@ -0,0 +1,432 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logscan implements an eth.TransactionsSource that builds the transaction history of an
+// address using only the standard JSON-RPC API of an Ethereum node, without an indexer like
+// EtherScan.
+//
+// ERC20 transfers are found by querying the `Transfer` logs of the token contract. Native ETH
+// transactions are found by walking the blocks. Transactions sending ETH to the address from a
+// contract (internal transactions) are not found, as this would require tracing.
+//
+// The scan starts at the block of the first activity of the address, which is found by a binary
+// search over the historical balance and nonce of the address. This needs an archive node. Without
+// one, ERC20 transfers are scanned from the genesis block, and native ETH transactions only from the
+// current tip, as walking all blocks would take too long.
+//
+// The blockchain is scanned in bounded ranges per call, so the history of an address with many
+// past blocks to scan is filled in over several calls. The progress is persisted in the account
+// database.
+package logscan
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/db"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/erc20"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/rpcclient"
+	ethtypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// logsBlockRange is the number of blocks queried per `eth_getLogs` request. Many nodes limit
+	// the range of a single request.
+	logsBlockRange = 5000
+	// maxLogsBlocksPerScan is the maximum number of blocks scanned for ERC20 transfers per call.
+	maxLogsBlocksPerScan = 200 * logsBlockRange
+	// maxBlocksPerScan is the maximum number of blocks walked for native ETH transactions per
+	// call.
+	maxBlocksPerScan = 2000
+)
+
+// transferEventTopic is the topic of the ERC20 `Transfer(address,address,uint256)` event.
+var transferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// Client is the subset of the node API needed to scan the blockchain. It is implemented by
+// node.Node.
+type Client interface {
+	// BlockNumber returns the current latest block number.
+	BlockNumber(ctx context.Context) (*big.Int, error)
+	// Balance returns the current confirmed balance of the address.
+	Balance(ctx context.Context, account common.Address) (*big.Int, error)
+	// ERC20Balance returns the current confirmed token balance of the given token for the address.
+	ERC20Balance(account common.Address, erc20Token *erc20.Token) (*big.Int, error)
+	// PendingNonceAt retrieves the current pending nonce associated with an account.
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	// BalanceAt returns the balance of the address at the given block.
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	// ERC20BalanceAt returns the token balance of the address at the given block.
+	ERC20BalanceAt(
+		ctx context.Context, account common.Address, erc20Token *erc20.Token, blockNumber *big.Int) (*big.Int, error)
+	// NonceAt returns the number of transactions sent by the address up to the given block.
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	TransactionReceiptWithBlockNumber(
+		ctx context.Context, hash common.Hash) (*rpcclient.RPCTransactionReceipt, error)
+	// BlockByNumber returns the block with the given number, including its transactions.
+	BlockByNumber(ctx context.Context, number *big.Int) (*rpcclient.RPCBlock, error)
+	// FilterLogs returns the logs matching the given query.
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Scanner implements eth.TransactionsSource by scanning the blockchain.
+type Scanner struct {
+	client Client
+	log    *logrus.Entry
+}
+
+// NewScanner creates a new instance of Scanner.
+func NewScanner(client Client, log *logrus.Entry) *Scanner {
+	return &Scanner{
+		client: client,
+		log:    log.WithField("group", "logscan"),
+	}
+}
+
+// hasHistory returns false if the address certainly never had a transaction: it has never sent a
+// transaction and has no balance. Receiving tokens via `transferFrom` requires an approval, which is
+// a transaction.
+func (scanner *Scanner) hasHistory(
+	ctx context.Context, address common.Address, erc20Token *erc20.Token) (bool, error) {
+	nonce, err := scanner.client.PendingNonceAt(ctx, address)
+	if err != nil {
+		return false, err
+	}
+	if nonce > 0 {
+		return true, nil
+	}
+	var balance *big.Int
+	if erc20Token != nil {
+		balance, err = scanner.client.ERC20Balance(address, erc20Token)
+	} else {
+		balance, err = scanner.client.Balance(ctx, address)
+	}
+	if err != nil {
+		return false, err
+	}
+	return balance.Sign() != 0, nil
+}
+
+// activeAt returns true if the address had sent a transaction or had a balance at the given block.
+// Once true, it stays true for all later blocks, as spending the balance requires a transaction.
+func (scanner *Scanner) activeAt(
+	ctx context.Context, address common.Address, erc20Token *erc20.Token, block uint64) (bool, error) {
+	blockNumber := new(big.Int).SetUint64(block)
+	nonce, err := scanner.client.NonceAt(ctx, address, blockNumber)
+	if err != nil {
+		return false, err
+	}
+	if nonce > 0 {
+		return true, nil
+	}
+	var balance *big.Int
+	if erc20Token != nil {
+		balance, err = scanner.client.ERC20BalanceAt(ctx, address, erc20Token, blockNumber)
+	} else {
+		balance, err = scanner.client.BalanceAt(ctx, address, blockNumber)
+	}
+	if err != nil {
+		return false, err
+	}
+	return balance.Sign() != 0, nil
+}
+
+// firstActivityBlock returns the first block at which the address was active, see activeAt, or
+// the tip if it was not active yet. Fails if the node does not serve the historical state.
+func (scanner *Scanner) firstActivityBlock(
+	ctx context.Context, address common.Address, erc20Token *erc20.Token, tip uint64) (uint64, error) {
+	low, high := uint64(0), tip
+	for low < high {
+		middle := low + (high-low)/2
+		active, err := scanner.activeAt(ctx, address, erc20Token, middle)
+		if err != nil {
+			return 0, err
+		}
+		if active {
+			high = middle
+		} else {
+			low = middle + 1
+		}
+	}
+	return low, nil
+}
+
+// startBlock returns the block from which the history of an address with past activity is scanned.
+func (scanner *Scanner) startBlock(
+	ctx context.Context, address common.Address, erc20Token *erc20.Token, tip uint64) uint64 {
+	start, err := scanner.firstActivityBlock(ctx, address, erc20Token, tip)
+	if err == nil {
+		scanner.log.WithField("cursor", start).Info("starting to scan at the first activity")
+		return start
+	}
+	if erc20Token != nil {
+		scanner.log.WithError(err).Warning(
+			"could not find the first activity, the node is not an archive node. Scanning from the genesis block")
+		return 0
+	}
+	scanner.log.WithError(err).Warning(
+		"could not find the first activity, the node is not an archive node. Past ETH transactions won't be shown")
+	return tip
+}
+
+// Transactions implements eth.TransactionsSource. It scans the next range of blocks for
+// transactions of the address and returns all transactions found so far. endBlock is ignored, the
+// scan goes up to blockTipHeight.
+//
+// Blocks with less than ethtypes.NumConfirmationsComplete confirmations are scanned again in the
+// next call, so that transactions removed by a reorg are dropped.
+func (scanner *Scanner) Transactions(
+	accountDB db.Interface,
+	blockTipHeight *big.Int,
+	address common.Address, endBlock *big.Int, erc20Token *erc20.Token) (
+	[]*accounts.TransactionData, error) {
+	ctx := context.TODO()
+	tip := blockTipHeight.Uint64()
+
+	cursor, scanned, err := scanner.scanCursor(accountDB)
+	if err != nil {
+		return nil, err
+	}
+	if !scanned {
+		hasHistory, err := scanner.hasHistory(ctx, address, erc20Token)
+		if err != nil {
+			return nil, err
+		}
+		if hasHistory {
+			cursor = scanner.startBlock(ctx, address, erc20Token, tip)
+		} else {
+			cursor = tip + 1
+			scanner.log.WithField("cursor", cursor).Info("no history, starting to scan at the tip")
+		}
+	}
+
+	var found []*ethtypes.ScannedTransaction
+	scanEnd := tip
+	if cursor <= tip {
+		if erc20Token != nil {
+			scanEnd = min(tip, cursor+maxLogsBlocksPerScan-1)
+			found, err = scanner.scanTransferLogs(ctx, cursor, scanEnd, address, erc20Token)
+		} else {
+			scanEnd = min(tip, cursor+maxBlocksPerScan-1)
+			found, err = scanner.scanBlocks(ctx, cursor, scanEnd, address)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Only advance the cursor past blocks which are deep enough to not be reorged.
+	newCursor := cursor
+	if cursor <= tip {
+		newCursor = scanEnd + 1
+		if tip+1 >= ethtypes.NumConfirmationsComplete {
+			newCursor = min(newCursor, tip+1-ethtypes.NumConfirmationsComplete+1)
+		} else {
+			newCursor = 0
+		}
+		newCursor = max(newCursor, cursor)
+	}
+
+	dbTx, err := accountDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+	if err := dbTx.DeleteScannedTransactionsFrom(cursor); err != nil {
+		return nil, err
+	}
+	for _, transaction := range found {
+		if err := dbTx.PutScannedTransaction(transaction); err != nil {
+			return nil, err
+		}
+	}
+	if err := dbTx.PutScanCursor(newCursor); err != nil {
+		return nil, err
+	}
+	transactions, err := dbTx.ScannedTransactions()
+	if err != nil {
+		return nil, err
+	}
+	if err := dbTx.Commit(); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	scanner.log.WithFields(logrus.Fields{"from": cursor, "to": scanEnd, "found": len(found)}).
+		Debug("scanned blocks")
+
+	isERC20 := erc20Token != nil
+	result := make([]*accounts.TransactionData, len(transactions))
+	for index, transaction := range transactions {
+		result[index] = transaction.TransactionData(tip, isERC20, address)
+	}
+	return result, nil
+}
+
+func (scanner *Scanner) scanCursor(accountDB db.Interface) (uint64, bool, error) {
+	dbTx, err := accountDB.Begin()
+	if err != nil {
+		return 0, false, err
+	}
+	defer dbTx.Rollback()
+	cursor, ok := dbTx.ScanCursor()
+	return cursor, ok, nil
+}
+
+// receipt fetches the receipt of a transaction found in a scanned block.
+func (scanner *Scanner) receipt(
+	ctx context.Context, hash common.Hash) (*rpcclient.RPCTransactionReceipt, error) {
+	receipt, err := scanner.client.TransactionReceiptWithBlockNumber(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, errp.Newf("receipt of transaction %s not found", hash.Hex())
+	}
+	return receipt, nil
+}
+
+// fee returns the fee paid by the sender of a transaction.
+func fee(receipt *rpcclient.RPCTransactionReceipt) *hexutil.Big {
+	if receipt.EffectiveGasPrice == nil {
+		return nil
+	}
+	return (*hexutil.Big)(new(big.Int).Mul(
+		new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice))
+}
+
+// scanBlocks walks the blocks from `from` to `to` (inclusive), returning the native ETH
+// transactions sent from or to the address.
+func (scanner *Scanner) scanBlocks(
+	ctx context.Context, from, to uint64, address common.Address) (
+	[]*ethtypes.ScannedTransaction, error) {
+	found := []*ethtypes.ScannedTransaction{}
+	for blockNumber := from; blockNumber <= to; blockNumber++ {
+		block, err := scanner.client.BlockByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+		if err != nil {
+			return nil, err
+		}
+		for _, blockTx := range block.Transactions {
+			isOutgoing := blockTx.From == address
+			isIncoming := blockTx.To != nil && *blockTx.To == address
+			if !isOutgoing && !isIncoming {
+				continue
+			}
+			receipt, err := scanner.receipt(ctx, blockTx.Hash)
+			if err != nil {
+				return nil, err
+			}
+			nonce := blockTx.Nonce
+			transaction := &ethtypes.ScannedTransaction{
+				TxHash:      blockTx.Hash,
+				BlockNumber: blockNumber,
+				Timestamp:   uint64(block.Timestamp),
+				From:        blockTx.From,
+				Value:       blockTx.Value,
+				Nonce:       &nonce,
+				GasUsed:     hexutil.Uint64(receipt.GasUsed),
+				Failed:      receipt.Status != types.ReceiptStatusSuccessful,
+			}
+			if blockTx.To != nil {
+				transaction.To = *blockTx.To
+			} else {
+				transaction.To = receipt.ContractAddress
+			}
+			if isOutgoing {
+				transaction.Fee = fee(receipt)
+			}
+			found = append(found, transaction)
+		}
+	}
+	return found, nil
+}
+
+// scanTransferLogs queries the ERC20 `Transfer` logs from `from` to `to` (inclusive) sent from or
+// to the address.
+func (scanner *Scanner) scanTransferLogs(
+	ctx context.Context, from, to uint64, address common.Address, erc20Token *erc20.Token) (
+	[]*ethtypes.ScannedTransaction, error) {
+	addressTopic := common.BytesToHash(address.Bytes())
+	logs := []types.Log{}
+	for rangeStart := from; rangeStart <= to; rangeStart += logsBlockRange {
+		rangeEnd := min(to, rangeStart+logsBlockRange-1)
+		// Topics are: event, from, to. One query each for outgoing and incoming transfers.
+		for _, topics := range [][][]common.Hash{
+			{{transferEventTopic}, {addressTopic}},
+			{{transferEventTopic}, {}, {addressTopic}},
+		} {
+			rangeLogs, err := scanner.client.FilterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: new(big.Int).SetUint64(rangeStart),
+				ToBlock:   new(big.Int).SetUint64(rangeEnd),
+				Addresses: []common.Address{erc20Token.ContractAddress()},
+				Topics:    topics,
+			})
+			if err != nil {
+				return nil, err
+			}
+			logs = append(logs, rangeLogs...)
+		}
+	}
+
+	found := []*ethtypes.ScannedTransaction{}
+	seen := map[string]struct{}{}
+	blockTimestamps := map[uint64]uint64{}
+	for _, log := range logs {
+		if log.Removed || len(log.Topics) != 3 || len(log.Data) != 32 {
+			// Not a standard ERC20 transfer.
+			continue
+		}
+		logIndex := log.Index
+		transaction := &ethtypes.ScannedTransaction{
+			TxHash:      log.TxHash,
+			LogIndex:    &logIndex,
+			BlockNumber: log.BlockNumber,
+			From:        common.BytesToAddress(log.Topics[1].Bytes()),
+			To:          common.BytesToAddress(log.Topics[2].Bytes()),
+			Value:       (*hexutil.Big)(new(big.Int).SetBytes(log.Data)),
+		}
+		// Transfers to self match both queries.
+		if _, ok := seen[transaction.InternalID()]; ok {
+			continue
+		}
+		seen[transaction.InternalID()] = struct{}{}
+
+		timestamp, ok := blockTimestamps[log.BlockNumber]
+		if !ok {
+			block, err := scanner.client.BlockByNumber(ctx, new(big.Int).SetUint64(log.BlockNumber))
+			if err != nil {
+				return nil, err
+			}
+			timestamp = uint64(block.Timestamp)
+			blockTimestamps[log.BlockNumber] = timestamp
+		}
+		transaction.Timestamp = timestamp
+		if transaction.From == address {
+			receipt, err := scanner.receipt(ctx, log.TxHash)
+			if err != nil {
+				return nil, err
+			}
+			transaction.GasUsed = hexutil.Uint64(receipt.GasUsed)
+			transaction.Fee = fee(receipt)
+		}
+		found = append(found, transaction)
+	}
+	return found, nil
+}
@@ -0,0 +1,346 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logscan
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/db"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/erc20"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/rpcclient"
+	ethtypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ourAddress   = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	otherAddress = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	testToken    = erc20.NewToken("0x3333333333333333333333333333333333333333", 6)
+)
+
+// fakeClient serves blocks with the given transactions and the given logs. The address is active
+// from the block firstActivity on. If noArchive is true, the historical state is not available.
+type fakeClient struct {
+	nonce            uint64
+	balance          *big.Int
+	firstActivity    uint64
+	noArchive        bool
+	blockTxs         map[uint64][]*rpcclient.RPCBlockTransaction
+	logs             []types.Log
+	requestedBlocks  []uint64
+	filterLogsRanges [][2]uint64
+}
+
+func (client *fakeClient) BlockNumber(ctx context.Context) (*big.Int, error) {
+	panic("not used")
+}
+
+func (client *fakeClient) Balance(ctx context.Context, account common.Address) (*big.Int, error) {
+	return client.balance, nil
+}
+
+func (client *fakeClient) ERC20Balance(account common.Address, erc20Token *erc20.Token) (*big.Int, error) {
+	return client.balance, nil
+}
+
+func (client *fakeClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return client.nonce, nil
+}
+
+func (client *fakeClient) historicalState(blockNumber *big.Int) (bool, error) {
+	if client.noArchive {
+		return false, errors.New("missing trie node")
+	}
+	return blockNumber.Uint64() >= client.firstActivity, nil
+}
+
+func (client *fakeClient) BalanceAt(
+	ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	active, err := client.historicalState(blockNumber)
+	if err != nil || !active {
+		return big.NewInt(0), err
+	}
+	return big.NewInt(1), nil
+}
+
+func (client *fakeClient) ERC20BalanceAt(
+	ctx context.Context, account common.Address, erc20Token *erc20.Token, blockNumber *big.Int) (*big.Int, error) {
+	return client.BalanceAt(ctx, account, blockNumber)
+}
+
+func (client *fakeClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	if _, err := client.historicalState(blockNumber); err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+func (client *fakeClient) TransactionReceiptWithBlockNumber(
+	ctx context.Context, hash common.Hash) (*rpcclient.RPCTransactionReceipt, error) {
+	return &rpcclient.RPCTransactionReceipt{
+		Receipt: types.Receipt{
+			Status:            types.ReceiptStatusSuccessful,
+			GasUsed:           21000,
+			EffectiveGasPrice: big.NewInt(10),
+		},
+	}, nil
+}
+
+func (client *fakeClient) BlockByNumber(ctx context.Context, number *big.Int) (*rpcclient.RPCBlock, error) {
+	client.requestedBlocks = append(client.requestedBlocks, number.Uint64())
+	return &rpcclient.RPCBlock{
+		Number:       hexutil.Uint64(number.Uint64()),
+		Timestamp:    hexutil.Uint64(1700000000 + number.Uint64()),
+		Transactions: client.blockTxs[number.Uint64()],
+	}, nil
+}
+
+func (client *fakeClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	client.filterLogsRanges = append(client.filterLogsRanges,
+		[2]uint64{query.FromBlock.Uint64(), query.ToBlock.Uint64()})
+	result := []types.Log{}
+	for _, log := range client.logs {
+		if log.BlockNumber < query.FromBlock.Uint64() || log.BlockNumber > query.ToBlock.Uint64() {
+			continue
+		}
+		matches := true
+		for index, alternatives := range query.Topics {
+			if len(alternatives) > 0 && log.Topics[index] != alternatives[0] {
+				matches = false
+			}
+		}
+		if matches {
+			result = append(result, log)
+		}
+	}
+	return result, nil
+}
+
+func newTestDB(t *testing.T) *db.DB {
+	t.Helper()
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "account.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, database.Close()) })
+	return database
+}
+
+func blockTx(hash string, from common.Address, to common.Address, value int64) *rpcclient.RPCBlockTransaction {
+	return &rpcclient.RPCBlockTransaction{
+		Hash:  common.HexToHash(hash),
+		From:  from,
+		To:    &to,
+		Value: (*hexutil.Big)(big.NewInt(value)),
+	}
+}
+
+func TestScanBlocks(t *testing.T) {
+	client := &fakeClient{
+		nonce: 1,
+		blockTxs: map[uint64][]*rpcclient.RPCBlockTransaction{
+			5: {
+				blockTx("0x01", otherAddress, ourAddress, 1000),
+				blockTx("0x02", otherAddress, otherAddress, 1),
+			},
+			25: {blockTx("0x03", ourAddress, otherAddress, 500)},
+		},
+	}
+	database := newTestDB(t)
+	scanner := NewScanner(client, logging.Get().WithGroup("logscan_test"))
+
+	transactions, err := scanner.Transactions(database, big.NewInt(30), ourAddress, big.NewInt(30), nil)
+	require.NoError(t, err)
+	require.Len(t, client.requestedBlocks, 31)
+	require.Len(t, transactions, 2)
+
+	// Sorted descending by height.
+	outgoing := transactions[0]
+	require.Equal(t, common.HexToHash("0x03").Hex(), outgoing.TxID)
+	require.Equal(t, accounts.TxTypeSend, outgoing.Type)
+	require.Equal(t, 25, outgoing.Height)
+	require.Equal(t, 6, outgoing.NumConfirmations)
+	require.Equal(t, accounts.TxStatusPending, outgoing.Status)
+	require.Equal(t, "210000", outgoing.Fee.BigInt().String())
+
+	incoming := transactions[1]
+	require.Equal(t, accounts.TxTypeReceive, incoming.Type)
+	require.Equal(t, accounts.TxStatusComplete, incoming.Status)
+	require.Nil(t, incoming.Fee)
+	require.Equal(t, "1000", incoming.Amount.BigInt().String())
+	require.Equal(t, int64(1700000005), incoming.Timestamp.Unix())
+
+	// Blocks with less than 12 confirmations are scanned again.
+	client.requestedBlocks = nil
+	transactions, err = scanner.Transactions(database, big.NewInt(31), ourAddress, big.NewInt(31), nil)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31}, client.requestedBlocks)
+	require.Len(t, transactions, 2)
+
+	// The outgoing transaction was reorged out.
+	delete(client.blockTxs, 25)
+	transactions, err = scanner.Transactions(database, big.NewInt(31), ourAddress, big.NewInt(31), nil)
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+}
+
+func TestScanBlocksBounded(t *testing.T) {
+	const tip = 3*maxBlocksPerScan + 500
+	client := &fakeClient{nonce: 1}
+	database := newTestDB(t)
+	scanner := NewScanner(client, logging.Get().WithGroup("logscan_test"))
+
+	_, err := scanner.Transactions(database, big.NewInt(tip), ourAddress, big.NewInt(tip), nil)
+	require.NoError(t, err)
+	require.Len(t, client.requestedBlocks, maxBlocksPerScan)
+
+	client.requestedBlocks = nil
+	_, err = scanner.Transactions(database, big.NewInt(tip), ourAddress, big.NewInt(tip), nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(maxBlocksPerScan), client.requestedBlocks[0])
+}
+
+func TestScanFromFirstActivity(t *testing.T) {
+	const (
+		firstActivity = 1_000_000
+		tip           = firstActivity + 2*maxBlocksPerScan + 100
+	)
+	client := &fakeClient{
+		nonce:         1,
+		firstActivity: firstActivity,
+		blockTxs: map[uint64][]*rpcclient.RPCBlockTransaction{
+			firstActivity: {blockTx("0x01", otherAddress, ourAddress, 1000)},
+			tip - 1:       {blockTx("0x02", ourAddress, otherAddress, 500)},
+		},
+	}
+	database := newTestDB(t)
+	scanner := NewScanner(client, logging.Get().WithGroup("logscan_test"))
+
+	// The scan reaches the tip after a few calls.
+	var transactions []*accounts.TransactionData
+	for range 3 {
+		client.requestedBlocks = nil
+		var err error
+		transactions, err = scanner.Transactions(database, big.NewInt(tip), ourAddress, big.NewInt(tip), nil)
+		require.NoError(t, err)
+	}
+	require.Equal(t, uint64(tip), client.requestedBlocks[len(client.requestedBlocks)-1])
+	require.Len(t, transactions, 2)
+	cursor, scanned, err := scanner.scanCursor(database)
+	require.NoError(t, err)
+	require.True(t, scanned)
+	require.Equal(t, uint64(tip+1-ethtypes.NumConfirmationsComplete+1), cursor)
+
+	// No block before the first activity was scanned.
+	database = newTestDB(t)
+	client.requestedBlocks = nil
+	_, err = scanner.Transactions(database, big.NewInt(tip), ourAddress, big.NewInt(tip), nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(firstActivity), client.requestedBlocks[0])
+}
+
+func TestScanWithoutArchiveNode(t *testing.T) {
+	client := &fakeClient{
+		nonce:     1,
+		noArchive: true,
+		logs:      []types.Log{transferLog("0x01", 0, 100, otherAddress, ourAddress, 1000)},
+	}
+	scanner := NewScanner(client, logging.Get().WithGroup("logscan_test"))
+
+	// Native ETH transactions are only scanned from the tip.
+	_, err := scanner.Transactions(newTestDB(t), big.NewInt(10000), ourAddress, big.NewInt(10000), nil)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{10000}, client.requestedBlocks)
+
+	// ERC20 transfers are scanned from the genesis block.
+	transactions, err := scanner.Transactions(
+		newTestDB(t), big.NewInt(10000), ourAddress, big.NewInt(10000), testToken)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), client.filterLogsRanges[0][0])
+	require.Len(t, transactions, 1)
+}
+
+func TestScanNoHistory(t *testing.T) {
+	client := &fakeClient{balance: big.NewInt(0)}
+	database := newTestDB(t)
+	scanner := NewScanner(client, logging.Get().WithGroup("logscan_test"))
+
+	transactions, err := scanner.Transactions(database, big.NewInt(1000), ourAddress, big.NewInt(1000), nil)
+	require.NoError(t, err)
+	require.Empty(t, transactions)
+	require.Empty(t, client.requestedBlocks)
+
+	// Only new blocks are scanned.
+	_, err = scanner.Transactions(database, big.NewInt(1002), ourAddress, big.NewInt(1002), nil)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1001, 1002}, client.requestedBlocks)
+}
+
+func transferLog(txHash string, index uint, blockNumber uint64, from, to common.Address, value int64) types.Log {
+	return types.Log{
+		Address: testToken.ContractAddress(),
+		Topics: []common.Hash{
+			transferEventTopic,
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data:        common.LeftPadBytes(big.NewInt(value).Bytes(), 32),
+		BlockNumber: blockNumber,
+		TxHash:      common.HexToHash(txHash),
+		Index:       index,
+	}
+}
+
+func TestScanTransferLogs(t *testing.T) {
+	client := &fakeClient{
+		nonce: 1,
+		logs: []types.Log{
+			transferLog("0x01", 0, 100, otherAddress, ourAddress, 1000),
+			transferLog("0x02", 3, 7000, ourAddress, otherAddress, 400),
+			transferLog("0x03", 1, 8000, ourAddress, ourAddress, 50),
+			transferLog("0x04", 0, 9000, otherAddress, otherAddress, 1),
+		},
+	}
+	database := newTestDB(t)
+	scanner := NewScanner(client, logging.Get().WithGroup("logscan_test"))
+
+	transactions, err := scanner.Transactions(database, big.NewInt(10000), ourAddress, big.NewInt(10000), testToken)
+	require.NoError(t, err)
+	// Two ranges, two queries each.
+	require.Equal(t, [][2]uint64{{0, 4999}, {0, 4999}, {5000, 9999}, {5000, 9999}, {10000, 10000}, {10000, 10000}},
+		client.filterLogsRanges)
+	require.Len(t, transactions, 3)
+
+	require.Equal(t, accounts.TxTypeSendSelf, transactions[0].Type)
+	require.Equal(t, common.HexToHash("0x03").Hex()+"-1", transactions[0].InternalID)
+
+	require.Equal(t, accounts.TxTypeSend, transactions[1].Type)
+	require.Equal(t, "400", transactions[1].Amount.BigInt().String())
+	require.Equal(t, "210000", transactions[1].Fee.BigInt().String())
+	require.True(t, transactions[1].IsErc20)
+	require.True(t, transactions[1].FeeIsDifferentUnit)
+
+	require.Equal(t, accounts.TxTypeReceive, transactions[2].Type)
+	require.Equal(t, ourAddress.Hex(), transactions[2].Addresses[0].Address)
+	require.Nil(t, transactions[2].Fee)
+}
@@ -0,0 +1,416 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package node implements rpcclient.Interface by talking standard Ethereum JSON-RPC to a
+// user-configured node, as an alternative to EtherScan.
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync/atomic"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/erc20"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/rpcclient"
+	ethtypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// erc20BalanceOfSelector is the function selector of `balanceOf(address)`.
+var erc20BalanceOfSelector = []byte{0x70, 0xa0, 0x82, 0x31}
+
+// feeHistoryBlocks is the number of recent blocks considered when estimating fees.
+const feeHistoryBlocks = 10
+
+// feeHistoryPercentiles are the priority fee percentiles queried for the low, normal and high fee
+// targets.
+var feeHistoryPercentiles = []float64{10, 50, 90}
+
+// Node is a client for the JSON-RPC API of an Ethereum node. See
+// https://ethereum.org/en/developers/docs/apis/json-rpc/.
+type Node struct {
+	url        string
+	httpClient *http.Client
+	requestID  atomic.Uint64
+}
+
+// NewNode creates a new instance of Node.
+func NewNode(url string, httpClient *http.Client) *Node {
+	return &Node{
+		url:        url,
+		httpClient: httpClient,
+	}
+}
+
+// call performs a JSON-RPC request and unmarshals the result into `result`. If the node returns
+// `null`, `result` is left untouched.
+func (node *Node) call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	requestBody, err := json.Marshal(struct {
+		JSONRPC string        `json:"jsonrpc"`
+		ID      uint64        `json:"id"`
+		Method  string        `json:"method"`
+		Params  []interface{} `json:"params"`
+	}{
+		JSONRPC: "2.0",
+		ID:      node.requestID.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, node.url, bytes.NewReader(requestBody))
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := node.httpClient.Do(request)
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode != http.StatusOK {
+		return errp.Newf("expected 200 OK, got %d", response.StatusCode)
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	var wrapped struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(body, &wrapped); err != nil {
+		return errp.Newf("unexpected response from the Ethereum node: %s", string(body))
+	}
+	if wrapped.Error != nil {
+		return errp.New(wrapped.Error.Message)
+	}
+	if result == nil || len(wrapped.Result) == 0 || string(wrapped.Result) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(wrapped.Result, result); err != nil {
+		return errp.WithStack(err)
+	}
+	return nil
+}
+
+// ChainID returns the chain ID of the network the node is connected to.
+func (node *Node) ChainID(ctx context.Context) (*big.Int, error) {
+	var result *hexutil.Big
+	if err := node.call(ctx, &result, "eth_chainId"); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, errp.New("expected result")
+	}
+	return (*big.Int)(result), nil
+}
+
+// TransactionReceiptWithBlockNumber implements rpcclient.Interface.
+func (node *Node) TransactionReceiptWithBlockNumber(
+	ctx context.Context, hash common.Hash) (*rpcclient.RPCTransactionReceipt, error) {
+	var receipt *types.Receipt
+	if err := node.call(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, nil
+	}
+	result := &rpcclient.RPCTransactionReceipt{Receipt: *receipt}
+	if receipt.BlockNumber != nil {
+		result.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+	return result, nil
+}
+
+// TransactionByHash implements rpcclient.Interface.
+func (node *Node) TransactionByHash(
+	ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	var result json.RawMessage
+	if err := node.call(ctx, &result, "eth_getTransactionByHash", hash); err != nil {
+		return nil, false, err
+	}
+	if result == nil {
+		return nil, false, errp.WithStack(ethereum.NotFound)
+	}
+	var tx types.Transaction
+	if err := json.Unmarshal(result, &tx); err != nil {
+		return nil, false, errp.WithStack(err)
+	}
+	var blockInfo struct {
+		BlockNumber *string `json:"blockNumber"`
+	}
+	if err := json.Unmarshal(result, &blockInfo); err != nil {
+		return nil, false, errp.WithStack(err)
+	}
+	return &tx, blockInfo.BlockNumber == nil, nil
+}
+
+// BlockNumber implements rpcclient.Interface.
+func (node *Node) BlockNumber(ctx context.Context) (*big.Int, error) {
+	var result hexutil.Big
+	if err := node.call(ctx, &result, "eth_blockNumber"); err != nil {
+		return nil, err
+	}
+	return (*big.Int)(&result), nil
+}
+
+// Balance implements rpcclient.Interface.
+func (node *Node) Balance(ctx context.Context, account common.Address) (*big.Int, error) {
+	return node.BalanceAt(ctx, account, nil)
+}
+
+// BalanceAt returns the balance of the address at the given block, or at the latest block if nil.
+// Old blocks are only served by archive nodes.
+func (node *Node) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var result hexutil.Big
+	if err := node.call(ctx, &result, "eth_getBalance", account, blockNumberArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	return (*big.Int)(&result), nil
+}
+
+// ERC20Balance implements rpcclient.Interface.
+func (node *Node) ERC20Balance(account common.Address, erc20Token *erc20.Token) (*big.Int, error) {
+	return node.ERC20BalanceAt(context.TODO(), account, erc20Token, nil)
+}
+
+// ERC20BalanceAt returns the token balance of the address at the given block, or at the latest
+// block if nil. Old blocks are only served by archive nodes.
+func (node *Node) ERC20BalanceAt(
+	ctx context.Context, account common.Address, erc20Token *erc20.Token, blockNumber *big.Int) (*big.Int, error) {
+	contractAddress := erc20Token.ContractAddress()
+	data := append(append([]byte{}, erc20BalanceOfSelector...), common.LeftPadBytes(account.Bytes(), 32)...)
+	result, err := node.CallContract(
+		ctx, ethereum.CallMsg{To: &contractAddress, Data: data}, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != 32 {
+		return nil, errp.Newf("unexpected balanceOf result: %x", result)
+	}
+	return new(big.Int).SetBytes(result), nil
+}
+
+// toCallArg converts a call message to the JSON-RPC transaction call object.
+func toCallArg(msg ethereum.CallMsg) map[string]interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+	}
+	if msg.To != nil {
+		arg["to"] = msg.To
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if msg.GasFeeCap != nil {
+		arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+	}
+	if msg.GasTipCap != nil {
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+	}
+	return arg
+}
+
+// blockNumberArg returns the block parameter for the given block number, `latest` if nil.
+func blockNumberArg(blockNumber *big.Int) string {
+	if blockNumber == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(blockNumber)
+}
+
+// BlockByNumber returns the block with the given number, including its transactions.
+func (node *Node) BlockByNumber(ctx context.Context, number *big.Int) (*rpcclient.RPCBlock, error) {
+	var result *rpcclient.RPCBlock
+	if err := node.call(ctx, &result, "eth_getBlockByNumber", hexutil.EncodeBig(number), true); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, errp.WithStack(ethereum.NotFound)
+	}
+	return result, nil
+}
+
+// toFilterArg converts a filter query to the JSON-RPC filter object.
+func toFilterArg(query ethereum.FilterQuery) map[string]interface{} {
+	arg := map[string]interface{}{
+		"fromBlock": blockNumberArg(query.FromBlock),
+		"toBlock":   blockNumberArg(query.ToBlock),
+	}
+	if len(query.Addresses) > 0 {
+		arg["address"] = query.Addresses
+	}
+	topics := make([]interface{}, len(query.Topics))
+	for index, alternatives := range query.Topics {
+		switch len(alternatives) {
+		case 0:
+			topics[index] = nil
+		case 1:
+			topics[index] = alternatives[0]
+		default:
+			topics[index] = alternatives
+		}
+	}
+	arg["topics"] = topics
+	return arg
+}
+
+// FilterLogs returns the logs matching the given query (`eth_getLogs`).
+func (node *Node) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	var result []types.Log
+	if err := node.call(ctx, &result, "eth_getLogs", toFilterArg(query)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CodeAt implements rpcclient.Interface.
+func (node *Node) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	var result hexutil.Bytes
+	if err := node.call(ctx, &result, "eth_getCode", contract, blockNumberArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CallContract implements rpcclient.Interface.
+func (node *Node) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var result hexutil.Bytes
+	if err := node.call(ctx, &result, "eth_call", toCallArg(msg), blockNumberArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EstimateGas implements rpcclient.Interface.
+func (node *Node) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	var result hexutil.Uint64
+	if err := node.call(ctx, &result, "eth_estimateGas", toCallArg(msg)); err != nil {
+		return 0, err
+	}
+	return uint64(result), nil
+}
+
+// NonceAt returns the number of transactions sent by the address up to the given block. Old
+// blocks are only served by archive nodes.
+func (node *Node) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	var result hexutil.Uint64
+	if err := node.call(ctx, &result, "eth_getTransactionCount", account, blockNumberArg(blockNumber)); err != nil {
+		return 0, err
+	}
+	return uint64(result), nil
+}
+
+// PendingNonceAt implements rpcclient.Interface.
+func (node *Node) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	var result hexutil.Uint64
+	if err := node.call(ctx, &result, "eth_getTransactionCount", account, "pending"); err != nil {
+		return 0, err
+	}
+	return uint64(result), nil
+}
+
+// SendTransaction implements rpcclient.Interface.
+func (node *Node) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	encodedTx, err := tx.MarshalBinary() // canonical RLP encoding, works for legacy and EIP-1559 txs
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	return node.call(ctx, nil, "eth_sendRawTransaction", hexutil.Bytes(encodedTx))
+}
+
+// SuggestGasPrice implements rpcclient.Interface.
+func (node *Node) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	var result hexutil.Big
+	if err := node.call(ctx, &result, "eth_gasPrice"); err != nil {
+		return nil, err
+	}
+	return (*big.Int)(&result), nil
+}
+
+// FeeTargets implements rpcclient.Interface. The fee targets are estimated using `eth_feeHistory`
+// of the last few blocks. The priority fee of each target is the median of the 10th, 50th and
+// 90th percentile priority fees of these blocks, and the max fee allows for the base fee to double
+// until the transaction is included.
+func (node *Node) FeeTargets(ctx context.Context) ([]*ethtypes.FeeTarget, error) {
+	var result struct {
+		BaseFeePerGas []*hexutil.Big   `json:"baseFeePerGas"`
+		Reward        [][]*hexutil.Big `json:"reward"`
+	}
+	if err := node.call(ctx, &result, "eth_feeHistory",
+		hexutil.Uint64(feeHistoryBlocks), "latest", feeHistoryPercentiles); err != nil {
+		return nil, err
+	}
+	if len(result.BaseFeePerGas) == 0 || len(result.Reward) == 0 {
+		return nil, errp.New("unexpected fee history response")
+	}
+	// The last entry is the base fee of the next block.
+	nextBaseFee := (*big.Int)(result.BaseFeePerGas[len(result.BaseFeePerGas)-1])
+	maxBaseFee := new(big.Int).Mul(nextBaseFee, big.NewInt(2))
+
+	// Highest priority first, same as the EtherScan fee targets.
+	targets := []struct {
+		code            accounts.FeeTargetCode
+		percentileIndex int
+	}{
+		{accounts.FeeTargetCodeHigh, 2},
+		{accounts.FeeTargetCodeNormal, 1},
+		{accounts.FeeTargetCodeLow, 0},
+	}
+	feeTargets := make([]*ethtypes.FeeTarget, len(targets))
+	for index, target := range targets {
+		rewards := []*big.Int{}
+		for _, blockRewards := range result.Reward {
+			if len(blockRewards) != len(feeHistoryPercentiles) {
+				return nil, errp.New("unexpected fee history response")
+			}
+			rewards = append(rewards, (*big.Int)(blockRewards[target.percentileIndex]))
+		}
+		sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+		tip := rewards[len(rewards)/2]
+		feeTargets[index] = &ethtypes.FeeTarget{
+			TargetCode: target.code,
+			GasFeeCap:  new(big.Int).Add(maxBaseFee, tip),
+			GasTipCap:  new(big.Int).Set(tip),
+		}
+	}
+	return feeTargets, nil
+}
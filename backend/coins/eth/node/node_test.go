@@ -0,0 +1,151 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/erc20"
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type rpcRequest struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// newTestNode starts a JSON-RPC server answering each method with the given raw JSON result. A
+// method mapped to an error string starting with "error:" returns a JSON-RPC error.
+func newTestNode(t *testing.T, results map[string]string, requests *[]rpcRequest) *Node {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if requests != nil {
+			*requests = append(*requests, request)
+		}
+		result, ok := results[request.Method]
+		if !ok {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + result + `}`))
+	}))
+	t.Cleanup(server.Close)
+	return NewNode(server.URL, server.Client())
+}
+
+func TestNode(t *testing.T) {
+	var requests []rpcRequest
+	node := newTestNode(t, map[string]string{
+		"eth_chainId":               `"0x1"`,
+		"eth_blockNumber":           `"0x10"`,
+		"eth_getBalance":            `"0xde0b6b3a7640000"`,
+		"eth_call":                  `"0x00000000000000000000000000000000000000000000000000000000000003e8"`,
+		"eth_getTransactionCount":   `"0x5"`,
+		"eth_gasPrice":              `"0x3b9aca00"`,
+		"eth_getTransactionReceipt": `null`,
+	}, &requests)
+	ctx := context.Background()
+	address := common.HexToAddress("0x773A77b9D32589be03f9132AF759e294f7851be9")
+
+	chainID, err := node.ChainID(ctx)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1), chainID)
+
+	blockNumber, err := node.BlockNumber(ctx)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(16), blockNumber)
+
+	balance, err := node.Balance(ctx, address)
+	require.NoError(t, err)
+	require.Equal(t, "1000000000000000000", balance.String())
+	require.JSONEq(t, `"0x773a77b9d32589be03f9132af759e294f7851be9"`, string(requests[len(requests)-1].Params[0]))
+	require.JSONEq(t, `"latest"`, string(requests[len(requests)-1].Params[1]))
+
+	token := erc20.NewToken("0x0000000000085d4780B73119b644AE5ecd22b376", 18)
+	balance, err = node.ERC20Balance(address, token)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1000), balance)
+	var callArg map[string]string
+	require.NoError(t, json.Unmarshal(requests[len(requests)-1].Params[0], &callArg))
+	require.Equal(t, "0x0000000000085d4780b73119b644ae5ecd22b376", callArg["to"])
+	require.Equal(t,
+		"0x70a08231000000000000000000000000773a77b9d32589be03f9132af759e294f7851be9",
+		callArg["data"])
+
+	nonce, err := node.PendingNonceAt(ctx, address)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), nonce)
+
+	// Historical state.
+	balance, err = node.BalanceAt(ctx, address, big.NewInt(10))
+	require.NoError(t, err)
+	require.Equal(t, "1000000000000000000", balance.String())
+	require.JSONEq(t, `"0xa"`, string(requests[len(requests)-1].Params[1]))
+	nonce, err = node.NonceAt(ctx, address, big.NewInt(11))
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), nonce)
+	require.JSONEq(t, `"0xb"`, string(requests[len(requests)-1].Params[1]))
+	balance, err = node.ERC20BalanceAt(ctx, address, token, big.NewInt(12))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1000), balance)
+	require.JSONEq(t, `"0xc"`, string(requests[len(requests)-1].Params[1]))
+
+	gasPrice, err := node.SuggestGasPrice(ctx)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1e9), gasPrice)
+
+	receipt, err := node.TransactionReceiptWithBlockNumber(ctx, common.Hash{})
+	require.NoError(t, err)
+	require.Nil(t, receipt)
+
+	// Method not available on the node.
+	_, err = node.EstimateGas(ctx, ethereum.CallMsg{From: address})
+	require.EqualError(t, err, "method not found")
+}
+
+func TestNodeFeeTargets(t *testing.T) {
+	node := newTestNode(t, map[string]string{
+		"eth_feeHistory": `{
+			"oldestBlock": "0x1",
+			"baseFeePerGas": ["0x64", "0x64", "0x64", "0xc8"],
+			"reward": [["0x1", "0x5", "0xa"], ["0x2", "0x6", "0x14"], ["0x3", "0x7", "0x1e"]]
+		}`,
+	}, nil)
+	feeTargets, err := node.FeeTargets(context.Background())
+	require.NoError(t, err)
+	require.Len(t, feeTargets, 3)
+
+	require.Equal(t, accounts.FeeTargetCodeHigh, feeTargets[0].TargetCode)
+	require.Equal(t, big.NewInt(20), feeTargets[0].GasTipCap)
+	require.Equal(t, big.NewInt(420), feeTargets[0].GasFeeCap)
+
+	require.Equal(t, accounts.FeeTargetCodeNormal, feeTargets[1].TargetCode)
+	require.Equal(t, big.NewInt(6), feeTargets[1].GasTipCap)
+	require.Equal(t, big.NewInt(406), feeTargets[1].GasFeeCap)
+
+	require.Equal(t, accounts.FeeTargetCodeLow, feeTargets[2].TargetCode)
+	require.Equal(t, big.NewInt(2), feeTargets[2].GasTipCap)
+	require.Equal(t, big.NewInt(402), feeTargets[2].GasFeeCap)
+}
@@ -31,6 +31,12 @@ var _ rpcclient.Interface = &InterfaceMock{}
 //			BlockNumberFunc: func(ctx context.Context) (*big.Int, error) {
 //				panic("mock out the BlockNumber method")
 //			},
+//			CallContractFunc: func(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+//				panic("mock out the CallContract method")
+//			},
+//			CodeAtFunc: func(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+//				panic("mock out the CodeAt method")
+//			},
 //			ERC20BalanceFunc: func(account common.Address, erc20Token *erc20.Token) (*big.Int, error) {
 //				panic("mock out the ERC20Balance method")
 //			},
@@ -68,6 +74,12 @@ type InterfaceMock struct {
 	// BlockNumberFunc mocks the BlockNumber method.
 	BlockNumberFunc func(ctx context.Context) (*big.Int, error)
 
+	// CallContractFunc mocks the CallContract method.
+	CallContractFunc func(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+
+	// CodeAtFunc mocks the CodeAt method.
+	CodeAtFunc func(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error)
+
 	// ERC20BalanceFunc mocks the ERC20Balance method.
 	ERC20BalanceFunc func(account common.Address, erc20Token *erc20.Token) (*big.Int, error)
 
@@ -106,6 +118,24 @@ type InterfaceMock struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 		}
+		// CallContract holds details about calls to the CallContract method.
+		CallContract []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Call is the call argument value.
+			Call ethereum.CallMsg
+			// BlockNumber is the blockNumber argument value.
+			BlockNumber *big.Int
+		}
+		// CodeAt holds details about calls to the CodeAt method.
+		CodeAt []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Contract is the contract argument value.
+			Contract common.Address
+			// BlockNumber is the blockNumber argument value.
+			BlockNumber *big.Int
+		}
 		// ERC20Balance holds details about calls to the ERC20Balance method.
 		ERC20Balance []struct {
 			// Account is the account argument value.
@@ -161,6 +191,8 @@ type InterfaceMock struct {
 	}
 	lockBalance                           sync.RWMutex
 	lockBlockNumber                       sync.RWMutex
+	lockCallContract                      sync.RWMutex
+	lockCodeAt                            sync.RWMutex
 	lockERC20Balance                      sync.RWMutex
 	lockEstimateGas                       sync.RWMutex
 	lockFeeTargets                        sync.RWMutex
@@ -239,6 +271,86 @@ func (mock *InterfaceMock) BlockNumberCalls() []struct {
 	return calls
 }
 
+// CallContract calls CallContractFunc.
+func (mock *InterfaceMock) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if mock.CallContractFunc == nil {
+		panic("InterfaceMock.CallContractFunc: method is nil but Interface.CallContract was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		Call        ethereum.CallMsg
+		BlockNumber *big.Int
+	}{
+		Ctx:         ctx,
+		Call:        call,
+		BlockNumber: blockNumber,
+	}
+	mock.lockCallContract.Lock()
+	mock.calls.CallContract = append(mock.calls.CallContract, callInfo)
+	mock.lockCallContract.Unlock()
+	return mock.CallContractFunc(ctx, call, blockNumber)
+}
+
+// CallContractCalls gets all the calls that were made to CallContract.
+// Check the length with:
+//
+//	len(mockedInterface.CallContractCalls())
+func (mock *InterfaceMock) CallContractCalls() []struct {
+	Ctx         context.Context
+	Call        ethereum.CallMsg
+	BlockNumber *big.Int
+} {
+	var calls []struct {
+		Ctx         context.Context
+		Call        ethereum.CallMsg
+		BlockNumber *big.Int
+	}
+	mock.lockCallContract.RLock()
+	calls = mock.calls.CallContract
+	mock.lockCallContract.RUnlock()
+	return calls
+}
+
+// CodeAt calls CodeAtFunc.
+func (mock *InterfaceMock) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if mock.CodeAtFunc == nil {
+		panic("InterfaceMock.CodeAtFunc: method is nil but Interface.CodeAt was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		Contract    common.Address
+		BlockNumber *big.Int
+	}{
+		Ctx:         ctx,
+		Contract:    contract,
+		BlockNumber: blockNumber,
+	}
+	mock.lockCodeAt.Lock()
+	mock.calls.CodeAt = append(mock.calls.CodeAt, callInfo)
+	mock.lockCodeAt.Unlock()
+	return mock.CodeAtFunc(ctx, contract, blockNumber)
+}
+
+// CodeAtCalls gets all the calls that were made to CodeAt.
+// Check the length with:
+//
+//	len(mockedInterface.CodeAtCalls())
+func (mock *InterfaceMock) CodeAtCalls() []struct {
+	Ctx         context.Context
+	Contract    common.Address
+	BlockNumber *big.Int
+} {
+	var calls []struct {
+		Ctx         context.Context
+		Contract    common.Address
+		BlockNumber *big.Int
+	}
+	mock.lockCodeAt.RLock()
+	calls = mock.calls.CodeAt
+	mock.lockCodeAt.RUnlock()
+	return calls
+}
+
 // ERC20Balance calls ERC20BalanceFunc.
 func (mock *InterfaceMock) ERC20Balance(account common.Address, erc20Token *erc20.Token) (*big.Int, error) {
 	if mock.ERC20BalanceFunc == nil {
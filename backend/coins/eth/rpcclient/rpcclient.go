@@ -22,6 +22,7 @@ import (
 	ethtypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/types"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
@@ -53,6 +54,11 @@ type Interface interface {
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
 	// FeeTargets returns EIP-1559 compatible priorities (maxFeePerGas + baseFee)
 	FeeTargets(ctx context.Context) ([]*ethtypes.FeeTarget, error)
+	// CodeAt returns the contract code of the given account. Together with CallContract, this
+	// implements bind.ContractCaller to call contracts using generated bindings.
+	CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error)
+	// CallContract executes a message call without creating a transaction.
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
 }
 
 // RPCTransactionReceipt is a receipt extended with the block number.
@@ -67,3 +73,21 @@ type RPCTransaction struct {
 	types.Transaction
 	BlockNumber *string `json:"blockNumber,omitempty"`
 }
+
+// RPCBlock is a block as returned by the `eth_getBlockByNumber` api call with full transaction
+// objects.
+type RPCBlock struct {
+	Number       hexutil.Uint64         `json:"number"`
+	Timestamp    hexutil.Uint64         `json:"timestamp"`
+	Transactions []*RPCBlockTransaction `json:"transactions"`
+}
+
+// RPCBlockTransaction is a transaction contained in an RPCBlock.
+type RPCBlockTransaction struct {
+	Hash common.Hash    `json:"hash"`
+	From common.Address `json:"from"`
+	// To is nil for contract creations.
+	To    *common.Address `json:"to"`
+	Value *hexutil.Big    `json:"value"`
+	Nonce hexutil.Uint64  `json:"nonce"`
+}
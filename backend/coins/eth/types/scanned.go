@@ -0,0 +1,119 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ScannedTransaction is a confirmed transaction found by scanning the blockchain. It is either a
+// native ETH transaction or an ERC20 `Transfer` event.
+type ScannedTransaction struct {
+	TxHash common.Hash `json:"txHash"`
+	// LogIndex is the index of the ERC20 `Transfer` log in the block. Nil for native ETH
+	// transactions.
+	LogIndex    *uint          `json:"logIndex,omitempty"`
+	BlockNumber uint64         `json:"blockNumber"`
+	Timestamp   uint64         `json:"timestamp"`
+	From        common.Address `json:"from"`
+	To          common.Address `json:"to"`
+	// Value is the amount in Wei, or in the smallest token unit for ERC20 transfers.
+	Value *hexutil.Big `json:"value"`
+	// Nonce is the nonce of native ETH transactions. Nil for ERC20 transfers.
+	Nonce   *hexutil.Uint64 `json:"nonce,omitempty"`
+	GasUsed hexutil.Uint64  `json:"gasUsed"`
+	// Fee is only set for transactions sent by the account, as only those pay a fee.
+	Fee *hexutil.Big `json:"fee,omitempty"`
+	// Failed is true if the contract execution failed.
+	Failed bool `json:"failed"`
+}
+
+// InternalID returns an ID unique per transaction and ERC20 transfer.
+func (tx *ScannedTransaction) InternalID() string {
+	if tx.LogIndex != nil {
+		return fmt.Sprintf("%s-%d", tx.TxHash.Hex(), *tx.LogIndex)
+	}
+	return tx.TxHash.Hex()
+}
+
+// TransactionData returns the tx data to be shown to the user.
+func (tx *ScannedTransaction) TransactionData(
+	tipHeight uint64, isERC20 bool, accountAddress common.Address) *accounts.TransactionData {
+	var txType accounts.TxType
+	switch {
+	case tx.From == accountAddress && tx.To == accountAddress:
+		txType = accounts.TxTypeSendSelf
+	case tx.From == accountAddress:
+		txType = accounts.TxTypeSend
+	default:
+		txType = accounts.TxTypeReceive
+	}
+	numConfirmations := 0
+	if tipHeight >= tx.BlockNumber {
+		numConfirmations = int(tipHeight - tx.BlockNumber + 1)
+	}
+	var status accounts.TxStatus
+	switch {
+	case tx.Failed:
+		status = accounts.TxStatusFailed
+	case numConfirmations >= NumConfirmationsComplete:
+		status = accounts.TxStatusComplete
+	default:
+		status = accounts.TxStatusPending
+	}
+	var fee *coin.Amount
+	if tx.Fee != nil {
+		amount := coin.NewAmount(tx.Fee.ToInt())
+		fee = &amount
+	}
+	var nonce *uint64
+	if tx.Nonce != nil {
+		n := uint64(*tx.Nonce)
+		nonce = &n
+	}
+	value := new(big.Int)
+	if tx.Value != nil {
+		value = tx.Value.ToInt()
+	}
+	amount := coin.NewAmount(value)
+	timestamp := time.Unix(int64(tx.Timestamp), 0)
+	return &accounts.TransactionData{
+		Fee:                      fee,
+		FeeIsDifferentUnit:       isERC20,
+		Timestamp:                &timestamp,
+		TxID:                     tx.TxHash.Hex(),
+		InternalID:               tx.InternalID(),
+		Height:                   int(tx.BlockNumber),
+		NumConfirmations:         numConfirmations,
+		NumConfirmationsComplete: NumConfirmationsComplete,
+		Status:                   status,
+		Type:                     txType,
+		Amount:                   amount,
+		Addresses: []accounts.AddressAndAmount{{
+			Address: tx.To.Hex(),
+			Amount:  amount,
+		}},
+		Gas:     uint64(tx.GasUsed),
+		Nonce:   nonce,
+		IsErc20: isERC20,
+	}
+}
@@ -44,6 +44,9 @@ type TransactionWithMetadata struct {
 	Success bool
 	// Number of broadcast attempts.
 	BroadcastAttempts uint16
+	// ReplacedBy is the hash of the transaction with the same nonce that replaced this one, e.g. to
+	// speed it up or to cancel it. Empty if the transaction was not replaced.
+	ReplacedBy string
 }
 
 // FeeTarget contains the gas price for a specific fee target.
@@ -83,6 +86,7 @@ func (txh *TransactionWithMetadata) MarshalJSON() ([]byte, error) {
 		"gasUsed":           hexutil.Uint64(txh.GasUsed),
 		"success":           txh.Success,
 		"broadcastAttempts": txh.BroadcastAttempts,
+		"replacedBy":        txh.ReplacedBy,
 	})
 }
 
@@ -94,6 +98,7 @@ func (txh *TransactionWithMetadata) UnmarshalJSON(input []byte) error {
 		GasUsed           hexutil.Uint64 `json:"gasUsed"`
 		Success           bool           `json:"success"`
 		BroadcastAttempts uint16         `json:"broadcastAttempts"`
+		ReplacedBy        string         `json:"replacedBy"`
 	}{}
 	if err := json.Unmarshal(input, &m); err != nil {
 		return err
@@ -106,6 +111,7 @@ func (txh *TransactionWithMetadata) UnmarshalJSON(input []byte) error {
 	txh.GasUsed = uint64(m.GasUsed)
 	txh.Success = m.Success
 	txh.BroadcastAttempts = m.BroadcastAttempts
+	txh.ReplacedBy = m.ReplacedBy
 	return nil
 }
 
@@ -27,6 +27,7 @@ func TestTransactionWithHeightJSON(t *testing.T) {
 		GasUsed:           21000,
 		Success:           true,
 		BroadcastAttempts: 10,
+		ReplacedBy:        "0x1234",
 	}
 	tx2 := new(ethtypes.TransactionWithMetadata)
 	require.NoError(t, json.Unmarshal(jsonp.MustMarshal(tx), tx2))
@@ -35,6 +36,7 @@ func TestTransactionWithHeightJSON(t *testing.T) {
 	require.Equal(t, tx.Success, tx2.Success)
 	require.Equal(t, tx.Transaction.Hash(), tx2.Transaction.Hash())
 	require.Equal(t, tx.BroadcastAttempts, tx2.BroadcastAttempts)
+	require.Equal(t, tx.ReplacedBy, tx2.ReplacedBy)
 }
 
 func TestFeeTarget(t *testing.T) {
@@ -102,6 +102,20 @@ type Keystore struct {
 type AccountsConfig struct {
 	Accounts  []*Account  `json:"accounts"`
 	Keystores []*Keystore `json:"keystores"`
+	// ERC20Tokens are ERC20 tokens added by the user, in addition to the tokens supported by
+	// default.
+	ERC20Tokens []*ERC20Token `json:"erc20Tokens,omitempty"`
+}
+
+// ERC20Token is an ERC20 token added by the user by its contract address.
+type ERC20Token struct {
+	// Code is the coin code of the token, e.g. "eth-erc20-custom-0x...".
+	Code coin.Code `json:"code"`
+	Name string    `json:"name"`
+	// Unit is the token symbol.
+	Unit            string `json:"unit"`
+	ContractAddress string `json:"contractAddress"`
+	Decimals        uint   `json:"decimals"`
 }
 
 // newDefaultAccountsonfig returns the default accounts config.
@@ -122,6 +136,17 @@ func (cfg AccountsConfig) Lookup(code accountsTypes.Code) *Account {
 	return nil
 }
 
+// LookupERC20Token returns the user-added ERC20 token with the given code, or nil if no such token
+// exists.
+func (cfg AccountsConfig) LookupERC20Token(code coin.Code) *ERC20Token {
+	for _, token := range cfg.ERC20Tokens {
+		if token.Code == code {
+			return token
+		}
+	}
+	return nil
+}
+
 // LookupByXpub returns the account code of the account containing the xpub in one of its signing
 // configurations, or an error otherwise. Only te "xpub" format should be provided, not
 // "ypub"/"zpub" etc. ERC20-tokens are excluded.
@@ -0,0 +1,99 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+)
+
+// APITokenScope is a permission granted to an API token.
+type APITokenScope string
+
+const (
+	// APITokenScopeReadBalances allows to read the accounts, their status and balances.
+	APITokenScopeReadBalances APITokenScope = "balances:read"
+	// APITokenScopeReadTransactions allows to read the transactions and receive addresses.
+	APITokenScopeReadTransactions APITokenScope = "transactions:read"
+	// APITokenScopeCreateProposals allows to create transaction proposals, without sending them.
+	APITokenScopeCreateProposals APITokenScope = "proposals:create"
+	// APITokenScopeSign allows to sign and send transactions and messages.
+	APITokenScopeSign APITokenScope = "sign"
+	// APITokenScopeAdmin allows everything, including managing the devices, settings and API
+	// tokens.
+	APITokenScopeAdmin APITokenScope = "admin"
+)
+
+// APITokenScopes are all scopes.
+var APITokenScopes = []APITokenScope{
+	APITokenScopeReadBalances,
+	APITokenScopeReadTransactions,
+	APITokenScopeCreateProposals,
+	APITokenScopeSign,
+	APITokenScopeAdmin,
+}
+
+// APIToken is a named token to access the HTTP API with limited permissions, e.g. to give an
+// accounting tool read-only access.
+type APIToken struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Hash is the hex-encoded SHA256 hash of the token. The token itself is not stored.
+	Hash   string          `json:"hash"`
+	Scopes []APITokenScope `json:"scopes"`
+	// AccountCodes restricts the token to these accounts. The token can access all accounts if it
+	// is empty.
+	AccountCodes []accountsTypes.Code `json:"accountCodes"`
+	Created      time.Time            `json:"created"`
+	// Expires is the time from which the token is not valid anymore. Nil if it does not expire.
+	Expires *time.Time `json:"expires"`
+}
+
+// HashAPIToken returns the hash of the token stored in APIToken.Hash.
+func HashAPIToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// HasScope returns true if the token was granted the scope. The admin scope includes all others.
+func (token *APIToken) HasScope(scope APITokenScope) bool {
+	for _, tokenScope := range token.Scopes {
+		if tokenScope == scope || tokenScope == APITokenScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAccount returns true if the token is not restricted to other accounts.
+func (token *APIToken) AllowsAccount(code accountsTypes.Code) bool {
+	if len(token.AccountCodes) == 0 {
+		return true
+	}
+	for _, accountCode := range token.AccountCodes {
+		if accountCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired returns true if the token is expired at the given time.
+func (token *APIToken) Expired(now time.Time) bool {
+	return token.Expires != nil && !now.Before(*token.Expires)
+}
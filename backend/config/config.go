@@ -15,6 +15,7 @@
 package config
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -23,6 +24,7 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/locker"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/random"
 )
 
 // ServerInfo holds information about the backend server(s).
@@ -54,11 +56,49 @@ const (
 	ETHTransactionsSourceNone ETHTransactionsSource = "none"
 	// ETHTransactionsSourceEtherScan configures to get transactions from EtherScan.
 	ETHTransactionsSourceEtherScan ETHTransactionsSource = "etherScan"
+	// ETHTransactionsSourceNode configures to get transactions by scanning the blockchain using
+	// the configured node. See `ethCoinConfig.NodeURL`.
+	ETHTransactionsSourceNode ETHTransactionsSource = "node"
 )
 
 // ethCoinConfig holds configurations for ethereum coins.
 type ethCoinConfig struct {
 	DeprecatedActiveERC20Tokens []string `json:"activeERC20Tokens"`
+	// NodeURL is the URL of an Ethereum JSON-RPC node to query balances and fees and to broadcast
+	// transactions with. If empty, EtherScan is used.
+	NodeURL string `json:"nodeURL"`
+	// TransactionsSource is where to get the transactions from. If empty, EtherScan is used.
+	TransactionsSource ETHTransactionsSource `json:"transactionsSource"`
+}
+
+// FiatQuoteConfig limits how much the exchange rate, which was used to convert a send amount given
+// in fiat, can change between creating the transaction proposal and signing it.
+type FiatQuoteConfig struct {
+	// MaxAgeSeconds is the maximum age of the exchange rate when signing.
+	MaxAgeSeconds int `json:"maxAgeSeconds"`
+	// TolerancePercent is the maximum deviation of the latest exchange rate from the rate used in
+	// the proposal when signing.
+	TolerancePercent float64 `json:"tolerancePercent"`
+}
+
+// Webhook is a URL to which backend events are posted, e.g. to get alerts in a chat app.
+type Webhook struct {
+	// URL receives the events as JSON in HTTP POST requests.
+	URL string `json:"url"`
+	// Secret is the key of the HMAC-SHA256 signature of the request body. It is required, and
+	// generated for webhooks configured without one.
+	Secret string `json:"secret"`
+	// Events are the types of the events posted to the URL. All events are posted if it is empty.
+	Events []string `json:"events"`
+}
+
+// LocalAPIConfig configures the API server listening on localhost, which other programs on this
+// computer can use with an API token.
+type LocalAPIConfig struct {
+	// Enabled starts the server when the app starts.
+	Enabled bool `json:"enabled"`
+	// Port is the port on 127.0.0.1 the server listens on.
+	Port int `json:"port"`
 }
 
 type proxyConfig struct {
@@ -76,12 +116,13 @@ type Backend struct {
 
 	Authentication bool `json:"authentication"`
 
-	BTC  btcCoinConfig `json:"btc"`
-	TBTC btcCoinConfig `json:"tbtc"`
-	RBTC btcCoinConfig `json:"rbtc"`
-	LTC  btcCoinConfig `json:"ltc"`
-	TLTC btcCoinConfig `json:"tltc"`
-	ETH  ethCoinConfig `json:"eth"`
+	BTC    btcCoinConfig `json:"btc"`
+	TBTC   btcCoinConfig `json:"tbtc"`
+	RBTC   btcCoinConfig `json:"rbtc"`
+	LTC    btcCoinConfig `json:"ltc"`
+	TLTC   btcCoinConfig `json:"tltc"`
+	ETH    ethCoinConfig `json:"eth"`
+	SEPETH ethCoinConfig `json:"sepeth"`
 
 	// Removed in v4.35 - don't reuse these two keys.
 	TETH struct{} `json:"teth"`
@@ -103,6 +144,18 @@ type Backend struct {
 	// BtcUnit is the unit used to represent Bitcoin amounts. See `coin.BtcUnit` for details.
 	BtcUnit coin.BtcUnit `json:"btcUnit"`
 
+	// FiatQuote applies to transactions whose amounts were given in fiat.
+	FiatQuote FiatQuoteConfig `json:"fiatQuote"`
+
+	// Webhooks receive the account and device events.
+	Webhooks []Webhook `json:"webhooks"`
+
+	// APITokens give third-party apps access to the HTTP API, in addition to the token of the app.
+	APITokens []APIToken `json:"apiTokens"`
+
+	// LocalAPI makes the HTTP API available to other programs, which need an API token.
+	LocalAPI LocalAPIConfig `json:"localAPI"`
+
 	// StartInTestnet represents whether the app should launch in testnet on the next start.
 	// It resets to `false` after the app starts.
 	StartInTestnet bool `json:"startInTestnet"`
@@ -236,6 +289,16 @@ func NewDefaultAppConfig() AppConfig {
 			FiatList: []string{rates.USD.String(), rates.EUR.String(), rates.CHF.String()},
 			MainFiat: rates.USD.String(),
 			BtcUnit:  coin.BtcUnitDefault,
+			FiatQuote: FiatQuoteConfig{
+				MaxAgeSeconds:    600,
+				TolerancePercent: 1,
+			},
+			Webhooks:  []Webhook{},
+			APITokens: []APIToken{},
+			LocalAPI: LocalAPIConfig{
+				Enabled: false,
+				Port:    8085,
+			},
 		},
 		Frontend: make(map[string]interface{}),
 	}
@@ -268,6 +331,7 @@ func NewConfig(appConfigFilename string, accountsConfigFilename string) (*Config
 	migrateFiatCode(&appconf)
 	migrateElectrumX(&appconf)
 	migrateUserLanguage(&appconf)
+	migrateWebhookSecrets(&appconf)
 	if err := config.SetAppConfig(appconf); err != nil {
 		return nil, errp.WithStack(err)
 	}
@@ -453,3 +517,14 @@ func migrateUserLanguage(appconf *AppConfig) {
 		delete(frontconf, "userLanguage")
 	}
 }
+
+// migrateWebhookSecrets generates a secret for webhooks configured without one, so that their
+// requests are signed.
+func migrateWebhookSecrets(appconf *AppConfig) {
+	for index := range appconf.Backend.Webhooks {
+		webhook := &appconf.Backend.Webhooks[index]
+		if webhook.Secret == "" {
+			webhook.Secret = hex.EncodeToString(random.BytesOrPanic(32))
+		}
+	}
+}
@@ -105,6 +105,10 @@ func TestMigrationsAtLoad(t *testing.T) {
 	appCfg.Frontend = map[string]interface{}{
 		"userLanguage": "de",
 	}
+	appCfg.Backend.Webhooks = []Webhook{
+		{URL: "https://example.com/unsigned"},
+		{URL: "https://example.com/signed", Secret: "secret"},
+	}
 	require.NoError(t, cfg.SetAppConfig(appCfg))
 	require.NoError(t, cfg.ModifyAccountsConfig(func(accountsCfg *AccountsConfig) error {
 		accountsCfg.Accounts = append(accountsCfg.Accounts,
@@ -116,6 +120,9 @@ func TestMigrationsAtLoad(t *testing.T) {
 	cfg2, err := NewConfig(appConfigFilename, accountsConfigFilename)
 	require.NoError(t, err)
 	require.Equal(t, "de", cfg2.AppConfig().Backend.UserLanguage)
+	webhooks := cfg2.AppConfig().Backend.Webhooks
+	require.Len(t, webhooks[0].Secret, 64)
+	require.Equal(t, "secret", webhooks[1].Secret)
 	require.Equal(t,
 		[]*Account{{CoinCode: coin.CodeETH, ActiveTokens: nil}},
 		cfg2.AccountsConfig().Accounts)
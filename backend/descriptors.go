@@ -0,0 +1,170 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bytes"
+	"fmt"
+
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+)
+
+// AccountDescriptors contains the BIP-380 output descriptors of an account, one per signing
+// configuration (subaccount).
+type AccountDescriptors struct {
+	AccountCode accountsTypes.Code `json:"accountCode"`
+	CoinCode    coinpkg.Code       `json:"coinCode"`
+	Name        string             `json:"name"`
+	Descriptors []string           `json:"descriptors"`
+}
+
+// ExportAccountDescriptors returns the output descriptors of all persisted Bitcoin-based accounts.
+// Accounts of other coins, which have no descriptors, are skipped.
+func (backend *Backend) ExportAccountDescriptors() ([]*AccountDescriptors, error) {
+	result := []*AccountDescriptors{}
+	for _, account := range backend.config.AccountsConfig().Accounts {
+		if account.HiddenBecauseUnused {
+			continue
+		}
+		coin, err := backend.Coin(account.CoinCode)
+		if err != nil {
+			backend.log.WithError(err).Errorf("skipping account %s, could not find coin", account.Code)
+			continue
+		}
+		btcCoin, ok := coin.(*btc.Coin)
+		if !ok {
+			continue
+		}
+		accountDescriptors := &AccountDescriptors{
+			AccountCode: account.Code,
+			CoinCode:    account.CoinCode,
+			Name:        account.Name,
+			Descriptors: make([]string, len(account.SigningConfigurations)),
+		}
+		for index, signingConfiguration := range account.SigningConfigurations {
+			descriptor, err := signingConfiguration.Descriptor(btcCoin.Net())
+			if err != nil {
+				return nil, err
+			}
+			accountDescriptors.Descriptors[index] = descriptor
+		}
+		result = append(result, accountDescriptors)
+	}
+	return result, nil
+}
+
+// parseAccountDescriptors parses the descriptors of one account. Several single-sig descriptors
+// with different script types form a unified account. They must all belong to the same keystore
+// and use account level keypaths (m/purpose'/coin'/account'). A multisig descriptor can only be
+// imported on its own.
+func parseAccountDescriptors(coin *btc.Coin, descriptors []string) (signing.Configurations, error) {
+	if len(descriptors) == 0 {
+		return nil, errp.New("No descriptors provided")
+	}
+	var configurations signing.Configurations
+	for _, descriptor := range descriptors {
+		configuration, err := signing.ParseDescriptor(descriptor, coin.Net())
+		if err != nil {
+			return nil, err
+		}
+		if configuration.BitcoinSimple != nil {
+			if _, err := configuration.AccountNumber(); err != nil {
+				return nil, errp.Newf(
+					"Unsupported keypath %s, expected m/purpose'/coin'/account'",
+					configuration.AbsoluteKeypath().Encode())
+			}
+			if configurations.FindScriptType(configuration.ScriptType()) != -1 {
+				return nil, errp.Newf("Duplicate descriptor for script type %s", configuration.ScriptType())
+			}
+		}
+		configurations = append(configurations, configuration)
+	}
+	if len(configurations) > 1 && configurations.IsMultisig() {
+		return nil, errp.New("A multisig descriptor must be imported on its own")
+	}
+	rootFingerprint, err := configurations.RootFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	for _, configuration := range configurations {
+		configurationRootFingerprint, err := signing.Configurations{configuration}.RootFingerprint()
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(rootFingerprint, configurationRootFingerprint) {
+			return nil, errp.New("All descriptors of an account must belong to the same keystore")
+		}
+	}
+	return configurations, nil
+}
+
+// CreateWatchonlyAccountFromDescriptors adds a watch-only account described by the given output
+// descriptors, e.g. exported from another wallet or Bitcoin Core. If the keystore of the account is
+// not known yet, the account is shown even if the keystore is not connected. Otherwise, the
+// watch-only setting of the keystore decides.
+//
+// The account code of the newly created account is returned.
+func (backend *Backend) CreateWatchonlyAccountFromDescriptors(
+	coinCode coinpkg.Code, name string, descriptors []string) (accountsTypes.Code, error) {
+	coin, err := backend.Coin(coinCode)
+	if err != nil {
+		return "", err
+	}
+	btcCoin, ok := coin.(*btc.Coin)
+	if !ok {
+		return "", errp.Newf("Descriptors are not supported for %s", coinCode)
+	}
+	configurations, err := parseAccountDescriptors(btcCoin, descriptors)
+	if err != nil {
+		return "", err
+	}
+	rootFingerprint, err := configurations.RootFingerprint()
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = fmt.Sprintf("%s watch-only", coin.Name())
+	}
+	accountCode := importedAccountCode(rootFingerprint, coinCode, configurations)
+	err = backend.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
+		// Watch-only accounts are only loaded if the watchonly setting of their keystore is
+		// enabled. It is enabled for keystores which are new to us, but the setting the user chose
+		// for a known keystore is kept.
+		if _, err := accountsConfig.LookupKeystore(rootFingerprint); err != nil {
+			keystore := accountsConfig.GetOrAddKeystore(rootFingerprint)
+			keystore.Watchonly = true
+			keystore.Name = name
+		}
+		t := true
+		backend.log.WithField("code", accountCode).Info("persist watch-only account from descriptors")
+		return backend.persistAccount(config.Account{
+			Watch:                 &t,
+			CoinCode:              coinCode,
+			Name:                  name,
+			Code:                  accountCode,
+			SigningConfigurations: configurations,
+		}, accountsConfig)
+	})
+	if err != nil {
+		return "", err
+	}
+	backend.ReinitializeAccounts()
+	return accountCode, nil
+}
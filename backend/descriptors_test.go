@@ -0,0 +1,126 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"strings"
+	"testing"
+
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountDescriptorsRoundtrip(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	b.registerKeystore(makeBitBox02Multi())
+
+	exported, err := b.ExportAccountDescriptors()
+	require.NoError(t, err)
+	var btcDescriptors *AccountDescriptors
+	for _, accountDescriptors := range exported {
+		// Only Bitcoin-based accounts are exported.
+		require.NotEqual(t, coinpkg.CodeETH, accountDescriptors.CoinCode)
+		if accountDescriptors.AccountCode == "v0-55555555-btc-0" {
+			btcDescriptors = accountDescriptors
+		}
+	}
+	require.NotNil(t, btcDescriptors)
+	require.Equal(t, coinpkg.CodeBTC, btcDescriptors.CoinCode)
+	require.Len(t, btcDescriptors.Descriptors, 3)
+	require.True(t, strings.HasPrefix(btcDescriptors.Descriptors[0], "wpkh([55555555/84h/0h/0h]xpub"))
+	require.NoError(t, b.Close())
+
+	// Import into a fresh backend without a connected keystore.
+	b = newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	acctCode, err := b.CreateWatchonlyAccountFromDescriptors(coinpkg.CodeBTC, "", btcDescriptors.Descriptors)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(acctCode), "v0-55555555-btc-imported-"))
+	accountsConfig := b.Config().AccountsConfig()
+	persistedAccount := accountsConfig.Lookup(acctCode)
+	require.NotNil(t, persistedAccount)
+	require.Equal(t, "Bitcoin watch-only", persistedAccount.Name)
+	require.Len(t, persistedAccount.SigningConfigurations, 3)
+	isWatchonly, err := accountsConfig.IsAccountWatchonly(persistedAccount)
+	require.NoError(t, err)
+	require.True(t, isWatchonly)
+	require.NotNil(t, b.Accounts().lookup(acctCode))
+
+	// The imported account exports the same descriptors.
+	exported, err = b.ExportAccountDescriptors()
+	require.NoError(t, err)
+	require.Len(t, exported, 1)
+	require.Equal(t, btcDescriptors.Descriptors, exported[0].Descriptors)
+
+	// Adding it again fails.
+	_, err = b.CreateWatchonlyAccountFromDescriptors(coinpkg.CodeBTC, "", btcDescriptors.Descriptors[:1])
+	require.Equal(t, errAccountAlreadyExists, errp.Cause(err))
+}
+
+func TestCreateWatchonlyAccountFromDescriptorsKnownKeystore(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	b.registerKeystore(makeBitBox02Multi())
+	exported, err := b.ExportAccountDescriptors()
+	require.NoError(t, err)
+	require.NotEmpty(t, exported)
+	require.NoError(t, b.Close())
+
+	b = newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	rootFingerprint := []byte{0x55, 0x55, 0x55, 0x55}
+	require.NoError(t, b.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
+		accountsConfig.Keystores = append(accountsConfig.Keystores, &config.Keystore{
+			RootFingerprint: rootFingerprint,
+			Name:            "My BitBox",
+			Watchonly:       false,
+		})
+		return nil
+	}))
+
+	// The user disabled watch-only for this keystore, so the import does not enable it.
+	_, err = b.CreateWatchonlyAccountFromDescriptors(coinpkg.CodeBTC, "", exported[0].Descriptors)
+	require.NoError(t, err)
+	keystore, err := b.Config().AccountsConfig().LookupKeystore(rootFingerprint)
+	require.NoError(t, err)
+	require.False(t, keystore.Watchonly)
+	require.Equal(t, "My BitBox", keystore.Name)
+}
+
+func TestCreateWatchonlyAccountFromDescriptorsErrors(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	ks := makeBitBox02Multi()
+	b.registerKeystore(ks)
+	exported, err := b.ExportAccountDescriptors()
+	require.NoError(t, err)
+	require.NotEmpty(t, exported)
+	descriptors := exported[0].Descriptors
+
+	// No descriptors.
+	_, err = b.CreateWatchonlyAccountFromDescriptors(coinpkg.CodeBTC, "", nil)
+	require.Error(t, err)
+	// Invalid descriptor.
+	_, err = b.CreateWatchonlyAccountFromDescriptors(coinpkg.CodeBTC, "", []string{"wpkh(invalid)"})
+	require.Error(t, err)
+	// Duplicate script type.
+	_, err = b.CreateWatchonlyAccountFromDescriptors(
+		coinpkg.CodeBTC, "", []string{descriptors[0], descriptors[0]})
+	require.Error(t, err)
+	// Not a Bitcoin-based coin.
+	_, err = b.CreateWatchonlyAccountFromDescriptors(coinpkg.CodeETH, "", descriptors)
+	require.Error(t, err)
+}
@@ -114,7 +114,8 @@ func (keystore *keystore) SupportsAccount(coin coinpkg.Coin, meta interface{}) b
 				return false
 			}
 		}
-		return scriptType != signing.ScriptTypeP2PKH
+		// Multisig accounts would first need to be registered on the device.
+		return scriptType != signing.ScriptTypeP2PKH && scriptType != signing.ScriptTypeP2WSH
 	default:
 		return true
 	}
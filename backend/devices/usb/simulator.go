@@ -0,0 +1,227 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usb
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
+	bitbox02common "github.com/BitBoxSwiss/bitbox02-api-go/api/common"
+	"github.com/BitBoxSwiss/bitbox02-api-go/communication/u2fhid"
+	"github.com/BitBoxSwiss/bitbox02-api-go/util/semver"
+	"github.com/sirupsen/logrus"
+)
+
+// simulatorDialTimeout is the timeout of connecting to the simulator. It is short as it is done
+// each time the devices are enumerated while the simulator is not connected.
+const simulatorDialTimeout = 200 * time.Millisecond
+
+// simulatorProductStrings maps the platform and edition bytes of the OP_INFO response to the USB
+// product string of the device.
+var simulatorProductStrings = map[[2]byte]string{
+	{0x00, 0x00}: bitbox02common.FirmwareDeviceProductStringBitBox02Multi,
+	{0x00, 0x01}: bitbox02common.FirmwareDeviceProductStringBitBox02BTCOnly,
+	{0x02, 0x00}: bitbox02common.FirmwareDeviceProductStringBitBox02PlusMulti,
+	{0x02, 0x01}: bitbox02common.FirmwareDeviceProductStringBitBox02PlusBTCOnly,
+}
+
+// parseSimulatorInfo parses the response of the OP_INFO api endpoint into the firmware version and
+// the USB product string.
+func parseSimulatorInfo(response []byte) (*semver.SemVer, string, error) {
+	if len(response) < 1 || len(response) < 1+int(response[0])+2 {
+		return nil, "", errp.New("unexpected OP_INFO response")
+	}
+	versionLen := int(response[0])
+	version, err := semver.NewSemVerFromString(string(response[1 : 1+versionLen]))
+	if err != nil {
+		return nil, "", err
+	}
+	platformAndEdition := [2]byte{response[1+versionLen], response[2+versionLen]}
+	product, ok := simulatorProductStrings[platformAndEdition]
+	if !ok {
+		return nil, "", errp.Newf("unrecognized platform/edition: %v", platformAndEdition)
+	}
+	return version, product, nil
+}
+
+// simulatorConn is the connection to the simulator. It remembers if it was closed or broke, so
+// that the simulator is reported as removed.
+type simulatorConn struct {
+	net.Conn
+	mu     sync.Mutex
+	closed bool
+}
+
+func (conn *simulatorConn) setClosedOnError(err error) {
+	if err != nil {
+		conn.mu.Lock()
+		conn.closed = true
+		conn.mu.Unlock()
+	}
+}
+
+// Read implements io.Reader.
+func (conn *simulatorConn) Read(p []byte) (int, error) {
+	n, err := conn.Conn.Read(p)
+	conn.setClosedOnError(err)
+	return n, err
+}
+
+// Write implements io.Writer.
+func (conn *simulatorConn) Write(p []byte) (int, error) {
+	n, err := conn.Conn.Write(p)
+	conn.setClosedOnError(err)
+	return n, err
+}
+
+// Close implements io.Closer.
+func (conn *simulatorConn) Close() error {
+	conn.mu.Lock()
+	conn.closed = true
+	conn.mu.Unlock()
+	return conn.Conn.Close()
+}
+
+func (conn *simulatorConn) isClosed() bool {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	return conn.closed
+}
+
+// simulatorDeviceInfo implements DeviceInfo for a BitBox02 simulator connected over TCP.
+type simulatorDeviceInfo struct {
+	identifier string
+	version    *semver.SemVer
+	product    string
+	conn       *simulatorConn
+}
+
+// VendorID implements DeviceInfo.
+func (info *simulatorDeviceInfo) VendorID() int {
+	return bitbox02VendorID
+}
+
+// ProductID implements DeviceInfo.
+func (info *simulatorDeviceInfo) ProductID() int {
+	return bitbox02ProductID
+}
+
+// UsagePage implements DeviceInfo.
+func (info *simulatorDeviceInfo) UsagePage() int {
+	return 0xffff
+}
+
+// Interface implements DeviceInfo.
+func (info *simulatorDeviceInfo) Interface() int {
+	return 0
+}
+
+// Serial implements DeviceInfo. Like the serial of a real device, it contains the firmware version.
+func (info *simulatorDeviceInfo) Serial() string {
+	return fmt.Sprintf("v%s", info.version)
+}
+
+// Product implements DeviceInfo.
+func (info *simulatorDeviceInfo) Product() string {
+	return info.product
+}
+
+// Identifier implements DeviceInfo.
+func (info *simulatorDeviceInfo) Identifier() string {
+	return info.identifier
+}
+
+// Open implements DeviceInfo. It returns the connection which was established when the simulator
+// was detected.
+func (info *simulatorDeviceInfo) Open() (io.ReadWriteCloser, error) {
+	if info.conn.isClosed() {
+		return nil, errp.New("The connection to the simulator was closed")
+	}
+	return info.conn, nil
+}
+
+// Simulator makes a BitBox02 simulator listening on a TCP address available like a BitBox02
+// plugged in via USB, e.g. to drive the backend against the simulator in integration tests.
+type Simulator struct {
+	address string
+
+	mu sync.Mutex
+	// deviceInfo is the connected simulator, or nil if it is not connected.
+	deviceInfo *simulatorDeviceInfo
+	// connections counts the connections made, so that each connection is registered as a new
+	// device.
+	connections int
+
+	log *logrus.Entry
+}
+
+// NewSimulator creates a new Simulator connecting to the simulator at the given address
+// (host:port).
+func NewSimulator(address string) *Simulator {
+	return &Simulator{
+		address: address,
+		log:     logging.Get().WithGroup("simulator").WithField("address", address),
+	}
+}
+
+// connect connects to the simulator and queries its firmware version and product.
+func (simulator *Simulator) connect() (*simulatorDeviceInfo, error) {
+	conn, err := net.DialTimeout("tcp", simulator.address, simulatorDialTimeout)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	response, err := u2fhid.NewCommunication(conn, bitboxCMD).Query([]byte("i"))
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	version, product, err := parseSimulatorInfo(response)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	simulator.connections++
+	return &simulatorDeviceInfo{
+		identifier: fmt.Sprintf("simulator-%s-%d", simulator.address, simulator.connections),
+		version:    version,
+		product:    product,
+		conn:       &simulatorConn{Conn: conn},
+	}, nil
+}
+
+// DeviceInfos returns the simulator if it is connected, connecting to it if needed. It can be
+// combined with DeviceInfos() to also detect devices plugged in via USB.
+func (simulator *Simulator) DeviceInfos() []DeviceInfo {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+	if simulator.deviceInfo != nil && simulator.deviceInfo.conn.isClosed() {
+		simulator.log.Info("Simulator disconnected")
+		simulator.deviceInfo = nil
+	}
+	if simulator.deviceInfo == nil {
+		deviceInfo, err := simulator.connect()
+		if err != nil {
+			return []DeviceInfo{}
+		}
+		simulator.log.WithField("version", deviceInfo.version).Info("Simulator connected")
+		simulator.deviceInfo = deviceInfo
+	}
+	return []DeviceInfo{simulator.deviceInfo}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usb
+
+import (
+	"net"
+	"testing"
+
+	bitbox02common "github.com/BitBoxSwiss/bitbox02-api-go/api/common"
+	"github.com/BitBoxSwiss/bitbox02-api-go/communication/u2fhid"
+	"github.com/stretchr/testify/require"
+)
+
+// infoResponse is the OP_INFO response of a locked BitBox02 Multi with firmware v9.21.0.
+var infoResponse = append(append([]byte{6}, "9.21.0"...), 0x00, 0x00, 0x00)
+
+// serveFakeSimulator answers the OP_INFO request of each connection, and then echoes all frames.
+func serveFakeSimulator(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer func() { _ = conn.Close() }()
+			communication := u2fhid.NewCommunication(conn, bitboxCMD)
+			request, err := communication.ReadFrame()
+			if err != nil || string(request) != "i" {
+				return
+			}
+			if err := communication.SendFrame(string(infoResponse)); err != nil {
+				return
+			}
+			for {
+				frame, err := communication.ReadFrame()
+				if err != nil {
+					return
+				}
+				if err := communication.SendFrame(string(frame)); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+func TestParseSimulatorInfo(t *testing.T) {
+	version, product, err := parseSimulatorInfo(infoResponse)
+	require.NoError(t, err)
+	require.Equal(t, "9.21.0", version.String())
+	require.Equal(t, bitbox02common.FirmwareDeviceProductStringBitBox02Multi, product)
+
+	_, product, err = parseSimulatorInfo(append(append([]byte{6}, "9.21.0"...), 0x02, 0x01, 0x01))
+	require.NoError(t, err)
+	require.Equal(t, bitbox02common.FirmwareDeviceProductStringBitBox02PlusBTCOnly, product)
+
+	_, _, err = parseSimulatorInfo(append(append([]byte{6}, "9.21.0"...), 0x01, 0x00, 0x00))
+	require.Error(t, err)
+	_, _, err = parseSimulatorInfo([]byte{6, '9'})
+	require.Error(t, err)
+	_, _, err = parseSimulatorInfo(nil)
+	require.Error(t, err)
+}
+
+func TestSimulator(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go serveFakeSimulator(listener)
+
+	simulator := NewSimulator(listener.Addr().String())
+	deviceInfos := simulator.DeviceInfos()
+	require.Len(t, deviceInfos, 1)
+	deviceInfo := deviceInfos[0]
+	require.True(t, isBitBox02(deviceInfo))
+	require.Equal(t, "v9.21.0", deviceInfo.Serial())
+	// Enumerating again does not reconnect.
+	require.Equal(t, deviceInfos, simulator.DeviceInfos())
+
+	device, err := deviceInfo.Open()
+	require.NoError(t, err)
+	communication := u2fhid.NewCommunication(device, bitboxCMD)
+	response, err := communication.Query([]byte("ping"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("ping"), response)
+
+	// After the device is closed, the simulator is connected again as a new device.
+	require.NoError(t, device.Close())
+	_, err = deviceInfo.Open()
+	require.Error(t, err)
+	deviceInfos = simulator.DeviceInfos()
+	require.Len(t, deviceInfos, 1)
+	require.NotEqual(t, deviceInfo.Identifier(), deviceInfos[0].Identifier())
+
+	// The simulator is removed when it is no longer reachable.
+	require.NoError(t, listener.Close())
+	device, err = deviceInfos[0].Open()
+	require.NoError(t, err)
+	require.NoError(t, device.Close())
+	require.Empty(t, simulator.DeviceInfos())
+}
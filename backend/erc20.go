@@ -16,10 +16,20 @@
 package backend
 
 import (
+	"strings"
+
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/erc20"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/ethereum/go-ethereum/common"
 )
 
+// customERC20TokenCodePrefix is the prefix of the coin codes of ERC20 tokens added by the user. The
+// coin code is the prefix followed by the lowercase contract address.
+const customERC20TokenCodePrefix = "eth-erc20-custom-"
+
 type erc20Token struct {
 	code  coin.Code
 	name  string
@@ -97,3 +107,136 @@ func erc20TokenByCode(code coin.Code) *erc20Token {
 	}
 	return nil
 }
+
+// erc20TokenByContractAddress returns the builtin token with the given contract address, or nil if
+// there is none.
+func erc20TokenByContractAddress(contractAddress string) *erc20Token {
+	for _, token := range erc20Tokens {
+		if strings.EqualFold(token.token.ContractAddress().Hex(), contractAddress) {
+			token := token
+			return &token
+		}
+	}
+	return nil
+}
+
+// customERC20TokenCode returns the coin code of the user-added token with the given contract
+// address.
+func customERC20TokenCode(contractAddress common.Address) coin.Code {
+	return coin.Code(customERC20TokenCodePrefix + strings.ToLower(contractAddress.Hex()))
+}
+
+// erc20TokenByCode returns the builtin or user-added ERC20 token with the given code, or nil if no
+// such token exists.
+func (backend *Backend) erc20TokenByCode(code coin.Code) *erc20Token {
+	if token := erc20TokenByCode(code); token != nil {
+		return token
+	}
+	// Only look up user-added tokens if the code can belong to one, as the accounts config might be
+	// locked when a builtin coin is requested.
+	if !strings.HasPrefix(string(code), customERC20TokenCodePrefix) {
+		return nil
+	}
+	customToken := backend.config.AccountsConfig().LookupERC20Token(code)
+	if customToken == nil {
+		return nil
+	}
+	return &erc20Token{
+		code:  customToken.Code,
+		name:  customToken.Name,
+		unit:  customToken.Unit,
+		token: erc20.NewToken(customToken.ContractAddress, customToken.Decimals),
+	}
+}
+
+// CustomERC20Tokens returns the ERC20 tokens added by the user.
+func (backend *Backend) CustomERC20Tokens() []*config.ERC20Token {
+	tokens := backend.config.AccountsConfig().ERC20Tokens
+	if tokens == nil {
+		return []*config.ERC20Token{}
+	}
+	return tokens
+}
+
+// AddCustomERC20Token adds the ERC20 token deployed at the given contract address. The token name,
+// symbol and decimals are fetched from the contract. Afterwards, the token can be enabled in ETH
+// accounts like the builtin tokens using `SetTokenActive()`.
+func (backend *Backend) AddCustomERC20Token(contractAddress string) (*config.ERC20Token, error) {
+	if !common.IsHexAddress(contractAddress) {
+		return nil, errp.Newf("invalid contract address %s", contractAddress)
+	}
+	address := common.HexToAddress(contractAddress)
+	if erc20TokenByContractAddress(address.Hex()) != nil {
+		return nil, errp.New("token is already supported")
+	}
+	code := customERC20TokenCode(address)
+	if backend.config.AccountsConfig().LookupERC20Token(code) != nil {
+		return nil, errp.New("token was already added")
+	}
+
+	ethCoin, err := backend.Coin(coin.CodeETH)
+	if err != nil {
+		return nil, err
+	}
+	info, err := ethCoin.(*eth.Coin).ERC20TokenInfo(address)
+	if err != nil {
+		return nil, errp.WithMessage(err, "could not get the token details; is this an ERC20 token contract?")
+	}
+	if info.Symbol == "" {
+		return nil, errp.New("the token contract has no symbol")
+	}
+	name := info.Name
+	if name == "" {
+		name = info.Symbol
+	}
+	token := &config.ERC20Token{
+		Code:            code,
+		Name:            name,
+		Unit:            info.Symbol,
+		ContractAddress: address.Hex(),
+		Decimals:        uint(info.Decimals),
+	}
+	err = backend.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
+		if accountsConfig.LookupERC20Token(code) != nil {
+			return errp.New("token was already added")
+		}
+		accountsConfig.ERC20Tokens = append(accountsConfig.ERC20Tokens, token)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	backend.log.WithField("code", code).Info("added custom ERC20 token")
+	return token, nil
+}
+
+// RemoveCustomERC20Token removes an ERC20 token added by the user. The token is disabled in all
+// ETH accounts.
+func (backend *Backend) RemoveCustomERC20Token(code coin.Code) error {
+	err := backend.config.ModifyAccountsConfig(func(accountsConfig *config.AccountsConfig) error {
+		var tokens []*config.ERC20Token
+		for _, token := range accountsConfig.ERC20Tokens {
+			if token.Code != code {
+				tokens = append(tokens, token)
+			}
+		}
+		if len(tokens) == len(accountsConfig.ERC20Tokens) {
+			return errp.Newf("Could not find token %s", code)
+		}
+		accountsConfig.ERC20Tokens = tokens
+		for _, acct := range accountsConfig.Accounts {
+			if acct.CoinCode != coin.CodeETH {
+				continue
+			}
+			if err := acct.SetTokenActive(string(code), false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	backend.ReinitializeAccounts()
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/erc20"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/rpcclient/mocks"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// mockERC20Contract mocks the client of the ETH coin so that calls to the name, symbol and
+// decimals methods of the contract at `contractAddress` return the given values.
+func mockERC20Contract(t *testing.T, b *Backend, contractAddress common.Address, name string, symbol string, decimals uint8) {
+	t.Helper()
+	contractABI, err := erc20.IERC20MetaData.GetAbi()
+	require.NoError(t, err)
+	results := map[string]interface{}{
+		"name":     name,
+		"symbol":   symbol,
+		"decimals": decimals,
+	}
+	c, err := b.Coin(coinpkg.CodeETH)
+	require.NoError(t, err)
+	c.(*eth.Coin).TstSetClient(&mocks.InterfaceMock{
+		CodeAtFunc: func(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+			if contract != contractAddress {
+				return nil, nil
+			}
+			return []byte{0x60, 0x80}, nil
+		},
+		CallContractFunc: func(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+			if *call.To != contractAddress {
+				return nil, nil
+			}
+			for methodName, result := range results {
+				method := contractABI.Methods[methodName]
+				if bytes.Equal(call.Data, method.ID) {
+					return method.Outputs.Pack(result)
+				}
+			}
+			return nil, nil
+		},
+	})
+}
+
+func TestCustomERC20Tokens(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+
+	const contractAddress = "0x1f9840a85d5aF5bf1D1762F925BDADdC4201F984"
+	const tokenCode = coinpkg.Code("eth-erc20-custom-0x1f9840a85d5af5bf1d1762f925bdaddc4201f984")
+	mockERC20Contract(t, b, common.HexToAddress(contractAddress), "Uniswap", "UNI", 18)
+
+	require.Empty(t, b.CustomERC20Tokens())
+
+	// Invalid address.
+	_, err := b.AddCustomERC20Token("0x1234")
+	require.Error(t, err)
+	// Builtin token.
+	_, err = b.AddCustomERC20Token("0xdac17f958d2ee523a2206206994597c13d831ec7")
+	require.Error(t, err)
+	// Not a token contract.
+	_, err = b.AddCustomERC20Token("0x0000000000000000000000000000000000000001")
+	require.Error(t, err)
+
+	token, err := b.AddCustomERC20Token(contractAddress)
+	require.NoError(t, err)
+	require.Equal(t, tokenCode, token.Code)
+	require.Equal(t, "Uniswap", token.Name)
+	require.Equal(t, "UNI", token.Unit)
+	require.Equal(t, contractAddress, token.ContractAddress)
+	require.Equal(t, uint(18), token.Decimals)
+	require.Len(t, b.CustomERC20Tokens(), 1)
+
+	// Already added.
+	_, err = b.AddCustomERC20Token(contractAddress)
+	require.Error(t, err)
+
+	tokenCoin, err := b.Coin(tokenCode)
+	require.NoError(t, err)
+	require.Equal(t, "Uniswap", tokenCoin.Name())
+	require.Equal(t, "UNI", tokenCoin.Unit(false))
+
+	// Activate the token in an ETH account.
+	ks := makeBitBox02Multi()
+	b.registerKeystore(ks)
+	require.NoError(t, b.SetTokenActive("v0-55555555-eth-0", string(tokenCode), true))
+	tokenAccount := b.Accounts().lookup(Erc20AccountCode("v0-55555555-eth-0", string(tokenCode)))
+	require.NotNil(t, tokenAccount)
+	require.Equal(t, tokenCode, tokenAccount.Coin().Code())
+
+	// Removing the token deactivates it.
+	require.NoError(t, b.RemoveCustomERC20Token(tokenCode))
+	require.Empty(t, b.CustomERC20Tokens())
+	require.Empty(t, b.Config().AccountsConfig().Lookup("v0-55555555-eth-0").ActiveTokens)
+	require.Nil(t, b.Accounts().lookup(Erc20AccountCode("v0-55555555-eth-0", string(tokenCode))))
+	require.Error(t, b.RemoveCustomERC20Token(tokenCode))
+}
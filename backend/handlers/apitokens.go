@@ -0,0 +1,276 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/gorilla/mux"
+)
+
+// apiScopeAny is the scope of routes which can be accessed with any valid API token.
+const apiScopeAny config.APITokenScope = ""
+
+// apiRouteScope is the permission needed to access a route with a scoped API token.
+type apiRouteScope struct {
+	scope config.APITokenScope
+	// allAccounts is true if the route returns data of all accounts, so that tokens restricted
+	// to some accounts can't access it.
+	allAccounts bool
+}
+
+// apiRouteScopes are the scopes of the unversioned API routes, keyed by method and path template
+// relative to /api. The account code of the account routes is replaced by `{code}`. Routes which
+// are not listed need the admin scope. A test checks that all keys are registered routes.
+var apiRouteScopes = map[string]apiRouteScope{
+	"GET /version": {scope: apiScopeAny},
+	"GET /rates":   {scope: apiScopeAny},
+
+	"GET /keystores":                        {scope: config.APITokenScopeReadBalances, allAccounts: true},
+	"GET /accounts":                         {scope: config.APITokenScopeReadBalances, allAccounts: true},
+	"GET /accounts/balance":                 {scope: config.APITokenScopeReadBalances, allAccounts: true},
+	"GET /accounts/total-balance":           {scope: config.APITokenScopeReadBalances, allAccounts: true},
+	"GET /accounts/coins-balance":           {scope: config.APITokenScopeReadBalances, allAccounts: true},
+	"GET /account-summary":                  {scope: config.APITokenScopeReadBalances, allAccounts: true},
+	"GET /account/{code}/status":            {scope: config.APITokenScopeReadBalances},
+	"GET /account/{code}/balance":           {scope: config.APITokenScopeReadBalances},
+	"GET /address-book":                     {scope: config.APITokenScopeReadTransactions, allAccounts: true},
+	"GET /account/{code}/info":              {scope: config.APITokenScopeReadTransactions},
+	"GET /account/{code}/utxos":             {scope: config.APITokenScopeReadTransactions},
+	"GET /account/{code}/transaction":       {scope: config.APITokenScopeReadTransactions},
+	"GET /account/{code}/transactions":      {scope: config.APITokenScopeReadTransactions},
+	"GET /account/{code}/receive-addresses": {scope: config.APITokenScopeReadTransactions},
+	"POST /account/{code}/receive-uri":      {scope: config.APITokenScopeReadTransactions},
+
+	"GET /account/{code}/fee-targets":                 {scope: config.APITokenScopeCreateProposals},
+	"GET /account/{code}/has-secure-output":           {scope: config.APITokenScopeCreateProposals},
+	"GET /account/{code}/has-payment-request":         {scope: config.APITokenScopeCreateProposals},
+	"POST /account/{code}/tx-proposal":                {scope: config.APITokenScopeCreateProposals},
+	"POST /account/{code}/psbt/export":                {scope: config.APITokenScopeCreateProposals},
+	"POST /account/{code}/sweep/proposal":             {scope: config.APITokenScopeCreateProposals},
+	"POST /account/{code}/sendtx":                     {scope: config.APITokenScopeSign},
+	"POST /account/{code}/psbt/sign":                  {scope: config.APITokenScopeSign},
+	"POST /account/{code}/psbt/broadcast":             {scope: config.APITokenScopeSign},
+	"POST /account/{code}/bump-fee":                   {scope: config.APITokenScopeSign},
+	"POST /account/{code}/cpfp":                       {scope: config.APITokenScopeSign},
+	"POST /account/{code}/sweep/send":                 {scope: config.APITokenScopeSign},
+	"POST /account/{code}/eth-speed-up-tx":            {scope: config.APITokenScopeSign},
+	"POST /account/{code}/eth-cancel-tx":              {scope: config.APITokenScopeSign},
+	"POST /account/{code}/eth-sign-msg":               {scope: config.APITokenScopeSign},
+	"POST /account/{code}/eth-sign-typed-msg":         {scope: config.APITokenScopeSign},
+	"POST /account/{code}/sign-address":               {scope: config.APITokenScopeSign},
+	"POST /account/{code}/eth-sign-wallet-connect-tx": {scope: config.APITokenScopeSign},
+}
+
+// apiRouteScopeOf returns the scope of the matched route of the unversioned API, and the account
+// code for account routes.
+func apiRouteScopeOf(r *http.Request) (apiRouteScope, accountsTypes.Code) {
+	adminScope := apiRouteScope{scope: config.APITokenScopeAdmin}
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return adminScope, ""
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return adminScope, ""
+	}
+	key, accountCode := apiRouteScopeKey(r.Method, template)
+	routeScope, ok := apiRouteScopes[key]
+	if !ok {
+		return adminScope, accountCode
+	}
+	return routeScope, accountCode
+}
+
+// apiRouteScopeKey returns the key in apiRouteScopes of the route with the given method and path
+// template, and the account code for account routes.
+func apiRouteScopeKey(method string, template string) (string, accountsTypes.Code) {
+	path := strings.TrimPrefix(template, "/api")
+	var accountCode accountsTypes.Code
+	if rest, ok := strings.CutPrefix(path, "/account/"); ok {
+		code, suffix, _ := strings.Cut(rest, "/")
+		accountCode = accountsTypes.Code(code)
+		path = "/account/{code}/" + suffix
+	}
+	return method + " " + path, accountCode
+}
+
+// apiAuthError is the reason a request is not authorized, with the HTTP status code to respond
+// with.
+type apiAuthError struct {
+	status  int
+	message string
+}
+
+// authenticateAPIRequest checks whether we are in dev or prod mode and, if we are in prod mode,
+// verifies that an authorization token is received as an HTTP Authorization header and that it is
+// valid. It returns the scoped API token used, or nil if the request has full access, i.e. with
+// the token of the app, or without a token in dev mode. Scoped API tokens are checked in dev mode
+// too, so that they can be tried out with servewallet.
+func (handlers *Handlers) authenticateAPIRequest(r *http.Request) (*config.APIToken, *apiAuthError) {
+	methodLogEntry := handlers.log.
+		WithField("path", r.URL.Path).
+		WithField("method", r.Method)
+	methodLogEntry.Debug("endpoint")
+	authorization := r.Header.Get("Authorization")
+	// In dev mode, we allow unauthorized requests
+	if handlers.apiData.devMode && len(authorization) == 0 {
+		return nil, nil
+	}
+	if len(authorization) == 0 {
+		methodLogEntry.Error("Missing token in API request. WARNING: this could be an attack on the API")
+		return nil, &apiAuthError{status: http.StatusUnauthorized, message: "missing token " + r.URL.Path}
+	}
+	if authorization == "Basic "+handlers.apiData.token {
+		return nil, nil
+	}
+	secret, ok := strings.CutPrefix(authorization, "Basic ")
+	var token *config.APIToken
+	if ok {
+		token = handlers.backend.LookupAPIToken(secret)
+	}
+	if token == nil {
+		methodLogEntry.Error("Incorrect token in API request. WARNING: this could be an attack on the API")
+		return nil, &apiAuthError{status: http.StatusUnauthorized, message: "incorrect token"}
+	}
+	if token.Expired(time.Now()) {
+		methodLogEntry.WithField("token", token.ID).Error("Expired token in API request")
+		return nil, &apiAuthError{status: http.StatusUnauthorized, message: "expired token"}
+	}
+	return token, nil
+}
+
+// authorizeAPIToken checks that the scoped API token can access a route with the given scope. The
+// account code is the account accessed by the route, if any. A nil token has full access.
+func authorizeAPIToken(
+	token *config.APIToken, routeScope apiRouteScope, accountCode accountsTypes.Code,
+) *apiAuthError {
+	if token == nil || (routeScope.scope == apiScopeAny && !routeScope.allAccounts) {
+		return nil
+	}
+	if routeScope.scope != apiScopeAny && !token.HasScope(routeScope.scope) {
+		return &apiAuthError{
+			status:  http.StatusForbidden,
+			message: "the token does not have the scope " + string(routeScope.scope),
+		}
+	}
+	if token.HasScope(config.APITokenScopeAdmin) {
+		return nil
+	}
+	if (routeScope.allAccounts && len(token.AccountCodes) != 0) ||
+		(accountCode != "" && !token.AllowsAccount(accountCode)) {
+		return &apiAuthError{
+			status:  http.StatusForbidden,
+			message: "the token does not have access to all requested accounts",
+		}
+	}
+	return nil
+}
+
+type apiTokenContextKey struct{}
+
+// withAPIToken returns the request with the scoped API token used to authenticate it.
+func withAPIToken(r *http.Request, token *config.APIToken) *http.Request {
+	if token == nil {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), apiTokenContextKey{}, token))
+}
+
+// apiTokenFromRequest returns the scoped API token used to authenticate the request, or nil if the
+// request has full access.
+func apiTokenFromRequest(r *http.Request) *config.APIToken {
+	token, _ := r.Context().Value(apiTokenContextKey{}).(*config.APIToken)
+	return token
+}
+
+// apiTokenInfo is an API token as returned to the frontend, without its hash.
+type apiTokenInfo struct {
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Scopes       []config.APITokenScope `json:"scopes"`
+	AccountCodes []accountsTypes.Code   `json:"accountCodes"`
+	Created      time.Time              `json:"created"`
+	Expires      *time.Time             `json:"expires"`
+	Expired      bool                   `json:"expired"`
+}
+
+func newAPITokenInfo(token *config.APIToken) *apiTokenInfo {
+	return &apiTokenInfo{
+		ID:           token.ID,
+		Name:         token.Name,
+		Scopes:       token.Scopes,
+		AccountCodes: token.AccountCodes,
+		Created:      token.Created,
+		Expires:      token.Expires,
+		Expired:      token.Expired(time.Now()),
+	}
+}
+
+func (handlers *Handlers) getAPITokens(*http.Request) interface{} {
+	result := []*apiTokenInfo{}
+	for _, token := range handlers.backend.APITokens() {
+		result = append(result, newAPITokenInfo(&token))
+	}
+	return result
+}
+
+func (handlers *Handlers) postCreateAPIToken(r *http.Request) interface{} {
+	type response struct {
+		Success      bool          `json:"success"`
+		Token        string        `json:"token,omitempty"`
+		APIToken     *apiTokenInfo `json:"apiToken,omitempty"`
+		ErrorMessage string        `json:"errorMessage,omitempty"`
+	}
+	var jsonBody struct {
+		Name         string                 `json:"name"`
+		Scopes       []config.APITokenScope `json:"scopes"`
+		AccountCodes []accountsTypes.Code   `json:"accountCodes"`
+		Expires      *time.Time             `json:"expires"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{ErrorMessage: errp.WithStack(err).Error()}
+	}
+	secret, token, err := handlers.backend.CreateAPIToken(
+		jsonBody.Name, jsonBody.Scopes, jsonBody.AccountCodes, jsonBody.Expires)
+	if err != nil {
+		return response{ErrorMessage: err.Error()}
+	}
+	return response{Success: true, Token: secret, APIToken: newAPITokenInfo(token)}
+}
+
+func (handlers *Handlers) postRevokeAPIToken(r *http.Request) interface{} {
+	type response struct {
+		Success      bool   `json:"success"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+	var jsonBody struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{ErrorMessage: errp.WithStack(err).Error()}
+	}
+	if err := handlers.backend.RevokeAPIToken(jsonBody.ID); err != nil {
+		return response{ErrorMessage: err.Error()}
+	}
+	return response{Success: true}
+}
@@ -0,0 +1,215 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/handlers"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+// apiTokensBackend initializes the account handlers, so that the account routes are registered.
+type apiTokensBackend struct {
+	*apiV1Backend
+}
+
+func (b *apiTokensBackend) OnAccountInit(f func(accounts.Interface)) {
+	for _, account := range b.accounts {
+		f(account)
+	}
+}
+
+// newAPITokensBackend returns a backend with API tokens of various scopes, whose token is the hash.
+func newAPITokensBackend() *apiTokensBackend {
+	expired := time.Now().Add(-time.Hour)
+	b := newAPIV1Backend()
+	b.apiTokens = []config.APIToken{
+		{
+			ID:     "dashboard",
+			Hash:   "dashboard-token",
+			Scopes: []config.APITokenScope{config.APITokenScopeReadBalances, config.APITokenScopeReadTransactions},
+		},
+		{
+			ID:           "restricted",
+			Hash:         "restricted-token",
+			Scopes:       []config.APITokenScope{config.APITokenScopeReadBalances},
+			AccountCodes: []accountsTypes.Code{"btc"},
+		},
+		{
+			ID:      "expired",
+			Hash:    "expired-token",
+			Scopes:  []config.APITokenScope{config.APITokenScopeAdmin},
+			Expires: &expired,
+		},
+		{
+			ID:     "admin",
+			Hash:   "admin-token",
+			Scopes: []config.APITokenScope{config.APITokenScopeAdmin},
+		},
+	}
+	return &apiTokensBackend{b}
+}
+
+// apiTokensRequest returns a function making a request to the handler with the token.
+func apiTokensRequest(t *testing.T, h http.Handler) func(token, method, path string) int {
+	t.Helper()
+	return func(token, method, path string) int {
+		t.Helper()
+		r := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Basic "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+}
+
+func TestAPITokenScopes(t *testing.T) {
+	h := handlers.NewHandlers(newAPITokensBackend(), handlers.NewConnectionData(8082, "token"))
+	request := apiTokensRequest(t, h.Router)
+
+	tests := []struct {
+		token  string
+		method string
+		path   string
+		status int
+	}{
+		{"", "GET", "/api/version", http.StatusUnauthorized},
+		{"wrong", "GET", "/api/version", http.StatusUnauthorized},
+		{"expired-token", "GET", "/api/version", http.StatusUnauthorized},
+		{"token", "GET", "/api/api-tokens", http.StatusOK},
+		{"admin-token", "GET", "/api/api-tokens", http.StatusOK},
+		{"dashboard-token", "GET", "/api/api-tokens", http.StatusForbidden},
+		{"dashboard-token", "GET", "/api/version", http.StatusOK},
+		{"dashboard-token", "GET", "/api/account/btc/status", http.StatusOK},
+		{"dashboard-token", "POST", "/api/account/btc/sendtx", http.StatusForbidden},
+		{"dashboard-token", "POST", "/api/account/btc/tx-proposal", http.StatusForbidden},
+		{"dashboard-token", "POST", "/api/account/btc/init", http.StatusForbidden},
+		{"restricted-token", "GET", "/api/account/btc/status", http.StatusOK},
+		{"restricted-token", "GET", "/api/account/unsynced/status", http.StatusForbidden},
+		{"restricted-token", "GET", "/api/accounts/total-balance", http.StatusForbidden},
+		{"restricted-token", "GET", "/api/keystores", http.StatusForbidden},
+		{"restricted-token", "GET", "/api/address-book", http.StatusForbidden},
+
+		{"", "GET", "/api/v1/version", http.StatusUnauthorized},
+		{"expired-token", "GET", "/api/v1/version", http.StatusUnauthorized},
+		{"restricted-token", "GET", "/api/v1/version", http.StatusOK},
+		{"dashboard-token", "GET", "/api/v1/devices", http.StatusForbidden},
+		{"admin-token", "GET", "/api/v1/devices", http.StatusOK},
+		{"dashboard-token", "GET", "/api/v1/accounts/btc/transactions", http.StatusOK},
+		{"dashboard-token", "POST", "/api/v1/accounts/btc/transactions/tx1/note", http.StatusForbidden},
+		{"restricted-token", "GET", "/api/v1/accounts/btc/balance", http.StatusOK},
+		{"restricted-token", "GET", "/api/v1/accounts/btc/transactions", http.StatusForbidden},
+		{"restricted-token", "GET", "/api/v1/accounts/unsynced/status", http.StatusForbidden},
+		{"restricted-token", "GET", "/api/v1/keystores", http.StatusForbidden},
+		{"restricted-token", "GET", "/api/v1/address-book?coinCode=btc", http.StatusForbidden},
+		{"dashboard-token", "GET", "/api/v1/address-book?coinCode=btc", http.StatusOK},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.status, request(test.token, test.method, test.path),
+			"%s %s %s", test.token, test.method, test.path)
+	}
+
+	// Tokens restricted to some accounts only see these accounts.
+	r := httptest.NewRequest("GET", "/api/v1/accounts", nil)
+	r.Header.Set("Authorization", "Basic restricted-token")
+	w := httptest.NewRecorder()
+	h.Router.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t,
+		`{"accounts": [{"code": "btc", "name": "btc", "coinCode": "btc", "coinName": "Bitcoin", "coinUnit": "BTC",
+		"active": true, "watchonly": false, "rootFingerprint": ""}]}`,
+		w.Body.String())
+}
+
+// TestAPITokenScopesDevMode checks that requests without a token have full access in dev mode,
+// while scoped tokens are still checked.
+func TestAPITokenScopesDevMode(t *testing.T) {
+	h := handlers.NewHandlers(newAPITokensBackend(), handlers.NewConnectionData(-1, ""))
+	request := apiTokensRequest(t, h.Router)
+
+	tests := []struct {
+		token  string
+		method string
+		path   string
+		status int
+	}{
+		{"", "GET", "/api/api-tokens", http.StatusOK},
+		{"", "GET", "/api/v1/devices", http.StatusOK},
+		{"wrong", "GET", "/api/version", http.StatusUnauthorized},
+		{"expired-token", "GET", "/api/version", http.StatusUnauthorized},
+		{"admin-token", "GET", "/api/api-tokens", http.StatusOK},
+		{"dashboard-token", "GET", "/api/api-tokens", http.StatusForbidden},
+		{"dashboard-token", "GET", "/api/v1/devices", http.StatusForbidden},
+		{"dashboard-token", "GET", "/api/v1/accounts/btc/transactions", http.StatusOK},
+		{"restricted-token", "GET", "/api/keystores", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.status, request(test.token, test.method, test.path),
+			"%s %s %s", test.token, test.method, test.path)
+	}
+}
+
+func TestLocalAPIServer(t *testing.T) {
+	h := handlers.NewHandlers(newAPITokensBackend(), handlers.NewConnectionData(-1, ""))
+	_, err := h.NewLocalAPIServer(8085)
+	require.Error(t, err)
+
+	h = handlers.NewHandlers(newAPITokensBackend(), handlers.NewConnectionData(8085, "token"))
+	server, err := h.NewLocalAPIServer(8085)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:8085", server.Addr)
+	request := apiTokensRequest(t, server.Handler)
+	require.Equal(t, http.StatusUnauthorized, request("", "GET", "/api/version"))
+	require.Equal(t, http.StatusOK, request("token", "GET", "/api/version"))
+	require.Equal(t, http.StatusOK, request("dashboard-token", "GET", "/api/v1/accounts/btc/transactions"))
+	require.Equal(t, http.StatusForbidden, request("dashboard-token", "GET", "/api/api-tokens"))
+	require.Equal(t, http.StatusNotFound, request("token", "GET", "/api/events"))
+}
+
+// TestAPIRouteScopesRegistered checks that the scopes of the unversioned API are declared for
+// registered routes, so that a renamed route does not silently fall back to the admin scope.
+func TestAPIRouteScopesRegistered(t *testing.T) {
+	h := handlers.NewHandlers(&apiTokensBackend{newAPIV1Backend()}, handlers.NewConnectionData(8082, "token"))
+	registered := map[string]bool{}
+	require.NoError(t, h.Router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(template, "/api/") {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			key, _ := handlers.APIRouteScopeKey(method, template)
+			registered[key] = true
+		}
+		return nil
+	}))
+	for _, key := range handlers.APIRouteScopeKeys() {
+		require.True(t, registered[key], "%s is not a registered route", key)
+	}
+}
@@ -0,0 +1,772 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// The versioned API is a stable subset of the API for third-party integrations. Unlike the
+// unversioned API used by the frontend, the responses are documented by an OpenAPI document
+// generated from the route registrations below, and errors are returned with a 4xx/5xx status
+// code and an apiV1ErrorResponse body. Fields must not be renamed or removed, and their types
+// must not change. New fields can be added.
+
+const (
+	apiV1Prefix = "/api/v1"
+	// apiV1Version is the version of the versioned API in the OpenAPI document. Bump the minor
+	// version when adding endpoints or fields.
+	apiV1Version = "1.1.0"
+
+	apiV1DefaultLimit = 100
+	apiV1MaxLimit     = 500
+)
+
+// apiV1ErrorCode identifies the kind of error of a failed request.
+type apiV1ErrorCode string
+
+const (
+	apiV1ErrorBadRequest       apiV1ErrorCode = "badRequest"
+	apiV1ErrorUnauthorized     apiV1ErrorCode = "unauthorized"
+	apiV1ErrorForbidden        apiV1ErrorCode = "forbidden"
+	apiV1ErrorNotFound         apiV1ErrorCode = "notFound"
+	apiV1ErrorMethodNotAllowed apiV1ErrorCode = "methodNotAllowed"
+	apiV1ErrorNotSynced        apiV1ErrorCode = "notSynced"
+	apiV1ErrorInternal         apiV1ErrorCode = "internal"
+)
+
+// apiV1ErrorStatus is the HTTP status code of each error code.
+var apiV1ErrorStatus = map[apiV1ErrorCode]int{
+	apiV1ErrorBadRequest:       http.StatusBadRequest,
+	apiV1ErrorUnauthorized:     http.StatusUnauthorized,
+	apiV1ErrorForbidden:        http.StatusForbidden,
+	apiV1ErrorNotFound:         http.StatusNotFound,
+	apiV1ErrorMethodNotAllowed: http.StatusMethodNotAllowed,
+	apiV1ErrorNotSynced:        http.StatusServiceUnavailable,
+	apiV1ErrorInternal:         http.StatusInternalServerError,
+}
+
+// apiV1Error is the error returned by the handlers of the versioned API. Other errors are
+// returned as internal errors.
+type apiV1Error struct {
+	code    apiV1ErrorCode
+	message string
+}
+
+func newAPIV1Error(code apiV1ErrorCode, format string, args ...interface{}) *apiV1Error {
+	return &apiV1Error{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// Error implements error.
+func (err *apiV1Error) Error() string {
+	return err.message
+}
+
+// apiV1PathParams documents the path parameters used in the routes.
+var apiV1PathParams = map[string]string{
+	"code":       "Account code, as returned by /accounts.",
+	"internalID": "Internal ID of the transaction, as returned by /accounts/{code}/transactions.",
+}
+
+// apiV1QueryParam is a query parameter of a route.
+type apiV1QueryParam struct {
+	name        string
+	description string
+	schema      *openAPISchema
+}
+
+// apiV1Route is an endpoint of the versioned API. It is used both to register the handler and to
+// generate its documentation.
+type apiV1Route struct {
+	method      string
+	path        string
+	operationID string
+	summary     string
+	// scope is the scope an API token needs to access the route.
+	scope config.APITokenScope
+	// allAccounts is true if the route returns data of all accounts, so that tokens restricted to
+	// some accounts can't access it.
+	allAccounts bool
+	queryParams []apiV1QueryParam
+	// request is a value of the type of the JSON request body, nil if there is none.
+	request interface{}
+	// response is a value of the type returned by the handler on success, nil for a free-form
+	// object.
+	response interface{}
+	// errors are the status codes of the errors returned by the handler, besides 401, 403 and
+	// 500, which all endpoints can return.
+	errors  []int
+	handler func(*http.Request) (interface{}, error)
+}
+
+type apiV1ErrorResponse struct {
+	Error apiV1ErrorObject `json:"error"`
+}
+
+type apiV1ErrorObject struct {
+	Code    apiV1ErrorCode `json:"code" enum:"badRequest,unauthorized,forbidden,notFound,methodNotAllowed,notSynced,internal" description:"Machine-readable error code."`
+	Message string         `json:"message" description:"Human-readable error message, not meant to be parsed."`
+}
+
+type apiV1VersionInfo struct {
+	Version    string `json:"version" description:"Version of the BitBoxApp."`
+	APIVersion string `json:"apiVersion" description:"Version of this API."`
+}
+
+type apiV1Keystores struct {
+	Keystores []*apiV1Keystore `json:"keystores"`
+}
+
+type apiV1Keystore struct {
+	Type            string `json:"type" enum:"hardware,software"`
+	RootFingerprint string `json:"rootFingerprint" description:"Hex-encoded root fingerprint."`
+}
+
+type apiV1Devices struct {
+	Devices []*apiV1Device `json:"devices"`
+}
+
+type apiV1Device struct {
+	ID          string `json:"id"`
+	ProductName string `json:"productName"`
+}
+
+type apiV1Accounts struct {
+	Accounts []*apiV1Account `json:"accounts"`
+}
+
+type apiV1Account struct {
+	Code            accountsTypes.Code `json:"code"`
+	Name            string             `json:"name"`
+	CoinCode        coinpkg.Code       `json:"coinCode"`
+	CoinName        string             `json:"coinName"`
+	CoinUnit        string             `json:"coinUnit"`
+	Active          bool               `json:"active" description:"Only active accounts are loaded."`
+	Watchonly       bool               `json:"watchonly" description:"Loaded even if the keystore is not connected."`
+	RootFingerprint string             `json:"rootFingerprint" description:"Hex-encoded root fingerprint of the keystore of the account."`
+}
+
+type apiV1AccountStatus struct {
+	Synced       bool    `json:"synced" description:"True once the account finished the initial sync."`
+	OfflineError *string `json:"offlineError" description:"The network error if the account is offline."`
+	FatalError   bool    `json:"fatalError" description:"True if the account failed and is unusable."`
+}
+
+type apiV1Amount struct {
+	Amount string `json:"amount" description:"Decimal amount, formatted in the unit."`
+	Unit   string `json:"unit"`
+}
+
+type apiV1Balance struct {
+	Available apiV1Amount `json:"available"`
+	Incoming  apiV1Amount `json:"incoming" description:"Sum of the unconfirmed incoming transactions."`
+}
+
+type apiV1Transactions struct {
+	Transactions []*apiV1Transaction `json:"transactions" description:"Newest first."`
+	NextCursor   *string             `json:"nextCursor" description:"Cursor of the next page, null on the last page."`
+}
+
+type apiV1Transaction struct {
+	InternalID               string          `json:"internalID" description:"Unique within the account. Usually the same as the txID."`
+	TxID                     string          `json:"txID"`
+	Type                     accounts.TxType `json:"type" enum:"receive,send,sendSelf"`
+	Status                   string          `json:"status" enum:"pending,complete,failed"`
+	Amount                   apiV1Amount     `json:"amount" description:"Amount received or sent, not including the fee."`
+	Fee                      *apiV1Amount    `json:"fee" description:"Null for incoming transactions."`
+	Time                     *time.Time      `json:"time" description:"Time of confirmation, or of creation if unconfirmed. Null if unknown."`
+	NumConfirmations         int             `json:"numConfirmations"`
+	NumConfirmationsComplete int             `json:"numConfirmationsComplete" description:"Confirmations needed for the status to become complete."`
+	Addresses                []string        `json:"addresses" description:"Addresses the funds were sent to or received on."`
+	Note                     string          `json:"note"`
+}
+
+type apiV1TransactionNote struct {
+	Note string `json:"note"`
+}
+
+type apiV1ReceiveAddresses struct {
+	AddressLists []*apiV1AddressList `json:"addressLists" description:"One list per address type."`
+}
+
+type apiV1AddressList struct {
+	ScriptType *signing.ScriptType `json:"scriptType" description:"Bitcoin script type, null for other coins."`
+	Addresses  []*apiV1Address     `json:"addresses"`
+}
+
+type apiV1Address struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+type apiV1Contacts struct {
+	Contacts []*apiV1Contact `json:"contacts"`
+}
+
+type apiV1Contact struct {
+	ID         string       `json:"id"`
+	CoinCode   coinpkg.Code `json:"coinCode"`
+	Name       string       `json:"name"`
+	Address    string       `json:"address"`
+	ERC20Token coinpkg.Code `json:"erc20Token" description:"Code of the ERC20 token the contact is restricted to, empty for all."`
+	Verified   bool         `json:"verified" description:"True if the address was confirmed by a signed message."`
+}
+
+// apiV1Routes returns the endpoints of the versioned API.
+func (handlers *Handlers) apiV1Routes() []*apiV1Route {
+	return []*apiV1Route{
+		{
+			method: "GET", path: "/openapi.json", operationID: "getOpenAPI",
+			summary: "OpenAPI document of this API",
+			scope:   apiScopeAny,
+		},
+		{
+			method: "GET", path: "/version", operationID: "getVersion",
+			summary:  "Version of the app and of this API",
+			scope:    apiScopeAny,
+			response: apiV1VersionInfo{},
+			handler:  handlers.getAPIV1Version,
+		},
+		{
+			method: "GET", path: "/keystores", operationID: "getKeystores",
+			summary:     "Connected keystores",
+			scope:       config.APITokenScopeReadBalances,
+			allAccounts: true,
+			response:    apiV1Keystores{},
+			handler:     handlers.getAPIV1Keystores,
+		},
+		{
+			method: "GET", path: "/devices", operationID: "getDevices",
+			summary:  "Connected devices",
+			scope:    config.APITokenScopeAdmin,
+			response: apiV1Devices{},
+			handler:  handlers.getAPIV1Devices,
+		},
+		{
+			method: "GET", path: "/accounts", operationID: "getAccounts",
+			summary:  "Accounts, including inactive ones",
+			scope:    config.APITokenScopeReadBalances,
+			response: apiV1Accounts{},
+			handler:  handlers.getAPIV1Accounts,
+		},
+		{
+			method: "GET", path: "/accounts/{code}", operationID: "getAccount",
+			summary:  "Active account",
+			scope:    config.APITokenScopeReadBalances,
+			response: apiV1Account{},
+			errors:   []int{http.StatusNotFound},
+			handler:  handlers.getAPIV1Account,
+		},
+		{
+			method: "GET", path: "/accounts/{code}/status", operationID: "getAccountStatus",
+			summary:  "Sync and connection status of an account",
+			scope:    config.APITokenScopeReadBalances,
+			response: apiV1AccountStatus{},
+			errors:   []int{http.StatusNotFound},
+			handler:  handlers.getAPIV1AccountStatus,
+		},
+		{
+			method: "GET", path: "/accounts/{code}/balance", operationID: "getAccountBalance",
+			summary:  "Balance of an account",
+			scope:    config.APITokenScopeReadBalances,
+			response: apiV1Balance{},
+			errors:   []int{http.StatusNotFound, http.StatusServiceUnavailable},
+			handler:  handlers.getAPIV1AccountBalance,
+		},
+		{
+			method: "GET", path: "/accounts/{code}/transactions", operationID: "getAccountTransactions",
+			summary: "Transactions of an account, newest first, paginated",
+			scope:   config.APITokenScopeReadTransactions,
+			queryParams: []apiV1QueryParam{
+				{
+					name:        "limit",
+					description: fmt.Sprintf("Maximum number of transactions to return, %d by default.", apiV1DefaultLimit),
+					schema:      &openAPISchema{Type: "integer", Minimum: intPtr(1), Maximum: intPtr(apiV1MaxLimit)},
+				},
+				{
+					name:        "cursor",
+					description: "The nextCursor of the previous page. Omit to get the first page.",
+					schema:      &openAPISchema{Type: "string"},
+				},
+			},
+			response: apiV1Transactions{},
+			errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusServiceUnavailable},
+			handler:  handlers.getAPIV1AccountTransactions,
+		},
+		{
+			method: "GET", path: "/accounts/{code}/transactions/{internalID}", operationID: "getAccountTransaction",
+			summary:  "Transaction of an account",
+			scope:    config.APITokenScopeReadTransactions,
+			response: apiV1Transaction{},
+			errors:   []int{http.StatusNotFound, http.StatusServiceUnavailable},
+			handler:  handlers.getAPIV1AccountTransaction,
+		},
+		{
+			method: "POST", path: "/accounts/{code}/transactions/{internalID}/note", operationID: "setTransactionNote",
+			summary:  "Set the note of a transaction",
+			scope:    config.APITokenScopeAdmin,
+			request:  apiV1TransactionNote{},
+			response: apiV1Transaction{},
+			errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusServiceUnavailable},
+			handler:  handlers.postAPIV1TransactionNote,
+		},
+		{
+			method: "GET", path: "/accounts/{code}/receive-addresses", operationID: "getReceiveAddresses",
+			summary:  "Unused receive addresses of an account",
+			scope:    config.APITokenScopeReadTransactions,
+			response: apiV1ReceiveAddresses{},
+			errors:   []int{http.StatusNotFound, http.StatusServiceUnavailable},
+			handler:  handlers.getAPIV1ReceiveAddresses,
+		},
+		{
+			method: "GET", path: "/address-book", operationID: "getContacts",
+			summary:     "Contacts of the address book",
+			scope:       config.APITokenScopeReadTransactions,
+			allAccounts: true,
+			queryParams: []apiV1QueryParam{
+				{
+					name:        "coinCode",
+					description: "Coin code of the contacts, e.g. btc. Required.",
+					schema:      &openAPISchema{Type: "string"},
+				},
+			},
+			response: apiV1Contacts{},
+			errors:   []int{http.StatusBadRequest},
+			handler:  handlers.getAPIV1Contacts,
+		},
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// registerAPIV1 registers the versioned API and its OpenAPI document at /api/v1.
+func (handlers *Handlers) registerAPIV1(router *mux.Router, connData *ConnectionData, log *logrus.Entry) {
+	routes := handlers.apiV1Routes()
+	document := newOpenAPIDocument(apiV1Version, routes)
+	// The routes are registered per path and dispatched by method here, as the method not allowed
+	// handler of gorilla/mux is not called for subrouters.
+	paths := []string{}
+	methods := map[string]map[string]*apiV1Route{}
+	for _, route := range routes {
+		if methods[route.path] == nil {
+			paths = append(paths, route.path)
+			methods[route.path] = map[string]*apiV1Route{}
+		}
+		methods[route.path][route.method] = route
+	}
+	for _, path := range paths {
+		pathMethods := methods[path]
+		router.Handle(path, handlers.apiV1Middleware(connData, log, func(r *http.Request) (interface{}, error) {
+			route, ok := pathMethods[r.Method]
+			if !ok {
+				return nil, newAPIV1Error(apiV1ErrorMethodNotAllowed, "method %s not allowed", r.Method)
+			}
+			authErr := authorizeAPIToken(
+				apiTokenFromRequest(r),
+				apiRouteScope{scope: route.scope, allAccounts: route.allAccounts},
+				accountsTypes.Code(mux.Vars(r)["code"]))
+			if authErr != nil {
+				return nil, newAPIV1Error(apiV1ErrorForbidden, "%s", authErr.message)
+			}
+			if route.handler == nil {
+				return document, nil
+			}
+			return route.handler(r)
+		}))
+	}
+	router.NotFoundHandler = handlers.apiV1Middleware(connData, log, func(r *http.Request) (interface{}, error) {
+		return nil, newAPIV1Error(apiV1ErrorNotFound, "unknown endpoint %s", r.URL.Path)
+	})
+}
+
+// apiV1Middleware checks the API token and writes the result of the handler, or the error with its
+// status code.
+func (handlers *Handlers) apiV1Middleware(
+	connData *ConnectionData,
+	log *logrus.Entry,
+	h func(*http.Request) (interface{}, error),
+) http.Handler {
+	writeError := func(w http.ResponseWriter, err *apiV1Error) {
+		w.WriteHeader(apiV1ErrorStatus[err.code])
+		writeJSON(w, apiV1ErrorResponse{Error: apiV1ErrorObject{Code: err.code, Message: err.message}})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if connData.isDev() {
+			setDevCORSHeader(w)
+		}
+		token, authErr := handlers.authenticateAPIRequest(r)
+		if authErr != nil {
+			writeError(w, newAPIV1Error(apiV1ErrorUnauthorized, "%s", authErr.message))
+			return
+		}
+		r = withAPIToken(r, token)
+		defer func() {
+			if r := recover(); r != nil {
+				handlers.log.WithField("panic", true).Errorf("%v\n%s", r, string(debug.Stack()))
+				writeError(w, newAPIV1Error(apiV1ErrorInternal, "%v", r))
+			}
+		}()
+		value, err := h(r)
+		if err != nil {
+			v1Err, ok := err.(*apiV1Error)
+			if !ok {
+				handlers.log.WithError(err).WithField("path", r.URL.Path).Error("endpoint failed")
+				v1Err = newAPIV1Error(apiV1ErrorInternal, "%s", err.Error())
+			}
+			writeError(w, v1Err)
+			return
+		}
+		writeJSON(w, value)
+	})
+}
+
+func (handlers *Handlers) getAPIV1Version(*http.Request) (interface{}, error) {
+	return apiV1VersionInfo{Version: backend.Version.String(), APIVersion: apiV1Version}, nil
+}
+
+func (handlers *Handlers) getAPIV1Keystores(*http.Request) (interface{}, error) {
+	result := apiV1Keystores{Keystores: []*apiV1Keystore{}}
+	keystore := handlers.backend.Keystore()
+	if keystore == nil {
+		return result, nil
+	}
+	rootFingerprint, err := keystore.RootFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	result.Keystores = append(result.Keystores, &apiV1Keystore{
+		Type:            string(keystore.Type()),
+		RootFingerprint: hex.EncodeToString(rootFingerprint),
+	})
+	return result, nil
+}
+
+func (handlers *Handlers) getAPIV1Devices(*http.Request) (interface{}, error) {
+	result := apiV1Devices{Devices: []*apiV1Device{}}
+	for deviceID, device := range handlers.backend.DevicesRegistered() {
+		result.Devices = append(result.Devices, &apiV1Device{ID: deviceID, ProductName: device.ProductName()})
+	}
+	sort.Slice(result.Devices, func(i, j int) bool { return result.Devices[i].ID < result.Devices[j].ID })
+	return result, nil
+}
+
+func newAPIV1Account(account accounts.Interface) *apiV1Account {
+	persistedAccount := account.Config().Config
+	rootFingerprint, err := persistedAccount.SigningConfigurations.RootFingerprint()
+	if err != nil {
+		rootFingerprint = nil
+	}
+	return &apiV1Account{
+		Code:            persistedAccount.Code,
+		Name:            persistedAccount.Name,
+		CoinCode:        account.Coin().Code(),
+		CoinName:        account.Coin().Name(),
+		CoinUnit:        account.Coin().Unit(false),
+		Active:          !persistedAccount.Inactive,
+		Watchonly:       persistedAccount.Watch != nil && *persistedAccount.Watch,
+		RootFingerprint: hex.EncodeToString(rootFingerprint),
+	}
+}
+
+func (handlers *Handlers) getAPIV1Accounts(r *http.Request) (interface{}, error) {
+	result := apiV1Accounts{Accounts: []*apiV1Account{}}
+	token := apiTokenFromRequest(r)
+	for _, account := range handlers.backend.Accounts() {
+		if account.Config().Config.HiddenBecauseUnused {
+			continue
+		}
+		if token != nil && !token.AllowsAccount(account.Config().Config.Code) {
+			continue
+		}
+		result.Accounts = append(result.Accounts, newAPIV1Account(account))
+	}
+	return result, nil
+}
+
+// lookupAPIV1Account returns the active account of the `code` path parameter.
+func (handlers *Handlers) lookupAPIV1Account(r *http.Request) (accounts.Interface, error) {
+	code := accountsTypes.Code(mux.Vars(r)["code"])
+	found := false
+	for _, account := range handlers.backend.Accounts() {
+		config := account.Config().Config
+		if config.Code == code && !config.Inactive && !config.HiddenBecauseUnused {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, newAPIV1Error(apiV1ErrorNotFound, "unknown or inactive account %q", code)
+	}
+	return handlers.backend.GetAccountFromCode(code)
+}
+
+// lookupAPIV1SyncedAccount is like lookupAPIV1Account, but fails if the account has not finished the initial
+// sync.
+func (handlers *Handlers) lookupAPIV1SyncedAccount(r *http.Request) (accounts.Interface, error) {
+	account, err := handlers.lookupAPIV1Account(r)
+	if err != nil {
+		return nil, err
+	}
+	if !account.Synced() {
+		return nil, newAPIV1Error(apiV1ErrorNotSynced, "account %q is not synced yet", account.Config().Config.Code)
+	}
+	return account, nil
+}
+
+func (handlers *Handlers) getAPIV1Account(r *http.Request) (interface{}, error) {
+	account, err := handlers.lookupAPIV1Account(r)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIV1Account(account), nil
+}
+
+func (handlers *Handlers) getAPIV1AccountStatus(r *http.Request) (interface{}, error) {
+	account, err := handlers.lookupAPIV1Account(r)
+	if err != nil {
+		return nil, err
+	}
+	var offlineError *string
+	if err := account.Offline(); err != nil {
+		message := err.Error()
+		offlineError = &message
+	}
+	return apiV1AccountStatus{
+		Synced:       account.Synced(),
+		OfflineError: offlineError,
+		FatalError:   account.FatalError(),
+	}, nil
+}
+
+func newAPIV1Amount(account accounts.Interface, amount coinpkg.Amount, isFee bool) apiV1Amount {
+	return apiV1Amount{
+		Amount: account.Coin().FormatAmount(amount, isFee),
+		Unit:   account.Coin().GetFormatUnit(isFee),
+	}
+}
+
+func (handlers *Handlers) getAPIV1AccountBalance(r *http.Request) (interface{}, error) {
+	account, err := handlers.lookupAPIV1SyncedAccount(r)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := account.Balance()
+	if err != nil {
+		return nil, err
+	}
+	return apiV1Balance{
+		Available: newAPIV1Amount(account, balance.Available(), false),
+		Incoming:  newAPIV1Amount(account, balance.Incoming(), false),
+	}, nil
+}
+
+func newAPIV1Transaction(account accounts.Interface, txInfo *accounts.TransactionData) *apiV1Transaction {
+	var fee *apiV1Amount
+	if txInfo.Fee != nil {
+		amount := newAPIV1Amount(account, *txInfo.Fee, true)
+		fee = &amount
+	}
+	timestamp := txInfo.Timestamp
+	if timestamp == nil {
+		timestamp = txInfo.CreatedTimestamp
+	}
+	addresses := []string{}
+	for _, addressAndAmount := range txInfo.Addresses {
+		addresses = append(addresses, addressAndAmount.Address)
+	}
+	return &apiV1Transaction{
+		InternalID:               txInfo.InternalID,
+		TxID:                     txInfo.TxID,
+		Type:                     txInfo.Type,
+		Status:                   string(txInfo.Status),
+		Amount:                   newAPIV1Amount(account, txInfo.Amount, false),
+		Fee:                      fee,
+		Time:                     timestamp,
+		NumConfirmations:         txInfo.NumConfirmations,
+		NumConfirmationsComplete: txInfo.NumConfirmationsComplete,
+		Addresses:                addresses,
+		Note:                     account.TxNote(txInfo.InternalID),
+	}
+}
+
+// apiV1AccountTransactions returns the transactions of the account, newest first.
+func apiV1AccountTransactions(account accounts.Interface) ([]*accounts.TransactionData, error) {
+	transactions, err := account.Transactions()
+	if err != nil {
+		return nil, err
+	}
+	result := []*accounts.TransactionData{}
+	for _, txInfo := range transactions {
+		if txInfo.IsErc20 && big.NewInt(0).Cmp(txInfo.Amount.BigInt()) == 0 {
+			// Skip 0 amount ERC20 txs, which are used in address poisoning attacks.
+			continue
+		}
+		result = append(result, txInfo)
+	}
+	return result, nil
+}
+
+// lookupAPIV1Transaction returns the transaction of the `internalID` path parameter.
+func (handlers *Handlers) lookupAPIV1Transaction(r *http.Request) (accounts.Interface, *accounts.TransactionData, error) {
+	account, err := handlers.lookupAPIV1SyncedAccount(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	transactions, err := apiV1AccountTransactions(account)
+	if err != nil {
+		return nil, nil, err
+	}
+	internalID := mux.Vars(r)["internalID"]
+	for _, txInfo := range transactions {
+		if txInfo.InternalID == internalID {
+			return account, txInfo, nil
+		}
+	}
+	return nil, nil, newAPIV1Error(apiV1ErrorNotFound, "unknown transaction %q", internalID)
+}
+
+// getAPIV1AccountTransactions returns a page of transactions. The cursor is the encoded internal ID
+// of the last transaction of the previous page, so that pages are stable when new transactions
+// arrive.
+func (handlers *Handlers) getAPIV1AccountTransactions(r *http.Request) (interface{}, error) {
+	limit := apiV1DefaultLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > apiV1MaxLimit {
+			return nil, newAPIV1Error(apiV1ErrorBadRequest, "limit must be between 1 and %d", apiV1MaxLimit)
+		}
+	}
+	account, err := handlers.lookupAPIV1SyncedAccount(r)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := apiV1AccountTransactions(account)
+	if err != nil {
+		return nil, err
+	}
+	start := 0
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		internalID, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, newAPIV1Error(apiV1ErrorBadRequest, "invalid cursor")
+		}
+		start = -1
+		for index, txInfo := range transactions {
+			if txInfo.InternalID == string(internalID) {
+				start = index + 1
+				break
+			}
+		}
+		if start == -1 {
+			return nil, newAPIV1Error(apiV1ErrorBadRequest, "invalid cursor")
+		}
+	}
+	end := start + limit
+	if end > len(transactions) {
+		end = len(transactions)
+	}
+	result := apiV1Transactions{Transactions: []*apiV1Transaction{}}
+	for _, txInfo := range transactions[start:end] {
+		result.Transactions = append(result.Transactions, newAPIV1Transaction(account, txInfo))
+	}
+	if end < len(transactions) {
+		nextCursor := base64.RawURLEncoding.EncodeToString([]byte(transactions[end-1].InternalID))
+		result.NextCursor = &nextCursor
+	}
+	return result, nil
+}
+
+func (handlers *Handlers) getAPIV1AccountTransaction(r *http.Request) (interface{}, error) {
+	account, txInfo, err := handlers.lookupAPIV1Transaction(r)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIV1Transaction(account, txInfo), nil
+}
+
+func (handlers *Handlers) postAPIV1TransactionNote(r *http.Request) (interface{}, error) {
+	var request apiV1TransactionNote
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, newAPIV1Error(apiV1ErrorBadRequest, "invalid request body: %v", err)
+	}
+	account, txInfo, err := handlers.lookupAPIV1Transaction(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := account.SetTxNote(txInfo.InternalID, request.Note); err != nil {
+		return nil, err
+	}
+	return newAPIV1Transaction(account, txInfo), nil
+}
+
+func (handlers *Handlers) getAPIV1ReceiveAddresses(r *http.Request) (interface{}, error) {
+	account, err := handlers.lookupAPIV1SyncedAccount(r)
+	if err != nil {
+		return nil, err
+	}
+	result := apiV1ReceiveAddresses{AddressLists: []*apiV1AddressList{}}
+	for _, addressList := range account.GetUnusedReceiveAddresses() {
+		addresses := []*apiV1Address{}
+		for _, address := range addressList.Addresses {
+			addresses = append(addresses, &apiV1Address{ID: address.ID(), Address: address.EncodeForHumans()})
+		}
+		result.AddressLists = append(result.AddressLists, &apiV1AddressList{
+			ScriptType: addressList.ScriptType,
+			Addresses:  addresses,
+		})
+	}
+	return result, nil
+}
+
+func (handlers *Handlers) getAPIV1Contacts(r *http.Request) (interface{}, error) {
+	coinCode := coinpkg.Code(r.URL.Query().Get("coinCode"))
+	if coinCode == "" {
+		return nil, newAPIV1Error(apiV1ErrorBadRequest, "missing coinCode")
+	}
+	result := apiV1Contacts{Contacts: []*apiV1Contact{}}
+	for _, contact := range handlers.backend.Contacts(coinCode) {
+		result.Contacts = append(result.Contacts, &apiV1Contact{
+			ID:         contact.ID,
+			CoinCode:   contact.CoinCode,
+			Name:       contact.Name,
+			Address:    contact.Address,
+			ERC20Token: contact.ERC20Token,
+			Verified:   contact.Proof != nil,
+		})
+	}
+	return result, nil
+}
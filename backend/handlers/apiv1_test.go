@@ -0,0 +1,500 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	accountsMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/mocks"
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/addressbook"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	coinMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/devices/device"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/handlers"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
+	keystoreMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/observable"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+var updateOpenAPI = flag.Bool("update-openapi", false, "write the OpenAPI document to testdata/openapi-v1.json")
+
+const openAPIGoldenFile = "testdata/openapi-v1.json"
+
+// apiV1Backend is a backend with the parts used by the versioned API.
+type apiV1Backend struct {
+	handlers.Backend
+
+	accounts backend.AccountsList
+	keystore keystore.Keystore
+	devices  map[string]device.Interface
+	contacts []*addressbook.Contact
+	// apiTokens are the scoped API tokens, with the token as hash for simplicity.
+	apiTokens []config.APIToken
+}
+
+func (b *apiV1Backend) Start() <-chan interface{}                      { return make(chan interface{}) }
+func (b *apiV1Backend) Observe(func(observable.Event)) func()          { return func() {} }
+func (b *apiV1Backend) OnAccountInit(func(accounts.Interface))         {}
+func (b *apiV1Backend) OnAccountUninit(func(accounts.Interface))       {}
+func (b *apiV1Backend) OnDeviceInit(func(device.Interface))            {}
+func (b *apiV1Backend) OnDeviceUninit(func(string))                    {}
+func (b *apiV1Backend) Accounts() backend.AccountsList                 { return b.accounts }
+func (b *apiV1Backend) Keystore() keystore.Keystore                    { return b.keystore }
+func (b *apiV1Backend) DevicesRegistered() map[string]device.Interface { return b.devices }
+
+func (b *apiV1Backend) GetAccountFromCode(code accountsTypes.Code) (accounts.Interface, error) {
+	for _, account := range b.accounts {
+		if account.Config().Config.Code == code {
+			return account, nil
+		}
+	}
+	return nil, errors.New("unknown account")
+}
+
+func (b *apiV1Backend) APITokens() []config.APIToken { return b.apiTokens }
+
+func (b *apiV1Backend) LookupAPIToken(secret string) *config.APIToken {
+	for _, token := range b.apiTokens {
+		if token.Hash == secret {
+			return &token
+		}
+	}
+	return nil
+}
+
+func (b *apiV1Backend) Contacts(coinCode coin.Code) []*addressbook.Contact {
+	result := []*addressbook.Contact{}
+	for _, contact := range b.contacts {
+		if contact.CoinCode == coinCode {
+			result = append(result, contact)
+		}
+	}
+	return result
+}
+
+type apiV1Device struct {
+	device.Interface
+}
+
+func (apiV1Device) ProductName() string { return "bitbox02" }
+
+type apiV1Address struct {
+	id string
+}
+
+func (address apiV1Address) ID() string              { return address.id }
+func (address apiV1Address) EncodeForHumans() string { return "bc1q" + address.id }
+func (address apiV1Address) AbsoluteKeypath() signing.AbsoluteKeypath {
+	return signing.AbsoluteKeypath{}
+}
+
+func newAPIV1Account(code accountsTypes.Code, synced bool, transactions []*accounts.TransactionData) *accountsMocks.InterfaceMock {
+	notes := map[string]string{}
+	var offline error
+	if !synced {
+		offline = errors.New("connection refused")
+	}
+	scriptType := signing.ScriptTypeP2WPKH
+	return &accountsMocks.InterfaceMock{
+		ConfigFunc: func() *accounts.AccountConfig {
+			return &accounts.AccountConfig{Config: &config.Account{
+				Code:     code,
+				Name:     string(code),
+				CoinCode: coin.CodeBTC,
+				Inactive: code == "inactive",
+			}}
+		},
+		CoinFunc: func() coin.Coin {
+			return &coinMocks.CoinMock{
+				CodeFunc: func() coin.Code { return coin.CodeBTC },
+				NameFunc: func() string { return "Bitcoin" },
+				UnitFunc: func(bool) string { return "BTC" },
+				FormatAmountFunc: func(amount coin.Amount, isFee bool) string {
+					return new(big.Rat).SetFrac(amount.BigInt(), big.NewInt(1e8)).FloatString(8)
+				},
+				GetFormatUnitFunc: func(bool) string { return "BTC" },
+			}
+		},
+		SyncedFunc:     func() bool { return synced },
+		OfflineFunc:    func() error { return offline },
+		FatalErrorFunc: func() bool { return false },
+		TransactionsFunc: func() (accounts.OrderedTransactions, error) {
+			return accounts.NewOrderedTransactions(transactions), nil
+		},
+		BalanceFunc: func() (*accounts.Balance, error) {
+			return accounts.NewBalance(coin.NewAmountFromInt64(150000), coin.NewAmountFromInt64(20000)), nil
+		},
+		TxNoteFunc: func(txID string) string { return notes[txID] },
+		SetTxNoteFunc: func(txID string, note string) error {
+			notes[txID] = note
+			return nil
+		},
+		GetUnusedReceiveAddressesFunc: func() []accounts.AddressList {
+			return []accounts.AddressList{{
+				ScriptType: &scriptType,
+				Addresses:  []accounts.Address{apiV1Address{id: "1"}, apiV1Address{id: "2"}},
+			}}
+		},
+	}
+}
+
+func newAPIV1Transactions() []*accounts.TransactionData {
+	confirmed := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	fee := coin.NewAmountFromInt64(300)
+	return []*accounts.TransactionData{
+		{
+			TxID: "tx1", InternalID: "tx1", Height: 100, Timestamp: &confirmed,
+			NumConfirmations: 10, NumConfirmationsComplete: 6,
+			Status: accounts.TxStatusComplete, Type: accounts.TxTypeReceive,
+			Amount:    coin.NewAmountFromInt64(100000),
+			Addresses: []accounts.AddressAndAmount{{Address: "bc1qreceive"}},
+		},
+		{
+			TxID: "tx2", InternalID: "tx2", Height: 101, Timestamp: &confirmed,
+			NumConfirmations: 9, NumConfirmationsComplete: 6,
+			Status: accounts.TxStatusComplete, Type: accounts.TxTypeSend,
+			Amount: coin.NewAmountFromInt64(30000), Fee: &fee,
+			Addresses: []accounts.AddressAndAmount{{Address: "bc1qsend"}},
+		},
+		{
+			TxID: "tx3", InternalID: "tx3", Height: 0,
+			NumConfirmationsComplete: 6,
+			Status:                   accounts.TxStatusPending, Type: accounts.TxTypeReceive,
+			Amount: coin.NewAmountFromInt64(20000),
+		},
+		{
+			// Address poisoning tx, not returned.
+			TxID: "tx4", InternalID: "tx4", Height: 102, IsErc20: true,
+			Status: accounts.TxStatusComplete, Type: accounts.TxTypeReceive,
+			Amount: coin.NewAmountFromInt64(0),
+		},
+	}
+}
+
+func newAPIV1Backend() *apiV1Backend {
+	return &apiV1Backend{
+		accounts: backend.AccountsList{
+			newAPIV1Account("btc", true, newAPIV1Transactions()),
+			newAPIV1Account("unsynced", false, nil),
+			newAPIV1Account("inactive", true, nil),
+		},
+		keystore: &keystoreMocks.KeystoreMock{
+			TypeFunc:            func() keystore.Type { return keystore.TypeHardware },
+			RootFingerprintFunc: func() ([]byte, error) { return []byte{1, 2, 3, 4}, nil },
+		},
+		devices: map[string]device.Interface{"device-id": apiV1Device{}},
+		contacts: []*addressbook.Contact{
+			{ID: "contact", CoinCode: coin.CodeBTC, Name: "Alice", Address: "bc1qalice"},
+		},
+	}
+}
+
+// apiV1Request performs a request against the handlers and returns the decoded response.
+func apiV1Request(t *testing.T, h *handlers.Handlers, method, path, body string) (int, interface{}) {
+	t.Helper()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.Router.ServeHTTP(w, r)
+	require.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"), path)
+	var value interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &value), path)
+	return w.Code, value
+}
+
+func getOpenAPIDocument(t *testing.T, h *handlers.Handlers) map[string]interface{} {
+	t.Helper()
+	status, document := apiV1Request(t, h, "GET", "/api/v1/openapi.json", "")
+	require.Equal(t, http.StatusOK, status)
+	return document.(map[string]interface{})
+}
+
+func lookupJSON(value interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+// validateSchema checks that the JSON value matches the OpenAPI schema. Objects generated from
+// structs must not have properties missing in the schema.
+func validateSchema(t *testing.T, document map[string]interface{}, schema map[string]interface{}, value interface{}, path string) {
+	t.Helper()
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		refSchema, ok := lookupJSON(document, "components", "schemas", name).(map[string]interface{})
+		require.True(t, ok, "%s: unknown schema %s", path, ref)
+		validateSchema(t, document, refSchema, value, path)
+		return
+	}
+	if value == nil {
+		require.Equal(t, true, schema["nullable"], "%s: null is not allowed", path)
+		return
+	}
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, subSchema := range allOf {
+			validateSchema(t, document, subSchema.(map[string]interface{}), value, path)
+		}
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		require.Contains(t, enum, value, "%s: not in the enum", path)
+	}
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		require.True(t, ok, "%s: expected an object, got %v", path, value)
+		properties, _ := schema["properties"].(map[string]interface{})
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			require.Contains(t, object, name, "%s: missing required property", path)
+		}
+		for name, propertyValue := range object {
+			propertyPath := path + "." + name
+			if propertySchema, ok := properties[name]; ok {
+				validateSchema(t, document, propertySchema.(map[string]interface{}), propertyValue, propertyPath)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				validateSchema(t, document, additional, propertyValue, propertyPath)
+			case bool:
+				require.True(t, additional, "%s: property not in the schema", propertyPath)
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		require.True(t, ok, "%s: expected an array, got %v", path, value)
+		for index, element := range array {
+			validateSchema(t, document, schema["items"].(map[string]interface{}), element, fmt.Sprintf("%s[%d]", path, index))
+		}
+	case "string":
+		str, ok := value.(string)
+		require.True(t, ok, "%s: expected a string, got %v", path, value)
+		if schema["format"] == "date-time" {
+			_, err := time.Parse(time.RFC3339, str)
+			require.NoError(t, err, path)
+		}
+	case "integer":
+		number, ok := value.(float64)
+		require.True(t, ok && number == math.Trunc(number), "%s: expected an integer, got %v", path, value)
+	case "number":
+		_, ok := value.(float64)
+		require.True(t, ok, "%s: expected a number, got %v", path, value)
+	case "boolean":
+		_, ok := value.(bool)
+		require.True(t, ok, "%s: expected a boolean, got %v", path, value)
+	default:
+		require.Fail(t, "unexpected schema type", "%s: %v", path, schema["type"])
+	}
+}
+
+// TestAPIV1Contract calls every endpoint of the versioned API and checks the responses, including
+// errors, against the OpenAPI document.
+func TestAPIV1Contract(t *testing.T) {
+	h := handlers.NewHandlers(newAPIV1Backend(), handlers.NewConnectionData(-1, ""))
+	document := getOpenAPIDocument(t, h)
+	errorSchema := map[string]interface{}{"$ref": "#/components/schemas/ErrorResponse"}
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"GET", "/api/v1/openapi.json", "", http.StatusOK},
+		{"GET", "/api/v1/version", "", http.StatusOK},
+		{"GET", "/api/v1/keystores", "", http.StatusOK},
+		{"GET", "/api/v1/devices", "", http.StatusOK},
+		{"GET", "/api/v1/accounts", "", http.StatusOK},
+		{"GET", "/api/v1/accounts/btc", "", http.StatusOK},
+		{"GET", "/api/v1/accounts/unknown", "", http.StatusNotFound},
+		{"GET", "/api/v1/accounts/inactive", "", http.StatusNotFound},
+		{"GET", "/api/v1/accounts/btc/status", "", http.StatusOK},
+		{"GET", "/api/v1/accounts/unsynced/status", "", http.StatusOK},
+		{"GET", "/api/v1/accounts/btc/balance", "", http.StatusOK},
+		{"GET", "/api/v1/accounts/unsynced/balance", "", http.StatusServiceUnavailable},
+		{"GET", "/api/v1/accounts/btc/transactions", "", http.StatusOK},
+		{"GET", "/api/v1/accounts/btc/transactions?limit=1", "", http.StatusOK},
+		{"GET", "/api/v1/accounts/btc/transactions?limit=0", "", http.StatusBadRequest},
+		{"GET", "/api/v1/accounts/btc/transactions?cursor=invalid", "", http.StatusBadRequest},
+		{"GET", "/api/v1/accounts/unsynced/transactions", "", http.StatusServiceUnavailable},
+		{"GET", "/api/v1/accounts/btc/transactions/tx2", "", http.StatusOK},
+		{"GET", "/api/v1/accounts/btc/transactions/unknown", "", http.StatusNotFound},
+		{"POST", "/api/v1/accounts/btc/transactions/tx1/note", `{"note": "salary"}`, http.StatusOK},
+		{"POST", "/api/v1/accounts/btc/transactions/tx1/note", `note`, http.StatusBadRequest},
+		{"POST", "/api/v1/accounts/btc/transactions/unknown/note", `{"note": ""}`, http.StatusNotFound},
+		{"GET", "/api/v1/accounts/btc/receive-addresses", "", http.StatusOK},
+		{"GET", "/api/v1/accounts/unsynced/receive-addresses", "", http.StatusServiceUnavailable},
+		{"GET", "/api/v1/address-book?coinCode=btc", "", http.StatusOK},
+		{"GET", "/api/v1/address-book", "", http.StatusBadRequest},
+		{"GET", "/api/v1/unknown", "", http.StatusNotFound},
+		{"POST", "/api/v1/version", "", http.StatusMethodNotAllowed},
+	}
+
+	tested := map[string]bool{}
+	for _, test := range tests {
+		name := test.method + " " + test.path
+		status, value := apiV1Request(t, h, test.method, test.path, test.body)
+		require.Equal(t, test.status, status, name)
+
+		var match mux.RouteMatch
+		r := httptest.NewRequest(test.method, test.path, nil)
+		require.True(t, h.Router.Match(r, &match))
+		var operation interface{}
+		operationPath := ""
+		if match.MatchErr == nil {
+			template, err := match.Route.GetPathTemplate()
+			require.NoError(t, err)
+			operationPath = strings.TrimPrefix(template, "/api/v1")
+			operation = lookupJSON(document, "paths", operationPath, strings.ToLower(test.method))
+		}
+		if operation == nil {
+			// Unknown endpoints and methods.
+			require.Contains(t, []int{http.StatusNotFound, http.StatusMethodNotAllowed}, status, name)
+			validateSchema(t, document, errorSchema, value, name)
+			continue
+		}
+		schema, ok := lookupJSON(operation, "responses", strconv.Itoa(status), "content", "application/json", "schema").(map[string]interface{})
+		require.True(t, ok, "%s: status %d not documented", name, status)
+		validateSchema(t, document, schema, value, name)
+		if status == http.StatusOK {
+			tested[test.method+" "+operationPath] = true
+		}
+	}
+
+	// Every documented endpoint must be tested.
+	for path, operations := range document["paths"].(map[string]interface{}) {
+		for method := range operations.(map[string]interface{}) {
+			operation := strings.ToUpper(method) + " " + path
+			require.True(t, tested[operation], "no successful contract test of %s", operation)
+		}
+	}
+}
+
+func TestAPIV1Responses(t *testing.T) {
+	h := handlers.NewHandlers(newAPIV1Backend(), handlers.NewConnectionData(-1, ""))
+
+	_, value := apiV1Request(t, h, "GET", "/api/v1/accounts/btc/balance", "")
+	require.Equal(t, map[string]interface{}{
+		"available": map[string]interface{}{"amount": "0.00150000", "unit": "BTC"},
+		"incoming":  map[string]interface{}{"amount": "0.00020000", "unit": "BTC"},
+	}, value)
+
+	_, value = apiV1Request(t, h, "GET", "/api/v1/accounts/btc/transactions/tx2", "")
+	require.Equal(t, map[string]interface{}{
+		"internalID":               "tx2",
+		"txID":                     "tx2",
+		"type":                     "send",
+		"status":                   "complete",
+		"amount":                   map[string]interface{}{"amount": "0.00030000", "unit": "BTC"},
+		"fee":                      map[string]interface{}{"amount": "0.00000300", "unit": "BTC"},
+		"time":                     "2025-03-01T12:00:00Z",
+		"numConfirmations":         float64(9),
+		"numConfirmationsComplete": float64(6),
+		"addresses":                []interface{}{"bc1qsend"},
+		"note":                     "",
+	}, value)
+
+	_, value = apiV1Request(t, h, "POST", "/api/v1/accounts/btc/transactions/tx2/note", `{"note": "rent"}`)
+	require.Equal(t, "rent", lookupJSON(value, "note"))
+	_, value = apiV1Request(t, h, "GET", "/api/v1/accounts/btc/transactions/tx2", "")
+	require.Equal(t, "rent", lookupJSON(value, "note"))
+
+	status, value := apiV1Request(t, h, "GET", "/api/v1/accounts/unsynced/balance", "")
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, "notSynced", lookupJSON(value, "error", "code"))
+
+	// Requests without the API token are rejected with an error object.
+	h = handlers.NewHandlers(newAPIV1Backend(), handlers.NewConnectionData(8082, "token"))
+	status, value = apiV1Request(t, h, "GET", "/api/v1/accounts", "")
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Equal(t, "unauthorized", lookupJSON(value, "error", "code"))
+}
+
+func TestAPIV1TransactionsPagination(t *testing.T) {
+	b := newAPIV1Backend()
+	h := handlers.NewHandlers(b, handlers.NewConnectionData(-1, ""))
+
+	getPage := func(query string) ([]string, interface{}) {
+		status, value := apiV1Request(t, h, "GET", "/api/v1/accounts/btc/transactions?"+query, "")
+		require.Equal(t, http.StatusOK, status)
+		ids := []string{}
+		for _, tx := range lookupJSON(value, "transactions").([]interface{}) {
+			ids = append(ids, lookupJSON(tx, "internalID").(string))
+		}
+		return ids, lookupJSON(value, "nextCursor")
+	}
+
+	ids, cursor := getPage("")
+	require.Equal(t, []string{"tx3", "tx2", "tx1"}, ids)
+	require.Nil(t, cursor)
+
+	ids, cursor = getPage("limit=2")
+	require.Equal(t, []string{"tx3", "tx2"}, ids)
+	require.NotNil(t, cursor)
+
+	// A new transaction does not shift the next page.
+	b.accounts[0] = newAPIV1Account("btc", true, append(newAPIV1Transactions(), &accounts.TransactionData{
+		TxID: "tx5", InternalID: "tx5", Status: accounts.TxStatusPending, Type: accounts.TxTypeReceive,
+		Amount: coin.NewAmountFromInt64(1000),
+	}))
+	ids, cursor = getPage("limit=2&cursor=" + cursor.(string))
+	require.Equal(t, []string{"tx1"}, ids)
+	require.Nil(t, cursor)
+}
+
+// TestAPIV1OpenAPIDocument makes sure that changes of the API are deliberate. If the change is
+// backwards compatible, update the document with:
+//
+//	go test ./backend/handlers -run TestAPIV1OpenAPIDocument -update-openapi
+func TestAPIV1OpenAPIDocument(t *testing.T) {
+	h := handlers.NewHandlers(newAPIV1Backend(), handlers.NewConnectionData(-1, ""))
+	document, err := json.MarshalIndent(getOpenAPIDocument(t, h), "", "  ")
+	require.NoError(t, err)
+	document = append(document, '\n')
+	if *updateOpenAPI {
+		require.NoError(t, os.MkdirAll(filepath.Dir(openAPIGoldenFile), 0755))
+		require.NoError(t, os.WriteFile(openAPIGoldenFile, document, 0644))
+	}
+	expected, err := os.ReadFile(openAPIGoldenFile)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(expected, document),
+		"the API changed, see the comment of TestAPIV1OpenAPIDocument:\n%s", document)
+}
@@ -26,10 +26,13 @@ import (
 	"net/http"
 	"os"
 	"runtime/debug"
+	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	accountsErrors "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
 	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/addressbook"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/banners"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/bitsurance"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
@@ -49,6 +52,9 @@ import (
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/exchanges"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/scheduler"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/taxreport"
 	utilConfig "github.com/BitBoxSwiss/bitbox-wallet-app/util/config"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/jsonp"
@@ -87,6 +93,7 @@ type Backend interface {
 	RatesUpdater() *rates.RateUpdater
 	DownloadCert(string) (string, error)
 	CheckElectrumServer(*config.ServerInfo) error
+	CheckETHNode(code coinpkg.Code, nodeURL string) error
 	RegisterTestKeystore(string)
 	NotifyUser(string)
 	SystemOpen(string) error
@@ -97,17 +104,43 @@ type Backend interface {
 	ExportLogs() error
 	ExportNotes() error
 	ImportNotes(jsonLines []byte) (*backend.ImportNotesResult, error)
+	ExportTaxReport(rootFingerprint []byte, fiat string, method taxreport.Method) (*taxreport.Report, error)
 	ChartData() (*backend.Chart, error)
 	SupportedCoins(keystore.Keystore) []coinpkg.Code
 	CanAddAccount(coinpkg.Code, keystore.Keystore) (string, bool)
 	CreateAndPersistAccountConfig(coinCode coinpkg.Code, name string, keystore keystore.Keystore) (accountsTypes.Code, error)
+	CreateAndPersistMultisigAccountConfig(coinCode coinpkg.Code, name string, threshold int, keyInfos []signing.KeyInfo, keystore keystore.Keystore) (accountsTypes.Code, error)
+	CreateWatchonlyAccountFromDescriptors(coinCode coinpkg.Code, name string, descriptors []string) (accountsTypes.Code, error)
+	ExportAccountDescriptors() ([]*backend.AccountDescriptors, error)
 	SetAccountActive(accountCode accountsTypes.Code, active bool) error
 	SetTokenActive(accountCode accountsTypes.Code, tokenCode string, active bool) error
+	CustomERC20Tokens() []*config.ERC20Token
+	AddCustomERC20Token(contractAddress string) (*config.ERC20Token, error)
+	RemoveCustomERC20Token(code coinpkg.Code) error
 	RenameAccount(accountCode accountsTypes.Code, name string) error
+	Contacts(coinCode coinpkg.Code) []*addressbook.Contact
+	AddContact(coinCode coinpkg.Code, name string, address string, erc20Token coinpkg.Code) (*addressbook.Contact, error)
+	UpdateContact(id string, name string, address string, erc20Token coinpkg.Code) (*addressbook.Contact, error)
+	RemoveContact(id string) error
+	VerifyContact(id string, message string, signature []byte) (*addressbook.Contact, error)
+	ScheduledPayments() []*backend.ScheduledPayment
+	AddScheduledPayment(payment scheduler.Payment) (*scheduler.Payment, error)
+	UpdateScheduledPayment(id string, name string, recipientAddress string, amount string, fiatUnit string) (*scheduler.Payment, error)
+	RemoveScheduledPayment(id string) error
+	SkipScheduledPayment(id string) error
+	SendScheduledPayment(id string) error
+	TestWebhooks() error
+	APITokens() []config.APIToken
+	CreateAPIToken(name string, scopes []config.APITokenScope, accountCodes []accountsTypes.Code, expires *time.Time) (string, *config.APIToken, error)
+	RevokeAPIToken(id string) error
+	LookupAPIToken(secret string) *config.APIToken
 	AOPP() backend.AOPP
 	AOPPCancel()
 	AOPPApprove()
 	AOPPChooseAccount(code accountsTypes.Code)
+	ParsePaymentURI(uri string) (*backend.PaymentURIRequest, error)
+	PaymentURI() *backend.PaymentURIRequest
+	PaymentURICancel()
 	GetAccountFromCode(code accountsTypes.Code) (accounts.Interface, error)
 	HTTPClient() *http.Client
 	LookupInsuredAccounts(accountCode accountsTypes.Code) ([]bitsurance.AccountDetails, error)
@@ -175,8 +208,7 @@ func NewHandlers(
 
 	getAPIRouter := func(subrouter *mux.Router) func(string, func(*http.Request) (interface{}, error)) *mux.Route {
 		return func(path string, f func(*http.Request) (interface{}, error)) *mux.Route {
-			return subrouter.Handle(path, ensureAPITokenValid(handlers.apiMiddleware(connData.isDev(), f),
-				connData, log))
+			return subrouter.Handle(path, handlers.ensureAPITokenValid(handlers.apiMiddleware(connData.isDev(), f)))
 		}
 	}
 
@@ -185,13 +217,12 @@ func NewHandlers(
 		return func(path string, f func(*http.Request) interface{}) *mux.Route {
 			return subrouter.Handle(
 				path,
-				ensureAPITokenValid(
+				handlers.ensureAPITokenValid(
 					handlers.apiMiddleware(
 						connData.isDev(),
 						func(r *http.Request) (interface{}, error) {
 							return f(r), nil
-						}),
-					connData, log))
+						})))
 		}
 	}
 
@@ -215,14 +246,35 @@ func NewHandlers(
 	getAPIRouterNoError(apiRouter)("/testing", handlers.getTesting).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/dev-servers", handlers.getDevServers).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/account-add", handlers.postAddAccount).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/account-add-multisig", handlers.postAddMultisigAccount).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/account-add-descriptors", handlers.postAddDescriptorsAccount).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/keystores", handlers.getKeystores).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/accounts", handlers.getAccounts).Methods("GET")
 	getAPIRouter(apiRouter)("/accounts/balance", handlers.getAccountsBalance).Methods("GET")
+	getAPIRouter(apiRouter)("/accounts/descriptors", handlers.getAccountsDescriptors).Methods("GET")
 	getAPIRouter(apiRouter)("/accounts/coins-balance", handlers.getCoinsTotalBalance).Methods("GET")
 	getAPIRouter(apiRouter)("/accounts/total-balance", handlers.getAccountsTotalBalance).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/set-account-active", handlers.postSetAccountActive).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/set-token-active", handlers.postSetTokenActive).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/erc20-tokens/custom", handlers.getCustomERC20Tokens).Methods("GET")
+	getAPIRouterNoError(apiRouter)("/erc20-tokens/custom/add", handlers.postAddCustomERC20Token).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/erc20-tokens/custom/remove", handlers.postRemoveCustomERC20Token).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/rename-account", handlers.postRenameAccount).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/address-book", handlers.getContacts).Methods("GET")
+	getAPIRouterNoError(apiRouter)("/address-book/add", handlers.postAddContact).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/address-book/update", handlers.postUpdateContact).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/address-book/remove", handlers.postRemoveContact).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/address-book/verify", handlers.postVerifyContact).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/scheduled-payments", handlers.getScheduledPayments).Methods("GET")
+	getAPIRouterNoError(apiRouter)("/scheduled-payments/add", handlers.postAddScheduledPayment).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/scheduled-payments/update", handlers.postUpdateScheduledPayment).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/scheduled-payments/remove", handlers.postRemoveScheduledPayment).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/scheduled-payments/skip", handlers.postSkipScheduledPayment).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/scheduled-payments/send", handlers.postSendScheduledPayment).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/webhooks/test", handlers.postTestWebhooks).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/api-tokens", handlers.getAPITokens).Methods("GET")
+	getAPIRouterNoError(apiRouter)("/api-tokens/create", handlers.postCreateAPIToken).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/api-tokens/revoke", handlers.postRevokeAPIToken).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/accounts/reinitialize", handlers.postAccountsReinitialize).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/account-summary", handlers.getAccountSummary).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/supported-coins", handlers.getSupportedCoins).Methods("GET")
@@ -239,6 +291,7 @@ func NewHandlers(
 	getAPIRouterNoError(apiRouter)("/coins/btc/parse-external-amount", handlers.getBTCParseExternalAmount).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/certs/download", handlers.postCertsDownload).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/electrum/check", handlers.postElectrumCheck).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/eth-node/check", handlers.postETHNodeCheck).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/socksproxy/check", handlers.postSocksProxyCheck).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/exchange/region-codes", handlers.getExchangeRegionCodes).Methods("GET")
 	getAPIRouterNoError(apiRouter)("/exchange/deals/{action}/{code}", handlers.getExchangeDeals).Methods("GET")
@@ -254,6 +307,9 @@ func NewHandlers(
 	getAPIRouterNoError(apiRouter)("/aopp/cancel", handlers.postAOPPCancel).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/aopp/approve", handlers.postAOPPApprove).Methods("POST")
 	getAPIRouter(apiRouter)("/aopp/choose-account", handlers.postAOPPChooseAccount).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/payment-uri", handlers.getPaymentURI).Methods("GET")
+	getAPIRouterNoError(apiRouter)("/payment-uri/parse", handlers.postParsePaymentURI).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/payment-uri/cancel", handlers.postPaymentURICancel).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/cancel-connect-keystore", handlers.postCancelConnectKeystore).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/set-watchonly", handlers.postSetWatchonly).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/on-auth-setting-changed", handlers.postOnAuthSettingChanged).Methods("POST")
@@ -261,6 +317,9 @@ func NewHandlers(
 	getAPIRouterNoError(apiRouter)("/accounts/eth-account-code", handlers.lookupEthAccountCode).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/notes/export", handlers.postExportNotes).Methods("POST")
 	getAPIRouterNoError(apiRouter)("/notes/import", handlers.postImportNotes).Methods("POST")
+	getAPIRouterNoError(apiRouter)("/tax-report/export", handlers.postExportTaxReport).Methods("POST")
+
+	handlers.registerAPIV1(router.PathPrefix(apiV1Prefix).Subrouter(), connData, log)
 
 	devicesRouter := getAPIRouterNoError(apiRouter.PathPrefix("/devices").Subrouter())
 	devicesRouter("/registered", handlers.getDevicesRegistered).Methods("GET")
@@ -579,6 +638,78 @@ func (handlers *Handlers) postAddAccount(r *http.Request) interface{} {
 	return response{Success: true, AccountCode: accountCode}
 }
 
+// postAddMultisigAccount adds a multisig account of which the connected keystore is a cosigner.
+func (handlers *Handlers) postAddMultisigAccount(r *http.Request) interface{} {
+	var jsonBody struct {
+		CoinCode  coinpkg.Code      `json:"coinCode"`
+		Name      string            `json:"name"`
+		Threshold int               `json:"threshold"`
+		KeyInfos  []signing.KeyInfo `json:"keyInfos"`
+	}
+
+	type response struct {
+		Success      bool               `json:"success"`
+		AccountCode  accountsTypes.Code `json:"accountCode,omitempty"`
+		ErrorMessage string             `json:"errorMessage,omitempty"`
+		ErrorCode    string             `json:"errorCode,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+
+	keystore := handlers.backend.Keystore()
+	if keystore == nil {
+		return response{Success: false, ErrorMessage: "Keystore not found"}
+	}
+
+	accountCode, err := handlers.backend.CreateAndPersistMultisigAccountConfig(
+		jsonBody.CoinCode, jsonBody.Name, jsonBody.Threshold, jsonBody.KeyInfos, keystore)
+	if err != nil {
+		handlers.log.WithError(err).Error("Could not add multisig account")
+		if errCode, ok := errp.Cause(err).(errp.ErrorCode); ok {
+			return response{Success: false, ErrorCode: string(errCode)}
+		}
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true, AccountCode: accountCode}
+}
+
+// postAddDescriptorsAccount adds a watch-only account from pasted output descriptors.
+func (handlers *Handlers) postAddDescriptorsAccount(r *http.Request) interface{} {
+	var jsonBody struct {
+		CoinCode    coinpkg.Code `json:"coinCode"`
+		Name        string       `json:"name"`
+		Descriptors []string     `json:"descriptors"`
+	}
+
+	type response struct {
+		Success      bool               `json:"success"`
+		AccountCode  accountsTypes.Code `json:"accountCode,omitempty"`
+		ErrorMessage string             `json:"errorMessage,omitempty"`
+		ErrorCode    string             `json:"errorCode,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+
+	accountCode, err := handlers.backend.CreateWatchonlyAccountFromDescriptors(
+		jsonBody.CoinCode, jsonBody.Name, jsonBody.Descriptors)
+	if err != nil {
+		handlers.log.WithError(err).Error("Could not add account from descriptors")
+		if errCode, ok := errp.Cause(err).(errp.ErrorCode); ok {
+			return response{Success: false, ErrorCode: string(errCode)}
+		}
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true, AccountCode: accountCode}
+}
+
+func (handlers *Handlers) getAccountsDescriptors(*http.Request) (interface{}, error) {
+	return handlers.backend.ExportAccountDescriptors()
+}
+
 func (handlers *Handlers) getKeystores(*http.Request) interface{} {
 	type json struct {
 		Type keystore.Type `json:"type"`
@@ -885,6 +1016,51 @@ func (handlers *Handlers) postSetTokenActive(r *http.Request) interface{} {
 	return response{Success: true}
 }
 
+func (handlers *Handlers) getCustomERC20Tokens(*http.Request) interface{} {
+	return handlers.backend.CustomERC20Tokens()
+}
+
+func (handlers *Handlers) postAddCustomERC20Token(r *http.Request) interface{} {
+	var jsonBody struct {
+		ContractAddress string `json:"contractAddress"`
+	}
+
+	type response struct {
+		Success      bool               `json:"success"`
+		ErrorMessage string             `json:"errorMessage,omitempty"`
+		Token        *config.ERC20Token `json:"token,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	token, err := handlers.backend.AddCustomERC20Token(jsonBody.ContractAddress)
+	if err != nil {
+		handlers.log.WithError(err).Error("could not add custom ERC20 token")
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true, Token: token}
+}
+
+func (handlers *Handlers) postRemoveCustomERC20Token(r *http.Request) interface{} {
+	var jsonBody struct {
+		Code coinpkg.Code `json:"code"`
+	}
+
+	type response struct {
+		Success      bool   `json:"success"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	if err := handlers.backend.RemoveCustomERC20Token(jsonBody.Code); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true}
+}
+
 func (handlers *Handlers) postRenameAccount(r *http.Request) interface{} {
 	var jsonBody struct {
 		AccountCode accountsTypes.Code `json:"accountCode"`
@@ -909,6 +1085,179 @@ func (handlers *Handlers) postRenameAccount(r *http.Request) interface{} {
 	return response{Success: true}
 }
 
+func (handlers *Handlers) getContacts(r *http.Request) interface{} {
+	return handlers.backend.Contacts(coinpkg.Code(r.URL.Query().Get("coinCode")))
+}
+
+type contactResponse struct {
+	Success      bool                 `json:"success"`
+	ErrorMessage string               `json:"errorMessage,omitempty"`
+	ErrorCode    string               `json:"errorCode,omitempty"`
+	Contact      *addressbook.Contact `json:"contact,omitempty"`
+}
+
+func newContactResponse(contact *addressbook.Contact, err error) contactResponse {
+	if err != nil {
+		if validationErr, ok := errp.Cause(err).(accountsErrors.TxValidationError); ok {
+			return contactResponse{Success: false, ErrorCode: validationErr.Error()}
+		}
+		return contactResponse{Success: false, ErrorMessage: err.Error()}
+	}
+	return contactResponse{Success: true, Contact: contact}
+}
+
+func (handlers *Handlers) postAddContact(r *http.Request) interface{} {
+	var jsonBody struct {
+		CoinCode   coinpkg.Code `json:"coinCode"`
+		Name       string       `json:"name"`
+		Address    string       `json:"address"`
+		ERC20Token coinpkg.Code `json:"erc20Token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return newContactResponse(nil, errp.WithStack(err))
+	}
+	return newContactResponse(handlers.backend.AddContact(
+		jsonBody.CoinCode, jsonBody.Name, jsonBody.Address, jsonBody.ERC20Token))
+}
+
+func (handlers *Handlers) postUpdateContact(r *http.Request) interface{} {
+	var jsonBody struct {
+		ID         string       `json:"id"`
+		Name       string       `json:"name"`
+		Address    string       `json:"address"`
+		ERC20Token coinpkg.Code `json:"erc20Token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return newContactResponse(nil, errp.WithStack(err))
+	}
+	return newContactResponse(handlers.backend.UpdateContact(
+		jsonBody.ID, jsonBody.Name, jsonBody.Address, jsonBody.ERC20Token))
+}
+
+func (handlers *Handlers) postRemoveContact(r *http.Request) interface{} {
+	var jsonBody struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return newContactResponse(nil, errp.WithStack(err))
+	}
+	return newContactResponse(nil, handlers.backend.RemoveContact(jsonBody.ID))
+}
+
+// postVerifyContact records a signed message proving control over the address of a contact. The
+// signature is base64 encoded.
+func (handlers *Handlers) postVerifyContact(r *http.Request) interface{} {
+	var jsonBody struct {
+		ID        string `json:"id"`
+		Message   string `json:"message"`
+		Signature string `json:"signature"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return newContactResponse(nil, errp.WithStack(err))
+	}
+	signature, err := base64.StdEncoding.DecodeString(jsonBody.Signature)
+	if err != nil {
+		return newContactResponse(nil, errp.WithStack(err))
+	}
+	return newContactResponse(handlers.backend.VerifyContact(jsonBody.ID, jsonBody.Message, signature))
+}
+
+func (handlers *Handlers) getScheduledPayments(*http.Request) interface{} {
+	return handlers.backend.ScheduledPayments()
+}
+
+type scheduledPaymentResponse struct {
+	Success      bool               `json:"success"`
+	Aborted      bool               `json:"aborted,omitempty"`
+	ErrorMessage string             `json:"errorMessage,omitempty"`
+	ErrorCode    string             `json:"errorCode,omitempty"`
+	Payment      *scheduler.Payment `json:"payment,omitempty"`
+}
+
+func newScheduledPaymentResponse(payment *scheduler.Payment, err error) scheduledPaymentResponse {
+	if err != nil {
+		if validationErr, ok := errp.Cause(err).(accountsErrors.TxValidationError); ok {
+			return scheduledPaymentResponse{Success: false, ErrorCode: validationErr.Error()}
+		}
+		return scheduledPaymentResponse{Success: false, ErrorMessage: err.Error()}
+	}
+	return scheduledPaymentResponse{Success: true, Payment: payment}
+}
+
+func (handlers *Handlers) postAddScheduledPayment(r *http.Request) interface{} {
+	var payment scheduler.Payment
+	if err := json.NewDecoder(r.Body).Decode(&payment); err != nil {
+		return newScheduledPaymentResponse(nil, errp.WithStack(err))
+	}
+	return newScheduledPaymentResponse(handlers.backend.AddScheduledPayment(payment))
+}
+
+func (handlers *Handlers) postUpdateScheduledPayment(r *http.Request) interface{} {
+	var jsonBody struct {
+		ID               string `json:"id"`
+		Name             string `json:"name"`
+		RecipientAddress string `json:"recipientAddress"`
+		Amount           string `json:"amount"`
+		FiatUnit         string `json:"fiatUnit"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return newScheduledPaymentResponse(nil, errp.WithStack(err))
+	}
+	return newScheduledPaymentResponse(handlers.backend.UpdateScheduledPayment(
+		jsonBody.ID, jsonBody.Name, jsonBody.RecipientAddress, jsonBody.Amount, jsonBody.FiatUnit))
+}
+
+func (handlers *Handlers) postRemoveScheduledPayment(r *http.Request) interface{} {
+	var jsonBody struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return newScheduledPaymentResponse(nil, errp.WithStack(err))
+	}
+	return newScheduledPaymentResponse(nil, handlers.backend.RemoveScheduledPayment(jsonBody.ID))
+}
+
+func (handlers *Handlers) postSkipScheduledPayment(r *http.Request) interface{} {
+	var jsonBody struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return newScheduledPaymentResponse(nil, errp.WithStack(err))
+	}
+	return newScheduledPaymentResponse(nil, handlers.backend.SkipScheduledPayment(jsonBody.ID))
+}
+
+// postSendScheduledPayment signs and sends the due payment of a scheduled payment with the
+// connected keystore.
+func (handlers *Handlers) postSendScheduledPayment(r *http.Request) interface{} {
+	var jsonBody struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return newScheduledPaymentResponse(nil, errp.WithStack(err))
+	}
+	err := handlers.backend.SendScheduledPayment(jsonBody.ID)
+	if errp.Cause(err) == keystore.ErrSigningAborted || errp.Cause(err) == errp.ErrUserAbort {
+		return scheduledPaymentResponse{Success: false, Aborted: true}
+	}
+	if err != nil {
+		handlers.log.WithError(err).Error("Failed to send scheduled payment")
+	}
+	return newScheduledPaymentResponse(nil, err)
+}
+
+// postTestWebhooks posts a test event to all configured webhooks.
+func (handlers *Handlers) postTestWebhooks(*http.Request) interface{} {
+	type response struct {
+		Success      bool   `json:"success"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	}
+	if err := handlers.backend.TestWebhooks(); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true}
+}
+
 func (handlers *Handlers) postAccountsReinitialize(*http.Request) interface{} {
 	handlers.backend.ReinitializeAccounts()
 	return nil
@@ -1105,6 +1454,36 @@ func (handlers *Handlers) postElectrumCheck(r *http.Request) interface{} {
 	}
 }
 
+func (handlers *Handlers) postETHNodeCheck(r *http.Request) interface{} {
+	var jsonBody struct {
+		CoinCode coinpkg.Code `json:"coinCode"`
+		URL      string       `json:"url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jsonBody); err != nil {
+		return map[string]interface{}{
+			"success":      false,
+			"errorMessage": err.Error(),
+		}
+	}
+
+	if err := handlers.backend.CheckETHNode(jsonBody.CoinCode, jsonBody.URL); err != nil {
+		handlers.log.
+			WithError(err).
+			WithField("coinCode", jsonBody.CoinCode).
+			Info("checking Ethereum node connection failed")
+		return map[string]interface{}{
+			"success":      false,
+			"errorMessage": err.Error(),
+		}
+	}
+	handlers.log.
+		WithField("coinCode", jsonBody.CoinCode).
+		Info("checking Ethereum node connection succeeded")
+	return map[string]interface{}{
+		"success": true,
+	}
+}
+
 func (handlers *Handlers) postSocksProxyCheck(r *http.Request) interface{} {
 	type response struct {
 		Success      bool   `json:"success"`
@@ -1155,39 +1534,34 @@ func (handlers *Handlers) eventsHandler(w http.ResponseWriter, r *http.Request)
 	}()
 }
 
-// isAPITokenValid checks whether we are in dev or prod mode and, if we are in prod mode, verifies
-// that an authorization token is received as an HTTP Authorization header and that it is valid.
-func isAPITokenValid(w http.ResponseWriter, r *http.Request, apiData *ConnectionData, log *logrus.Entry) bool {
-	methodLogEntry := log.
-		WithField("path", r.URL.Path).
-		WithField("method", r.Method)
-	methodLogEntry.Debug("endpoint")
-	// In dev mode, we allow unauthorized requests
-	if apiData.devMode {
-		return true
-	}
-
-	if len(r.Header.Get("Authorization")) == 0 {
-		methodLogEntry.Error("Missing token in API request. WARNING: this could be an attack on the API")
-		http.Error(w, "missing token "+r.URL.Path, http.StatusUnauthorized)
-		return false
-	} else if len(r.Header.Get("Authorization")) != 0 && r.Header.Get("Authorization") != "Basic "+apiData.token {
-		methodLogEntry.Error("Incorrect token in API request. WARNING: this could be an attack on the API")
-		http.Error(w, "incorrect token", http.StatusUnauthorized)
-		return false
-	}
-	return true
-}
-
-// ensureAPITokenValid wraps the given handler with another handler function that calls isAPITokenValid().
-func ensureAPITokenValid(h http.Handler, apiData *ConnectionData, log *logrus.Entry) http.Handler {
+// ensureAPITokenValid wraps the given handler with another handler function that responds with
+// 401 Unauthorized if the request does not have a valid API token, and with 403 Forbidden if the
+// token lacks the scope of the route. The scoped token is added to the request context.
+func (handlers *Handlers) ensureAPITokenValid(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if isAPITokenValid(w, r, apiData, log) {
-			h.ServeHTTP(w, r)
+		token, authErr := handlers.authenticateAPIRequest(r)
+		if authErr == nil {
+			routeScope, accountCode := apiRouteScopeOf(r)
+			authErr = authorizeAPIToken(token, routeScope, accountCode)
+		}
+		if authErr != nil {
+			http.Error(w, authErr.message, authErr.status)
+			return
 		}
+		h.ServeHTTP(w, withAPIToken(r, token))
 	})
 }
 
+// setDevCORSHeader enables us to run a server on a different port serving just the UI, while still
+// allowing it to access the API.
+func setDevCORSHeader(w http.ResponseWriter) {
+	vitePort, ok := os.LookupEnv("VITE_PORT")
+	if !ok {
+		vitePort = "8080"
+	}
+	w.Header().Set("Access-Control-Allow-Origin", fmt.Sprintf("http://localhost:%s", vitePort))
+}
+
 func (handlers *Handlers) apiMiddleware(devMode bool, h func(*http.Request) (interface{}, error)) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
@@ -1200,13 +1574,7 @@ func (handlers *Handlers) apiMiddleware(devMode bool, h func(*http.Request) (int
 
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		if devMode {
-			// This enables us to run a server on a different port serving just the UI, while still
-			// allowing it to access the API.
-			vitePort, ok := os.LookupEnv("VITE_PORT")
-			if !ok {
-				vitePort = "8080"
-			}
-			w.Header().Set("Access-Control-Allow-Origin", fmt.Sprintf("http://localhost:%s", vitePort))
+			setDevCORSHeader(w)
 		}
 		value, err := h(r)
 		if err != nil {
@@ -1538,6 +1906,32 @@ func (handlers *Handlers) postAOPPApprove(r *http.Request) interface{} {
 	return nil
 }
 
+func (handlers *Handlers) getPaymentURI(r *http.Request) interface{} {
+	return handlers.backend.PaymentURI()
+}
+
+func (handlers *Handlers) postParsePaymentURI(r *http.Request) interface{} {
+	type response struct {
+		Success      bool                       `json:"success"`
+		ErrorMessage string                     `json:"errorMessage,omitempty"`
+		Request      *backend.PaymentURIRequest `json:"request,omitempty"`
+	}
+	var uri string
+	if err := json.NewDecoder(r.Body).Decode(&uri); err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	request, err := handlers.backend.ParsePaymentURI(uri)
+	if err != nil {
+		return response{Success: false, ErrorMessage: err.Error()}
+	}
+	return response{Success: true, Request: request}
+}
+
+func (handlers *Handlers) postPaymentURICancel(r *http.Request) interface{} {
+	handlers.backend.PaymentURICancel()
+	return nil
+}
+
 func (handlers *Handlers) postCancelConnectKeystore(r *http.Request) interface{} {
 	handlers.backend.CancelConnectKeystore()
 	return nil
@@ -1594,6 +1988,68 @@ func (handlers *Handlers) postExportNotes(r *http.Request) interface{} {
 	return result{Success: true}
 }
 
+func (handlers *Handlers) postExportTaxReport(r *http.Request) interface{} {
+	type yearSummary struct {
+		Year      int    `json:"year"`
+		Unit      string `json:"unit"`
+		Proceeds  string `json:"proceeds"`
+		CostBasis string `json:"costBasis"`
+		Gain      string `json:"gain"`
+		Fees      string `json:"fees"`
+	}
+	type result struct {
+		Success bool   `json:"success"`
+		Message string `json:"message,omitempty"`
+		Aborted bool   `json:"aborted"`
+		Fiat    string `json:"fiat,omitempty"`
+		// MissingRates is the number of transactions without historical exchange rate.
+		MissingRates int           `json:"missingRates"`
+		Summary      []yearSummary `json:"summary,omitempty"`
+	}
+	var request struct {
+		RootFingerprint jsonp.HexBytes `json:"rootFingerprint"`
+		// Defaults to the main fiat currency.
+		Fiat   string `json:"fiat"`
+		Method string `json:"method"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return result{Success: false, Message: err.Error()}
+	}
+	method, err := taxreport.NewMethod(request.Method)
+	if err != nil {
+		return result{Success: false, Message: err.Error()}
+	}
+	fiat := request.Fiat
+	if fiat == "" {
+		fiat = handlers.backend.Config().AppConfig().Backend.MainFiat
+	}
+	report, err := handlers.backend.ExportTaxReport([]byte(request.RootFingerprint), fiat, method)
+	if err != nil {
+		if errp.Cause(err) == errp.ErrUserAbort {
+			return result{Success: false, Aborted: true}
+		}
+		handlers.log.WithError(err).Error("Error exporting tax report")
+		return result{Success: false, Message: err.Error()}
+	}
+	summary := make([]yearSummary, len(report.Summary))
+	for i, yearly := range report.Summary {
+		summary[i] = yearSummary{
+			Year:      yearly.Year,
+			Unit:      yearly.Unit,
+			Proceeds:  coinpkg.FormatAsCurrency(yearly.Proceeds, fiat),
+			CostBasis: coinpkg.FormatAsCurrency(yearly.CostBasis, fiat),
+			Gain:      coinpkg.FormatAsCurrency(yearly.Gain, fiat),
+			Fees:      coinpkg.FormatAsCurrency(yearly.Fees, fiat),
+		}
+	}
+	return result{
+		Success:      true,
+		Fiat:         fiat,
+		MissingRates: report.MissingRates,
+		Summary:      summary,
+	}
+}
+
 func (handlers *Handlers) postImportNotes(r *http.Request) interface{} {
 	type result struct {
 		Success bool                       `json:"success"`
@@ -0,0 +1,44 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+)
+
+// NewLocalAPIServer returns the HTTP server of the API for other programs on this computer. It
+// listens on 127.0.0.1 only and requires the token of the app or an API token, so it can't be
+// used in dev mode. The events websocket is not served, as the events are meant for the frontend
+// of the app.
+func (handlers *Handlers) NewLocalAPIServer(port int) (*http.Server, error) {
+	if handlers.apiData.devMode {
+		return nil, errp.New("The local API server is not available in dev mode")
+	}
+	return &http.Server{
+		Addr: fmt.Sprintf("127.0.0.1:%d", port),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/events" {
+				http.NotFound(w, r)
+				return
+			}
+			handlers.Router.ServeHTTP(w, r)
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}, nil
+}
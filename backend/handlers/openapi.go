@@ -0,0 +1,335 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// openAPIDocument is an OpenAPI 3 document describing the versioned API. Only the parts of the
+// specification used by the API are modeled.
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Servers    []openAPIServer                         `json:"servers"`
+	Security   []map[string][]string                   `json:"security"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Description string                      `json:"description"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description"`
+	Required    bool           `json:"required"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema         `json:"schemas"`
+	SecuritySchemes map[string]*openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type        string `json:"type"`
+	In          string `json:"in"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// openAPISchema is the subset of the OpenAPI 3.0 schema object generated from Go types.
+type openAPISchema struct {
+	Ref         string   `json:"$ref,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Format      string   `json:"format,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Nullable    bool     `json:"nullable,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Minimum     *int     `json:"minimum,omitempty"`
+	Maximum     *int     `json:"maximum,omitempty"`
+	// Items is the schema of the elements of an array.
+	Items      *openAPISchema            `json:"items,omitempty"`
+	Properties map[string]*openAPISchema `json:"properties,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+	// AdditionalProperties is false for structs, which have a fixed set of properties, and the
+	// schema of the values for maps.
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	// AllOf is used to add a description or nullable to a reference, as siblings of $ref are
+	// ignored in OpenAPI 3.0.
+	AllOf []*openAPISchema `json:"allOf,omitempty"`
+}
+
+const openAPIJSON = "application/json"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// openAPIPathParamRegexp matches the parameters in a gorilla/mux path template, which uses the same
+// syntax as OpenAPI for parameters without a pattern.
+var openAPIPathParamRegexp = regexp.MustCompile(`\{([^}]+)\}`)
+
+// openAPIGenerator generates the schemas of Go types, collecting the named structs as reusable
+// components.
+type openAPIGenerator struct {
+	schemas map[string]*openAPISchema
+	// names maps the struct types to their component name, to detect conflicting names.
+	names map[reflect.Type]string
+}
+
+// schemaName returns the component name of a struct type, with the apiV1 prefix stripped, e.g.
+// `Account` for `apiV1Account`.
+func schemaName(typ reflect.Type) string {
+	name := strings.TrimPrefix(typ.Name(), "apiV1")
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// schema returns the schema of values of the given type as encoded by encoding/json.
+func (generator *openAPIGenerator) schema(typ reflect.Type) *openAPISchema {
+	if typ == timeType {
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		schema := generator.schema(typ.Elem())
+		if schema.Ref != "" {
+			return (&openAPISchema{Nullable: true}).withAllOf(schema)
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &openAPISchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &openAPISchema{Type: "array", Items: generator.schema(typ.Elem())}
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			panic(fmt.Sprintf("openapi: unsupported map key type %s", typ.Key()))
+		}
+		return &openAPISchema{Type: "object", AdditionalProperties: generator.schema(typ.Elem())}
+	case reflect.Struct:
+		return generator.structSchema(typ)
+	default:
+		panic(fmt.Sprintf("openapi: unsupported type %s", typ))
+	}
+}
+
+// structSchema registers the schema of the struct as a component and returns a reference to it.
+func (generator *openAPIGenerator) structSchema(typ reflect.Type) *openAPISchema {
+	if typ.Name() == "" {
+		panic(fmt.Sprintf("openapi: anonymous struct %s, use a named type", typ))
+	}
+	name := schemaName(typ)
+	ref := &openAPISchema{Ref: "#/components/schemas/" + name}
+	if _, ok := generator.names[typ]; ok {
+		return ref
+	}
+	if _, ok := generator.schemas[name]; ok {
+		panic(fmt.Sprintf("openapi: two types with the schema name %s", name))
+	}
+	generator.names[typ] = name
+	schema := &openAPISchema{
+		Type:                 "object",
+		Properties:           map[string]*openAPISchema{},
+		Required:             []string{},
+		AdditionalProperties: false,
+	}
+	// Register before generating the fields to support recursive types.
+	generator.schemas[name] = schema
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous {
+			panic(fmt.Sprintf("openapi: embedded field %s in %s is not supported", field.Name, typ))
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if tag[0] == "-" || tag[0] == "" {
+			panic(fmt.Sprintf("openapi: field %s in %s needs a json name", field.Name, typ))
+		}
+		omitEmpty := len(tag) > 1 && tag[1] == "omitempty"
+		fieldSchema := generator.schema(field.Type)
+		if description := field.Tag.Get("description"); description != "" {
+			if fieldSchema.Ref != "" {
+				fieldSchema = (&openAPISchema{Description: description}).withAllOf(fieldSchema)
+			} else {
+				fieldSchema.Description = description
+			}
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			fieldSchema.Enum = strings.Split(enum, ",")
+		}
+		schema.Properties[tag[0]] = fieldSchema
+		if !omitEmpty {
+			schema.Required = append(schema.Required, tag[0])
+		}
+	}
+	return ref
+}
+
+// withAllOf returns the schema extended by the referenced schema. OpenAPI 3.0 does not allow
+// siblings of $ref, so they are combined using a single-element allOf.
+func (schema *openAPISchema) withAllOf(ref *openAPISchema) *openAPISchema {
+	schema.AllOf = []*openAPISchema{ref}
+	return schema
+}
+
+// newOpenAPIDocument generates the OpenAPI document of the given API routes.
+func newOpenAPIDocument(version string, routes []*apiV1Route) *openAPIDocument {
+	generator := &openAPIGenerator{
+		schemas: map[string]*openAPISchema{},
+		names:   map[reflect.Type]string{},
+	}
+	errorSchema := generator.schema(reflect.TypeOf(apiV1ErrorResponse{}))
+	document := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title: "BitBoxApp API",
+			Description: "Versioned API of the BitBoxApp backend for third-party integrations. " +
+				"Errors are returned with a 4xx or 5xx status code and an error object.",
+			Version: version,
+		},
+		Servers:  []openAPIServer{{URL: apiV1Prefix}},
+		Security: []map[string][]string{{"apiToken": {}}},
+		Paths:    map[string]map[string]*openAPIOperation{},
+		Components: openAPIComponents{
+			Schemas: generator.schemas,
+			SecuritySchemes: map[string]*openAPISecurityScheme{
+				"apiToken": {
+					Type: "apiKey",
+					In:   "header",
+					Name: "Authorization",
+					Description: "`Basic <token>`, with the API token of the running app or a scoped API token " +
+						"created in the app. Not needed in dev mode.",
+				},
+			},
+		},
+	}
+	for _, route := range routes {
+		operation := &openAPIOperation{
+			OperationID: route.operationID,
+			Summary:     route.summary,
+			Description: "Accessible with any API token.",
+			Responses:   map[string]*openAPIResponse{},
+		}
+		statuses := []int{http.StatusUnauthorized, http.StatusInternalServerError}
+		if route.scope != apiScopeAny {
+			operation.Description = fmt.Sprintf("Requires an API token with the `%s` scope.", route.scope)
+			statuses = append(statuses, http.StatusForbidden)
+		}
+		if route.allAccounts {
+			operation.Description += " Not accessible with API tokens restricted to some accounts."
+		}
+		for _, match := range openAPIPathParamRegexp.FindAllStringSubmatch(route.path, -1) {
+			description, ok := apiV1PathParams[match[1]]
+			if !ok {
+				panic(fmt.Sprintf("openapi: undocumented path parameter %s", match[1]))
+			}
+			operation.Parameters = append(operation.Parameters, &openAPIParameter{
+				Name:        match[1],
+				In:          "path",
+				Description: description,
+				Required:    true,
+				Schema:      &openAPISchema{Type: "string"},
+			})
+		}
+		for _, param := range route.queryParams {
+			operation.Parameters = append(operation.Parameters, &openAPIParameter{
+				Name:        param.name,
+				In:          "query",
+				Description: param.description,
+				Required:    false,
+				Schema:      param.schema,
+			})
+		}
+		if route.request != nil {
+			operation.RequestBody = &openAPIRequestBody{
+				Required: true,
+				Content: map[string]openAPIMediaType{
+					openAPIJSON: {Schema: generator.schema(reflect.TypeOf(route.request))},
+				},
+			}
+		}
+		responseSchema := &openAPISchema{Type: "object"}
+		if route.response != nil {
+			responseSchema = generator.schema(reflect.TypeOf(route.response))
+		}
+		operation.Responses[strconv.Itoa(http.StatusOK)] = &openAPIResponse{
+			Description: http.StatusText(http.StatusOK),
+			Content:     map[string]openAPIMediaType{openAPIJSON: {Schema: responseSchema}},
+		}
+		statuses = append(statuses, route.errors...)
+		sort.Ints(statuses)
+		for _, status := range statuses {
+			operation.Responses[strconv.Itoa(status)] = &openAPIResponse{
+				Description: http.StatusText(status),
+				Content:     map[string]openAPIMediaType{openAPIJSON: {Schema: errorSchema}},
+			}
+		}
+		if document.Paths[route.path] == nil {
+			document.Paths[route.path] = map[string]*openAPIOperation{}
+		}
+		document.Paths[route.path][strings.ToLower(route.method)] = operation
+	}
+	return document
+}
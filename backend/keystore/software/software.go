@@ -127,7 +127,8 @@ func (keystore *Keystore) SupportsAccount(coin coin.Coin, meta interface{}) bool
 		return scriptType == signing.ScriptTypeP2PKH ||
 			scriptType == signing.ScriptTypeP2WPKHP2SH ||
 			scriptType == signing.ScriptTypeP2WPKH ||
-			scriptType == signing.ScriptTypeP2TR
+			scriptType == signing.ScriptTypeP2TR ||
+			scriptType == signing.ScriptTypeP2WSH
 
 	default:
 		return false
@@ -23,18 +23,23 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/notes"
 	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/addressbook"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
+	btcutil "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/util"
 	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/util"
 	utilcfg "github.com/BitBoxSwiss/bitbox-wallet-app/util/config"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/wire"
 )
 
 // We extend the BIP-329 JSON entry with this data so the BitBoxApp can more easily identify which
@@ -46,16 +51,22 @@ import (
 //     We could still try to identify which actual descriptor fits the transaction, but that is some
 //     effort and has some gnarly edge cases like a transaction paying to multiple descriptors of one
 //     unified account at once. We could add support for this anyway if needed.
+//
+// Address book contacts are exported as `addr` entries without account code. For Ethereum contacts
+// restricted to an ERC20 token, the token code is added.
 type bip329BitBoxApp struct {
 	CoinCode    coinpkg.Code       `json:"coinCode"`
-	AccountCode accountsTypes.Code `json:"code"`
+	AccountCode accountsTypes.Code `json:"code,omitempty"`
+	ERC20Token  coinpkg.Code       `json:"erc20Token,omitempty"`
 }
 
 type bip329Type string
 
 const (
-	bip329TypeTx   bip329Type = "tx"
-	bip329TypeXpub bip329Type = "xpub"
+	bip329TypeTx     bip329Type = "tx"
+	bip329TypeXpub   bip329Type = "xpub"
+	bip329TypeOutput bip329Type = "output"
+	bip329TypeAddr   bip329Type = "addr"
 )
 
 // https://github.com/bitcoin/bips/blob/master/bip-0329.mediawiki#specification
@@ -64,6 +75,8 @@ type bip329Entry struct {
 	Type  bip329Type `json:"type"`
 	Ref   string     `json:"ref"`
 	Label string     `json:"label,omitempty"`
+	// Spendable is only used for outputs. False if the output is frozen.
+	Spendable *bool `json:"spendable,omitempty"`
 
 	// We don't use the origin field currently, see the docstring of `bip329BitBoxApp` above for the
 	// reason why.
@@ -72,6 +85,70 @@ type bip329Entry struct {
 	BitBoxApp *bip329BitBoxApp `json:"bitboxapp,omitempty"`
 }
 
+// coinControlAccount is implemented by accounts which support freezing and labeling outputs, see
+// `btc.Account`.
+type coinControlAccount interface {
+	UTXOStates() (map[wire.OutPoint]types.UTXOState, error)
+	SetUTXOFrozen(outPoint wire.OutPoint, frozen bool) (bool, error)
+	SetUTXOLabel(outPoint wire.OutPoint, label string) (bool, error)
+}
+
+// exportOutputs exports the labels and frozen states of the outputs of the account, sorted by
+// outpoint.
+func exportOutputs(writer io.Writer, account accounts.Interface) error {
+	coinControl, ok := account.(coinControlAccount)
+	if !ok {
+		return nil
+	}
+	states, err := coinControl.UTXOStates()
+	if err != nil {
+		return err
+	}
+	entries := make([]bip329Entry, 0, len(states))
+	for outPoint, state := range states {
+		entry := bip329Entry{
+			Type:  bip329TypeOutput,
+			Ref:   outPoint.String(),
+			Label: state.Label,
+			BitBoxApp: &bip329BitBoxApp{
+				CoinCode:    account.Config().Config.CoinCode,
+				AccountCode: account.Config().Config.Code,
+			},
+		}
+		if state.Frozen {
+			spendable := false
+			entry.Spendable = &spendable
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Ref < entries[j].Ref })
+	for _, entry := range entries {
+		if err := json.NewEncoder(writer).Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportContacts exports the address book, sorted by coin code and name.
+func (backend *Backend) exportContacts(writer io.Writer) error {
+	for _, contact := range backend.addressBook.AllContacts() {
+		entry := bip329Entry{
+			Type:  bip329TypeAddr,
+			Ref:   contact.Address,
+			Label: contact.Name,
+			BitBoxApp: &bip329BitBoxApp{
+				CoinCode:   contact.CoinCode,
+				ERC20Token: contact.ERC20Token,
+			},
+		}
+		if err := json.NewEncoder(writer).Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (backend *Backend) exportNotes(writer io.Writer) error {
 	accounts := backend.Accounts()
 
@@ -145,12 +222,16 @@ func (backend *Backend) exportNotes(writer io.Writer) error {
 				return err
 			}
 		}
+
+		if err := exportOutputs(writer, account); err != nil {
+			return err
+		}
 	}
-	return nil
+	return backend.exportContacts(writer)
 }
 
-// ExportNotes exports the transactions and accounts labels of all accounts of all
-// connected/remembered keystores. Deactivated accounts are included in the export, except for
+// ExportNotes exports the transactions, outputs and accounts labels of all accounts of all
+// connected/remembered keystores, as well as the address book. Deactivated accounts are included in the export, except for
 // deactivated ERC-20 accounts. We export to a file using an extended version of BIP-329:
 // https://github.com/bitcoin/bips/blob/master/bip-0329.mediawiki
 func (backend *Backend) ExportNotes() error {
@@ -195,6 +276,10 @@ type ImportNotesResult struct {
 	AccountCount int `json:"accountCount"`
 	// TransactionCount is the number of transaction notes updated.
 	TransactionCount int `json:"transactionCount"`
+	// OutputCount is the number of outputs whose label or frozen state was updated.
+	OutputCount int `json:"outputCount"`
+	// ContactCount is the number of address book contacts added or renamed.
+	ContactCount int `json:"contactCount"`
 }
 
 // ImportNotes imports notes from a jsonlines document according to BIP-329:
@@ -237,7 +322,9 @@ func (backend *Backend) ImportNotes(jsonLines []byte) (*ImportNotesResult, error
 
 		label := util.TruncateString(strings.TrimSpace(entry.Label), notes.MaxNoteLen)
 		ref := strings.TrimSpace(entry.Ref)
-		if label == "" || ref == "" {
+		// Outputs can be imported without label to only freeze or unfreeze them.
+		hasOutputState := entry.Type == bip329TypeOutput && entry.Spendable != nil
+		if (label == "" && !hasOutputState) || ref == "" {
 			continue
 		}
 
@@ -310,6 +397,82 @@ func (backend *Backend) ImportNotes(jsonLines []byte) (*ImportNotesResult, error
 			if changed {
 				result.TransactionCount += 1
 			}
+
+		case bip329TypeOutput:
+			// Import output label and frozen state.
+			outPoint, err := btcutil.ParseOutPoint([]byte(ref))
+			if err != nil {
+				// Not a valid outpoint, skipping.
+				continue
+			}
+			var account accounts.Interface
+			if entry.BitBoxApp != nil {
+				account = backend.Accounts().lookup(entry.BitBoxApp.AccountCode)
+			} else {
+				acct, err := backend.Accounts().lookupByTransactionInternalID(outPoint.Hash.String())
+				if err != nil {
+					return nil, err
+				}
+				account = acct
+			}
+			if account == nil {
+				// Could not find account containing this output. Skipping.
+				continue
+			}
+			if err := account.Initialize(); err != nil {
+				return nil, err
+			}
+			coinControl, ok := account.(coinControlAccount)
+			if !ok {
+				continue
+			}
+			changed := false
+			if label != "" {
+				labelChanged, err := coinControl.SetUTXOLabel(*outPoint, label)
+				if err != nil {
+					return nil, err
+				}
+				changed = changed || labelChanged
+			}
+			if entry.Spendable != nil {
+				frozenChanged, err := coinControl.SetUTXOFrozen(*outPoint, !*entry.Spendable)
+				if err != nil {
+					return nil, err
+				}
+				changed = changed || frozenChanged
+			}
+			if changed {
+				result.OutputCount += 1
+			}
+
+		case bip329TypeAddr:
+			// Import address book contact. Entries of other wallets are assumed to be Bitcoin
+			// addresses.
+			coinCode := coinpkg.CodeBTC
+			var erc20Token coinpkg.Code
+			if entry.BitBoxApp != nil {
+				coinCode = entry.BitBoxApp.CoinCode
+				erc20Token = entry.BitBoxApp.ERC20Token
+			}
+			address, err := backend.normalizeContactAddress(coinCode, ref, erc20Token)
+			if err != nil {
+				// Not a valid address of a supported coin, skipping.
+				continue
+			}
+			name := util.TruncateString(label, addressbook.MaxNameLen)
+			contact := backend.addressBook.Lookup(coinCode, erc20Token, address)
+			switch {
+			case contact == nil || contact.ERC20Token != erc20Token:
+				if _, err := backend.AddContact(coinCode, name, address, erc20Token); err != nil {
+					return nil, err
+				}
+				result.ContactCount += 1
+			case contact.Name != name:
+				if _, err := backend.UpdateContact(contact.ID, name, address, erc20Token); err != nil {
+					return nil, err
+				}
+				result.ContactCount += 1
+			}
 		}
 	}
 
@@ -22,16 +22,53 @@ import (
 	"testing"
 
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	accountsMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/mocks"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/notes"
 	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
 	"github.com/BitBoxSwiss/bitbox-wallet-app/util/test"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
 )
 
+// coinControlAccountMock adds in-memory output labels and frozen states to an account mock.
+type coinControlAccountMock struct {
+	*accountsMocks.InterfaceMock
+	states map[wire.OutPoint]types.UTXOState
+}
+
+func (account *coinControlAccountMock) UTXOStates() (map[wire.OutPoint]types.UTXOState, error) {
+	return account.states, nil
+}
+
+func (account *coinControlAccountMock) modify(outPoint wire.OutPoint, f func(*types.UTXOState)) bool {
+	state := account.states[outPoint]
+	f(&state)
+	if state == account.states[outPoint] {
+		return false
+	}
+	if state.IsEmpty() {
+		delete(account.states, outPoint)
+	} else {
+		account.states[outPoint] = state
+	}
+	return true
+}
+
+func (account *coinControlAccountMock) SetUTXOFrozen(outPoint wire.OutPoint, frozen bool) (bool, error) {
+	return account.modify(outPoint, func(state *types.UTXOState) { state.Frozen = frozen }), nil
+}
+
+func (account *coinControlAccountMock) SetUTXOLabel(outPoint wire.OutPoint, label string) (bool, error) {
+	return account.modify(outPoint, func(state *types.UTXOState) { state.Label = label }), nil
+}
+
 type notesTestSuite struct {
 	suite.Suite
 	backend *Backend
@@ -84,7 +121,10 @@ func (s *notesTestSuite) SetupTest() {
 		accountMock.NotesFunc = notesFunc(config.Config.Code)
 		accountMock.TransactionsFunc = transactionsFunc(config.Config.Code)
 
-		return accountMock
+		return &coinControlAccountMock{
+			InterfaceMock: accountMock,
+			states:        map[wire.OutPoint]types.UTXOState{},
+		}
 	}
 	s.backend.makeEthAccount = func(config *accounts.AccountConfig, coin *eth.Coin, httpClient *http.Client, log *logrus.Entry) accounts.Interface {
 		accountMock := MockEthAccount(config, coin, httpClient, log)
@@ -266,3 +306,114 @@ INVALID LINE
 	s.Require().NotNil(btcAcct)
 	s.Require().Equal("", btcAcct.Notes().TxNote("btc-tx-id"))
 }
+
+func (s *notesTestSuite) TestOutputs() {
+	btcAcct, ok := s.backend.Accounts().lookup("v0-55555555-btc-0").(*coinControlAccountMock)
+	s.Require().True(ok)
+
+	frozen := wire.OutPoint{Hash: chainhash.HashH([]byte("frozen")), Index: 1}
+	labeled := wire.OutPoint{Hash: chainhash.HashH([]byte("labeled")), Index: 0}
+	_, err := btcAcct.SetUTXOFrozen(frozen, true)
+	s.Require().NoError(err)
+	_, err = btcAcct.SetUTXOLabel(labeled, "cold storage")
+	s.Require().NoError(err)
+
+	var export bytes.Buffer
+	s.Require().NoError(s.backend.exportNotes(&export))
+	expectedLines := []string{
+		fmt.Sprintf(`{"type":"output","ref":"%s","spendable":false,"bitboxapp":{"coinCode":"btc","code":"v0-55555555-btc-0"}}`, frozen),
+		fmt.Sprintf(`{"type":"output","ref":"%s","label":"cold storage","bitboxapp":{"coinCode":"btc","code":"v0-55555555-btc-0"}}`, labeled),
+	}
+	if labeled.String() < frozen.String() {
+		expectedLines[0], expectedLines[1] = expectedLines[1], expectedLines[0]
+	}
+	s.Require().Contains(export.String(), strings.Join(expectedLines, "\n")+"\n")
+
+	// Round trip into fresh state.
+	btcAcct.states = map[wire.OutPoint]types.UTXOState{}
+	result, err := s.backend.ImportNotes(export.Bytes())
+	s.Require().NoError(err)
+	s.Require().Equal(2, result.OutputCount)
+	s.Require().Equal(
+		map[wire.OutPoint]types.UTXOState{
+			frozen:  {Frozen: true},
+			labeled: {Label: "cold storage"},
+		},
+		btcAcct.states)
+
+	// Unfreeze, and skip outputs which cannot be found or are invalid.
+	unknown := wire.OutPoint{Hash: chainhash.HashH([]byte("unknown")), Index: 0}
+	export.Reset()
+	fmt.Fprintf(&export, `{"type":"output","ref":"%s","spendable":true,"bitboxapp":{"coinCode":"btc","code":"v0-55555555-btc-0"}}
+{"type":"output","ref":"%s","label":"not found"}
+{"type":"output","ref":"invalid","label":"invalid"}
+{"type":"output","ref":"%s","label":"cold storage","bitboxapp":{"coinCode":"btc","code":"v0-55555555-btc-0"}}
+`, frozen, unknown, labeled)
+	result, err = s.backend.ImportNotes(export.Bytes())
+	s.Require().NoError(err)
+	s.Require().Equal(&ImportNotesResult{OutputCount: 1}, result)
+	s.Require().Equal(
+		map[wire.OutPoint]types.UTXOState{labeled: {Label: "cold storage"}},
+		btcAcct.states)
+}
+
+func (s *notesTestSuite) TestContacts() {
+	const btcAddress = "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh"
+	const ethAddress = "0x773a0c5dd7e2b5fe8fd6e7e6b1d1db7e8ab0f7f5"
+	checksummedETHAddress := ethcommon.HexToAddress(ethAddress).Hex()
+
+	supplier, err := s.backend.AddContact(coinpkg.CodeBTC, "Supplier", strings.ToUpper(btcAddress), "")
+	s.Require().NoError(err)
+	s.Require().Equal(btcAddress, supplier.Address)
+	_, err = s.backend.AddContact(coinpkg.CodeETH, "Exchange", ethAddress, "eth-erc20-usdt")
+	s.Require().NoError(err)
+	_, err = s.backend.AddContact(coinpkg.CodeBTC, "Invalid", ethAddress, "")
+	s.Require().Error(err)
+	_, err = s.backend.AddContact(coinpkg.CodeBTC, "Token", btcAddress, "eth-erc20-usdt")
+	s.Require().Error(err)
+
+	// Proposals find the contact by the recipient address.
+	btcCoin, err := s.backend.Coin(coinpkg.CodeBTC)
+	s.Require().NoError(err)
+	s.Require().Equal(supplier, s.backend.lookupContact(btcCoin, btcAddress))
+	usdtCoin, err := s.backend.Coin("eth-erc20-usdt")
+	s.Require().NoError(err)
+	s.Require().Equal("Exchange", s.backend.lookupContact(usdtCoin, checksummedETHAddress).Name)
+	ethCoin, err := s.backend.Coin(coinpkg.CodeETH)
+	s.Require().NoError(err)
+	s.Require().Nil(s.backend.lookupContact(ethCoin, ethAddress))
+
+	var export bytes.Buffer
+	s.Require().NoError(s.backend.exportNotes(&export))
+	s.Require().Contains(export.String(), fmt.Sprintf(
+		`{"type":"addr","ref":"%s","label":"Supplier","bitboxapp":{"coinCode":"btc"}}
+{"type":"addr","ref":"%s","label":"Exchange","bitboxapp":{"coinCode":"eth","erc20Token":"eth-erc20-usdt"}}
+`, btcAddress, checksummedETHAddress))
+
+	// Importing the same contacts again changes nothing.
+	result, err := s.backend.ImportNotes(export.Bytes())
+	s.Require().NoError(err)
+	s.Require().Equal(0, result.ContactCount)
+
+	for _, contact := range s.backend.addressBook.AllContacts() {
+		s.Require().NoError(s.backend.RemoveContact(contact.ID))
+	}
+	result, err = s.backend.ImportNotes(export.Bytes())
+	s.Require().NoError(err)
+	s.Require().Equal(2, result.ContactCount)
+	s.Require().Len(s.backend.Contacts(coinpkg.CodeBTC), 1)
+	s.Require().Len(s.backend.Contacts(coinpkg.CodeETH), 1)
+
+	// Entries without BitBoxApp data are Bitcoin addresses. Existing contacts are renamed.
+	result, err = s.backend.ImportNotes([]byte(fmt.Sprintf(
+		`{"type":"addr","ref":"%s","label":"Supplier Inc."}
+{"type":"addr","ref":"%s","label":"Not a Bitcoin address"}
+{"type":"addr","ref":"bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq","label":"Landlord"}
+`, btcAddress, ethAddress)))
+	s.Require().NoError(err)
+	s.Require().Equal(&ImportNotesResult{ContactCount: 2}, result)
+	contacts := s.backend.Contacts(coinpkg.CodeBTC)
+	s.Require().Len(contacts, 2)
+	s.Require().Equal("Landlord", contacts[0].Name)
+	s.Require().Equal("Supplier Inc.", contacts[1].Name)
+}
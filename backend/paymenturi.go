@@ -0,0 +1,187 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/paymenturi"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/observable"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/observable/action"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// PaymentURIRequest is a payment requested by a payment URI, resolved to the accounts that can pay
+// it.
+type PaymentURIRequest struct {
+	// URI is the original payment URI.
+	URI string `json:"uri"`
+	// CoinCode is the coin requested by the URI.
+	CoinCode coinpkg.Code `json:"coinCode"`
+	// Accounts are the accounts which can pay the request. The first one is preselected.
+	Accounts []account `json:"accounts"`
+	// AccountCode is the code of the preselected account. Empty if there is no matching account.
+	AccountCode accountsTypes.Code `json:"accountCode"`
+	// Address is the recipient address.
+	Address string `json:"address"`
+	// Amount is the requested amount formatted in the unit of the coin, as expected by the tx
+	// proposal. Empty if no amount was requested.
+	Amount  string `json:"amount"`
+	Label   string `json:"label"`
+	Message string `json:"message"`
+	// Note is the suggested transaction note.
+	Note string `json:"note"`
+	// PayjoinURL is the BIP-78 payjoin endpoint requested by the recipient. Payjoin is not
+	// supported, so the payment is made using a regular transaction.
+	PayjoinURL string `json:"payjoinURL,omitempty"`
+}
+
+// TxProposalArgs returns the tx proposal arguments prefilled with the recipient, amount and note
+// of the payment request. The default fee target is used.
+func (request *PaymentURIRequest) TxProposalArgs() *accounts.TxProposalArgs {
+	return &accounts.TxProposalArgs{
+		RecipientAddress: request.Address,
+		Amount:           coinpkg.NewSendAmount(request.Amount),
+		FeeTargetCode:    accounts.DefaultFeeTarget,
+		Note:             request.Note,
+	}
+}
+
+// paymentURICoinCode returns the code of the coin requested by the given payment URI.
+func (backend *Backend) paymentURICoinCode(uri *paymenturi.URI) (coinpkg.Code, error) {
+	switch uri.Scheme {
+	case paymenturi.SchemeBitcoin:
+		switch {
+		case backend.arguments.Regtest():
+			return coinpkg.CodeRBTC, nil
+		case backend.Testing():
+			return coinpkg.CodeTBTC, nil
+		default:
+			return coinpkg.CodeBTC, nil
+		}
+	case paymenturi.SchemeLitecoin:
+		if backend.Testing() {
+			return coinpkg.CodeTLTC, nil
+		}
+		return coinpkg.CodeLTC, nil
+	case paymenturi.SchemeEthereum:
+		var code coinpkg.Code
+		switch uri.ChainID {
+		case params.MainnetChainConfig.ChainID.Uint64():
+			code = coinpkg.CodeETH
+		case params.SepoliaChainConfig.ChainID.Uint64():
+			code = coinpkg.CodeSEPETH
+		default:
+			return "", errp.Newf("unsupported chain ID %d", uri.ChainID)
+		}
+		if uri.ERC20ContractAddress == "" {
+			return code, nil
+		}
+		if code != coinpkg.CodeETH {
+			return "", errp.New("tokens are only supported on Ethereum mainnet")
+		}
+		if token := erc20TokenByContractAddress(uri.ERC20ContractAddress); token != nil {
+			return token.code, nil
+		}
+		code = customERC20TokenCode(common.HexToAddress(uri.ERC20ContractAddress))
+		if backend.config.AccountsConfig().LookupERC20Token(code) == nil {
+			return "", errp.Newf("unknown token %s", uri.ERC20ContractAddress)
+		}
+		return code, nil
+	default:
+		return "", errp.Newf("unsupported payment URI scheme: %s", uri.Scheme)
+	}
+}
+
+// ParsePaymentURI parses a BIP-21 or EIP-681 payment URI and resolves the accounts which can pay
+// it.
+func (backend *Backend) ParsePaymentURI(uri string) (*PaymentURIRequest, error) {
+	parsed, err := paymenturi.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	coinCode, err := backend.paymentURICoinCode(parsed)
+	if err != nil {
+		return nil, err
+	}
+	coin, err := backend.Coin(coinCode)
+	if err != nil {
+		return nil, err
+	}
+	request := &PaymentURIRequest{
+		URI:        uri,
+		CoinCode:   coinCode,
+		Accounts:   []account{},
+		Address:    parsed.Address,
+		Label:      parsed.Label,
+		Message:    parsed.Message,
+		Note:       parsed.Message,
+		PayjoinURL: parsed.PayjoinURL,
+	}
+	if request.Note == "" {
+		request.Note = parsed.Label
+	}
+	if parsed.Amount != nil {
+		request.Amount = coin.FormatAmount(coinpkg.NewAmount(parsed.Amount), false)
+	}
+	for _, acct := range backend.Accounts() {
+		accountConfig := acct.Config().Config
+		if accountConfig.Inactive || accountConfig.HiddenBecauseUnused || acct.Coin().Code() != coinCode {
+			continue
+		}
+		request.Accounts = append(request.Accounts, account{Name: accountConfig.Name, Code: accountConfig.Code})
+	}
+	if len(request.Accounts) > 0 {
+		request.AccountCode = request.Accounts[0].Code
+	}
+	return request, nil
+}
+
+// handlePaymentURI handles a payment URI opened with the app, e.g. by clicking a `bitcoin:` link.
+// The resolved payment request is made available to the frontend.
+func (backend *Backend) handlePaymentURI(uri string) {
+	request, err := backend.ParsePaymentURI(uri)
+	if err != nil {
+		backend.log.WithError(err).Warning("Handling payment URI failed")
+		return
+	}
+	defer backend.accountsAndKeystoreLock.Lock()()
+	backend.paymentURI = request
+	backend.notifyPaymentURI()
+}
+
+func (backend *Backend) notifyPaymentURI() {
+	backend.Notify(observable.Event{
+		Subject: "payment-uri",
+		Action:  action.Replace,
+		Object:  backend.paymentURI,
+	})
+}
+
+// PaymentURI returns the last payment request opened with the app, or nil.
+func (backend *Backend) PaymentURI() *PaymentURIRequest {
+	defer backend.accountsAndKeystoreLock.RLock()()
+	return backend.paymentURI
+}
+
+// PaymentURICancel dismisses the last payment request opened with the app.
+func (backend *Backend) PaymentURICancel() {
+	defer backend.accountsAndKeystoreLock.Lock()()
+	backend.paymentURI = nil
+	backend.notifyPaymentURI()
+}
@@ -0,0 +1,320 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package paymenturi parses and generates payment URIs, as found in invoices and QR codes:
+// BIP-21 for Bitcoin and Litecoin (https://github.com/bitcoin/bips/blob/master/bip-0021.mediawiki)
+// and EIP-681 for Ethereum and ERC20 tokens (https://eips.ethereum.org/EIPS/eip-681).
+package paymenturi
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Scheme is the scheme of a payment URI.
+type Scheme string
+
+const (
+	// SchemeBitcoin is the BIP-21 scheme for Bitcoin.
+	SchemeBitcoin Scheme = "bitcoin"
+	// SchemeLitecoin is the BIP-21 scheme for Litecoin.
+	SchemeLitecoin Scheme = "litecoin"
+	// SchemeEthereum is the EIP-681 scheme for Ethereum and ERC20 tokens.
+	SchemeEthereum Scheme = "ethereum"
+)
+
+// EthereumMainnetChainID is the chain ID of EIP-681 URIs that do not specify one.
+const EthereumMainnetChainID = 1
+
+// bip21Decimals is the number of decimals of BIP-21 amounts, which are given in BTC/LTC.
+const bip21Decimals = 8
+
+// eip681TransferFunction is the ERC20 function invoked by EIP-681 token transfer URIs.
+const eip681TransferFunction = "transfer"
+
+var bip21AmountRegexp = regexp.MustCompile(`^([0-9]+\.?[0-9]*|\.[0-9]+)$`)
+
+var eip681NumberRegexp = regexp.MustCompile(`^\+?([0-9]+(\.[0-9]+)?)([eE]([0-9]+))?$`)
+
+// URI is a parsed payment URI.
+type URI struct {
+	Scheme Scheme
+	// Address is the recipient address. For ERC20 token transfers, it is the token recipient, not
+	// the token contract.
+	Address string
+	// Amount is the requested amount in the smallest unit (satoshi, wei or the token base unit). nil
+	// if no amount is requested. Amounts which are not positive are not encoded.
+	Amount *big.Int
+	// Label is the BIP-21 label of the recipient.
+	Label string
+	// Message is the BIP-21 message describing the payment.
+	Message string
+	// PayjoinURL is the BIP-78 payjoin endpoint (`pj` parameter). Payjoin is not supported, but
+	// the payment can still be made using a regular transaction.
+	PayjoinURL string
+	// ChainID is the EIP-155 chain ID of an Ethereum URI. Only applies if Scheme is
+	// SchemeEthereum.
+	ChainID uint64
+	// ERC20ContractAddress is the token contract for ERC20 token transfers. Empty for plain
+	// Ethereum payments. Only applies if Scheme is SchemeEthereum.
+	ERC20ContractAddress string
+}
+
+// New creates a URI for payments in the given coin to the given address. In case of an ERC20
+// token, the token contract and the chain ID are set accordingly.
+func New(coin coinpkg.Coin, address string) (*URI, error) {
+	switch coin.Code() {
+	case coinpkg.CodeBTC, coinpkg.CodeTBTC, coinpkg.CodeRBTC:
+		return &URI{Scheme: SchemeBitcoin, Address: address}, nil
+	case coinpkg.CodeLTC, coinpkg.CodeTLTC:
+		return &URI{Scheme: SchemeLitecoin, Address: address}, nil
+	}
+	ethCoin, ok := coin.(*eth.Coin)
+	if !ok {
+		return nil, errp.Newf("payment URIs are not supported for %s", coin.Code())
+	}
+	uri := &URI{Scheme: SchemeEthereum, Address: address, ChainID: ethCoin.ChainID()}
+	if token := ethCoin.ERC20Token(); token != nil {
+		uri.ERC20ContractAddress = token.ContractAddress().Hex()
+	}
+	return uri, nil
+}
+
+// Parse parses a BIP-21 (bitcoin:, litecoin:) or EIP-681 (ethereum:) payment URI.
+func Parse(uri string) (*URI, error) {
+	scheme, rest, ok := strings.Cut(strings.TrimSpace(uri), ":")
+	if !ok {
+		return nil, errp.New("invalid payment URI")
+	}
+	// Some wallets add slashes like in URLs, e.g. `bitcoin://<address>`.
+	rest = strings.TrimPrefix(rest, "//")
+	switch Scheme(strings.ToLower(scheme)) {
+	case SchemeBitcoin:
+		return parseBIP21(SchemeBitcoin, rest)
+	case SchemeLitecoin:
+		return parseBIP21(SchemeLitecoin, rest)
+	case SchemeEthereum:
+		return parseEIP681(rest)
+	default:
+		return nil, errp.Newf("unsupported payment URI scheme: %s", scheme)
+	}
+}
+
+// parseQuery parses the URI parameters, rejecting parameters that appear more than once.
+func parseQuery(rawQuery string) (map[string]string, error) {
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	params := map[string]string{}
+	for key, values := range query {
+		if len(values) != 1 {
+			return nil, errp.Newf("duplicate payment URI parameter: %s", key)
+		}
+		params[key] = values[0]
+	}
+	return params, nil
+}
+
+func parseBIP21(scheme Scheme, rest string) (*URI, error) {
+	address, rawQuery, _ := strings.Cut(rest, "?")
+	address, err := url.PathUnescape(address)
+	if err != nil {
+		return nil, errp.WithStack(err)
+	}
+	if address == "" {
+		// E.g. a Lightning-only URI `bitcoin:?lightning=<invoice>`.
+		return nil, errp.New("payment URI without an on-chain address")
+	}
+	params, err := parseQuery(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+	result := &URI{Scheme: scheme, Address: address}
+	for key, value := range params {
+		switch key {
+		case "amount":
+			if !bip21AmountRegexp.MatchString(value) {
+				return nil, errp.Newf("invalid payment URI amount: %s", value)
+			}
+			amount, err := coinpkg.NewAmountFromString(value, new(big.Int).Exp(big.NewInt(10), big.NewInt(bip21Decimals), nil))
+			if err != nil {
+				return nil, errp.Newf("invalid payment URI amount: %s", value)
+			}
+			result.Amount = amount.BigInt()
+		case "label":
+			result.Label = value
+		case "message":
+			result.Message = value
+		case "pj":
+			result.PayjoinURL = value
+		case "lightning":
+			// The BOLT11 invoice of a unified QR code. Lightning is not supported here, so the
+			// on-chain address is used instead.
+		default:
+			// Unknown parameters must be ignored unless they are marked as required.
+			if strings.HasPrefix(key, "req-") {
+				return nil, errp.Newf("unsupported required payment URI parameter: %s", key)
+			}
+		}
+	}
+	return result, nil
+}
+
+// parseEIP681Number parses a number as specified by EIP-681, which can be in scientific notation,
+// e.g. `2.014e18`. The result must be a non-negative integer.
+func parseEIP681Number(value string) (*big.Int, error) {
+	match := eip681NumberRegexp.FindStringSubmatch(value)
+	if match == nil {
+		return nil, errp.Newf("invalid number: %s", value)
+	}
+	number, ok := new(big.Rat).SetString(match[1])
+	if !ok {
+		return nil, errp.Newf("invalid number: %s", value)
+	}
+	if match[4] != "" {
+		exponent, err := strconv.ParseUint(match[4], 10, 16)
+		if err != nil {
+			return nil, errp.Newf("invalid number: %s", value)
+		}
+		factor := new(big.Int).Exp(big.NewInt(10), new(big.Int).SetUint64(exponent), nil)
+		number.Mul(number, new(big.Rat).SetInt(factor))
+	}
+	if !number.IsInt() {
+		return nil, errp.Newf("not an integer: %s", value)
+	}
+	return number.Num(), nil
+}
+
+func parseEIP681(rest string) (*URI, error) {
+	path, rawQuery, _ := strings.Cut(rest, "?")
+	path = strings.TrimPrefix(path, "pay-")
+	target, functionName, _ := strings.Cut(path, "/")
+	target, chainID, hasChainID := strings.Cut(target, "@")
+	if !common.IsHexAddress(target) {
+		// ENS names are not supported.
+		return nil, errp.Newf("invalid Ethereum address: %s", target)
+	}
+	result := &URI{Scheme: SchemeEthereum, ChainID: EthereumMainnetChainID}
+	if hasChainID {
+		parsedChainID, err := strconv.ParseUint(chainID, 10, 64)
+		if err != nil {
+			return nil, errp.Newf("invalid chain ID: %s", chainID)
+		}
+		result.ChainID = parsedChainID
+	}
+	params, err := parseQuery(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+	// The gas parameters (gas, gasLimit, gasPrice) are ignored, as the fees are chosen by the user.
+	switch functionName {
+	case "":
+		result.Address = target
+		if value, ok := params["value"]; ok {
+			amount, err := parseEIP681Number(value)
+			if err != nil {
+				return nil, err
+			}
+			result.Amount = amount
+		}
+	case eip681TransferFunction:
+		result.ERC20ContractAddress = target
+		recipient, ok := params["address"]
+		if !ok || !common.IsHexAddress(recipient) {
+			return nil, errp.Newf("invalid token recipient: %s", recipient)
+		}
+		result.Address = recipient
+		if value, ok := params["value"]; ok {
+			amount, err := parseEIP681Number(value)
+			if err != nil {
+				return nil, err
+			}
+			if amount.Sign() != 0 {
+				return nil, errp.New("token transfers must not send Ether")
+			}
+		}
+		if value, ok := params["uint256"]; ok {
+			amount, err := parseEIP681Number(value)
+			if err != nil {
+				return nil, err
+			}
+			result.Amount = amount
+		}
+	default:
+		return nil, errp.Newf("unsupported contract function: %s", functionName)
+	}
+	return result, nil
+}
+
+// escape escapes a BIP-21 parameter value. Spaces are encoded as `%20`, as some wallets do not
+// decode `+`.
+func escape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// String encodes the URI.
+func (uri *URI) String() string {
+	hasAmount := uri.Amount != nil && uri.Amount.Sign() > 0
+	var params []string
+	var result string
+	switch uri.Scheme {
+	case SchemeEthereum:
+		chainID := ""
+		if uri.ChainID != 0 && uri.ChainID != EthereumMainnetChainID {
+			chainID = fmt.Sprintf("@%d", uri.ChainID)
+		}
+		if uri.ERC20ContractAddress != "" {
+			result = fmt.Sprintf("%s:%s%s/%s", uri.Scheme, uri.ERC20ContractAddress, chainID, eip681TransferFunction)
+			params = append(params, "address="+uri.Address)
+			if hasAmount {
+				params = append(params, "uint256="+uri.Amount.String())
+			}
+		} else {
+			result = fmt.Sprintf("%s:%s%s", uri.Scheme, uri.Address, chainID)
+			if hasAmount {
+				params = append(params, "value="+uri.Amount.String())
+			}
+		}
+	default:
+		result = fmt.Sprintf("%s:%s", uri.Scheme, uri.Address)
+		if hasAmount {
+			factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(bip21Decimals), nil)
+			amount := new(big.Rat).SetFrac(uri.Amount, factor).FloatString(bip21Decimals)
+			params = append(params, "amount="+strings.TrimRight(strings.TrimRight(amount, "0"), "."))
+		}
+		if uri.Label != "" {
+			params = append(params, "label="+escape(uri.Label))
+		}
+		if uri.Message != "" {
+			params = append(params, "message="+escape(uri.Message))
+		}
+		if uri.PayjoinURL != "" {
+			params = append(params, "pj="+escape(uri.PayjoinURL))
+		}
+	}
+	if len(params) > 0 {
+		result += "?" + strings.Join(params, "&")
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package paymenturi
+
+import (
+	"math/big"
+	"testing"
+
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	coinMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth/erc20"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	btcAddress   = "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+	ethAddress   = "0xa29163852021BF4C139D03Dff59ae763AC73e84e"
+	tokenAddress = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+)
+
+func TestParseBIP21(t *testing.T) {
+	uri, err := Parse("bitcoin:" + btcAddress)
+	require.NoError(t, err)
+	require.Equal(t, &URI{Scheme: SchemeBitcoin, Address: btcAddress}, uri)
+
+	uri, err = Parse("BITCOIN:" + btcAddress +
+		"?amount=0.0012&label=Luke%20Jr&message=Donation+for+project%20xyz&lightning=lnbc1&foo=bar&pj=https://example.com/pj")
+	require.NoError(t, err)
+	require.Equal(t, &URI{
+		Scheme:     SchemeBitcoin,
+		Address:    btcAddress,
+		Amount:     big.NewInt(120000),
+		Label:      "Luke Jr",
+		Message:    "Donation for project xyz",
+		PayjoinURL: "https://example.com/pj",
+	}, uri)
+
+	uri, err = Parse("litecoin://ltc1qaddress?amount=1")
+	require.NoError(t, err)
+	require.Equal(t, &URI{Scheme: SchemeLitecoin, Address: "ltc1qaddress", Amount: big.NewInt(1e8)}, uri)
+
+	for _, invalid := range []string{
+		"bitcoin:",
+		"bitcoin:?lightning=lnbc1",
+		"bitcoin:" + btcAddress + "?amount=0.123456789",
+		"bitcoin:" + btcAddress + "?amount=-1",
+		"bitcoin:" + btcAddress + "?amount=1e3",
+		"bitcoin:" + btcAddress + "?amount=1,5",
+		"bitcoin:" + btcAddress + "?amount=1&amount=2",
+		"bitcoin:" + btcAddress + "?req-somethingyoudontunderstand=50",
+		"dogecoin:" + btcAddress,
+		btcAddress,
+	} {
+		_, err := Parse(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestParseEIP681(t *testing.T) {
+	uri, err := Parse("ethereum:" + ethAddress)
+	require.NoError(t, err)
+	require.Equal(t, &URI{Scheme: SchemeEthereum, Address: ethAddress, ChainID: 1}, uri)
+
+	uri, err = Parse("ethereum:pay-" + ethAddress + "@11155111?value=2.014e18&gasPrice=1e9")
+	require.NoError(t, err)
+	require.Equal(t, &URI{
+		Scheme:  SchemeEthereum,
+		Address: ethAddress,
+		Amount:  big.NewInt(2014e15),
+		ChainID: 11155111,
+	}, uri)
+
+	uri, err = Parse("ethereum:" + tokenAddress + "/transfer?address=" + ethAddress + "&uint256=1e6")
+	require.NoError(t, err)
+	require.Equal(t, &URI{
+		Scheme:               SchemeEthereum,
+		Address:              ethAddress,
+		Amount:               big.NewInt(1e6),
+		ChainID:              1,
+		ERC20ContractAddress: tokenAddress,
+	}, uri)
+
+	for _, invalid := range []string{
+		"ethereum:vitalik.eth",
+		"ethereum:" + ethAddress + "@mainnet",
+		"ethereum:" + ethAddress + "?value=1.5",
+		"ethereum:" + ethAddress + "?value=-1",
+		"ethereum:" + tokenAddress + "/transfer?uint256=1",
+		"ethereum:" + tokenAddress + "/transfer?address=" + ethAddress + "&value=1",
+		"ethereum:" + tokenAddress + "/approve?address=" + ethAddress + "&uint256=1",
+	} {
+		_, err := Parse(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, uri := range []string{
+		"bitcoin:" + btcAddress,
+		"bitcoin:" + btcAddress + "?amount=0.0012&label=Luke%20Jr&message=a%26b",
+		"litecoin:ltc1qaddress?amount=1",
+		"ethereum:" + ethAddress,
+		"ethereum:" + ethAddress + "@11155111?value=2014000000000000000",
+		"ethereum:" + tokenAddress + "/transfer?address=" + ethAddress + "&uint256=1000000",
+	} {
+		parsed, err := Parse(uri)
+		require.NoError(t, err)
+		require.Equal(t, uri, parsed.String())
+	}
+	// Amounts which are not positive are left out.
+	require.Equal(t, "bitcoin:"+btcAddress, (&URI{Scheme: SchemeBitcoin, Address: btcAddress, Amount: big.NewInt(0)}).String())
+	require.Equal(t, "ethereum:"+ethAddress, (&URI{Scheme: SchemeEthereum, Address: ethAddress, Amount: big.NewInt(-1)}).String())
+}
+
+func TestNew(t *testing.T) {
+	uri, err := New(&coinMocks.CoinMock{CodeFunc: func() coinpkg.Code { return coinpkg.CodeTBTC }}, btcAddress)
+	require.NoError(t, err)
+	require.Equal(t, &URI{Scheme: SchemeBitcoin, Address: btcAddress}, uri)
+
+	ethCoin := eth.NewCoin(nil, coinpkg.CodeSEPETH, "Sepolia", "SEPETH", "SEPETH", params.SepoliaChainConfig, "", nil, nil)
+	uri, err = New(ethCoin, ethAddress)
+	require.NoError(t, err)
+	require.Equal(t, &URI{Scheme: SchemeEthereum, Address: ethAddress, ChainID: 11155111}, uri)
+
+	tokenCoin := eth.NewCoin(nil, "eth-erc20-usdt", "Tether USD", "USDT", "ETH", params.MainnetChainConfig, "", nil,
+		erc20.NewToken(tokenAddress, 6))
+	uri, err = New(tokenCoin, ethAddress)
+	require.NoError(t, err)
+	require.Equal(t, &URI{
+		Scheme:               SchemeEthereum,
+		Address:              ethAddress,
+		ChainID:              1,
+		ERC20ContractAddress: tokenAddress,
+	}, uri)
+}
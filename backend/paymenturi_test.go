@@ -0,0 +1,82 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePaymentURI(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	b.registerKeystore(makeBitBox02Multi())
+
+	const btcURI = "bitcoin:bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq?amount=0.0012&label=Shop&message=Order%2042"
+	request, err := b.ParsePaymentURI(btcURI)
+	require.NoError(t, err)
+	require.Equal(t, &PaymentURIRequest{
+		URI:         btcURI,
+		CoinCode:    coinpkg.CodeBTC,
+		Accounts:    []account{{Name: "Bitcoin", Code: "v0-55555555-btc-0"}},
+		AccountCode: "v0-55555555-btc-0",
+		Address:     "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+		Amount:      "0.00120000",
+		Label:       "Shop",
+		Message:     "Order 42",
+		Note:        "Order 42",
+	}, request)
+	args := request.TxProposalArgs()
+	require.Equal(t, "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", args.RecipientAddress)
+	require.Equal(t, coinpkg.NewSendAmount("0.00120000"), args.Amount)
+	require.Equal(t, accounts.DefaultFeeTarget, args.FeeTargetCode)
+	require.Equal(t, "Order 42", args.Note)
+
+	// ERC20 transfer of a builtin token, without an enabled token account.
+	request, err = b.ParsePaymentURI(
+		"ethereum:0xdac17f958d2ee523a2206206994597c13d831ec7/transfer?address=0xa29163852021BF4C139D03Dff59ae763AC73e84e&uint256=1.5e6")
+	require.NoError(t, err)
+	require.Equal(t, coinpkg.Code("eth-erc20-usdt"), request.CoinCode)
+	require.Equal(t, "0xa29163852021BF4C139D03Dff59ae763AC73e84e", request.Address)
+	require.Equal(t, "1.5", request.Amount)
+	require.Empty(t, request.Accounts)
+	require.Equal(t, accountsTypes.Code(""), request.AccountCode)
+
+	request, err = b.ParsePaymentURI("ethereum:0xa29163852021BF4C139D03Dff59ae763AC73e84e?value=1e16")
+	require.NoError(t, err)
+	require.Equal(t, coinpkg.CodeETH, request.CoinCode)
+	require.Equal(t, "0.01", request.Amount)
+	require.Equal(t, accountsTypes.Code("v0-55555555-eth-0"), request.AccountCode)
+
+	// Unknown token.
+	_, err = b.ParsePaymentURI(
+		"ethereum:0x1f9840a85d5aF5bf1D1762F925BDADdC4201F984/transfer?address=0xa29163852021BF4C139D03Dff59ae763AC73e84e")
+	require.Error(t, err)
+	// Unsupported chain.
+	_, err = b.ParsePaymentURI("ethereum:0xa29163852021BF4C139D03Dff59ae763AC73e84e@137")
+	require.Error(t, err)
+
+	// URIs opened with the app are made available to the frontend.
+	require.Nil(t, b.PaymentURI())
+	b.HandleURI(btcURI)
+	require.NotNil(t, b.PaymentURI())
+	require.Equal(t, btcURI, b.PaymentURI().URI)
+	b.PaymentURICancel()
+	require.Nil(t, b.PaymentURI())
+}
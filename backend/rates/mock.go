@@ -41,5 +41,6 @@ func MockRateUpdater() *RateUpdater {
 			"USD": 1.0,
 		},
 	}
+	updater.lastUpdated = time.Now()
 	return updater
 }
@@ -98,6 +98,8 @@ type RateUpdater struct {
 
 	// last contains most recent conversion to fiat, keyed by a coin.
 	last map[string]map[string]float64
+	// lastUpdated is the time at which last was fetched.
+	lastUpdated time.Time
 	// stopLastUpdateLoop is the cancel function of the lastUpdateLoop context.
 	stopLastUpdateLoop context.CancelFunc
 
@@ -183,6 +185,11 @@ func (updater *RateUpdater) LatestPriceForPair(coinUnit, fiat string) (float64,
 	return last[coinUnit][fiat], nil
 }
 
+// LatestPriceUpdated returns the time at which the rates returned by LatestPrice() were fetched.
+func (updater *RateUpdater) LatestPriceUpdated() time.Time {
+	return updater.lastUpdated
+}
+
 // HistoricalPriceAt returns a historical exchange rate for the given coin.
 // The returned value may be imprecise if at arg matches no timestamp exactly.
 // In this case, linear interpolation is used as an approximation.
@@ -345,6 +352,7 @@ func (updater *RateUpdater) updateLast(ctx context.Context) {
 		}
 	}
 
+	updater.lastUpdated = time.Now()
 	if reflect.DeepEqual(rates, updater.last) {
 		return
 	}
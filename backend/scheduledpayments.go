@@ -0,0 +1,425 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/eth"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/scheduler"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/observable"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/observable/action"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// scheduledPaymentsCheckInterval is how often the scheduled payments are checked for due
+	// payments.
+	scheduledPaymentsCheckInterval = 10 * time.Minute
+	// scheduledPaymentsSyncTimeout is how long to wait for an account to sync before giving up on
+	// preparing the tx proposal of a due payment.
+	scheduledPaymentsSyncTimeout = 5 * time.Minute
+)
+
+// ScheduledPaymentProposal is the tx proposal prepared for a due scheduled payment. Amounts are
+// formatted in the unit of the coin of the account.
+type ScheduledPaymentProposal struct {
+	Amount   string                       `json:"amount"`
+	Fee      string                       `json:"fee"`
+	Total    string                       `json:"total"`
+	Unit     string                       `json:"unit"`
+	FeeUnit  string                       `json:"feeUnit"`
+	Warnings []accounts.TxProposalWarning `json:"warnings"`
+	// ErrorCode or ErrorMessage is set instead of the amounts if the proposal could not be created,
+	// e.g. if the account balance is insufficient.
+	ErrorCode    string `json:"errorCode,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// ScheduledPayment is a recurring payment with the due date of its next payment.
+type ScheduledPayment struct {
+	*scheduler.Payment
+	// FormattedAmount is the amount in the unit the account currently shows amounts in, or the
+	// fiat amount. It is empty if the account is not loaded.
+	FormattedAmount string    `json:"formattedAmount"`
+	NextDue         time.Time `json:"nextDue"`
+	Due             bool      `json:"due"`
+	// Proposal is set if the payment is due and its tx proposal was prepared after the keystore of
+	// the account connected.
+	Proposal *ScheduledPaymentProposal `json:"proposal,omitempty"`
+}
+
+func (backend *Backend) notifyScheduledPaymentsChanged() {
+	backend.Notify(observable.Event{
+		Subject: "scheduled-payments",
+		Action:  action.Reload,
+	})
+}
+
+// normalizeRecipientAddress validates the recipient address of a payment from an account of the
+// given coin and returns it in a canonical format.
+func normalizeRecipientAddress(coin coinpkg.Coin, address string) (string, error) {
+	address = strings.TrimSpace(address)
+	switch specificCoin := coin.(type) {
+	case *btc.Coin:
+		return specificCoin.NormalizeAddress(address)
+	case *eth.Coin:
+		if !eth.IsValidEthAddress(address) {
+			return "", errp.WithStack(errors.ErrInvalidAddress)
+		}
+		return ethcommon.HexToAddress(address).Hex(), nil
+	default:
+		return "", errp.Newf("Unsupported coin %s", coin.Code())
+	}
+}
+
+// validateScheduledPayment normalizes the recipient address of the payment and checks that the
+// amount is valid. A coin amount is given in the unit the account currently shows amounts in, e.g.
+// sats, and is converted to the smallest unit of the coin.
+func (backend *Backend) validateScheduledPayment(payment *scheduler.Payment) error {
+	account := backend.Accounts().lookup(payment.AccountCode)
+	if account == nil {
+		return errp.Newf("Could not find account %s", payment.AccountCode)
+	}
+	coin := account.Coin()
+	address, err := normalizeRecipientAddress(coin, payment.RecipientAddress)
+	if err != nil {
+		return err
+	}
+	payment.RecipientAddress = address
+	payment.Amount = strings.TrimSpace(payment.Amount)
+	if payment.FiatUnit == "" {
+		amount, err := coin.ParseAmount(payment.Amount)
+		if err != nil || amount.BigInt().Sign() <= 0 {
+			return errp.WithStack(errors.ErrInvalidAmount)
+		}
+		payment.Amount = amount.BigInt().String()
+		return nil
+	}
+	fiatAmount, ok := new(big.Rat).SetString(payment.Amount)
+	if !ok || fiatAmount.Sign() <= 0 {
+		return errp.WithStack(errors.ErrInvalidAmount)
+	}
+	return nil
+}
+
+// formatScheduledPaymentAmount returns the amount of the payment in the unit the account currently
+// shows and parses amounts in, or the fiat amount.
+func formatScheduledPaymentAmount(coin coinpkg.Coin, payment *scheduler.Payment) (string, error) {
+	if payment.FiatUnit != "" {
+		return payment.Amount, nil
+	}
+	amount, err := coinpkg.NewAmountFromString(payment.Amount, big.NewInt(1))
+	if err != nil {
+		return "", err
+	}
+	return coin.FormatAmount(amount, false), nil
+}
+
+// scheduledPaymentTxProposalArgs returns the arguments to create the tx proposal of a payment.
+// Fiat amounts are converted by the account, which locks the exchange rate in the proposal.
+func scheduledPaymentTxProposalArgs(
+	account accounts.Interface, payment *scheduler.Payment) (*accounts.TxProposalArgs, error) {
+	amount, err := formatScheduledPaymentAmount(account.Coin(), payment)
+	if err != nil {
+		return nil, err
+	}
+	_, defaultFeeTarget := account.FeeTargets()
+	return &accounts.TxProposalArgs{
+		RecipientAddress: payment.RecipientAddress,
+		Amount:           coinpkg.NewSendAmount(amount),
+		Fiat:             rates.Fiat(payment.FiatUnit),
+		FeeTargetCode:    defaultFeeTarget,
+		Note:             payment.Name,
+	}, nil
+}
+
+// prepareScheduledPayment waits for the account to sync and creates the tx proposal of the payment.
+func (backend *Backend) prepareScheduledPayment(
+	account accounts.Interface, payment *scheduler.Payment) *ScheduledPaymentProposal {
+	proposalError := func(err error) *ScheduledPaymentProposal {
+		backend.log.WithError(err).WithField("payment", payment.ID).Error("could not prepare scheduled payment")
+		if validationErr, ok := errp.Cause(err).(errors.TxValidationError); ok {
+			return &ScheduledPaymentProposal{ErrorCode: validationErr.Error()}
+		}
+		return &ScheduledPaymentProposal{ErrorMessage: err.Error()}
+	}
+	if err := account.Initialize(); err != nil {
+		return proposalError(err)
+	}
+	start := time.Now()
+	for !account.Synced() {
+		if account.FatalError() || time.Since(start) > scheduledPaymentsSyncTimeout {
+			return proposalError(errp.New("The account could not be synced"))
+		}
+		time.Sleep(time.Second)
+	}
+	args, err := scheduledPaymentTxProposalArgs(account, payment)
+	if err != nil {
+		return proposalError(err)
+	}
+	// The proposal is only shown to the user, so it must not replace the proposal of a
+	// transaction the user is about to send from the account.
+	args.DryRun = true
+	result, err := account.TxProposal(args)
+	if err != nil {
+		return proposalError(err)
+	}
+	coin := account.Coin()
+	return &ScheduledPaymentProposal{
+		Amount:   coin.FormatAmount(result.Amount, false),
+		Fee:      coin.FormatAmount(result.Fee, true),
+		Total:    coin.FormatAmount(result.Total, false),
+		Unit:     coin.GetFormatUnit(false),
+		FeeUnit:  coin.GetFormatUnit(true),
+		Warnings: result.Warnings,
+	}
+}
+
+// prepareScheduledPayments creates the tx proposals of the due payments of the accounts of the
+// connected keystore, so that the user only needs to review and confirm them. Payments whose
+// proposal was already prepared since the keystore connected are skipped.
+func (backend *Backend) prepareScheduledPayments() {
+	backend.scheduledPaymentsCheckMu.Lock()
+	defer backend.scheduledPaymentsCheckMu.Unlock()
+
+	keystore := backend.Keystore()
+	if keystore == nil {
+		return
+	}
+	fingerprint, err := keystore.RootFingerprint()
+	if err != nil {
+		backend.log.WithError(err).Error("could not retrieve keystore fingerprint")
+		return
+	}
+	accountsList := backend.Accounts()
+	for _, payment := range backend.scheduler.Due(time.Now()) {
+		backend.scheduledPaymentProposalsMu.RLock()
+		_, prepared := backend.scheduledPaymentProposals[payment.ID]
+		generation := backend.scheduledPaymentProposalsGeneration
+		backend.scheduledPaymentProposalsMu.RUnlock()
+		if prepared {
+			continue
+		}
+		account := accountsList.lookup(payment.AccountCode)
+		if account == nil || account.Config().Config.Inactive ||
+			!account.Config().Config.SigningConfigurations.ContainsRootFingerprint(fingerprint) {
+			continue
+		}
+		proposal := backend.prepareScheduledPayment(account, payment)
+		backend.scheduledPaymentProposalsMu.Lock()
+		// Discard the proposal if the payment was changed or the keystore disconnected meanwhile.
+		stale := generation != backend.scheduledPaymentProposalsGeneration
+		if !stale {
+			backend.scheduledPaymentProposals[payment.ID] = proposal
+		}
+		backend.scheduledPaymentProposalsMu.Unlock()
+		if !stale {
+			backend.notifyScheduledPaymentsChanged()
+		}
+	}
+}
+
+// clearScheduledPaymentProposals removes the prepared tx proposals, e.g. when the keystore
+// disconnects, or only the one of the given payment if id is not empty.
+func (backend *Backend) clearScheduledPaymentProposals(id string) {
+	backend.scheduledPaymentProposalsMu.Lock()
+	defer backend.scheduledPaymentProposalsMu.Unlock()
+	backend.scheduledPaymentProposalsGeneration++
+	if id == "" {
+		backend.scheduledPaymentProposals = map[string]*ScheduledPaymentProposal{}
+		return
+	}
+	delete(backend.scheduledPaymentProposals, id)
+}
+
+// checkScheduledPayments notifies the user once about every due payment and prepares their tx
+// proposals if the keystore of the account is connected.
+func (backend *Backend) checkScheduledPayments() {
+	notified := false
+	backend.scheduledPaymentsCheckMu.Lock()
+	for _, payment := range backend.scheduler.Due(time.Now()) {
+		if payment.Notified {
+			continue
+		}
+		backend.NotifyUser(fmt.Sprintf("Scheduled payment due: %s", payment.Name))
+		if err := backend.scheduler.MarkNotified(payment.ID); err != nil {
+			backend.log.WithError(err).Error("could not mark scheduled payment as notified")
+		}
+		notified = true
+	}
+	backend.scheduledPaymentsCheckMu.Unlock()
+	if notified {
+		backend.notifyScheduledPaymentsChanged()
+	}
+	backend.prepareScheduledPayments()
+}
+
+// scheduledPaymentsLoop periodically checks for due payments until the context is canceled.
+func (backend *Backend) scheduledPaymentsLoop(ctx context.Context) {
+	ticker := time.NewTicker(scheduledPaymentsCheckInterval)
+	defer ticker.Stop()
+	for {
+		backend.checkScheduledPayments()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScheduledPayments returns all recurring payments, sorted by the due date of their next payment.
+func (backend *Backend) ScheduledPayments() []*ScheduledPayment {
+	now := time.Now()
+	accountsList := backend.Accounts()
+	backend.scheduledPaymentProposalsMu.RLock()
+	defer backend.scheduledPaymentProposalsMu.RUnlock()
+	result := []*ScheduledPayment{}
+	for _, payment := range backend.scheduler.Payments() {
+		var formattedAmount string
+		if account := accountsList.lookup(payment.AccountCode); account != nil {
+			formattedAmount, _ = formatScheduledPaymentAmount(account.Coin(), payment)
+		}
+		result = append(result, &ScheduledPayment{
+			Payment:         payment,
+			FormattedAmount: formattedAmount,
+			NextDue:         payment.NextDue(),
+			Due:             payment.IsDue(now),
+			Proposal:        backend.scheduledPaymentProposals[payment.ID],
+		})
+	}
+	return result
+}
+
+// AddScheduledPayment adds a recurring payment. The recipient address is validated against the coin
+// of the account of the payment.
+func (backend *Backend) AddScheduledPayment(payment scheduler.Payment) (*scheduler.Payment, error) {
+	payment.Name = strings.TrimSpace(payment.Name)
+	if err := backend.validateScheduledPayment(&payment); err != nil {
+		return nil, err
+	}
+	added, err := backend.scheduler.Add(payment)
+	if err != nil {
+		return nil, err
+	}
+	backend.notifyScheduledPaymentsChanged()
+	go backend.checkScheduledPayments()
+	return added, nil
+}
+
+// UpdateScheduledPayment changes the name, recipient and amount of the recurring payment with the
+// given ID. A tx proposal prepared for it is discarded.
+func (backend *Backend) UpdateScheduledPayment(
+	id string, name string, recipientAddress string, amount string, fiatUnit string) (*scheduler.Payment, error) {
+	payment := backend.scheduler.Payment(id)
+	if payment == nil {
+		return nil, errp.Newf("Could not find scheduled payment %s", id)
+	}
+	payment.Name = strings.TrimSpace(name)
+	payment.RecipientAddress = recipientAddress
+	payment.Amount = amount
+	payment.FiatUnit = fiatUnit
+	if err := backend.validateScheduledPayment(payment); err != nil {
+		return nil, err
+	}
+	updated, err := backend.scheduler.Update(*payment)
+	if err != nil {
+		return nil, err
+	}
+	backend.clearScheduledPaymentProposals(id)
+	backend.notifyScheduledPaymentsChanged()
+	go backend.prepareScheduledPayments()
+	return updated, nil
+}
+
+// RemoveScheduledPayment removes the recurring payment with the given ID.
+func (backend *Backend) RemoveScheduledPayment(id string) error {
+	if err := backend.scheduler.Remove(id); err != nil {
+		return err
+	}
+	backend.clearScheduledPaymentProposals(id)
+	backend.notifyScheduledPaymentsChanged()
+	return nil
+}
+
+// SkipScheduledPayment skips the due payment of the recurring payment with the given ID without
+// sending it.
+func (backend *Backend) SkipScheduledPayment(id string) error {
+	backend.scheduledPaymentsSendMu.Lock()
+	defer backend.scheduledPaymentsSendMu.Unlock()
+
+	payment := backend.scheduler.Payment(id)
+	if payment == nil {
+		return errp.Newf("Could not find scheduled payment %s", id)
+	}
+	if _, err := backend.scheduler.Advance(payment.ID, payment.Occurrence); err != nil {
+		return err
+	}
+	backend.clearScheduledPaymentProposals(payment.ID)
+	backend.notifyScheduledPaymentsChanged()
+	return nil
+}
+
+// SendScheduledPayment creates a fresh tx proposal for the due payment of the recurring payment
+// with the given ID, and signs and sends it with the connected keystore. The proposal does not
+// replace the one the user may be about to send from the account. On success, the payment is due
+// again after its cadence.
+func (backend *Backend) SendScheduledPayment(id string) error {
+	// The payment is looked up and checked to be due only after taking the lock, so that a payment
+	// which was just sent or skipped is not sent again.
+	backend.scheduledPaymentsSendMu.Lock()
+	defer backend.scheduledPaymentsSendMu.Unlock()
+
+	payment := backend.scheduler.Payment(id)
+	if payment == nil {
+		return errp.Newf("Could not find scheduled payment %s", id)
+	}
+	if !payment.IsDue(time.Now()) {
+		return errp.New("The payment is not due yet")
+	}
+	account := backend.Accounts().lookup(payment.AccountCode)
+	if account == nil {
+		return errp.Newf("Could not find account %s", payment.AccountCode)
+	}
+	args, err := scheduledPaymentTxProposalArgs(account, payment)
+	if err != nil {
+		return err
+	}
+	// The payment is recorded as sent before it is broadcast, so that it is not sent again if the
+	// app quits meanwhile. This is undone if it could not be sent.
+	if _, err := backend.scheduler.Advance(payment.ID, payment.Occurrence); err != nil {
+		return err
+	}
+	if err := account.SendTxProposal(args, payment.Name); err != nil {
+		if rewindErr := backend.scheduler.Rewind(payment.ID, payment.Occurrence); rewindErr != nil {
+			backend.log.WithError(rewindErr).Error("could not rewind scheduled payment which was not sent")
+		}
+		return err
+	}
+	backend.clearScheduledPaymentProposals(payment.ID)
+	backend.notifyScheduledPaymentsChanged()
+	return nil
+}
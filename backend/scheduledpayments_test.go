@@ -0,0 +1,228 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/scheduler"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type notifyUserEnvironment struct {
+	environment
+	mu            sync.Mutex
+	notifications []string
+}
+
+func (e *notifyUserEnvironment) NotifyUser(msg string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.notifications = append(e.notifications, msg)
+}
+
+func (e *notifyUserEnvironment) Notifications() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string{}, e.notifications...)
+}
+
+func TestScheduledPayments(t *testing.T) {
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	env := &notifyUserEnvironment{}
+	b.environment = env
+
+	const accountCode = "v0-55555555-btc-0"
+	var txProposalCalls []*accounts.TxProposalArgs
+	var sendTxCalls []*accounts.TxProposalArgs
+	var sendTxErr error
+	var callsMu sync.Mutex
+	b.makeBtcAccount = func(config *accounts.AccountConfig, coin *btc.Coin, gapLimits *types.GapLimits, log *logrus.Entry) accounts.Interface {
+		account := MockBtcAccount(t, config, coin, gapLimits, log)
+		account.SyncedFunc = func() bool { return true }
+		account.FeeTargetsFunc = func() ([]accounts.FeeTarget, accounts.FeeTargetCode) {
+			return nil, accounts.FeeTargetCodeMempoolHalfHour
+		}
+		account.TxProposalFunc = func(args *accounts.TxProposalArgs) (*accounts.TxProposalResult, error) {
+			callsMu.Lock()
+			defer callsMu.Unlock()
+			txProposalCalls = append(txProposalCalls, args)
+			return &accounts.TxProposalResult{
+				Amount: coinpkg.NewAmountFromInt64(100000),
+				Fee:    coinpkg.NewAmountFromInt64(1000),
+				Total:  coinpkg.NewAmountFromInt64(101000),
+			}, nil
+		}
+		account.SendTxProposalFunc = func(args *accounts.TxProposalArgs, txNote string) error {
+			callsMu.Lock()
+			defer callsMu.Unlock()
+			require.Equal(t, "Salary", txNote)
+			sendTxCalls = append(sendTxCalls, args)
+			return sendTxErr
+		}
+		return account
+	}
+	lastTxProposalCall := func() (*accounts.TxProposalArgs, int) {
+		callsMu.Lock()
+		defer callsMu.Unlock()
+		return txProposalCalls[len(txProposalCalls)-1], len(txProposalCalls)
+	}
+
+	bitbox02LikeKeystore := makeBitBox02Multi()
+	bitbox02LikeKeystore.RootFingerprintFunc = func() ([]byte, error) {
+		return rootFingerprint1, nil
+	}
+	b.registerKeystore(bitbox02LikeKeystore)
+
+	payment := scheduler.Payment{
+		AccountCode:      accountCode,
+		Name:             "Salary",
+		RecipientAddress: "invalid",
+		Amount:           "0.001",
+		Cadence:          scheduler.CadenceMonthly,
+		Interval:         1,
+		Start:            time.Now().Add(-time.Hour),
+	}
+	_, err := b.AddScheduledPayment(payment)
+	require.Error(t, err)
+	payment.RecipientAddress = "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh"
+	payment.Amount = "-1"
+	_, err = b.AddScheduledPayment(payment)
+	require.Error(t, err)
+	payment.Amount = "0.001"
+	payment.AccountCode = "v0-55555555-btc-99"
+	_, err = b.AddScheduledPayment(payment)
+	require.Error(t, err)
+	payment.AccountCode = accountCode
+	added, err := b.AddScheduledPayment(payment)
+	require.NoError(t, err)
+	// Coin amounts are stored in the smallest unit.
+	require.Equal(t, "100000", added.Amount)
+	require.Equal(t, "0.00100000", b.ScheduledPayments()[0].FormattedAmount)
+
+	// The due payment is notified once and its proposal is prepared, as the keystore is connected.
+	require.Eventually(t, func() bool {
+		return b.ScheduledPayments()[0].Proposal != nil
+	}, time.Second, 10*time.Millisecond)
+	b.checkScheduledPayments()
+	require.Equal(t, []string{"Scheduled payment due: Salary"}, env.Notifications())
+	scheduledPayments := b.ScheduledPayments()
+	require.Len(t, scheduledPayments, 1)
+	require.True(t, scheduledPayments[0].Due)
+	require.Equal(t, &ScheduledPaymentProposal{
+		Amount:  "0.00100000",
+		Fee:     "0.00001000",
+		Total:   "0.00101000",
+		Unit:    "BTC",
+		FeeUnit: "BTC",
+	}, scheduledPayments[0].Proposal)
+	args, numCalls := lastTxProposalCall()
+	require.Equal(t, 1, numCalls)
+	require.True(t, args.DryRun)
+	require.Equal(t, coinpkg.NewSendAmount("0.00100000"), args.Amount)
+	require.Equal(t, accounts.FeeTargetCodeMempoolHalfHour, args.FeeTargetCode)
+	require.Equal(t, "Salary", args.Note)
+
+	// Fiat amounts are passed on to the account, which converts them.
+	_, err = b.UpdateScheduledPayment(added.ID, "Salary", payment.RecipientAddress, "3000", "USD")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		args, _ := lastTxProposalCall()
+		return args.Fiat == rates.USD
+	}, time.Second, 10*time.Millisecond)
+	args, _ = lastTxProposalCall()
+	require.True(t, args.DryRun)
+	require.Equal(t, coinpkg.NewSendAmount("3000"), args.Amount)
+	require.Equal(t, "3000", b.ScheduledPayments()[0].FormattedAmount)
+
+	// Coin amounts are given and passed on in the unit the account shows, e.g. sats.
+	coin, err := b.Coin(coinpkg.CodeBTC)
+	require.NoError(t, err)
+	coin.(*btc.Coin).SetFormatUnit(coinpkg.BtcUnitSats)
+	updated, err := b.UpdateScheduledPayment(added.ID, "Salary", payment.RecipientAddress, "200000", "")
+	require.NoError(t, err)
+	require.Equal(t, "200000", updated.Amount)
+	require.Equal(t, "200000", b.ScheduledPayments()[0].FormattedAmount)
+	require.Eventually(t, func() bool {
+		args, _ := lastTxProposalCall()
+		return args.Amount == coinpkg.NewSendAmount("200000")
+	}, time.Second, 10*time.Millisecond)
+	coin.(*btc.Coin).SetFormatUnit(coinpkg.BtcUnitDefault)
+	require.Equal(t, "0.00200000", b.ScheduledPayments()[0].FormattedAmount)
+
+	// The proposal is discarded when the keystore disconnects, and prepared again when it connects.
+	b.DeregisterKeystore()
+	require.Nil(t, b.ScheduledPayments()[0].Proposal)
+	b.registerKeystore(bitbox02LikeKeystore)
+	require.Eventually(t, func() bool {
+		proposal := b.ScheduledPayments()[0].Proposal
+		return proposal != nil && proposal.ErrorMessage == ""
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, env.Notifications(), 1)
+
+	// A payment which could not be sent is still due.
+	callsMu.Lock()
+	sendTxErr = errors.New("rejected on the device")
+	callsMu.Unlock()
+	require.Error(t, b.SendScheduledPayment(added.ID))
+	require.True(t, b.ScheduledPayments()[0].Due)
+	require.Equal(t, 0, b.ScheduledPayments()[0].Occurrence)
+	callsMu.Lock()
+	sendTxErr = nil
+	sendTxCalls = nil
+	callsMu.Unlock()
+
+	// Sending the payment concurrently, e.g. by clicking twice, sends it only once.
+	sendErrs := make(chan error, 2)
+	for range 2 {
+		go func() { sendErrs <- b.SendScheduledPayment(added.ID) }()
+	}
+	numErrs := 0
+	for range 2 {
+		if <-sendErrs != nil {
+			numErrs++
+		}
+	}
+	require.Equal(t, 1, numErrs)
+	callsMu.Lock()
+	require.Len(t, sendTxCalls, 1)
+	require.Equal(t, coinpkg.NewSendAmount("0.00200000"), sendTxCalls[0].Amount)
+	require.Equal(t, accounts.FeeTargetCodeMempoolHalfHour, sendTxCalls[0].FeeTargetCode)
+	// The active tx proposal of the account, which the user may be about to send, is not
+	// replaced.
+	for _, args := range txProposalCalls {
+		require.True(t, args.DryRun)
+	}
+	callsMu.Unlock()
+
+	scheduledPayments = b.ScheduledPayments()
+	require.False(t, scheduledPayments[0].Due)
+	require.Equal(t, 1, scheduledPayments[0].Occurrence)
+	require.Equal(t, added.DueAt(1), scheduledPayments[0].NextDue)
+	require.Error(t, b.SendScheduledPayment(added.ID))
+
+	require.NoError(t, b.RemoveScheduledPayment(added.ID))
+	require.Empty(t, b.ScheduledPayments())
+}
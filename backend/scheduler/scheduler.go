@@ -0,0 +1,400 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scheduler provides functionality to store recurring payments and to find out which of
+// them are due.
+package scheduler
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/random"
+)
+
+// MaxNameLen is the maximum length of the name of a payment.
+const MaxNameLen = 256
+
+// Cadence is the unit of time between two occurrences of a recurring payment.
+type Cadence string
+
+const (
+	// CadenceDaily repeats the payment every `Interval` days.
+	CadenceDaily Cadence = "daily"
+	// CadenceWeekly repeats the payment every `Interval` weeks.
+	CadenceWeekly Cadence = "weekly"
+	// CadenceMonthly repeats the payment every `Interval` months. If the day of the month of the
+	// first payment does not exist in a month, the payment is due on the last day of that month.
+	CadenceMonthly Cadence = "monthly"
+	// CadenceYearly repeats the payment every `Interval` years.
+	CadenceYearly Cadence = "yearly"
+)
+
+// Payment is a recurring payment template.
+type Payment struct {
+	ID          string             `json:"id"`
+	AccountCode accountsTypes.Code `json:"accountCode"`
+	Name        string             `json:"name"`
+	// RecipientAddress must be normalized by the caller.
+	RecipientAddress string `json:"recipientAddress"`
+	// Amount is the amount to send in the smallest unit of the coin of the account, e.g. "100000"
+	// satoshi, or in the fiat currency FiatUnit if it is set. Fiat amounts are converted when the
+	// payment is due.
+	Amount   string  `json:"amount"`
+	FiatUnit string  `json:"fiatUnit,omitempty"`
+	Cadence  Cadence `json:"cadence"`
+	// Interval is the number of cadence units between two payments, e.g. 2 for every second week.
+	Interval int `json:"interval"`
+	// Start is the due date of the first payment.
+	Start time.Time `json:"start"`
+	// Occurrence is the number of payments that were sent or skipped.
+	Occurrence int `json:"occurrence"`
+	// Notified is true if the user was notified that the current occurrence is due.
+	Notified bool `json:"notified"`
+}
+
+// addMonths adds the given number of months to t. Unlike `time.AddDate()`, days that do not exist
+// in the resulting month are clamped to the last day of the month instead of overflowing into the
+// next month, so that e.g. a monthly payment starting on January 31 is due on February 28.
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	firstOfMonth := time.Date(year, month+time.Month(months), 1,
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if lastDay := firstOfMonth.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+// DueAt returns the due date of the n-th payment, the first payment having n=0. The payment must
+// have a valid cadence.
+func (payment *Payment) DueAt(n int) time.Time {
+	steps := n * payment.Interval
+	switch payment.Cadence {
+	case CadenceDaily:
+		return payment.Start.AddDate(0, 0, steps)
+	case CadenceWeekly:
+		return payment.Start.AddDate(0, 0, 7*steps)
+	case CadenceMonthly:
+		return addMonths(payment.Start, steps)
+	case CadenceYearly:
+		return addMonths(payment.Start, 12*steps)
+	default:
+		panic(errp.Newf("unknown cadence %s", payment.Cadence))
+	}
+}
+
+// NextDue returns the due date of the next payment that was not yet sent or skipped.
+func (payment *Payment) NextDue() time.Time {
+	return payment.DueAt(payment.Occurrence)
+}
+
+// IsDue returns true if the next payment is due at the given time.
+func (payment *Payment) IsDue(now time.Time) bool {
+	return !payment.NextDue().After(now)
+}
+
+func (payment *Payment) validate() error {
+	if strings.TrimSpace(payment.Name) == "" {
+		return errp.New("Name cannot be empty")
+	}
+	if len(payment.Name) > MaxNameLen {
+		return errp.Newf("Length of name must be smaller than %d. Got %d", MaxNameLen, len(payment.Name))
+	}
+	if payment.AccountCode == "" {
+		return errp.New("Account cannot be empty")
+	}
+	if payment.RecipientAddress == "" {
+		return errp.New("Recipient address cannot be empty")
+	}
+	if payment.Amount == "" {
+		return errp.New("Amount cannot be empty")
+	}
+	if payment.FiatUnit == "" {
+		amount, ok := new(big.Int).SetString(payment.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			return errp.Newf("Invalid amount %s", payment.Amount)
+		}
+	}
+	switch payment.Cadence {
+	case CadenceDaily, CadenceWeekly, CadenceMonthly, CadenceYearly:
+	default:
+		return errp.Newf("Unknown cadence %s", payment.Cadence)
+	}
+	if payment.Interval < 1 {
+		return errp.New("Interval must be at least 1")
+	}
+	if payment.Start.IsZero() {
+		return errp.New("Start date cannot be empty")
+	}
+	return nil
+}
+
+// Data is the scheduled payments JSON data serialized to disk.
+type Data struct {
+	Payments []*Payment `json:"payments"`
+}
+
+// read deserializes the json file into the scheduler data. If the file does not exist yet, no
+// error is returned, and empty data is returned.
+func read(filename string) (*Data, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Data{Payments: []*Payment{}}, nil
+		}
+		return nil, errp.WithStack(err)
+	}
+	defer file.Close() //nolint:errcheck
+	var data Data
+	if err := json.NewDecoder(file).Decode(&data); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	if data.Payments == nil {
+		data.Payments = []*Payment{}
+	}
+	for _, payment := range data.Payments {
+		if err := payment.validate(); err != nil {
+			return nil, errp.WithMessage(err, "Invalid scheduled payment "+payment.ID)
+		}
+	}
+	return &data, nil
+}
+
+func write(data *Data, filename string) error {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return errp.WithStack(err)
+	}
+	return nil
+}
+
+// Scheduler holds the recurring payments of all accounts.
+type Scheduler struct {
+	filename string
+	data     *Data
+	dataMu   sync.RWMutex
+}
+
+// Load makes a new Scheduler instance, already pre-loading all payments into RAM. If the file does
+// not exist, no error is returned. Returns an error for other kinds of file read errors.
+func Load(filename string) (*Scheduler, error) {
+	data, err := read(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &Scheduler{
+		filename: filename,
+		data:     data,
+	}, nil
+}
+
+// find returns the payment with the given ID and its index. The dataMu lock must be held.
+func (scheduler *Scheduler) find(id string) (*Payment, int) {
+	for index, payment := range scheduler.data.Payments {
+		if payment.ID == id {
+			return payment, index
+		}
+	}
+	return nil, -1
+}
+
+// Payments returns copies of all payments, sorted by the due date of their next payment.
+func (scheduler *Scheduler) Payments() []*Payment {
+	scheduler.dataMu.RLock()
+	defer scheduler.dataMu.RUnlock()
+
+	result := []*Payment{}
+	for _, payment := range scheduler.data.Payments {
+		paymentCopy := *payment
+		result = append(result, &paymentCopy)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].NextDue().Before(result[j].NextDue())
+	})
+	return result
+}
+
+// Payment returns a copy of the payment with the given ID, or nil if it does not exist.
+func (scheduler *Scheduler) Payment(id string) *Payment {
+	scheduler.dataMu.RLock()
+	defer scheduler.dataMu.RUnlock()
+
+	payment, _ := scheduler.find(id)
+	if payment == nil {
+		return nil
+	}
+	paymentCopy := *payment
+	return &paymentCopy
+}
+
+// Due returns copies of the payments which are due at the given time, sorted by due date.
+func (scheduler *Scheduler) Due(now time.Time) []*Payment {
+	result := []*Payment{}
+	for _, payment := range scheduler.Payments() {
+		if payment.IsDue(now) {
+			result = append(result, payment)
+		}
+	}
+	return result
+}
+
+// Add adds a new payment and returns it with its newly assigned ID. The ID and the progress of the
+// given payment are ignored.
+func (scheduler *Scheduler) Add(payment Payment) (*Payment, error) {
+	scheduler.dataMu.Lock()
+	defer scheduler.dataMu.Unlock()
+
+	if err := payment.validate(); err != nil {
+		return nil, err
+	}
+	payment.ID = hex.EncodeToString(random.BytesOrPanic(16))
+	payment.Occurrence = 0
+	payment.Notified = false
+	newPayment := payment
+	previous := scheduler.data.Payments
+	scheduler.data.Payments = append(append([]*Payment{}, previous...), &newPayment)
+	if err := write(scheduler.data, scheduler.filename); err != nil {
+		scheduler.data.Payments = previous
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// Update updates the name, recipient and amount of the payment with the ID of the given payment.
+// The account and the schedule of a payment cannot be changed; remove the payment and add a new one
+// instead.
+func (scheduler *Scheduler) Update(payment Payment) (*Payment, error) {
+	scheduler.dataMu.Lock()
+	defer scheduler.dataMu.Unlock()
+
+	existing, _ := scheduler.find(payment.ID)
+	if existing == nil {
+		return nil, errp.Newf("Could not find scheduled payment %s", payment.ID)
+	}
+	updated := *existing
+	updated.Name = payment.Name
+	updated.RecipientAddress = payment.RecipientAddress
+	updated.Amount = payment.Amount
+	updated.FiatUnit = payment.FiatUnit
+	if err := updated.validate(); err != nil {
+		return nil, err
+	}
+	previous := *existing
+	*existing = updated
+	if err := write(scheduler.data, scheduler.filename); err != nil {
+		*existing = previous
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// Remove removes the payment with the given ID.
+func (scheduler *Scheduler) Remove(id string) error {
+	scheduler.dataMu.Lock()
+	defer scheduler.dataMu.Unlock()
+
+	payment, index := scheduler.find(id)
+	if payment == nil {
+		return errp.Newf("Could not find scheduled payment %s", id)
+	}
+	previous := scheduler.data.Payments
+	scheduler.data.Payments = append(append([]*Payment{}, previous[:index]...), previous[index+1:]...)
+	if err := write(scheduler.data, scheduler.filename); err != nil {
+		scheduler.data.Payments = previous
+		return err
+	}
+	return nil
+}
+
+// MarkNotified records that the user was notified that the next payment with the given ID is due.
+func (scheduler *Scheduler) MarkNotified(id string) error {
+	scheduler.dataMu.Lock()
+	defer scheduler.dataMu.Unlock()
+
+	payment, _ := scheduler.find(id)
+	if payment == nil {
+		return errp.Newf("Could not find scheduled payment %s", id)
+	}
+	previous := *payment
+	payment.Notified = true
+	if err := write(scheduler.data, scheduler.filename); err != nil {
+		*payment = previous
+		return err
+	}
+	return nil
+}
+
+// Advance records that the given occurrence of the payment with the given ID was sent or skipped,
+// and returns a copy of the payment with the following due date. Fails if the occurrence is not the
+// next payment anymore, e.g. because it was sent or skipped in the meantime.
+func (scheduler *Scheduler) Advance(id string, occurrence int) (*Payment, error) {
+	scheduler.dataMu.Lock()
+	defer scheduler.dataMu.Unlock()
+
+	payment, _ := scheduler.find(id)
+	if payment == nil {
+		return nil, errp.Newf("Could not find scheduled payment %s", id)
+	}
+	if payment.Occurrence != occurrence {
+		return nil, errp.Newf("Payment %d of scheduled payment %s was already sent or skipped", occurrence, id)
+	}
+	previous := *payment
+	payment.Occurrence++
+	payment.Notified = false
+	if err := write(scheduler.data, scheduler.filename); err != nil {
+		*payment = previous
+		return nil, err
+	}
+	paymentCopy := *payment
+	return &paymentCopy, nil
+}
+
+// Rewind undoes Advance() of the given occurrence of the payment with the given ID, e.g. because
+// the payment could not be sent after all. The payment counts as notified, as the user was
+// sending it.
+func (scheduler *Scheduler) Rewind(id string, occurrence int) error {
+	scheduler.dataMu.Lock()
+	defer scheduler.dataMu.Unlock()
+
+	payment, _ := scheduler.find(id)
+	if payment == nil {
+		return errp.Newf("Could not find scheduled payment %s", id)
+	}
+	if payment.Occurrence != occurrence+1 {
+		return errp.Newf("Payment %d of scheduled payment %s is not the last one sent or skipped", occurrence, id)
+	}
+	previous := *payment
+	payment.Occurrence = occurrence
+	payment.Notified = true
+	if err := write(scheduler.data, scheduler.filename); err != nil {
+		*payment = previous
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/test"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+}
+
+func TestDueAt(t *testing.T) {
+	payment := &Payment{Cadence: CadenceMonthly, Interval: 1, Start: date(2024, time.January, 31)}
+	require.Equal(t, date(2024, time.January, 31), payment.DueAt(0))
+	require.Equal(t, date(2024, time.February, 29), payment.DueAt(1))
+	require.Equal(t, date(2024, time.March, 31), payment.DueAt(2))
+	require.Equal(t, date(2024, time.April, 30), payment.DueAt(3))
+	require.Equal(t, date(2025, time.January, 31), payment.DueAt(12))
+
+	payment.Interval = 3
+	require.Equal(t, date(2024, time.April, 30), payment.DueAt(1))
+
+	payment = &Payment{Cadence: CadenceDaily, Interval: 2, Start: date(2024, time.December, 31)}
+	require.Equal(t, date(2025, time.January, 2), payment.DueAt(1))
+
+	payment = &Payment{Cadence: CadenceWeekly, Interval: 1, Start: date(2024, time.December, 31)}
+	require.Equal(t, date(2025, time.January, 14), payment.DueAt(2))
+
+	payment = &Payment{Cadence: CadenceYearly, Interval: 1, Start: date(2024, time.February, 29)}
+	require.Equal(t, date(2025, time.February, 28), payment.DueAt(1))
+	require.Equal(t, date(2028, time.February, 29), payment.DueAt(4))
+}
+
+func TestScheduler(t *testing.T) {
+	filename := test.TstTempFile("scheduledpayments")
+	scheduler, err := Load(filename)
+	require.NoError(t, err)
+	require.Empty(t, scheduler.Payments())
+
+	salary, err := scheduler.Add(Payment{
+		AccountCode:      "v0-55555555-btc-0",
+		Name:             "Salary",
+		RecipientAddress: "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh",
+		Amount:           "3000",
+		FiatUnit:         "CHF",
+		Cadence:          CadenceMonthly,
+		Interval:         1,
+		Start:            date(2025, time.January, 25),
+		Occurrence:       5,
+		Notified:         true,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, salary.ID)
+	require.Equal(t, 0, salary.Occurrence)
+	require.False(t, salary.Notified)
+	rent, err := scheduler.Add(Payment{
+		AccountCode:      "v0-55555555-btc-0",
+		Name:             "Rent",
+		RecipientAddress: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+		Amount:           "1000000",
+		Cadence:          CadenceMonthly,
+		Interval:         1,
+		Start:            date(2025, time.January, 1),
+	})
+	require.NoError(t, err)
+
+	invalid := *rent
+	invalid.Cadence = "hourly"
+	_, err = scheduler.Add(invalid)
+	require.Error(t, err)
+	invalid = *rent
+	invalid.Interval = 0
+	_, err = scheduler.Add(invalid)
+	require.Error(t, err)
+	invalid = *rent
+	invalid.Name = " "
+	_, err = scheduler.Add(invalid)
+	require.Error(t, err)
+	// Coin amounts are in the smallest unit.
+	invalid = *rent
+	invalid.Amount = "0.01"
+	_, err = scheduler.Add(invalid)
+	require.Error(t, err)
+
+	// Sorted by next due date.
+	require.Equal(t, []*Payment{rent, salary}, scheduler.Payments())
+	require.Empty(t, scheduler.Due(date(2024, time.December, 31)))
+	require.Equal(t, []*Payment{rent}, scheduler.Due(date(2025, time.January, 1)))
+	require.Equal(t, []*Payment{rent, salary}, scheduler.Due(date(2025, time.January, 25)))
+
+	require.NoError(t, scheduler.MarkNotified(rent.ID))
+	require.True(t, scheduler.Payment(rent.ID).Notified)
+	advanced, err := scheduler.Advance(rent.ID, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, advanced.Occurrence)
+	require.False(t, advanced.Notified)
+	require.Equal(t, date(2025, time.February, 1), advanced.NextDue())
+	require.Equal(t, []*Payment{salary}, scheduler.Due(date(2025, time.January, 25)))
+	_, err = scheduler.Advance(rent.ID, 0)
+	require.Error(t, err)
+	require.Equal(t, 1, scheduler.Payment(rent.ID).Occurrence)
+	_, err = scheduler.Advance("unknown", 0)
+	require.Error(t, err)
+
+	// An advance can be undone if the payment could not be sent.
+	require.Error(t, scheduler.Rewind(rent.ID, 1))
+	require.NoError(t, scheduler.Rewind(rent.ID, 0))
+	require.Equal(t, 0, scheduler.Payment(rent.ID).Occurrence)
+	require.True(t, scheduler.Payment(rent.ID).Notified)
+	require.Error(t, scheduler.Rewind(rent.ID, 0))
+	_, err = scheduler.Advance(rent.ID, 0)
+	require.NoError(t, err)
+
+	// Only the name, recipient and amount can be updated.
+	update := *salary
+	update.Amount = "3100"
+	update.Cadence = CadenceWeekly
+	updated, err := scheduler.Update(update)
+	require.NoError(t, err)
+	require.Equal(t, "3100", updated.Amount)
+	require.Equal(t, CadenceMonthly, updated.Cadence)
+	update.Amount = ""
+	_, err = scheduler.Update(update)
+	require.Error(t, err)
+	require.Equal(t, "3100", scheduler.Payment(salary.ID).Amount)
+
+	// Payments are persisted.
+	reloaded, err := Load(filename)
+	require.NoError(t, err)
+	require.Equal(t, scheduler.Payments(), reloaded.Payments())
+
+	require.NoError(t, scheduler.Remove(rent.ID))
+	require.Error(t, scheduler.Remove(rent.ID))
+	require.Nil(t, scheduler.Payment(rent.ID))
+	require.Equal(t, []*Payment{updated}, scheduler.Payments())
+}
+
+func TestSchedulerWriteError(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scheduler")
+	require.NoError(t, os.Mkdir(dir, 0700))
+	scheduler, err := Load(filepath.Join(dir, "scheduledpayments.json"))
+	require.NoError(t, err)
+	rent, err := scheduler.Add(Payment{
+		AccountCode:      "v0-55555555-btc-0",
+		Name:             "Rent",
+		RecipientAddress: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+		Amount:           "1000000",
+		Cadence:          CadenceMonthly,
+		Interval:         1,
+		Start:            date(2025, time.January, 1),
+	})
+	require.NoError(t, err)
+
+	// The folder of the file was removed, so writing it fails and nothing changes.
+	require.NoError(t, os.RemoveAll(dir))
+	_, err = scheduler.Add(*rent)
+	require.Error(t, err)
+	update := *rent
+	update.Name = "Office"
+	_, err = scheduler.Update(update)
+	require.Error(t, err)
+	require.Error(t, scheduler.MarkNotified(rent.ID))
+	_, err = scheduler.Advance(rent.ID, 0)
+	require.Error(t, err)
+	require.Error(t, scheduler.Remove(rent.ID))
+	require.Equal(t, []*Payment{rent}, scheduler.Payments())
+}
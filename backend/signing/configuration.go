@@ -80,6 +80,26 @@ type BitcoinSimple struct {
 	ScriptType ScriptType `json:"scriptType"`
 }
 
+// maxMultisigCosigners is the maximum number of cosigners in a multisig configuration. This is
+// the maximum supported by hardware signers such as the BitBox02.
+const maxMultisigCosigners = 15
+
+// BitcoinMultisig represents an m-of-n P2WSH multisig Bitcoin/Litecoin signing configuration. The
+// witness script is `sortedmulti`, i.e. the public keys are sorted lexicographically (BIP-67).
+type BitcoinMultisig struct {
+	// Threshold is the number of signatures required to spend (m).
+	Threshold int `json:"threshold"`
+	// KeyInfos are the keys of all cosigners (n).
+	KeyInfos []KeyInfo `json:"keyInfos"`
+	// OurKeyIndex is the index in KeyInfos of the key belonging to the keystore of this account.
+	OurKeyIndex int `json:"ourKeyIndex"`
+}
+
+// OurKeyInfo returns the key belonging to the keystore of this account.
+func (multisig *BitcoinMultisig) OurKeyInfo() KeyInfo {
+	return multisig.KeyInfos[multisig.OurKeyIndex]
+}
+
 // EthereumSimple represents a simple (standard single-sig, no exotic signing methods) Ethereum
 // signing configuration.
 type EthereumSimple struct {
@@ -90,8 +110,9 @@ type EthereumSimple struct {
 type Configuration struct {
 	// Poor man's union type: only one of the below can be non-nil.
 
-	BitcoinSimple  *BitcoinSimple  `json:"bitcoinSimple,omitempty"`
-	EthereumSimple *EthereumSimple `json:"ethereumSimple,omitempty"`
+	BitcoinSimple   *BitcoinSimple   `json:"bitcoinSimple,omitempty"`
+	BitcoinMultisig *BitcoinMultisig `json:"bitcoinMultisig,omitempty"`
+	EthereumSimple  *EthereumSimple  `json:"ethereumSimple,omitempty"`
 }
 
 // NewBitcoinConfiguration creates a new configuration.
@@ -116,6 +137,43 @@ func NewBitcoinConfiguration(
 	}
 }
 
+// NewBitcoinMultisigConfiguration creates a new m-of-n multisig configuration. ourKeyIndex is the
+// index of the key in keyInfos which belongs to the keystore of the account.
+func NewBitcoinMultisigConfiguration(
+	threshold int,
+	keyInfos []KeyInfo,
+	ourKeyIndex int,
+) (*Configuration, error) {
+	if len(keyInfos) == 0 || len(keyInfos) > maxMultisigCosigners {
+		return nil, errp.Newf("A multisig configuration needs between 1 and %d cosigners",
+			maxMultisigCosigners)
+	}
+	if threshold < 1 || threshold > len(keyInfos) {
+		return nil, errp.Newf("Invalid multisig threshold %d-of-%d", threshold, len(keyInfos))
+	}
+	if ourKeyIndex < 0 || ourKeyIndex >= len(keyInfos) {
+		return nil, errp.New("Invalid index of our key in the multisig configuration")
+	}
+	xpubs := map[string]struct{}{}
+	for _, keyInfo := range keyInfos {
+		if keyInfo.ExtendedPublicKey.IsPrivate() {
+			return nil, errp.New("A cosigner key is private! Only extended public keys are accepted.")
+		}
+		xpub := keyInfo.ExtendedPublicKey.String()
+		if _, ok := xpubs[xpub]; ok {
+			return nil, errp.New("Duplicate cosigner in the multisig configuration")
+		}
+		xpubs[xpub] = struct{}{}
+	}
+	return &Configuration{
+		BitcoinMultisig: &BitcoinMultisig{
+			Threshold:   threshold,
+			KeyInfos:    keyInfos,
+			OurKeyIndex: ourKeyIndex,
+		},
+	}, nil
+}
+
 // NewEthereumConfiguration creates a new configuration.
 func NewEthereumConfiguration(
 	rootFingerprint []byte,
@@ -138,22 +196,33 @@ func NewEthereumConfiguration(
 
 // ScriptType returns the configuration's keypath.
 func (configuration *Configuration) ScriptType() ScriptType {
+	if configuration.BitcoinMultisig != nil {
+		return ScriptTypeP2WSH
+	}
 	return configuration.BitcoinSimple.ScriptType
 }
 
-// AbsoluteKeypath returns the configuration's keypath.
+// AbsoluteKeypath returns the configuration's keypath. For multisig configurations, this is the
+// keypath of our key.
 func (configuration *Configuration) AbsoluteKeypath() AbsoluteKeypath {
 	if configuration.BitcoinSimple != nil {
 		return configuration.BitcoinSimple.KeyInfo.AbsoluteKeypath
 	}
+	if configuration.BitcoinMultisig != nil {
+		return configuration.BitcoinMultisig.OurKeyInfo().AbsoluteKeypath
+	}
 	return configuration.EthereumSimple.KeyInfo.AbsoluteKeypath
 }
 
-// ExtendedPublicKey returns the configuration's extended public key.
+// ExtendedPublicKey returns the configuration's extended public key. For multisig configurations,
+// this is our extended public key.
 func (configuration *Configuration) ExtendedPublicKey() *hdkeychain.ExtendedKey {
 	if configuration.BitcoinSimple != nil {
 		return configuration.BitcoinSimple.KeyInfo.ExtendedPublicKey
 	}
+	if configuration.BitcoinMultisig != nil {
+		return configuration.BitcoinMultisig.OurKeyInfo().ExtendedPublicKey
+	}
 	return configuration.EthereumSimple.KeyInfo.ExtendedPublicKey
 }
 
@@ -161,6 +230,7 @@ func (configuration *Configuration) ExtendedPublicKey() *hdkeychain.ExtendedKey
 // The configuration keypath must be a BIP44 keypath:
 // m/purpose'/coin'/account' for Bitcoin-based coins.
 // m/44'/coin'/0'/0/account for Ethereum.
+// m/48'/coin'/account'/script_type' for Bitcoin-based multisig (BIP-48), using our key.
 // For invalid keypaths, zero is returned for the account number, along with an error.
 func (configuration *Configuration) AccountNumber() (uint16, error) {
 	if configuration.BitcoinSimple != nil {
@@ -170,6 +240,13 @@ func (configuration *Configuration) AccountNumber() (uint16, error) {
 		}
 		return uint16(keypath[2] - hdkeychain.HardenedKeyStart), nil
 	}
+	if configuration.BitcoinMultisig != nil {
+		keypath := configuration.BitcoinMultisig.OurKeyInfo().AbsoluteKeypath.ToUInt32()
+		if len(keypath) != 4 || keypath[2] < hdkeychain.HardenedKeyStart {
+			return 0, errp.Newf("unexpected bitcoin multisig keypath: %v", keypath)
+		}
+		return uint16(keypath[2] - hdkeychain.HardenedKeyStart), nil
+	}
 	if configuration.EthereumSimple != nil {
 		keypath := configuration.EthereumSimple.KeyInfo.AbsoluteKeypath.ToUInt32()
 		if len(keypath) != 5 || keypath[4] >= hdkeychain.HardenedKeyStart {
@@ -208,6 +285,31 @@ func (configuration *Configuration) Derive(relativeKeypath RelativeKeypath) (*Co
 			derivedPublicKey,
 		), nil
 	}
+	multisig := configuration.BitcoinMultisig
+	if multisig != nil {
+		if relativeKeypath.Hardened() {
+			return nil, errp.New("A configuration can only be derived with a non-hardened relative keypath.")
+		}
+		keyInfos := make([]KeyInfo, len(multisig.KeyInfos))
+		for index, keyInfo := range multisig.KeyInfos {
+			derivedPublicKey, err := relativeKeypath.Derive(keyInfo.ExtendedPublicKey)
+			if err != nil {
+				return nil, err
+			}
+			keyInfos[index] = KeyInfo{
+				RootFingerprint:   keyInfo.RootFingerprint,
+				AbsoluteKeypath:   keyInfo.AbsoluteKeypath.Append(relativeKeypath),
+				ExtendedPublicKey: derivedPublicKey,
+			}
+		}
+		return &Configuration{
+			BitcoinMultisig: &BitcoinMultisig{
+				Threshold:   multisig.Threshold,
+				KeyInfos:    keyInfos,
+				OurKeyIndex: multisig.OurKeyIndex,
+			},
+		}, nil
+	}
 
 	return nil, errp.New("Can only call this on a bitcoin configuration")
 }
@@ -218,11 +320,17 @@ func (configuration *Configuration) String() string {
 		return fmt.Sprintf("bitcoinSimple;scriptType=%s;%s",
 			configuration.BitcoinSimple.ScriptType, configuration.BitcoinSimple.KeyInfo)
 	}
+	if configuration.BitcoinMultisig != nil {
+		return fmt.Sprintf("bitcoinMultisig;threshold=%d-of-%d;%s",
+			configuration.BitcoinMultisig.Threshold,
+			len(configuration.BitcoinMultisig.KeyInfos),
+			configuration.BitcoinMultisig.OurKeyInfo())
+	}
 	return fmt.Sprintf("ethereumSimple;%s", configuration.EthereumSimple.KeyInfo)
 }
 
 // Configurations is an unordered collection of configurations. All entries must have the same root
-// fingerprint.
+// fingerprint. For multisig configurations, the root fingerprint of our key is considered.
 type Configurations []*Configuration
 
 // RootFingerprint gets the fingerprint of the first config (assuming that all configurations have
@@ -233,6 +341,9 @@ func (configs Configurations) RootFingerprint() ([]byte, error) {
 		if config.BitcoinSimple != nil {
 			return config.BitcoinSimple.KeyInfo.RootFingerprint, nil
 		}
+		if config.BitcoinMultisig != nil {
+			return config.BitcoinMultisig.OurKeyInfo().RootFingerprint, nil
+		}
 		if config.EthereumSimple != nil {
 			return config.EthereumSimple.KeyInfo.RootFingerprint, nil
 		}
@@ -248,6 +359,11 @@ func (configs Configurations) ContainsRootFingerprint(rootFingerprint []byte) bo
 				return true
 			}
 		}
+		if config.BitcoinMultisig != nil {
+			if bytes.Equal(config.BitcoinMultisig.OurKeyInfo().RootFingerprint, rootFingerprint) {
+				return true
+			}
+		}
 		if config.EthereumSimple != nil {
 			if bytes.Equal(config.EthereumSimple.KeyInfo.RootFingerprint, rootFingerprint) {
 				return true
@@ -267,3 +383,13 @@ func (configs Configurations) FindScriptType(scriptType ScriptType) int {
 	}
 	return -1
 }
+
+// IsMultisig returns true if one of the configurations is a multisig configuration.
+func (configs Configurations) IsMultisig() bool {
+	for _, config := range configs {
+		if config.BitcoinMultisig != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -148,3 +148,79 @@ func TestAccountNumber(t *testing.T) {
 	require.Error(t, err)
 	require.Equal(t, uint16(0), num)
 }
+
+func TestBitcoinMultisig(t *testing.T) {
+	keyInfos := make([]KeyInfo, 3)
+	for i := range keyInfos {
+		seed := make([]byte, 32)
+		seed[0] = byte(i)
+		xprv, err := hdkeychain.NewMaster(seed, &chaincfg.TestNet3Params)
+		require.NoError(t, err)
+		keypath := mustKeypath("m/48'/1'/5'/2'")
+		xprv, err = keypath.Derive(xprv)
+		require.NoError(t, err)
+		xpub, err := xprv.Neuter()
+		require.NoError(t, err)
+		keyInfos[i] = KeyInfo{
+			RootFingerprint:   []byte{byte(i), 2, 3, 4},
+			AbsoluteKeypath:   keypath,
+			ExtendedPublicKey: xpub,
+		}
+	}
+
+	_, err := NewBitcoinMultisigConfiguration(0, keyInfos, 0)
+	require.Error(t, err)
+	_, err = NewBitcoinMultisigConfiguration(4, keyInfos, 0)
+	require.Error(t, err)
+	_, err = NewBitcoinMultisigConfiguration(2, keyInfos, 3)
+	require.Error(t, err)
+	_, err = NewBitcoinMultisigConfiguration(2, []KeyInfo{keyInfos[0], keyInfos[0]}, 0)
+	require.Error(t, err)
+	xprv, err := hdkeychain.NewMaster(make([]byte, 32), &chaincfg.TestNet3Params)
+	require.NoError(t, err)
+	_, err = NewBitcoinMultisigConfiguration(2, []KeyInfo{
+		keyInfos[0],
+		{RootFingerprint: []byte{9, 2, 3, 4}, AbsoluteKeypath: keyInfos[0].AbsoluteKeypath, ExtendedPublicKey: xprv},
+	}, 0)
+	require.Error(t, err)
+
+	cfg, err := NewBitcoinMultisigConfiguration(2, keyInfos, 1)
+	require.NoError(t, err)
+	require.Equal(t, ScriptTypeP2WSH, cfg.ScriptType())
+	require.Equal(t, keyInfos[1].ExtendedPublicKey, cfg.ExtendedPublicKey())
+	num, err := cfg.AccountNumber()
+	require.NoError(t, err)
+	require.Equal(t, uint16(5), num)
+
+	configs := Configurations{cfg}
+	rootFingerprint, err := configs.RootFingerprint()
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3, 4}, rootFingerprint)
+	require.True(t, configs.ContainsRootFingerprint([]byte{1, 2, 3, 4}))
+	require.False(t, configs.ContainsRootFingerprint([]byte{0, 2, 3, 4}))
+
+	jsonBytes, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	var cfgDecoded Configuration
+	require.NoError(t, json.Unmarshal(jsonBytes, &cfgDecoded))
+	require.Nil(t, cfgDecoded.BitcoinSimple)
+	require.NotNil(t, cfgDecoded.BitcoinMultisig)
+	require.Equal(t, 2, cfgDecoded.BitcoinMultisig.Threshold)
+	require.Equal(t, 1, cfgDecoded.BitcoinMultisig.OurKeyIndex)
+	require.Len(t, cfgDecoded.BitcoinMultisig.KeyInfos, 3)
+	for i, keyInfo := range cfgDecoded.BitcoinMultisig.KeyInfos {
+		require.Equal(t, keyInfos[i].ExtendedPublicKey.String(), keyInfo.ExtendedPublicKey.String())
+		require.Equal(t, keyInfos[i].RootFingerprint, keyInfo.RootFingerprint)
+	}
+
+	relativeKeypath, err := NewRelativeKeypath("1/7")
+	require.NoError(t, err)
+	derived, err := cfg.Derive(relativeKeypath)
+	require.NoError(t, err)
+	require.Equal(t, "m/48'/1'/5'/2'/1/7", derived.AbsoluteKeypath().Encode())
+	for i, keyInfo := range derived.BitcoinMultisig.KeyInfos {
+		expected, err := relativeKeypath.Derive(keyInfos[i].ExtendedPublicKey)
+		require.NoError(t, err)
+		require.Equal(t, expected.String(), keyInfo.ExtendedPublicKey.String())
+	}
+}
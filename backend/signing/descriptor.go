@@ -0,0 +1,317 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signing
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// Output script descriptors, see BIP-380 and the following BIPs for the individual script
+// expressions.
+//
+// Only the descriptors matching the account types of the BitBoxApp are supported:
+//
+//	pkh(KEY), sh(wpkh(KEY)), wpkh(KEY), tr(KEY), wsh(sortedmulti(M,KEY,...,KEY))
+//
+// where KEY is an extended public key with key origin, deriving the receive and change addresses
+// of the account: `[fingerprint/keypath]xpub/<0;1>/*` (BIP-389 multipath). As the BitBoxApp always
+// derives the change addresses at `/1/*`, `[fingerprint/keypath]xpub/0/*` is also accepted when
+// importing.
+
+const (
+	descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	descriptorChecksumLength  = 8
+
+	descriptorMultipathSuffix = "/<0;1>/*"
+	descriptorReceiveSuffix   = "/0/*"
+)
+
+var descriptorChecksumGenerator = [5]uint64{
+	0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd,
+}
+
+func descriptorPolymod(symbols []uint64) uint64 {
+	checksum := uint64(1)
+	for _, value := range symbols {
+		top := checksum >> 35
+		checksum = (checksum&0x7ffffffff)<<5 ^ value
+		for i, generator := range descriptorChecksumGenerator {
+			if (top>>i)&1 == 1 {
+				checksum ^= generator
+			}
+		}
+	}
+	return checksum
+}
+
+// DescriptorChecksum computes the 8 character BIP-380 checksum of a descriptor (without the
+// `#checksum` suffix).
+func DescriptorChecksum(descriptor string) (string, error) {
+	symbols := []uint64{}
+	groups := []uint64{}
+	for _, char := range descriptor {
+		position := strings.IndexRune(descriptorInputCharset, char)
+		if position == -1 {
+			return "", errp.Newf("invalid character in descriptor: %q", char)
+		}
+		symbols = append(symbols, uint64(position&31))
+		groups = append(groups, uint64(position>>5))
+		if len(groups) == 3 {
+			symbols = append(symbols, groups[0]*9+groups[1]*3+groups[2])
+			groups = groups[:0]
+		}
+	}
+	switch len(groups) {
+	case 1:
+		symbols = append(symbols, groups[0])
+	case 2:
+		symbols = append(symbols, groups[0]*3+groups[1])
+	}
+	symbols = append(symbols, make([]uint64, descriptorChecksumLength)...)
+	checksum := descriptorPolymod(symbols) ^ 1
+	result := make([]byte, descriptorChecksumLength)
+	for i := range result {
+		result[i] = descriptorChecksumCharset[(checksum>>(5*(descriptorChecksumLength-1-i)))&31]
+	}
+	return string(result), nil
+}
+
+// encodeDescriptorKey encodes a key expression with key origin and multipath receive/change
+// derivation. The extended key is encoded with the version bytes of the given network.
+func encodeDescriptorKey(keyInfo KeyInfo, net *chaincfg.Params) (string, error) {
+	if len(keyInfo.RootFingerprint) != 4 {
+		return "", errp.Newf("invalid root fingerprint length: %d", len(keyInfo.RootFingerprint))
+	}
+	xpub, err := hdkeychain.NewKeyFromString(keyInfo.ExtendedPublicKey.String())
+	if err != nil {
+		return "", errp.WithStack(err)
+	}
+	xpub.SetNet(net)
+	var origin strings.Builder
+	origin.WriteString(hex.EncodeToString(keyInfo.RootFingerprint))
+	for _, element := range keyInfo.AbsoluteKeypath.ToUInt32() {
+		if element >= hdkeychain.HardenedKeyStart {
+			fmt.Fprintf(&origin, "/%dh", element-hdkeychain.HardenedKeyStart)
+		} else {
+			fmt.Fprintf(&origin, "/%d", element)
+		}
+	}
+	return fmt.Sprintf("[%s]%s%s", origin.String(), xpub.String(), descriptorMultipathSuffix), nil
+}
+
+// Descriptor returns the output descriptor of a Bitcoin-based configuration, including its
+// checksum. The extended keys are encoded with the version bytes of the given network, e.g. `xpub`
+// or `tpub`.
+func (configuration *Configuration) Descriptor(net *chaincfg.Params) (string, error) {
+	var descriptor string
+	switch {
+	case configuration.BitcoinSimple != nil:
+		key, err := encodeDescriptorKey(configuration.BitcoinSimple.KeyInfo, net)
+		if err != nil {
+			return "", err
+		}
+		switch configuration.BitcoinSimple.ScriptType {
+		case ScriptTypeP2PKH:
+			descriptor = fmt.Sprintf("pkh(%s)", key)
+		case ScriptTypeP2WPKHP2SH:
+			descriptor = fmt.Sprintf("sh(wpkh(%s))", key)
+		case ScriptTypeP2WPKH:
+			descriptor = fmt.Sprintf("wpkh(%s)", key)
+		case ScriptTypeP2TR:
+			descriptor = fmt.Sprintf("tr(%s)", key)
+		default:
+			return "", errp.Newf("unsupported script type: %s", configuration.BitcoinSimple.ScriptType)
+		}
+	case configuration.BitcoinMultisig != nil:
+		keys := make([]string, len(configuration.BitcoinMultisig.KeyInfos))
+		for index, keyInfo := range configuration.BitcoinMultisig.KeyInfos {
+			key, err := encodeDescriptorKey(keyInfo, net)
+			if err != nil {
+				return "", err
+			}
+			keys[index] = key
+		}
+		descriptor = fmt.Sprintf("wsh(sortedmulti(%d,%s))",
+			configuration.BitcoinMultisig.Threshold, strings.Join(keys, ","))
+	default:
+		return "", errp.New("descriptors are only available for Bitcoin-based configurations")
+	}
+	checksum, err := DescriptorChecksum(descriptor)
+	if err != nil {
+		return "", err
+	}
+	return descriptor + "#" + checksum, nil
+}
+
+// parseDescriptorKeypath parses the key origin keypath, e.g. `84h/0h/0h`. Both `h` and `'` are
+// accepted as hardened markers.
+func parseDescriptorKeypath(path string) (AbsoluteKeypath, error) {
+	if path == "" {
+		return NewAbsoluteKeypathFromUint32(), nil
+	}
+	elements := []uint32{}
+	for _, element := range strings.Split(path, "/") {
+		offset := uint32(0)
+		if trimmed := strings.TrimRight(element, "h'H"); trimmed != element {
+			if len(element)-len(trimmed) != 1 {
+				return nil, errp.Newf("invalid keypath element: %s", element)
+			}
+			offset = hdkeychain.HardenedKeyStart
+			element = trimmed
+		}
+		index, err := strconv.ParseUint(element, 10, 31)
+		if err != nil {
+			return nil, errp.Newf("invalid keypath element: %s", element)
+		}
+		elements = append(elements, uint32(index)+offset)
+	}
+	return NewAbsoluteKeypathFromUint32(elements...), nil
+}
+
+// parseDescriptorKey parses a key expression of the form `[fingerprint/keypath]xpub/<0;1>/*`.
+func parseDescriptorKey(key string, net *chaincfg.Params) (*KeyInfo, error) {
+	if !strings.HasPrefix(key, "[") {
+		return nil, errp.New("the key origin is required in the descriptor keys")
+	}
+	originEnd := strings.Index(key, "]")
+	if originEnd == -1 {
+		return nil, errp.New("invalid key origin")
+	}
+	origin, xpubWithPath := key[1:originEnd], key[originEnd+1:]
+	fingerprintHex, path, _ := strings.Cut(origin, "/")
+	rootFingerprint, err := hex.DecodeString(fingerprintHex)
+	if err != nil || len(rootFingerprint) != 4 {
+		return nil, errp.Newf("invalid key origin fingerprint: %s", fingerprintHex)
+	}
+	absoluteKeypath, err := parseDescriptorKeypath(path)
+	if err != nil {
+		return nil, err
+	}
+	var encodedXPub string
+	switch {
+	case strings.HasSuffix(xpubWithPath, descriptorMultipathSuffix):
+		encodedXPub = strings.TrimSuffix(xpubWithPath, descriptorMultipathSuffix)
+	case strings.HasSuffix(xpubWithPath, descriptorReceiveSuffix):
+		encodedXPub = strings.TrimSuffix(xpubWithPath, descriptorReceiveSuffix)
+	default:
+		return nil, errp.Newf(
+			"unsupported key derivation, expected %s or %s",
+			descriptorMultipathSuffix, descriptorReceiveSuffix)
+	}
+	xpub, err := hdkeychain.NewKeyFromString(encodedXPub)
+	if err != nil {
+		return nil, errp.Wrap(err, "Could not read an extended public key.")
+	}
+	if xpub.IsPrivate() {
+		return nil, errp.New("private keys are not allowed in descriptors")
+	}
+	if !xpub.IsForNet(net) {
+		return nil, errp.New("the extended public key is for a different network")
+	}
+	return &KeyInfo{
+		RootFingerprint:   rootFingerprint,
+		AbsoluteKeypath:   absoluteKeypath,
+		ExtendedPublicKey: xpub,
+	}, nil
+}
+
+// unwrapDescriptor returns the argument of `function(argument)`, or false if the expression is not
+// a call of `function`.
+func unwrapDescriptor(expression string, function string) (string, bool) {
+	if !strings.HasPrefix(expression, function+"(") || !strings.HasSuffix(expression, ")") {
+		return "", false
+	}
+	return expression[len(function)+1 : len(expression)-1], true
+}
+
+// ParseDescriptor parses an output descriptor into a signing configuration. If the descriptor has a
+// checksum, it is verified. The extended keys must belong to the given network. For multisig
+// descriptors, the first key is assumed to be ours.
+func ParseDescriptor(descriptor string, net *chaincfg.Params) (*Configuration, error) {
+	descriptor = strings.TrimSpace(descriptor)
+	if body, checksum, ok := strings.Cut(descriptor, "#"); ok {
+		expected, err := DescriptorChecksum(body)
+		if err != nil {
+			return nil, err
+		}
+		if checksum != expected {
+			return nil, errp.New("invalid descriptor checksum")
+		}
+		descriptor = body
+	}
+	if inner, ok := unwrapDescriptor(descriptor, "sh"); ok {
+		key, ok := unwrapDescriptor(inner, "wpkh")
+		if !ok {
+			return nil, errp.New("only sh(wpkh(...)) descriptors are supported")
+		}
+		return parseSimpleDescriptor(ScriptTypeP2WPKHP2SH, key, net)
+	}
+	if inner, ok := unwrapDescriptor(descriptor, "wsh"); ok {
+		multi, ok := unwrapDescriptor(inner, "sortedmulti")
+		if !ok {
+			return nil, errp.New("only wsh(sortedmulti(...)) descriptors are supported")
+		}
+		return parseMultisigDescriptor(multi, net)
+	}
+	for function, scriptType := range map[string]ScriptType{
+		"pkh":  ScriptTypeP2PKH,
+		"wpkh": ScriptTypeP2WPKH,
+		"tr":   ScriptTypeP2TR,
+	} {
+		if key, ok := unwrapDescriptor(descriptor, function); ok {
+			return parseSimpleDescriptor(scriptType, key, net)
+		}
+	}
+	return nil, errp.New("unsupported descriptor")
+}
+
+func parseSimpleDescriptor(scriptType ScriptType, key string, net *chaincfg.Params) (*Configuration, error) {
+	keyInfo, err := parseDescriptorKey(key, net)
+	if err != nil {
+		return nil, err
+	}
+	return NewBitcoinConfiguration(
+		scriptType, keyInfo.RootFingerprint, keyInfo.AbsoluteKeypath, keyInfo.ExtendedPublicKey), nil
+}
+
+func parseMultisigDescriptor(multi string, net *chaincfg.Params) (*Configuration, error) {
+	arguments := strings.Split(multi, ",")
+	if len(arguments) < 2 {
+		return nil, errp.New("invalid sortedmulti expression")
+	}
+	threshold, err := strconv.Atoi(arguments[0])
+	if err != nil {
+		return nil, errp.Newf("invalid multisig threshold: %s", arguments[0])
+	}
+	keyInfos := make([]KeyInfo, len(arguments)-1)
+	for index, key := range arguments[1:] {
+		keyInfo, err := parseDescriptorKey(key, net)
+		if err != nil {
+			return nil, err
+		}
+		keyInfos[index] = *keyInfo
+	}
+	return NewBitcoinMultisigConfiguration(threshold, keyInfos, 0)
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+const descriptorTestXPub = "xpub6DJ2dNUysrn5Vt36jH2KLBT2i1auw1tTSSomg8PhqNiUtx8QX2SvC9nrHu81fT41fvDUnhMjEzQgXnQjKEu3oaqMSzhSrHMxyyoEAmUHQbY"
+
+func TestDescriptorChecksum(t *testing.T) {
+	// Test vectors from BIP-380 and Bitcoin Core.
+	checksum, err := DescriptorChecksum("raw(deadbeef)")
+	require.NoError(t, err)
+	require.Equal(t, "89f8spxm", checksum)
+
+	checksum, err = DescriptorChecksum("wpkh([d34db33f/84h/0h/0h]" + descriptorTestXPub + "/0/*)")
+	require.NoError(t, err)
+	require.Equal(t, "cjjspncu", checksum)
+
+	_, err = DescriptorChecksum("wpkh(é)")
+	require.Error(t, err)
+}
+
+func TestDescriptor(t *testing.T) {
+	xpub, err := hdkeychain.NewKeyFromString(descriptorTestXPub)
+	require.NoError(t, err)
+	rootFingerprint := []byte{0xd3, 0x4d, 0xb3, 0x3f}
+	net := &chaincfg.MainNetParams
+
+	for _, c := range []struct {
+		scriptType ScriptType
+		keypath    string
+		expected   string
+	}{
+		{ScriptTypeP2PKH, "m/44'/0'/0'", "pkh([d34db33f/44h/0h/0h]" + descriptorTestXPub + "/<0;1>/*)"},
+		{ScriptTypeP2WPKHP2SH, "m/49'/0'/0'", "sh(wpkh([d34db33f/49h/0h/0h]" + descriptorTestXPub + "/<0;1>/*))"},
+		{ScriptTypeP2WPKH, "m/84'/0'/0'", "wpkh([d34db33f/84h/0h/0h]" + descriptorTestXPub + "/<0;1>/*)"},
+		{ScriptTypeP2TR, "m/86'/0'/0'", "tr([d34db33f/86h/0h/0h]" + descriptorTestXPub + "/<0;1>/*)"},
+	} {
+		t.Run(string(c.scriptType), func(t *testing.T) {
+			configuration := NewBitcoinConfiguration(c.scriptType, rootFingerprint, mustKeypath(c.keypath), xpub)
+			descriptor, err := configuration.Descriptor(net)
+			require.NoError(t, err)
+			checksum, err := DescriptorChecksum(c.expected)
+			require.NoError(t, err)
+			require.Equal(t, c.expected+"#"+checksum, descriptor)
+
+			parsed, err := ParseDescriptor(descriptor, net)
+			require.NoError(t, err)
+			require.Equal(t, configuration.String(), parsed.String())
+			require.Equal(t, xpub.String(), parsed.ExtendedPublicKey().String())
+			require.Equal(t, rootFingerprint, parsed.BitcoinSimple.KeyInfo.RootFingerprint)
+
+			// Without checksum, with apostrophes and the receive chain only.
+			body := strings.ReplaceAll(c.expected, "h/", "'/")
+			body = strings.ReplaceAll(body, "h]", "']")
+			body = strings.ReplaceAll(body, "/<0;1>/*", "/0/*")
+			parsed, err = ParseDescriptor(body, net)
+			require.NoError(t, err)
+			require.Equal(t, configuration.String(), parsed.String())
+		})
+	}
+
+	// Ethereum configurations have no descriptor.
+	_, err = NewEthereumConfiguration(rootFingerprint, mustKeypath("m/44'/60'/0'/0/0"), xpub).Descriptor(net)
+	require.Error(t, err)
+}
+
+func TestDescriptorTestnet(t *testing.T) {
+	master, err := hdkeychain.NewMaster(make([]byte, 32), &chaincfg.TestNet3Params)
+	require.NoError(t, err)
+	xpub, err := master.Neuter()
+	require.NoError(t, err)
+	configuration := NewBitcoinConfiguration(
+		ScriptTypeP2WPKH, []byte{1, 2, 3, 4}, mustKeypath("m/84'/1'/0'"), xpub)
+	descriptor, err := configuration.Descriptor(&chaincfg.TestNet3Params)
+	require.NoError(t, err)
+	require.Contains(t, descriptor, "]tpub")
+
+	_, err = ParseDescriptor(descriptor, &chaincfg.TestNet3Params)
+	require.NoError(t, err)
+	// Wrong network.
+	_, err = ParseDescriptor(descriptor, &chaincfg.MainNetParams)
+	require.Error(t, err)
+}
+
+func TestDescriptorMultisig(t *testing.T) {
+	net := &chaincfg.MainNetParams
+	keyInfos := make([]KeyInfo, 3)
+	for i := range keyInfos {
+		seed := make([]byte, 32)
+		seed[0] = byte(i)
+		master, err := hdkeychain.NewMaster(seed, net)
+		require.NoError(t, err)
+		keypath := mustKeypath("m/48'/0'/0'/2'")
+		xprv, err := keypath.Derive(master)
+		require.NoError(t, err)
+		xpub, err := xprv.Neuter()
+		require.NoError(t, err)
+		keyInfos[i] = KeyInfo{
+			RootFingerprint:   []byte{byte(i), 0, 0, 0},
+			AbsoluteKeypath:   keypath,
+			ExtendedPublicKey: xpub,
+		}
+	}
+	configuration, err := NewBitcoinMultisigConfiguration(2, keyInfos, 1)
+	require.NoError(t, err)
+	descriptor, err := configuration.Descriptor(net)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(descriptor, "wsh(sortedmulti(2,[00000000/48h/0h/0h/2h]xpub"))
+
+	parsed, err := ParseDescriptor(descriptor, net)
+	require.NoError(t, err)
+	require.NotNil(t, parsed.BitcoinMultisig)
+	require.Equal(t, 2, parsed.BitcoinMultisig.Threshold)
+	require.Len(t, parsed.BitcoinMultisig.KeyInfos, 3)
+	for i, keyInfo := range parsed.BitcoinMultisig.KeyInfos {
+		require.Equal(t, keyInfos[i].ExtendedPublicKey.String(), keyInfo.ExtendedPublicKey.String())
+	}
+	// The first key is assumed to be ours.
+	require.Equal(t, 0, parsed.BitcoinMultisig.OurKeyIndex)
+}
+
+func TestParseDescriptorErrors(t *testing.T) {
+	net := &chaincfg.MainNetParams
+	key := "[d34db33f/84h/0h/0h]" + descriptorTestXPub
+	for _, descriptor := range []string{
+		// Invalid checksum.
+		"wpkh(" + key + "/0/*)#cjjspncv",
+		// Missing key origin.
+		"wpkh(" + descriptorTestXPub + "/0/*)",
+		// Unsupported derivation.
+		"wpkh(" + key + "/0/0)",
+		"wpkh(" + key + ")",
+		// Unsupported script expressions.
+		"sh(" + key + "/0/*)",
+		"wsh(multi(1," + key + "/0/*))",
+		"combo(" + key + "/0/*)",
+		// Invalid keypath.
+		"wpkh([d34db33f/84hh/0h/0h]" + descriptorTestXPub + "/0/*)",
+		// Invalid threshold.
+		"wsh(sortedmulti(2," + key + "/0/*))",
+		// Private key.
+		"wpkh([d34db33f/84h/0h/0h]xprv9s21ZrQH143K3gie3VFLgx8JcmqZNsBcBc6vAdJrsf4bPRhx69U8qZe3EYAyvRWyQdEfz7ZpyYtL8jW2d2Lfkfh6g2zivq8JdZPQqxoxLwB/0/*)",
+	} {
+		_, err := ParseDescriptor(descriptor, net)
+		require.Error(t, err, descriptor)
+	}
+}
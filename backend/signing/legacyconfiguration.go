@@ -35,7 +35,7 @@ type LegacyConfiguration struct {
 	scriptType         ScriptType // Only used in btc and ltc, dummy for eth
 	absoluteKeypath    AbsoluteKeypath
 	extendedPublicKeys []*hdkeychain.ExtendedKey // Should be empty for address based watch only accounts
-	signingThreshold   int                       // TODO Multisig Only
+	signingThreshold   int                       // Only greater than 1 for multisig
 	address            string                    // For address based accounts only
 }
 
@@ -103,6 +103,19 @@ func ConvertToLegacyConfigurations(configurations Configurations) LegacyConfigur
 		if cfg.BitcoinSimple != nil {
 			scriptType = cfg.BitcoinSimple.ScriptType
 		}
+		if cfg.BitcoinMultisig != nil {
+			extendedPublicKeys := make([]*hdkeychain.ExtendedKey, len(cfg.BitcoinMultisig.KeyInfos))
+			for i, keyInfo := range cfg.BitcoinMultisig.KeyInfos {
+				extendedPublicKeys[i] = keyInfo.ExtendedPublicKey
+			}
+			result = append(result, &LegacyConfiguration{
+				scriptType:         ScriptTypeP2WSH,
+				absoluteKeypath:    cfg.AbsoluteKeypath(),
+				extendedPublicKeys: extendedPublicKeys,
+				signingThreshold:   cfg.BitcoinMultisig.Threshold,
+			})
+			continue
+		}
 		result = append(result, &LegacyConfiguration{
 			scriptType:         scriptType,
 			absoluteKeypath:    cfg.AbsoluteKeypath(),
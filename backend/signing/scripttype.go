@@ -29,4 +29,8 @@ const (
 
 	// ScriptTypeP2TR is a BIP-86 segwit v1 PayToTaproot output.
 	ScriptTypeP2TR ScriptType = "p2tr"
+
+	// ScriptTypeP2WSH is a segwit v0 PayToWitnessScriptHash output. It is only used for multisig
+	// (`sortedmulti`) configurations, see `BitcoinMultisig`.
+	ScriptTypeP2WSH ScriptType = "p2wsh"
 )
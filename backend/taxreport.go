@@ -0,0 +1,201 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/taxreport"
+	utilcfg "github.com/BitBoxSwiss/bitbox-wallet-app/util/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+)
+
+// taxReportEvents collects the events of the tax report from the confirmed transactions of all
+// accounts of the keystore with the given root fingerprint, including inactive accounts.
+//
+// A payment from one of these accounts to another one, e.g. from a BTC account to another BTC
+// account, is a transfer and not taxable. Fees are always disposals. The fees of ERC20 token
+// transactions are paid in ETH and part of the ETH account transactions.
+func (backend *Backend) taxReportEvents(rootFingerprint []byte, fiat string) ([]*taxreport.Event, error) {
+	type accountTransactions struct {
+		account      accounts.Interface
+		transactions accounts.OrderedTransactions
+	}
+	all := []accountTransactions{}
+	// Keyed by coin code and tx ID.
+	sent := map[string]bool{}
+	received := map[string]*big.Int{}
+	for _, account := range backend.Accounts() {
+		if account.FatalError() || account.Config().Config.HiddenBecauseUnused {
+			continue
+		}
+		if !account.Config().Config.SigningConfigurations.ContainsRootFingerprint(rootFingerprint) {
+			continue
+		}
+		if err := account.Initialize(); err != nil {
+			return nil, err
+		}
+		transactions, err := account.Transactions()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, accountTransactions{account: account, transactions: transactions})
+		for _, tx := range transactions {
+			key := string(account.Coin().Code()) + ":" + tx.TxID
+			switch tx.Type {
+			case accounts.TxTypeSend:
+				sent[key] = true
+			case accounts.TxTypeReceive:
+				if received[key] == nil {
+					received[key] = new(big.Int)
+				}
+				received[key].Add(received[key], tx.Amount.BigInt())
+			}
+		}
+	}
+
+	events := []*taxreport.Event{}
+	for _, item := range all {
+		account := item.account
+		coinCode := string(account.Coin().Code())
+		coinDecimals := coin.DecimalsExp(account.Coin())
+		addEvent := func(tx *accounts.TransactionData, eventType taxreport.EventType, amount *big.Int) {
+			if amount.Sign() <= 0 {
+				return
+			}
+			amountRat := new(big.Rat).SetFrac(amount, coinDecimals)
+			var fiatValue *big.Rat
+			price := backend.RatesUpdater().HistoricalPriceAt(coinCode, fiat, *tx.Timestamp)
+			if price != 0 {
+				fiatValue = new(big.Rat).Mul(amountRat, new(big.Rat).SetFloat64(price))
+			}
+			events = append(events, &taxreport.Event{
+				Time:        *tx.Timestamp,
+				Type:        eventType,
+				Asset:       coinCode,
+				Unit:        account.Coin().Unit(false),
+				Decimals:    int(account.Coin().Decimals(false)),
+				Amount:      amountRat,
+				FiatValue:   fiatValue,
+				AccountCode: string(account.Config().Config.Code),
+				TxID:        tx.TxID,
+				Note:        account.TxNote(tx.InternalID),
+			})
+		}
+		// Oldest first, so transactions with the same timestamp are processed in order.
+		for i := len(item.transactions) - 1; i >= 0; i-- {
+			tx := item.transactions[i]
+			if tx.Timestamp == nil || tx.Status == accounts.TxStatusPending {
+				continue
+			}
+			key := coinCode + ":" + tx.TxID
+			if tx.Status != accounts.TxStatusFailed {
+				switch tx.Type {
+				case accounts.TxTypeReceive:
+					if sent[key] {
+						addEvent(tx, taxreport.EventTypeTransfer, tx.Amount.BigInt())
+					} else {
+						addEvent(tx, taxreport.EventTypeAcquisition, tx.Amount.BigInt())
+					}
+				case accounts.TxTypeSend:
+					amount := tx.Amount.BigInt()
+					transferred := new(big.Int)
+					if received[key] != nil {
+						transferred.Set(received[key])
+					}
+					if transferred.Cmp(amount) > 0 {
+						transferred.Set(amount)
+					}
+					addEvent(tx, taxreport.EventTypeTransfer, transferred)
+					addEvent(tx, taxreport.EventTypeDisposal, new(big.Int).Sub(amount, transferred))
+				case accounts.TxTypeSendSelf:
+					addEvent(tx, taxreport.EventTypeTransfer, tx.Amount.BigInt())
+				}
+			}
+			if tx.Type != accounts.TxTypeReceive && tx.Fee != nil && !tx.FeeIsDifferentUnit {
+				addEvent(tx, taxreport.EventTypeFee, tx.Fee.BigInt())
+			}
+		}
+	}
+	return events, nil
+}
+
+// TaxReport computes the realized gains of all accounts of the keystore with the given root
+// fingerprint, using historical exchange rates to the given fiat currency at the time of each
+// transaction.
+func (backend *Backend) TaxReport(
+	rootFingerprint []byte, fiat string, method taxreport.Method) (*taxreport.Report, error) {
+	events, err := backend.taxReportEvents(rootFingerprint, fiat)
+	if err != nil {
+		return nil, err
+	}
+	return taxreport.Compute(events, method, fiat), nil
+}
+
+// ExportTaxReport computes the tax report (see TaxReport) and exports it to a CSV file with one row
+// per event, and a second CSV file with the per-year summary next to it.
+func (backend *Backend) ExportTaxReport(
+	rootFingerprint []byte, fiat string, method taxreport.Method) (*taxreport.Report, error) {
+	report, err := backend.TaxReport(rootFingerprint, fiat, method)
+	if err != nil {
+		return nil, err
+	}
+	exportsDir, err := utilcfg.ExportsDir()
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("%s-tax-report-%s.csv", time.Now().Format("2006-01-02-at-15-04-05"), method)
+	suggestedPath := filepath.Join(exportsDir, name)
+	path := backend.Environment().GetSaveFilename(suggestedPath)
+	if path == "" {
+		return nil, errp.ErrUserAbort
+	}
+	summaryPath := strings.TrimSuffix(path, ".csv") + "-summary.csv"
+	for _, file := range []struct {
+		path  string
+		write func(*os.File) error
+	}{
+		{path, func(f *os.File) error { return report.WriteCSV(f) }},
+		{summaryPath, func(f *os.File) error { return report.WriteSummaryCSV(f) }},
+	} {
+		f, err := os.Create(file.path)
+		if err != nil {
+			return nil, errp.WithStack(err)
+		}
+		if err := file.write(f); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		if err := f.Close(); err != nil {
+			return nil, errp.WithStack(err)
+		}
+	}
+	backend.log.Infof("Exported tax report to %s and %s", path, summaryPath)
+
+	if runtime.GOOS == "android" || runtime.GOOS == "ios" {
+		if err := backend.environment.SystemOpen(path); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
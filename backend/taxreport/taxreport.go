@@ -0,0 +1,327 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package taxreport computes the cost basis and realized gains of coin acquisitions and disposals,
+// for tax reporting.
+package taxreport
+
+import (
+	"encoding/csv"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+)
+
+// Method is the method used to match disposed coins with the acquisitions they came from. See the
+// Method* constants.
+type Method string
+
+const (
+	// MethodFIFO disposes the coins acquired first.
+	MethodFIFO Method = "fifo"
+	// MethodLIFO disposes the coins acquired last.
+	MethodLIFO Method = "lifo"
+	// MethodAverageCost uses the average cost of all coins held as the cost basis.
+	MethodAverageCost Method = "averageCost"
+)
+
+// NewMethod checks if the method is valid and returns a Method in that case.
+func NewMethod(method string) (Method, error) {
+	switch Method(method) {
+	case MethodFIFO, MethodLIFO, MethodAverageCost:
+		return Method(method), nil
+	default:
+		return "", errp.Newf("Unrecognized cost basis method %s", method)
+	}
+}
+
+// EventType is the type of an event. See the EventType* constants.
+type EventType string
+
+const (
+	// EventTypeAcquisition is a receipt of coins from outside, which adds to the holdings at the
+	// fiat value at that time.
+	EventTypeAcquisition EventType = "acquisition"
+	// EventTypeDisposal is a payment to outside, which realizes a gain or loss.
+	EventTypeDisposal EventType = "disposal"
+	// EventTypeFee is a transaction fee. It is treated as a disposal at the fiat value of the fee.
+	EventTypeFee EventType = "fee"
+	// EventTypeTransfer is a move of coins between our own accounts. It is not taxable and does not
+	// change the holdings.
+	EventTypeTransfer EventType = "transfer"
+)
+
+// Event is a change of the holdings of one asset.
+type Event struct {
+	Time time.Time
+	Type EventType
+	// Asset identifies the coin, e.g. the coin code. Holdings are tracked per asset across all
+	// accounts.
+	Asset string
+	// Unit is the unit of Amount, e.g. "BTC".
+	Unit string
+	// Decimals is the number of decimals of Amount shown in the report.
+	Decimals int
+	// Amount is the amount of coins in Unit. Always positive.
+	Amount *big.Rat
+	// FiatValue is the value of Amount in fiat at the time of the event. Nil if the exchange rate
+	// is not available.
+	FiatValue   *big.Rat
+	AccountCode string
+	TxID        string
+	Note        string
+}
+
+// Row is an event in the report, with the realized gain of disposals and fees.
+type Row struct {
+	*Event
+	// CostBasis is the fiat cost of the coins disposed. Nil for acquisitions and transfers.
+	CostBasis *big.Rat
+	// Gain is the realized gain (negative for a loss). Nil for acquisitions and transfers.
+	Gain *big.Rat
+}
+
+// YearSummary sums up the disposals and fees of one asset in one calendar year (UTC).
+type YearSummary struct {
+	Year  int
+	Asset string
+	Unit  string
+	// Proceeds is the fiat value of the disposals and fees.
+	Proceeds  *big.Rat
+	CostBasis *big.Rat
+	Gain      *big.Rat
+	// Fees is the fiat value of the fees. It is part of Proceeds.
+	Fees *big.Rat
+}
+
+// Report is the tax report of a list of events.
+type Report struct {
+	Fiat    string
+	Method  Method
+	Rows    []*Row
+	Summary []*YearSummary
+	// MissingRates is the number of events for which the exchange rate was not available. Their
+	// fiat value is counted as zero.
+	MissingRates int
+}
+
+// lot is an amount of coins acquired together.
+type lot struct {
+	amount *big.Rat
+	cost   *big.Rat
+}
+
+// holdings are the coins held of one asset.
+type holdings struct {
+	method Method
+	// lots are sorted by acquisition time. With the average cost method, there is at most one lot
+	// containing all coins.
+	lots []*lot
+}
+
+func (h *holdings) add(amount, cost *big.Rat) {
+	if h.method == MethodAverageCost && len(h.lots) == 1 {
+		h.lots[0].amount.Add(h.lots[0].amount, amount)
+		h.lots[0].cost.Add(h.lots[0].cost, cost)
+		return
+	}
+	h.lots = append(h.lots, &lot{
+		amount: new(big.Rat).Set(amount),
+		cost:   new(big.Rat).Set(cost),
+	})
+}
+
+// remove removes the amount from the holdings and returns the cost basis of the removed coins. If
+// the holdings are insufficient, e.g. because the acquisition is not part of the history, the
+// missing coins have a zero cost basis.
+func (h *holdings) remove(amount *big.Rat) *big.Rat {
+	costBasis := new(big.Rat)
+	remaining := new(big.Rat).Set(amount)
+	for remaining.Sign() > 0 && len(h.lots) > 0 {
+		index := 0
+		if h.method == MethodLIFO {
+			index = len(h.lots) - 1
+		}
+		current := h.lots[index]
+		if current.amount.Cmp(remaining) <= 0 {
+			costBasis.Add(costBasis, current.cost)
+			remaining.Sub(remaining, current.amount)
+			h.lots = append(h.lots[:index], h.lots[index+1:]...)
+			continue
+		}
+		// Partially consume the lot.
+		cost := new(big.Rat).Mul(current.cost, new(big.Rat).Quo(remaining, current.amount))
+		costBasis.Add(costBasis, cost)
+		current.cost.Sub(current.cost, cost)
+		current.amount.Sub(current.amount, remaining)
+		remaining.SetInt64(0)
+	}
+	return costBasis
+}
+
+// Compute computes the cost basis and realized gains of the events. Holdings are tracked per asset
+// across all accounts, so transfers between our own accounts do not affect them. Events with the
+// same time are processed in the given order.
+func Compute(events []*Event, method Method, fiat string) *Report {
+	sorted := append([]*Event{}, events...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	report := &Report{Fiat: fiat, Method: method, Rows: []*Row{}, Summary: []*YearSummary{}}
+	allHoldings := map[string]*holdings{}
+	type summaryKey struct {
+		year  int
+		asset string
+	}
+	summaries := map[summaryKey]*YearSummary{}
+	for _, event := range sorted {
+		row := &Row{Event: event}
+		report.Rows = append(report.Rows, row)
+		fiatValue := event.FiatValue
+		if fiatValue == nil {
+			fiatValue = new(big.Rat)
+			if event.Type != EventTypeTransfer {
+				report.MissingRates++
+			}
+		}
+		h, ok := allHoldings[event.Asset]
+		if !ok {
+			h = &holdings{method: method}
+			allHoldings[event.Asset] = h
+		}
+		switch event.Type {
+		case EventTypeAcquisition:
+			h.add(event.Amount, fiatValue)
+		case EventTypeDisposal, EventTypeFee:
+			row.CostBasis = h.remove(event.Amount)
+			row.Gain = new(big.Rat).Sub(fiatValue, row.CostBasis)
+
+			key := summaryKey{year: event.Time.UTC().Year(), asset: event.Asset}
+			summary, ok := summaries[key]
+			if !ok {
+				summary = &YearSummary{
+					Year:      key.year,
+					Asset:     key.asset,
+					Unit:      event.Unit,
+					Proceeds:  new(big.Rat),
+					CostBasis: new(big.Rat),
+					Gain:      new(big.Rat),
+					Fees:      new(big.Rat),
+				}
+				summaries[key] = summary
+				report.Summary = append(report.Summary, summary)
+			}
+			summary.Proceeds.Add(summary.Proceeds, fiatValue)
+			summary.CostBasis.Add(summary.CostBasis, row.CostBasis)
+			summary.Gain.Add(summary.Gain, row.Gain)
+			if event.Type == EventTypeFee {
+				summary.Fees.Add(summary.Fees, fiatValue)
+			}
+		}
+	}
+	sort.Slice(report.Summary, func(i, j int) bool {
+		if report.Summary[i].Year == report.Summary[j].Year {
+			return report.Summary[i].Asset < report.Summary[j].Asset
+		}
+		return report.Summary[i].Year < report.Summary[j].Year
+	})
+	return report
+}
+
+// formatFiat formats a fiat amount with two decimals. Nil results in the empty string.
+func formatFiat(amount *big.Rat) string {
+	if amount == nil {
+		return ""
+	}
+	return amount.FloatString(2)
+}
+
+// WriteCSV writes one row per event in CSV format (comma-separated).
+func (report *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{
+		"Time",
+		"Type",
+		"Account",
+		"Amount",
+		"Unit",
+		"Fiat Value",
+		"Cost Basis",
+		"Gain",
+		"Fiat",
+		"Transaction ID",
+		"Note",
+	})
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	for _, row := range report.Rows {
+		err := writer.Write([]string{
+			row.Time.Format(time.RFC3339),
+			string(row.Type),
+			row.AccountCode,
+			row.Amount.FloatString(row.Decimals),
+			row.Unit,
+			formatFiat(row.FiatValue),
+			formatFiat(row.CostBasis),
+			formatFiat(row.Gain),
+			report.Fiat,
+			row.TxID,
+			row.Note,
+		})
+		if err != nil {
+			return errp.WithStack(err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteSummaryCSV writes the per-year summary in CSV format (comma-separated).
+func (report *Report) WriteSummaryCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{
+		"Year",
+		"Unit",
+		"Proceeds",
+		"Cost Basis",
+		"Gain",
+		"Fees",
+		"Fiat",
+		"Method",
+	})
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	for _, summary := range report.Summary {
+		err := writer.Write([]string{
+			strconv.Itoa(summary.Year),
+			summary.Unit,
+			formatFiat(summary.Proceeds),
+			formatFiat(summary.CostBasis),
+			formatFiat(summary.Gain),
+			formatFiat(summary.Fees),
+			report.Fiat,
+			string(report.Method),
+		})
+		if err != nil {
+			return errp.WithStack(err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,155 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taxreport
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func event(day int, eventType EventType, amount, fiatValue int64) *Event {
+	var fiat *big.Rat
+	if fiatValue >= 0 {
+		fiat = big.NewRat(fiatValue, 1)
+	}
+	return &Event{
+		Time:        time.Date(2023, 12, 29, 0, 0, 0, 0, time.UTC).AddDate(0, 0, day),
+		Type:        eventType,
+		Asset:       "btc",
+		Unit:        "BTC",
+		Decimals:    8,
+		Amount:      big.NewRat(amount, 1),
+		FiatValue:   fiat,
+		AccountCode: "v0-55555555-btc-0",
+		TxID:        "txid",
+	}
+}
+
+// testEvents: buy 1 @ 100, buy 1 @ 300, transfer 1, sell 1 @ 250 (in 2023), fee 1 worth 400 (in
+// 2024).
+func testEvents() []*Event {
+	return []*Event{
+		// Out of order to check the sorting.
+		event(1, EventTypeAcquisition, 1, 300),
+		event(0, EventTypeAcquisition, 1, 100),
+		event(1, EventTypeTransfer, 1, 280),
+		event(2, EventTypeDisposal, 1, 250),
+		event(4, EventTypeFee, 1, 400),
+	}
+}
+
+func TestNewMethod(t *testing.T) {
+	for _, method := range []Method{MethodFIFO, MethodLIFO, MethodAverageCost} {
+		parsed, err := NewMethod(string(method))
+		require.NoError(t, err)
+		require.Equal(t, method, parsed)
+	}
+	_, err := NewMethod("hifo")
+	require.Error(t, err)
+}
+
+func TestCompute(t *testing.T) {
+	for _, c := range []struct {
+		method     Method
+		costBasis  [2]string
+		summary    [2]string
+		summaryFee string
+	}{
+		{MethodFIFO, [2]string{"100.00", "300.00"}, [2]string{"150.00", "100.00"}, "400.00"},
+		{MethodLIFO, [2]string{"300.00", "100.00"}, [2]string{"-50.00", "300.00"}, "400.00"},
+		{MethodAverageCost, [2]string{"200.00", "200.00"}, [2]string{"50.00", "200.00"}, "400.00"},
+	} {
+		t.Run(string(c.method), func(t *testing.T) {
+			report := Compute(testEvents(), c.method, "USD")
+			require.Len(t, report.Rows, 5)
+			require.Equal(t, 0, report.MissingRates)
+			require.Equal(t, EventTypeAcquisition, report.Rows[0].Type)
+			require.Equal(t, "100", report.Rows[0].FiatValue.RatString())
+			require.Nil(t, report.Rows[0].CostBasis)
+			// Transfers do not affect the holdings.
+			require.Nil(t, report.Rows[2].CostBasis)
+			require.Equal(t, c.costBasis[0], formatFiat(report.Rows[3].CostBasis))
+			require.Equal(t, c.costBasis[1], formatFiat(report.Rows[4].CostBasis))
+
+			require.Len(t, report.Summary, 2)
+			require.Equal(t, 2023, report.Summary[0].Year)
+			require.Equal(t, c.summary[0], formatFiat(report.Summary[0].Gain))
+			require.Equal(t, "0.00", formatFiat(report.Summary[0].Fees))
+			require.Equal(t, 2024, report.Summary[1].Year)
+			require.Equal(t, c.summary[1], formatFiat(report.Summary[1].Gain))
+			require.Equal(t, c.summaryFee, formatFiat(report.Summary[1].Fees))
+		})
+	}
+}
+
+func TestComputePartialLots(t *testing.T) {
+	events := []*Event{
+		event(0, EventTypeAcquisition, 2, 100),
+		event(1, EventTypeAcquisition, 2, 300),
+		event(2, EventTypeDisposal, 3, 600),
+		// More than held: the missing coin has no cost basis.
+		event(3, EventTypeDisposal, 2, 400),
+	}
+	report := Compute(events, MethodFIFO, "USD")
+	require.Equal(t, "250.00", formatFiat(report.Rows[2].CostBasis))
+	require.Equal(t, "350.00", formatFiat(report.Rows[2].Gain))
+	require.Equal(t, "150.00", formatFiat(report.Rows[3].CostBasis))
+
+	report = Compute(events, MethodLIFO, "USD")
+	require.Equal(t, "350.00", formatFiat(report.Rows[2].CostBasis))
+	require.Equal(t, "50.00", formatFiat(report.Rows[3].CostBasis))
+}
+
+func TestComputeMissingRates(t *testing.T) {
+	events := []*Event{
+		event(0, EventTypeAcquisition, 1, -1),
+		event(1, EventTypeTransfer, 1, -1),
+		event(2, EventTypeDisposal, 1, 250),
+	}
+	report := Compute(events, MethodFIFO, "USD")
+	require.Equal(t, 1, report.MissingRates)
+	require.Equal(t, "250.00", formatFiat(report.Rows[2].Gain))
+}
+
+func TestWriteCSV(t *testing.T) {
+	events := testEvents()
+	events[1].Note = "salary, december"
+	report := Compute(events, MethodFIFO, "USD")
+
+	var result bytes.Buffer
+	require.NoError(t, report.WriteCSV(&result))
+	require.Equal(t,
+		`Time,Type,Account,Amount,Unit,Fiat Value,Cost Basis,Gain,Fiat,Transaction ID,Note
+2023-12-29T00:00:00Z,acquisition,v0-55555555-btc-0,1.00000000,BTC,100.00,,,USD,txid,"salary, december"
+2023-12-30T00:00:00Z,acquisition,v0-55555555-btc-0,1.00000000,BTC,300.00,,,USD,txid,
+2023-12-30T00:00:00Z,transfer,v0-55555555-btc-0,1.00000000,BTC,280.00,,,USD,txid,
+2023-12-31T00:00:00Z,disposal,v0-55555555-btc-0,1.00000000,BTC,250.00,100.00,150.00,USD,txid,
+2024-01-02T00:00:00Z,fee,v0-55555555-btc-0,1.00000000,BTC,400.00,300.00,100.00,USD,txid,
+`,
+		result.String())
+
+	result.Reset()
+	require.NoError(t, report.WriteSummaryCSV(&result))
+	require.Equal(t,
+		`Year,Unit,Proceeds,Cost Basis,Gain,Fees,Fiat,Method
+2023,BTC,250.00,100.00,150.00,0.00,USD,fifo
+2024,BTC,400.00,300.00,100.00,400.00,USD,fifo
+`,
+		result.String())
+}
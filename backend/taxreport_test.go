@@ -0,0 +1,152 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/btc/types"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/taxreport"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxReport(t *testing.T) {
+	// Exchange rates of rates.MockRateUpdater(): 1, 2 and 3 USD/BTC.
+	t0 := time.Unix(1598832062, 0)
+	t1 := time.Unix(1598918700, 0)
+	t2 := time.Unix(1598922501, 0)
+	// No LTC rate available.
+	t3 := time.Unix(1599091262, 0)
+
+	tx := func(txID string, txType accounts.TxType, timestamp *time.Time, amount int64, fee int64) *accounts.TransactionData {
+		var feeAmount *coinpkg.Amount
+		if fee != 0 {
+			amount := coinpkg.NewAmountFromInt64(fee)
+			feeAmount = &amount
+		}
+		status := accounts.TxStatusComplete
+		if timestamp == nil {
+			status = accounts.TxStatusPending
+		}
+		return &accounts.TransactionData{
+			TxID:       txID,
+			InternalID: txID,
+			Type:       txType,
+			Status:     status,
+			Timestamp:  timestamp,
+			Amount:     coinpkg.NewAmountFromInt64(amount),
+			Fee:        feeAmount,
+		}
+	}
+	transactions := map[string]accounts.OrderedTransactions{
+		"v0-55555555-btc-0": {
+			tx("pending", accounts.TxTypeSend, nil, 10000000, 1000),
+			tx("self", accounts.TxTypeSendSelf, &t2, 20000000, 10000),
+			// 1 BTC to the second BTC account and 0.5 BTC to a third party.
+			tx("payment", accounts.TxTypeSend, &t1, 150000000, 100000),
+			tx("deposit", accounts.TxTypeReceive, &t0, 200000000, 0),
+		},
+		"v0-55555555-btc-1": {
+			tx("payment", accounts.TxTypeReceive, &t1, 100000000, 0),
+		},
+		"v0-55555555-ltc-0": {
+			tx("ltc-deposit", accounts.TxTypeReceive, &t3, 100000000, 0),
+		},
+	}
+
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	defer b.Close()
+	b.makeBtcAccount = func(config *accounts.AccountConfig, coin *btc.Coin, gapLimits *types.GapLimits, log *logrus.Entry) accounts.Interface {
+		accountMock := MockBtcAccount(t, config, coin, gapLimits, log)
+		accountMock.TransactionsFunc = func() (accounts.OrderedTransactions, error) {
+			return transactions[string(config.Config.Code)], nil
+		}
+		accountMock.TxNoteFunc = func(internalTxID string) string {
+			if internalTxID == "deposit" {
+				return "salary"
+			}
+			return ""
+		}
+		return accountMock
+	}
+	ks := makeBitBox02Multi()
+	b.registerKeystore(ks)
+	_, err := b.CreateAndPersistAccountConfig(coinpkg.CodeBTC, "Savings", ks)
+	require.NoError(t, err)
+	b.ReinitializeAccounts()
+	// This needs to be after all changes in accounts, otherwise it will try to fetch
+	// new values and fail.
+	b.ratesUpdater = rates.MockRateUpdater()
+	defer b.ratesUpdater.Stop()
+	rootFingerprint, err := ks.RootFingerprint()
+	require.NoError(t, err)
+
+	events, err := b.taxReportEvents(rootFingerprint, "USD")
+	require.NoError(t, err)
+	type simpleEvent struct {
+		accountCode string
+		txID        string
+		eventType   taxreport.EventType
+		amount      string
+		fiatValue   string
+		note        string
+	}
+	simpleEvents := []simpleEvent{}
+	for _, event := range events {
+		fiatValue := ""
+		if event.FiatValue != nil {
+			fiatValue = event.FiatValue.FloatString(4)
+		}
+		simpleEvents = append(simpleEvents, simpleEvent{
+			event.AccountCode, event.TxID, event.Type, event.Amount.FloatString(event.Decimals),
+			fiatValue, event.Note,
+		})
+	}
+	require.ElementsMatch(t,
+		[]simpleEvent{
+			{"v0-55555555-btc-0", "deposit", taxreport.EventTypeAcquisition, "2.00000000", "2.0000", "salary"},
+			{"v0-55555555-btc-0", "payment", taxreport.EventTypeTransfer, "1.00000000", "2.0000", ""},
+			{"v0-55555555-btc-0", "payment", taxreport.EventTypeDisposal, "0.50000000", "1.0000", ""},
+			{"v0-55555555-btc-0", "payment", taxreport.EventTypeFee, "0.00100000", "0.0020", ""},
+			{"v0-55555555-btc-0", "self", taxreport.EventTypeTransfer, "0.20000000", "0.6000", ""},
+			{"v0-55555555-btc-0", "self", taxreport.EventTypeFee, "0.00010000", "0.0003", ""},
+			{"v0-55555555-btc-1", "payment", taxreport.EventTypeTransfer, "1.00000000", "2.0000", ""},
+			{"v0-55555555-ltc-0", "ltc-deposit", taxreport.EventTypeAcquisition, "1.00000000", "", ""},
+		},
+		simpleEvents)
+
+	report, err := b.TaxReport(rootFingerprint, "USD", taxreport.MethodFIFO)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.MissingRates)
+	require.Len(t, report.Summary, 1)
+	summary := report.Summary[0]
+	require.Equal(t, 2020, summary.Year)
+	require.Equal(t, "BTC", summary.Unit)
+	require.Equal(t, "1.0023", summary.Proceeds.FloatString(4))
+	require.Equal(t, "0.5011", summary.CostBasis.FloatString(4))
+	require.Equal(t, "0.5012", summary.Gain.FloatString(4))
+	require.Equal(t, "0.0023", summary.Fees.FloatString(4))
+
+	// Accounts of other keystores are not included.
+	events, err = b.taxReportEvents([]byte{1, 2, 3, 4}, "USD")
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
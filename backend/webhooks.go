@@ -0,0 +1,182 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"math/big"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/devices/device"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/webhooks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+)
+
+// webhookAccount identifies the account an event is about.
+type webhookAccount struct {
+	AccountCode accountsTypes.Code `json:"accountCode"`
+	AccountName string             `json:"accountName"`
+	CoinCode    coin.Code          `json:"coinCode"`
+}
+
+func newWebhookAccount(account accounts.Interface) webhookAccount {
+	return webhookAccount{
+		AccountCode: account.Config().Config.Code,
+		AccountName: account.Config().Config.Name,
+		CoinCode:    account.Coin().Code(),
+	}
+}
+
+// webhookTransaction is the data of the transaction events.
+type webhookTransaction struct {
+	webhookAccount
+	TxID             string          `json:"txID"`
+	InternalID       string          `json:"internalID"`
+	Type             accounts.TxType `json:"type"`
+	Amount           string          `json:"amount"`
+	Unit             string          `json:"unit"`
+	NumConfirmations int             `json:"numConfirmations"`
+	Addresses        []string        `json:"addresses"`
+	Note             string          `json:"note"`
+}
+
+// webhookAccountStatus is the data of the account status events.
+type webhookAccountStatus struct {
+	webhookAccount
+	// Error is the reason the account is offline.
+	Error string `json:"error,omitempty"`
+}
+
+// accountStatus is the status of an account reported to webhooks.
+type accountStatus struct {
+	offline    bool
+	fatalError bool
+}
+
+// dispatchWebhook posts the event to the webhooks subscribed to it.
+func (backend *Backend) dispatchWebhook(eventType webhooks.EventType, data interface{}) {
+	if err := backend.webhooks.Dispatch(eventType, data); err != nil {
+		backend.log.WithError(err).WithField("event", eventType).Error("Could not queue webhook event")
+	}
+}
+
+// webhookTransactions posts the new incoming transactions of the account and the transactions
+// which became complete to the webhooks. The transactions seen when an account is checked for the
+// first time are only recorded, so that the existing history does not trigger events.
+func (backend *Backend) webhookTransactions(account accounts.Interface) {
+	if !backend.webhooks.Enabled() {
+		return
+	}
+	backend.webhookTransactionsMu.Lock()
+	defer backend.webhookTransactionsMu.Unlock()
+
+	transactions, err := account.Transactions()
+	if err != nil {
+		backend.log.WithError(err).Error("Could not get the transactions for the webhooks")
+		return
+	}
+	accountCode := string(account.Config().Config.Code)
+	seen := backend.webhooks.Transactions(accountCode)
+	current := map[string]bool{}
+	for _, txInfo := range transactions {
+		if txInfo.IsErc20 && big.NewInt(0).Cmp(txInfo.Amount.BigInt()) == 0 {
+			// Skip 0 amount ERC20 txs, which are used in address poisoning attacks.
+			continue
+		}
+		complete := txInfo.NumConfirmationsComplete > 0 &&
+			txInfo.NumConfirmations >= txInfo.NumConfirmationsComplete
+		current[txInfo.InternalID] = complete
+		if seen == nil {
+			continue
+		}
+		wasComplete, known := seen[txInfo.InternalID]
+		if known && (wasComplete || !complete) {
+			continue
+		}
+		addresses := []string{}
+		for _, addressAndAmount := range txInfo.Addresses {
+			addresses = append(addresses, addressAndAmount.Address)
+		}
+		data := webhookTransaction{
+			webhookAccount:   newWebhookAccount(account),
+			TxID:             txInfo.TxID,
+			InternalID:       txInfo.InternalID,
+			Type:             txInfo.Type,
+			Amount:           account.Coin().FormatAmount(txInfo.Amount, false),
+			Unit:             account.Coin().GetFormatUnit(false),
+			NumConfirmations: txInfo.NumConfirmations,
+			Addresses:        addresses,
+			Note:             account.TxNote(txInfo.InternalID),
+		}
+		if !known && txInfo.Type == accounts.TxTypeReceive {
+			backend.dispatchWebhook(webhooks.EventTransactionIncoming, data)
+		}
+		if complete {
+			backend.dispatchWebhook(webhooks.EventTransactionConfirmed, data)
+		}
+	}
+	if err := backend.webhooks.SetTransactions(accountCode, current); err != nil {
+		backend.log.WithError(err).Error("Could not store the transactions seen for the webhooks")
+	}
+}
+
+// webhookAccountStatus posts changes of whether the account is offline or failed to the webhooks.
+func (backend *Backend) webhookAccountStatus(account accounts.Interface) {
+	if !backend.webhooks.Enabled() {
+		return
+	}
+	offlineErr := account.Offline()
+	status := accountStatus{offline: offlineErr != nil, fatalError: account.FatalError()}
+	accountCode := account.Config().Config.Code
+
+	backend.webhookAccountStatusMu.Lock()
+	previous := backend.webhookAccountStatuses[accountCode]
+	backend.webhookAccountStatuses[accountCode] = status
+	backend.webhookAccountStatusMu.Unlock()
+
+	data := webhookAccountStatus{webhookAccount: newWebhookAccount(account)}
+	switch {
+	case status.offline && !previous.offline:
+		data.Error = offlineErr.Error()
+		backend.dispatchWebhook(webhooks.EventAccountOffline, data)
+	case !status.offline && previous.offline:
+		backend.dispatchWebhook(webhooks.EventAccountOnline, data)
+	}
+	if status.fatalError && !previous.fatalError {
+		backend.dispatchWebhook(webhooks.EventAccountFatalError, data)
+	}
+}
+
+// webhookDevice posts a device connect or disconnect to the webhooks.
+func (backend *Backend) webhookDevice(eventType webhooks.EventType, theDevice device.Interface) {
+	if !backend.webhooks.Enabled() {
+		return
+	}
+	backend.dispatchWebhook(eventType, map[string]string{
+		"deviceID":    theDevice.Identifier(),
+		"productName": theDevice.ProductName(),
+	})
+}
+
+// TestWebhooks posts a test event to all webhooks.
+func (backend *Backend) TestWebhooks() error {
+	if !backend.webhooks.Enabled() {
+		return errp.New("No webhooks configured")
+	}
+	return backend.webhooks.Dispatch(webhooks.EventTest, map[string]string{
+		"message": "This is a test event from the BitBoxApp.",
+	})
+}
@@ -0,0 +1,437 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhooks posts backend events to user-defined URLs, so that they can be received without
+// the app being open in front of the user. The requests are signed with HMAC-SHA256 and retried
+// with exponential backoff. Events which were not delivered yet are persisted and delivered after a
+// restart.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/random"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SignatureHeader is the request header containing the signature of the request body, which is
+	// "sha256=" followed by the hex encoded HMAC-SHA256 of the body keyed with the webhook secret.
+	SignatureHeader = "X-BitBox-Signature"
+	// EventHeader is the request header containing the event type.
+	EventHeader = "X-BitBox-Event"
+
+	// maxAttempts is the number of delivery attempts after which an event is dropped.
+	maxAttempts = 10
+	// initialBackoff is the delay before the first retry. It doubles with each further attempt, up
+	// to maxBackoff.
+	initialBackoff = 30 * time.Second
+	maxBackoff     = time.Hour
+	// maxQueueLength limits the number of events waiting to be delivered. When it is exceeded, the
+	// oldest events are dropped.
+	maxQueueLength = 1000
+	requestTimeout = 30 * time.Second
+)
+
+// EventType is the type of an event posted to webhooks.
+type EventType string
+
+const (
+	// EventTransactionIncoming is posted when a new incoming transaction appears in an account.
+	EventTransactionIncoming EventType = "transaction.incoming"
+	// EventTransactionConfirmed is posted when a transaction reaches the number of confirmations
+	// after which it is considered complete.
+	EventTransactionConfirmed EventType = "transaction.confirmed"
+	// EventAccountOffline is posted when the connection of an account to the blockchain network
+	// fails.
+	EventAccountOffline EventType = "account.offline"
+	// EventAccountOnline is posted when an account which was offline is connected again.
+	EventAccountOnline EventType = "account.online"
+	// EventAccountFatalError is posted when an account could not be loaded or synced.
+	EventAccountFatalError EventType = "account.fatalError"
+	// EventDeviceConnected is posted when a device is plugged in.
+	EventDeviceConnected EventType = "device.connected"
+	// EventDeviceDisconnected is posted when a device is unplugged.
+	EventDeviceDisconnected EventType = "device.disconnected"
+	// EventTest is posted to all webhooks on request, to test the configuration.
+	EventTest EventType = "test"
+)
+
+// Event is posted as the JSON body of a webhook request.
+type Event struct {
+	// ID identifies the event, so that receivers can discard events delivered more than once.
+	ID   string          `json:"id"`
+	Type EventType       `json:"type"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// delivery is an event waiting to be posted to a webhook.
+type delivery struct {
+	// URL identifies the webhook. If the webhook is removed from the config, the delivery is
+	// dropped.
+	URL         string    `json:"url"`
+	Event       *Event    `json:"event"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"nextAttempt"`
+}
+
+// state is the persisted state of the dispatcher.
+type state struct {
+	Queue []*delivery `json:"queue"`
+	// Transactions maps account codes to the transactions of the account which were seen, and
+	// whether they were complete (confirmed). Nil for accounts whose transactions were not seen
+	// yet.
+	Transactions map[string]map[string]bool `json:"transactions"`
+}
+
+func read(filename string) (*state, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &state{Queue: []*delivery{}, Transactions: map[string]map[string]bool{}}, nil
+		}
+		return nil, errp.WithStack(err)
+	}
+	defer file.Close() //nolint:errcheck
+	var data state
+	if err := json.NewDecoder(file).Decode(&data); err != nil {
+		return nil, errp.WithStack(err)
+	}
+	if data.Queue == nil {
+		data.Queue = []*delivery{}
+	}
+	if data.Transactions == nil {
+		data.Transactions = map[string]map[string]bool{}
+	}
+	return &data, nil
+}
+
+func write(data *state, filename string) error {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	defer func() { _ = file.Close() }()
+	if err := json.NewEncoder(file).Encode(data); err != nil {
+		return errp.WithStack(err)
+	}
+	return nil
+}
+
+// Sign returns the value of the SignatureHeader for the request body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff returns the delay before the next attempt after the given number of failed attempts.
+func backoff(attempts int) time.Duration {
+	delay := initialBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
+
+// validateURL checks that the URL of a webhook uses https, so that the events can't be read or
+// changed on the way. Plain http is only allowed to localhost.
+func validateURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	host := parsed.Hostname()
+	ip := net.ParseIP(host)
+	isLocalhost := host == "localhost" || (ip != nil && ip.IsLoopback())
+	if (parsed.Scheme == "https" && host != "") || (parsed.Scheme == "http" && isLocalhost) {
+		return nil
+	}
+	return errp.Newf("The webhook URL %s must use https, except for localhost", rawURL)
+}
+
+// subscribed returns whether the event type is posted to the webhook.
+func subscribed(webhook config.Webhook, eventType EventType) bool {
+	if len(webhook.Events) == 0 || eventType == EventTest {
+		return true
+	}
+	for _, event := range webhook.Events {
+		if EventType(event) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Dispatcher queues events and posts them to the configured webhooks.
+type Dispatcher struct {
+	filename string
+	// webhooks returns the configured webhooks.
+	webhooks   func() []config.Webhook
+	httpClient *http.Client
+
+	dataMu sync.Mutex
+	data   *state
+	// wake is signaled when an event is queued, so that it is delivered right away.
+	wake chan struct{}
+
+	log *logrus.Entry
+}
+
+// NewDispatcher makes a new Dispatcher, loading the persisted state from the given file. If the
+// file does not exist, no error is returned.
+func NewDispatcher(
+	filename string, webhooks func() []config.Webhook, httpClient *http.Client,
+) (*Dispatcher, error) {
+	data, err := read(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &Dispatcher{
+		filename:   filename,
+		webhooks:   webhooks,
+		httpClient: httpClient,
+		data:       data,
+		wake:       make(chan struct{}, 1),
+		log:        logging.Get().WithGroup("webhooks"),
+	}, nil
+}
+
+// Enabled returns whether any webhooks are configured.
+func (dispatcher *Dispatcher) Enabled() bool {
+	return len(dispatcher.webhooks()) != 0
+}
+
+// lookup returns the configured webhook with the given URL, or nil.
+func (dispatcher *Dispatcher) lookup(url string) *config.Webhook {
+	for _, webhook := range dispatcher.webhooks() {
+		if webhook.URL == url {
+			return &webhook
+		}
+	}
+	return nil
+}
+
+// Dispatch queues the event for all webhooks subscribed to its type. The data is encoded as JSON.
+// Webhooks without a secret are skipped, as their requests could not be signed, as are webhooks
+// whose URL does not use https, and an error is returned for them.
+func (dispatcher *Dispatcher) Dispatch(eventType EventType, eventData interface{}) error {
+	jsonData, err := json.Marshal(eventData)
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	event := &Event{
+		ID:   hex.EncodeToString(random.BytesOrPanic(16)),
+		Type: eventType,
+		Time: time.Now(),
+		Data: jsonData,
+	}
+	queued := false
+	var skippedErr error
+	dispatcher.dataMu.Lock()
+	defer dispatcher.dataMu.Unlock()
+	for _, webhook := range dispatcher.webhooks() {
+		if !subscribed(webhook, eventType) {
+			continue
+		}
+		if webhook.Secret == "" {
+			skippedErr = errp.Newf("The webhook %s has no secret, so its events are not posted", webhook.URL)
+			continue
+		}
+		if err := validateURL(webhook.URL); err != nil {
+			skippedErr = err
+			continue
+		}
+		dispatcher.data.Queue = append(dispatcher.data.Queue, &delivery{
+			URL:         webhook.URL,
+			Event:       event,
+			NextAttempt: event.Time,
+		})
+		queued = true
+	}
+	if !queued {
+		return skippedErr
+	}
+	if excess := len(dispatcher.data.Queue) - maxQueueLength; excess > 0 {
+		dispatcher.log.Warningf("Dropping %d undelivered events", excess)
+		dispatcher.data.Queue = dispatcher.data.Queue[excess:]
+	}
+	select {
+	case dispatcher.wake <- struct{}{}:
+	default:
+	}
+	if err := write(dispatcher.data, dispatcher.filename); err != nil {
+		return err
+	}
+	return skippedErr
+}
+
+// Transactions returns the transactions of the account which were seen, and whether they were
+// complete, or nil if the transactions of the account were not seen yet.
+func (dispatcher *Dispatcher) Transactions(accountCode string) map[string]bool {
+	dispatcher.dataMu.Lock()
+	defer dispatcher.dataMu.Unlock()
+	transactions, ok := dispatcher.data.Transactions[accountCode]
+	if !ok {
+		return nil
+	}
+	result := make(map[string]bool, len(transactions))
+	for txID, complete := range transactions {
+		result[txID] = complete
+	}
+	return result
+}
+
+// SetTransactions stores the transactions of the account which were seen, and whether they were
+// complete.
+func (dispatcher *Dispatcher) SetTransactions(accountCode string, transactions map[string]bool) error {
+	dispatcher.dataMu.Lock()
+	defer dispatcher.dataMu.Unlock()
+	dispatcher.data.Transactions[accountCode] = transactions
+	return write(dispatcher.data, dispatcher.filename)
+}
+
+// post posts the event to the webhook.
+func (dispatcher *Dispatcher) post(ctx context.Context, webhook *config.Webhook, event *Event) error {
+	// The secret or URL could have been changed after the event was queued.
+	if webhook.Secret == "" {
+		return errp.New("The webhook has no secret")
+	}
+	if err := validateURL(webhook.URL); err != nil {
+		return err
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, string(event.Type))
+	request.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+	response, err := dispatcher.httpClient.Do(request)
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	_ = response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errp.Newf("unexpected status code %d", response.StatusCode)
+	}
+	return nil
+}
+
+// remove removes the delivery from the queue. The dataMu lock must be held.
+func (dispatcher *Dispatcher) remove(theDelivery *delivery) {
+	for index, queued := range dispatcher.data.Queue {
+		if queued == theDelivery {
+			dispatcher.data.Queue = append(dispatcher.data.Queue[:index], dispatcher.data.Queue[index+1:]...)
+			return
+		}
+	}
+}
+
+// deliverDue attempts the deliveries which are due, and returns when the next delivery is due, or
+// the zero time if the queue is empty.
+func (dispatcher *Dispatcher) deliverDue(ctx context.Context, now time.Time) time.Time {
+	dispatcher.dataMu.Lock()
+	due := []*delivery{}
+	for _, queued := range dispatcher.data.Queue {
+		if !queued.NextAttempt.After(now) {
+			due = append(due, queued)
+		}
+	}
+	dispatcher.dataMu.Unlock()
+
+	for _, theDelivery := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		log := dispatcher.log.WithFields(logrus.Fields{"url": theDelivery.URL, "event": theDelivery.Event.Type})
+		webhook := dispatcher.lookup(theDelivery.URL)
+		var err error
+		if webhook != nil {
+			err = dispatcher.post(ctx, webhook, theDelivery.Event)
+		}
+		dispatcher.dataMu.Lock()
+		switch {
+		case webhook == nil:
+			log.Info("Dropping event of removed webhook")
+			dispatcher.remove(theDelivery)
+		case err == nil:
+			dispatcher.remove(theDelivery)
+		default:
+			theDelivery.Attempts++
+			if theDelivery.Attempts >= maxAttempts {
+				log.WithError(err).Error("Dropping event after too many failed attempts")
+				dispatcher.remove(theDelivery)
+			} else {
+				log.WithError(err).Warning("Posting event failed")
+				theDelivery.NextAttempt = now.Add(backoff(theDelivery.Attempts))
+			}
+		}
+		if err := write(dispatcher.data, dispatcher.filename); err != nil {
+			log.WithError(err).Error("Could not persist the webhook events")
+		}
+		dispatcher.dataMu.Unlock()
+	}
+
+	dispatcher.dataMu.Lock()
+	defer dispatcher.dataMu.Unlock()
+	var next time.Time
+	for _, queued := range dispatcher.data.Queue {
+		if next.IsZero() || queued.NextAttempt.Before(next) {
+			next = queued.NextAttempt
+		}
+	}
+	return next
+}
+
+// Run delivers the queued events until the context is done.
+func (dispatcher *Dispatcher) Run(ctx context.Context) {
+	for {
+		next := dispatcher.deliverDue(ctx, time.Now())
+		var timer <-chan time.Time
+		if !next.IsZero() {
+			timer = time.After(time.Until(next))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-dispatcher.wake:
+		case <-timer:
+		}
+	}
+}
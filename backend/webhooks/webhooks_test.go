@@ -0,0 +1,226 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/test"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a webhook endpoint recording the events it receives.
+type receiver struct {
+	mu     sync.Mutex
+	fail   bool
+	events []*Event
+	// signatures are the signature headers of the events, signed with the secret "secret".
+	signatures []string
+	server     *httptest.Server
+}
+
+func newReceiver(t *testing.T) *receiver {
+	t.Helper()
+	r := &receiver{}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		var event Event
+		require.NoError(t, json.Unmarshal(body, &event))
+		require.Equal(t, string(event.Type), request.Header.Get(EventHeader))
+		require.Equal(t, "application/json", request.Header.Get("Content-Type"))
+		signature := request.Header.Get(SignatureHeader)
+		require.Equal(t, Sign("secret", body), signature)
+		r.events = append(r.events, &event)
+		r.signatures = append(r.signatures, signature)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *receiver) setFail(fail bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fail = fail
+}
+
+func (r *receiver) received() ([]*Event, []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Event{}, r.events...), append([]string{}, r.signatures...)
+}
+
+func TestSign(t *testing.T) {
+	// Test vector of RFC 4231, test case 2.
+	require.Equal(t,
+		"sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		Sign("Jefe", []byte("what do ya want for nothing?")))
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, 30*time.Second, backoff(1))
+	require.Equal(t, time.Minute, backoff(2))
+	require.Equal(t, 4*time.Minute, backoff(4))
+	require.Equal(t, time.Hour, backoff(8))
+	require.Equal(t, time.Hour, backoff(maxAttempts))
+}
+
+func TestValidateURL(t *testing.T) {
+	require.NoError(t, validateURL("https://example.com/hook?token=1"))
+	require.NoError(t, validateURL("http://localhost:8080/hook"))
+	require.NoError(t, validateURL("http://127.0.0.1:8080/hook"))
+	require.NoError(t, validateURL("http://[::1]/hook"))
+	require.Error(t, validateURL("http://example.com/hook"))
+	require.Error(t, validateURL("http://192.168.1.10/hook"))
+	require.Error(t, validateURL("http://localhost.example.com/hook"))
+	require.Error(t, validateURL("ftp://localhost/hook"))
+	require.Error(t, validateURL("https:///hook"))
+	require.Error(t, validateURL("example.com"))
+}
+
+func TestSubscribed(t *testing.T) {
+	webhook := config.Webhook{URL: "https://example.com"}
+	require.True(t, subscribed(webhook, EventDeviceConnected))
+	webhook.Events = []string{string(EventTransactionIncoming)}
+	require.True(t, subscribed(webhook, EventTransactionIncoming))
+	require.False(t, subscribed(webhook, EventDeviceConnected))
+	require.True(t, subscribed(webhook, EventTest))
+}
+
+func TestDispatcher(t *testing.T) {
+	all := newReceiver(t)
+	transactionsOnly := newReceiver(t)
+	webhooks := []config.Webhook{
+		{URL: all.server.URL, Secret: "secret"},
+		{URL: transactionsOnly.server.URL, Secret: "secret", Events: []string{string(EventTransactionIncoming)}},
+	}
+	filename := test.TstTempFile("webhooks")
+	dispatcher, err := NewDispatcher(filename, func() []config.Webhook { return webhooks }, http.DefaultClient)
+	require.NoError(t, err)
+	require.True(t, dispatcher.Enabled())
+
+	ctx := context.Background()
+	// Events dispatched during the test are due at this time.
+	now := time.Now().Add(time.Second)
+	require.Zero(t, dispatcher.deliverDue(ctx, now))
+
+	require.NoError(t, dispatcher.Dispatch(EventTransactionIncoming, map[string]string{"txID": "abc"}))
+	require.NoError(t, dispatcher.Dispatch(EventDeviceConnected, map[string]string{"deviceID": "1"}))
+	require.Zero(t, dispatcher.deliverDue(ctx, now))
+
+	events, signatures := all.received()
+	require.Len(t, events, 2)
+	require.Equal(t, EventTransactionIncoming, events[0].Type)
+	require.JSONEq(t, `{"txID": "abc"}`, string(events[0].Data))
+	require.Equal(t, EventDeviceConnected, events[1].Type)
+	require.NotEmpty(t, signatures[0])
+	events, signatures = transactionsOnly.received()
+	require.Len(t, events, 1)
+	require.Equal(t, EventTransactionIncoming, events[0].Type)
+	require.NotEmpty(t, signatures[0])
+
+	// Failed deliveries are retried with backoff, also after a restart.
+	transactionsOnly.setFail(true)
+	require.NoError(t, dispatcher.Dispatch(EventTransactionIncoming, map[string]string{"txID": "def"}))
+	next := dispatcher.deliverDue(ctx, now)
+	require.Equal(t, now.Add(initialBackoff), next)
+	require.Len(t, func() []*Event { events, _ := all.received(); return events }(), 3)
+
+	dispatcher, err = NewDispatcher(filename, func() []config.Webhook { return webhooks }, http.DefaultClient)
+	require.NoError(t, err)
+	require.True(t, next.Equal(dispatcher.deliverDue(ctx, now.Add(time.Second))))
+	next = dispatcher.deliverDue(ctx, next)
+	require.True(t, now.Add(initialBackoff).Add(backoff(2)).Equal(next))
+
+	transactionsOnly.setFail(false)
+	require.Zero(t, dispatcher.deliverDue(ctx, next))
+	events, _ = transactionsOnly.received()
+	require.Len(t, events, 2)
+	require.JSONEq(t, `{"txID": "def"}`, string(events[1].Data))
+
+	// Events are dropped after too many attempts.
+	transactionsOnly.setFail(true)
+	require.NoError(t, dispatcher.Dispatch(EventTransactionIncoming, nil))
+	next = now
+	for i := 0; i < maxAttempts; i++ {
+		next = dispatcher.deliverDue(ctx, next)
+	}
+	require.Zero(t, next)
+
+	// Events of removed webhooks are dropped.
+	require.NoError(t, dispatcher.Dispatch(EventTransactionIncoming, nil))
+	webhooks = webhooks[:1]
+	require.Zero(t, dispatcher.deliverDue(ctx, time.Now().Add(time.Hour)))
+
+	// Events are not posted unsigned to webhooks without a secret.
+	unsigned := newReceiver(t)
+	webhooks = []config.Webhook{{URL: unsigned.server.URL}}
+	require.Error(t, dispatcher.Dispatch(EventTest, nil))
+	require.Zero(t, dispatcher.deliverDue(ctx, time.Now().Add(time.Hour)))
+	events, _ = unsigned.received()
+	require.Empty(t, events)
+
+	// Events are not posted to URLs without https, except for localhost.
+	webhooks = []config.Webhook{{URL: "http://example.com/hook", Secret: "secret"}}
+	require.Error(t, dispatcher.Dispatch(EventTest, nil))
+	require.Zero(t, dispatcher.deliverDue(ctx, time.Now().Add(time.Hour)))
+
+	webhooks = nil
+	require.False(t, dispatcher.Enabled())
+}
+
+func TestDispatcherRun(t *testing.T) {
+	r := newReceiver(t)
+	dispatcher, err := NewDispatcher(test.TstTempFile("webhooks"),
+		func() []config.Webhook { return []config.Webhook{{URL: r.server.URL, Secret: "secret"}} }, http.DefaultClient)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dispatcher.Run(ctx)
+
+	require.NoError(t, dispatcher.Dispatch(EventTest, nil))
+	require.Eventually(t, func() bool {
+		events, _ := r.received()
+		return len(events) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTransactions(t *testing.T) {
+	filename := test.TstTempFile("webhooks")
+	dispatcher, err := NewDispatcher(filename, func() []config.Webhook { return nil }, http.DefaultClient)
+	require.NoError(t, err)
+	require.Nil(t, dispatcher.Transactions("account"))
+	require.NoError(t, dispatcher.SetTransactions("account", map[string]bool{"a": true, "b": false}))
+
+	dispatcher, err = NewDispatcher(filename, func() []config.Webhook { return nil }, http.DefaultClient)
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"a": true, "b": false}, dispatcher.Transactions("account"))
+	require.NoError(t, dispatcher.SetTransactions("other", map[string]bool{}))
+	require.Equal(t, map[string]bool{}, dispatcher.Transactions("other"))
+}
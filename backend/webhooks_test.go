@@ -0,0 +1,197 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	accountsMocks "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/mocks"
+	coinpkg "github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/webhooks"
+	"github.com/stretchr/testify/require"
+)
+
+// newWebhooksBackend returns a backend with a webhook subscribed to all events, and a mocked
+// account whose transactions and status can be changed by the test.
+func newWebhooksBackend(t *testing.T) (
+	*Backend, *accountsMocks.InterfaceMock, *[]*accounts.TransactionData, *error) {
+	t.Helper()
+	b := newBackend(t, testnetDisabled, regtestDisabled)
+	t.Cleanup(func() { require.NoError(t, b.Close()) })
+	require.NoError(t, b.config.ModifyAppConfig(func(appConfig *config.AppConfig) error {
+		appConfig.Backend.Webhooks = []config.Webhook{
+			{URL: "http://localhost:8080/hook", Secret: "secret"},
+		}
+		return nil
+	}))
+
+	coin, err := b.Coin(coinpkg.CodeBTC)
+	require.NoError(t, err)
+	transactions := []*accounts.TransactionData{}
+	var offlineErr error
+	account := &accountsMocks.InterfaceMock{
+		ConfigFunc: func() *accounts.AccountConfig {
+			return &accounts.AccountConfig{
+				Config: &config.Account{Code: "v0-55555555-btc-0", Name: "Bitcoin"},
+			}
+		},
+		CoinFunc: func() coinpkg.Coin { return coin },
+		TransactionsFunc: func() (accounts.OrderedTransactions, error) {
+			return accounts.NewOrderedTransactions(transactions), nil
+		},
+		TxNoteFunc:     func(string) string { return "" },
+		OfflineFunc:    func() error { return offlineErr },
+		FatalErrorFunc: func() bool { return false },
+	}
+	return b, account, &transactions, &offlineErr
+}
+
+// queuedWebhookEvents returns the types and data of the events queued for delivery.
+func queuedWebhookEvents(t *testing.T, b *Backend) ([]webhooks.EventType, []map[string]interface{}) {
+	t.Helper()
+	jsonBytes, err := os.ReadFile(filepath.Join(b.arguments.MainDirectoryPath(), "webhooks.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	require.NoError(t, err)
+	var state struct {
+		Queue []struct {
+			Event webhooks.Event `json:"event"`
+		} `json:"queue"`
+	}
+	require.NoError(t, json.Unmarshal(jsonBytes, &state))
+	types := []webhooks.EventType{}
+	data := []map[string]interface{}{}
+	for _, delivery := range state.Queue {
+		types = append(types, delivery.Event.Type)
+		var eventData map[string]interface{}
+		require.NoError(t, json.Unmarshal(delivery.Event.Data, &eventData))
+		data = append(data, eventData)
+	}
+	return types, data
+}
+
+func TestWebhookTransactions(t *testing.T) {
+	b, account, transactions, _ := newWebhooksBackend(t)
+
+	existingTx := &accounts.TransactionData{
+		TxID:                     "existing",
+		InternalID:               "existing",
+		Type:                     accounts.TxTypeReceive,
+		Amount:                   coinpkg.NewAmountFromInt64(1000),
+		NumConfirmations:         1,
+		NumConfirmationsComplete: 6,
+	}
+	*transactions = []*accounts.TransactionData{existingTx}
+
+	// The transactions seen on the first sync are only recorded.
+	b.webhookTransactions(account)
+	eventTypes, _ := queuedWebhookEvents(t, b)
+	require.Empty(t, eventTypes)
+	require.Equal(t,
+		map[string]bool{"existing": false},
+		b.webhooks.Transactions("v0-55555555-btc-0"))
+
+	// A new incoming transaction.
+	newTx := &accounts.TransactionData{
+		TxID:                     "new",
+		InternalID:               "new",
+		Type:                     accounts.TxTypeReceive,
+		Amount:                   coinpkg.NewAmountFromInt64(150000000),
+		NumConfirmations:         0,
+		NumConfirmationsComplete: 6,
+		Addresses: []accounts.AddressAndAmount{
+			{Address: "bc1qaddress", Amount: coinpkg.NewAmountFromInt64(150000000)},
+		},
+	}
+	*transactions = []*accounts.TransactionData{existingTx, newTx}
+	b.webhookTransactions(account)
+	eventTypes, eventData := queuedWebhookEvents(t, b)
+	require.Equal(t, []webhooks.EventType{webhooks.EventTransactionIncoming}, eventTypes)
+	require.Equal(t, "new", eventData[0]["txID"])
+	require.Equal(t, "v0-55555555-btc-0", eventData[0]["accountCode"])
+	require.Equal(t, "1.50000000", eventData[0]["amount"])
+	require.Equal(t, "BTC", eventData[0]["unit"])
+	require.Equal(t, []interface{}{"bc1qaddress"}, eventData[0]["addresses"])
+
+	// Nothing changed, no new events.
+	b.webhookTransactions(account)
+	eventTypes, _ = queuedWebhookEvents(t, b)
+	require.Len(t, eventTypes, 1)
+
+	// Both transactions reach the number of confirmations after which they are complete.
+	existingTx.NumConfirmations = 6
+	newTx.NumConfirmations = 7
+	b.webhookTransactions(account)
+	eventTypes, eventData = queuedWebhookEvents(t, b)
+	require.Equal(t, []webhooks.EventType{
+		webhooks.EventTransactionIncoming,
+		webhooks.EventTransactionConfirmed,
+		webhooks.EventTransactionConfirmed,
+	}, eventTypes)
+	require.ElementsMatch(t,
+		[]interface{}{"existing", "new"},
+		[]interface{}{eventData[1]["txID"], eventData[2]["txID"]})
+
+	// Complete transactions are only posted once.
+	existingTx.NumConfirmations = 8
+	b.webhookTransactions(account)
+	eventTypes, _ = queuedWebhookEvents(t, b)
+	require.Len(t, eventTypes, 3)
+}
+
+func TestWebhookTransactionsDisabled(t *testing.T) {
+	b, account, _, _ := newWebhooksBackend(t)
+	require.NoError(t, b.config.ModifyAppConfig(func(appConfig *config.AppConfig) error {
+		appConfig.Backend.Webhooks = nil
+		return nil
+	}))
+	b.webhookTransactions(account)
+	require.Nil(t, b.webhooks.Transactions("v0-55555555-btc-0"))
+	require.Empty(t, account.TransactionsCalls())
+}
+
+func TestWebhookAccountStatus(t *testing.T) {
+	b, account, _, offlineErr := newWebhooksBackend(t)
+
+	// An account which is online is not reported.
+	b.webhookAccountStatus(account)
+	eventTypes, _ := queuedWebhookEvents(t, b)
+	require.Empty(t, eventTypes)
+
+	*offlineErr = errors.New("connection failed")
+	b.webhookAccountStatus(account)
+	// Still offline, not reported again.
+	b.webhookAccountStatus(account)
+	eventTypes, eventData := queuedWebhookEvents(t, b)
+	require.Equal(t, []webhooks.EventType{webhooks.EventAccountOffline}, eventTypes)
+	require.Equal(t, "connection failed", eventData[0]["error"])
+	require.Equal(t, "v0-55555555-btc-0", eventData[0]["accountCode"])
+
+	*offlineErr = nil
+	b.webhookAccountStatus(account)
+	eventTypes, eventData = queuedWebhookEvents(t, b)
+	require.Equal(t, []webhooks.EventType{
+		webhooks.EventAccountOffline,
+		webhooks.EventAccountOnline,
+	}, eventTypes)
+	require.NotContains(t, eventData[1], "error")
+}
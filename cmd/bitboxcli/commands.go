@@ -0,0 +1,819 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	accountsTypes "github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/types"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/keystore"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/signing"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+)
+
+// command is a subcommand of bitboxcli.
+type command struct {
+	name        string
+	description string
+	// setup defines the flags of the command and returns the function running it, which is called
+	// after the flags are parsed.
+	setup func(flags *flag.FlagSet) func(cli *cli) error
+}
+
+var commands = []*command{
+	{"keystores", "list the keystores and whether they are connected", keystoresCommand},
+	{"accounts", "list the accounts", accountsCommand},
+	{"balance", "show the balance of all accounts or of one account", balanceCommand},
+	{"transactions", "list the transactions of an account", transactionsCommand},
+	{"fee-targets", "list the fee targets of an account", feeTargetsCommand},
+	{"receive", "show the unused receive addresses of an account, optionally verifying one on the BitBox", receiveCommand},
+	{"propose", "create a transaction proposal without sending it", proposeCommand},
+	{"send", "create a transaction, sign it on the BitBox and broadcast it", sendCommand},
+	{"export-notes", "export the notes and labels of all accounts (BIP-329)", exportNotesCommand},
+	{"export-csv", "export the transactions of an account as CSV", exportCSVCommand},
+	{"api-tokens", "list the scoped API tokens", apiTokensCommand},
+	{"create-api-token", "create a scoped API token, e.g. for read-only access by an accounting tool", createAPITokenCommand},
+	{"revoke-api-token", "revoke a scoped API token", revokeAPITokenCommand},
+}
+
+func lookupCommand(name string) *command {
+	for _, command := range commands {
+		if command.name == name {
+			return command
+		}
+	}
+	return nil
+}
+
+// printJSON writes the value as indented JSON to stdout.
+func (cli *cli) printJSON(value interface{}) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return errp.WithStack(err)
+	}
+	_, err := os.Stdout.Write(buf.Bytes())
+	return errp.WithStack(err)
+}
+
+// print writes the result to stdout, as JSON in case of JSON output, and as a table with the given
+// header and rows otherwise. The header can be nil, e.g. for rows of name/value pairs.
+func (cli *cli) print(result interface{}, header []string, rows [][]string) error {
+	if cli.jsonOutput {
+		return cli.printJSON(result)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if header != nil {
+		fmt.Fprintln(writer, strings.Join(header, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return errp.WithStack(writer.Flush())
+}
+
+// amountJSON is an amount formatted in the unit of the coin.
+type amountJSON struct {
+	Amount string `json:"amount"`
+	Unit   string `json:"unit"`
+}
+
+func formatAmount(theCoin coin.Coin, amount coin.Amount, isFee bool) amountJSON {
+	return amountJSON{
+		Amount: theCoin.FormatAmount(amount, isFee),
+		Unit:   theCoin.GetFormatUnit(isFee),
+	}
+}
+
+// String implements fmt.Stringer.
+func (amount amountJSON) String() string {
+	return amount.Amount + " " + amount.Unit
+}
+
+// rootFingerprint returns the hex encoded root fingerprint of the account's keystore.
+func rootFingerprint(account accounts.Interface) string {
+	fingerprint, err := account.Config().Config.SigningConfigurations.RootFingerprint()
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(fingerprint)
+}
+
+// connectedRootFingerprint returns the hex encoded root fingerprint of the connected keystore, or
+// "" if no keystore is connected.
+func (cli *cli) connectedRootFingerprint() string {
+	connected := cli.backend.Keystore()
+	if connected == nil {
+		return ""
+	}
+	fingerprint, err := connected.RootFingerprint()
+	if err != nil {
+		cli.log.WithError(err).Error("Could not retrieve the root fingerprint")
+		return ""
+	}
+	return hex.EncodeToString(fingerprint)
+}
+
+// activeAccounts returns the accounts which are shown in the BitBoxApp sidebar.
+func (cli *cli) activeAccounts() []accounts.Interface {
+	result := []accounts.Interface{}
+	for _, account := range cli.backend.Accounts() {
+		persistedAccount := account.Config().Config
+		if persistedAccount.Inactive || persistedAccount.HiddenBecauseUnused {
+			continue
+		}
+		result = append(result, account)
+	}
+	return result
+}
+
+// account returns the initialized and synced account with the given code.
+func (cli *cli) account(code string) (accounts.Interface, error) {
+	if code == "" {
+		return nil, errp.New("Missing -account. Use the accounts command to list the account codes")
+	}
+	if err := cli.waitForKeystore(false); err != nil {
+		return nil, err
+	}
+	account, err := cli.backend.GetAccountFromCode(accountsTypes.Code(code))
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.waitSynced(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func accountFlag(flags *flag.FlagSet) *string {
+	return flags.String("account", "", "the code of the account, as listed by the accounts command")
+}
+
+func keystoresCommand(flags *flag.FlagSet) func(cli *cli) error {
+	return func(cli *cli) error {
+		if err := cli.waitForKeystore(false); err != nil {
+			return err
+		}
+		type keystoreJSON struct {
+			Name            string    `json:"name"`
+			RootFingerprint string    `json:"rootFingerprint"`
+			Connected       bool      `json:"connected"`
+			Watchonly       bool      `json:"watchonly"`
+			LastConnected   time.Time `json:"lastConnected"`
+		}
+		connected := cli.connectedRootFingerprint()
+		result := []keystoreJSON{}
+		rows := [][]string{}
+		for _, keystore := range cli.backend.Config().AccountsConfig().Keystores {
+			fingerprint := hex.EncodeToString(keystore.RootFingerprint)
+			result = append(result, keystoreJSON{
+				Name:            keystore.Name,
+				RootFingerprint: fingerprint,
+				Connected:       fingerprint == connected,
+				Watchonly:       keystore.Watchonly,
+				LastConnected:   keystore.LastConnected,
+			})
+			rows = append(rows, []string{
+				fingerprint,
+				keystore.Name,
+				fmt.Sprint(fingerprint == connected),
+				fmt.Sprint(keystore.Watchonly),
+				keystore.LastConnected.Format(time.RFC3339),
+			})
+		}
+		return cli.print(result, []string{"FINGERPRINT", "NAME", "CONNECTED", "WATCHONLY", "LAST CONNECTED"}, rows)
+	}
+}
+
+func accountsCommand(flags *flag.FlagSet) func(cli *cli) error {
+	all := flags.Bool("all", false, "also list deactivated accounts")
+	return func(cli *cli) error {
+		if err := cli.waitForKeystore(false); err != nil {
+			return err
+		}
+		type accountJSON struct {
+			Code            accountsTypes.Code `json:"code"`
+			Name            string             `json:"name"`
+			CoinCode        coin.Code          `json:"coinCode"`
+			RootFingerprint string             `json:"rootFingerprint"`
+			Active          bool               `json:"active"`
+		}
+		result := []accountJSON{}
+		rows := [][]string{}
+		for _, account := range cli.backend.Accounts() {
+			persistedAccount := account.Config().Config
+			if persistedAccount.HiddenBecauseUnused || (persistedAccount.Inactive && !*all) {
+				continue
+			}
+			result = append(result, accountJSON{
+				Code:            persistedAccount.Code,
+				Name:            persistedAccount.Name,
+				CoinCode:        persistedAccount.CoinCode,
+				RootFingerprint: rootFingerprint(account),
+				Active:          !persistedAccount.Inactive,
+			})
+			rows = append(rows, []string{
+				string(persistedAccount.Code),
+				persistedAccount.Name,
+				string(persistedAccount.CoinCode),
+				rootFingerprint(account),
+				fmt.Sprint(!persistedAccount.Inactive),
+			})
+		}
+		return cli.print(result, []string{"CODE", "NAME", "COIN", "FINGERPRINT", "ACTIVE"}, rows)
+	}
+}
+
+func balanceCommand(flags *flag.FlagSet) func(cli *cli) error {
+	accountCode := flags.String("account", "", "the code of the account; all accounts if empty")
+	return func(cli *cli) error {
+		var accountsList []accounts.Interface
+		if *accountCode != "" {
+			account, err := cli.account(*accountCode)
+			if err != nil {
+				return err
+			}
+			accountsList = []accounts.Interface{account}
+		} else {
+			if err := cli.waitForKeystore(false); err != nil {
+				return err
+			}
+			for _, account := range cli.activeAccounts() {
+				if err := account.Initialize(); err != nil {
+					return err
+				}
+				if err := cli.waitSynced(account); err != nil {
+					return err
+				}
+				accountsList = append(accountsList, account)
+			}
+		}
+		type balanceJSON struct {
+			Code      accountsTypes.Code `json:"code"`
+			Name      string             `json:"name"`
+			Available amountJSON         `json:"available"`
+			Incoming  amountJSON         `json:"incoming"`
+		}
+		result := []balanceJSON{}
+		rows := [][]string{}
+		for _, account := range accountsList {
+			balance, err := account.Balance()
+			if err != nil {
+				return err
+			}
+			persistedAccount := account.Config().Config
+			entry := balanceJSON{
+				Code:      persistedAccount.Code,
+				Name:      persistedAccount.Name,
+				Available: formatAmount(account.Coin(), balance.Available(), false),
+				Incoming:  formatAmount(account.Coin(), balance.Incoming(), false),
+			}
+			result = append(result, entry)
+			rows = append(rows, []string{
+				string(entry.Code), entry.Name, entry.Available.String(), entry.Incoming.String(),
+			})
+		}
+		return cli.print(result, []string{"CODE", "NAME", "AVAILABLE", "INCOMING"}, rows)
+	}
+}
+
+func transactionsCommand(flags *flag.FlagSet) func(cli *cli) error {
+	accountCode := accountFlag(flags)
+	return func(cli *cli) error {
+		account, err := cli.account(*accountCode)
+		if err != nil {
+			return err
+		}
+		transactions, err := account.Transactions()
+		if err != nil {
+			return err
+		}
+		type transactionJSON struct {
+			TxID             string            `json:"txID"`
+			InternalID       string            `json:"internalID"`
+			Type             accounts.TxType   `json:"type"`
+			Status           accounts.TxStatus `json:"status"`
+			NumConfirmations int               `json:"numConfirmations"`
+			Time             *time.Time        `json:"time"`
+			Amount           amountJSON        `json:"amount"`
+			Fee              *amountJSON       `json:"fee"`
+			Addresses        []string          `json:"addresses"`
+			Note             string            `json:"note"`
+		}
+		result := []transactionJSON{}
+		rows := [][]string{}
+		for _, txInfo := range transactions {
+			if txInfo.IsErc20 && big.NewInt(0).Cmp(txInfo.Amount.BigInt()) == 0 {
+				// Skip 0 amount ERC20 txs to mitigate address poisoning attacks, like the BitBoxApp.
+				continue
+			}
+			timestamp := txInfo.Timestamp
+			if timestamp == nil {
+				timestamp = txInfo.CreatedTimestamp
+			}
+			addresses := []string{}
+			for _, addressAndAmount := range txInfo.Addresses {
+				addresses = append(addresses, addressAndAmount.Address)
+			}
+			entry := transactionJSON{
+				TxID:             txInfo.TxID,
+				InternalID:       txInfo.InternalID,
+				Type:             txInfo.Type,
+				Status:           txInfo.Status,
+				NumConfirmations: txInfo.NumConfirmations,
+				Time:             timestamp,
+				Amount:           formatAmount(account.Coin(), txInfo.Amount, false),
+				Addresses:        addresses,
+				Note:             account.TxNote(txInfo.InternalID),
+			}
+			fee := ""
+			if txInfo.Fee != nil {
+				formattedFee := formatAmount(account.Coin(), *txInfo.Fee, true)
+				entry.Fee = &formattedFee
+				fee = formattedFee.String()
+			}
+			formattedTime := ""
+			if timestamp != nil {
+				formattedTime = timestamp.Format(time.RFC3339)
+			}
+			result = append(result, entry)
+			rows = append(rows, []string{
+				formattedTime, string(entry.Type), entry.Amount.String(), fee, string(entry.Status),
+				fmt.Sprint(entry.NumConfirmations), entry.TxID, entry.Note,
+			})
+		}
+		return cli.print(result,
+			[]string{"TIME", "TYPE", "AMOUNT", "FEE", "STATUS", "CONFIRMATIONS", "TXID", "NOTE"}, rows)
+	}
+}
+
+func feeTargetsCommand(flags *flag.FlagSet) func(cli *cli) error {
+	accountCode := accountFlag(flags)
+	return func(cli *cli) error {
+		account, err := cli.account(*accountCode)
+		if err != nil {
+			return err
+		}
+		type feeTargetJSON struct {
+			Code        accounts.FeeTargetCode `json:"code"`
+			FeeRateInfo string                 `json:"feeRateInfo"`
+			Default     bool                   `json:"default"`
+		}
+		feeTargets, defaultFeeTarget := account.FeeTargets()
+		result := []feeTargetJSON{}
+		rows := [][]string{}
+		for _, feeTarget := range feeTargets {
+			entry := feeTargetJSON{
+				Code:        feeTarget.Code(),
+				FeeRateInfo: feeTarget.FormattedFeeRate(),
+				Default:     feeTarget.Code() == defaultFeeTarget,
+			}
+			result = append(result, entry)
+			rows = append(rows, []string{string(entry.Code), entry.FeeRateInfo, fmt.Sprint(entry.Default)})
+		}
+		return cli.print(result, []string{"CODE", "FEE RATE", "DEFAULT"}, rows)
+	}
+}
+
+func receiveCommand(flags *flag.FlagSet) func(cli *cli) error {
+	accountCode := accountFlag(flags)
+	scriptType := flags.String("script-type", "",
+		"only show the address of this script type (BTC/LTC), e.g. p2wpkh or p2tr")
+	verify := flags.Bool("verify", false, "verify the (first) address on the BitBox")
+	return func(cli *cli) error {
+		account, err := cli.account(*accountCode)
+		if err != nil {
+			return err
+		}
+		type addressJSON struct {
+			ScriptType *signing.ScriptType `json:"scriptType"`
+			Address    string              `json:"address"`
+			AddressID  string              `json:"addressID"`
+		}
+		result := []addressJSON{}
+		rows := [][]string{}
+		for _, addressList := range account.GetUnusedReceiveAddresses() {
+			if len(addressList.Addresses) == 0 {
+				continue
+			}
+			scriptTypeString := ""
+			if addressList.ScriptType != nil {
+				scriptTypeString = string(*addressList.ScriptType)
+			}
+			if *scriptType != "" && scriptTypeString != *scriptType {
+				continue
+			}
+			address := addressList.Addresses[0]
+			result = append(result, addressJSON{
+				ScriptType: addressList.ScriptType,
+				Address:    address.EncodeForHumans(),
+				AddressID:  address.ID(),
+			})
+			rows = append(rows, []string{scriptTypeString, address.EncodeForHumans()})
+		}
+		if len(result) == 0 {
+			return errp.New("No unused receive address found")
+		}
+		if err := cli.print(result, []string{"SCRIPT TYPE", "ADDRESS"}, rows); err != nil {
+			return err
+		}
+		if !*verify {
+			return nil
+		}
+		return cli.withKeystore(func() error {
+			canVerify, _, err := account.CanVerifyAddresses()
+			if err != nil {
+				return err
+			}
+			if !canVerify {
+				return errp.New("The keystore can't verify addresses")
+			}
+			cli.notice("Please verify the address %s on your BitBox.", result[0].Address)
+			_, err = account.VerifyAddress(result[0].AddressID)
+			return err
+		})
+	}
+}
+
+// txProposalFlags are the flags of the commands creating a transaction proposal.
+type txProposalFlags struct {
+	accountCode *string
+	recipient   *string
+	amount      *string
+	fiat        *string
+	feeTarget   *string
+	customFee   *string
+	note        *string
+}
+
+func newTxProposalFlags(flags *flag.FlagSet) *txProposalFlags {
+	return &txProposalFlags{
+		accountCode: accountFlag(flags),
+		recipient:   flags.String("to", "", "the recipient address"),
+		amount: flags.String("amount", "",
+			"the amount to send in the unit of the coin (or of -fiat), or \"all\" to send all coins"),
+		fiat: flags.String("fiat", "", "the fiat currency of -amount, e.g. USD or CHF"),
+		feeTarget: flags.String("fee-target", "",
+			"the fee target as listed by the fee-targets command; the default fee target if empty"),
+		customFee: flags.String("custom-fee", "",
+			"the fee rate of the custom fee target, in sat/vB for BTC/LTC and Gwei for ETH"),
+		note: flags.String("note", "", "the transaction note"),
+	}
+}
+
+// args validates the flags and returns the arguments of the transaction proposal for the account.
+func (txFlags *txProposalFlags) args(account accounts.Interface, dryRun bool) (*accounts.TxProposalArgs, error) {
+	if *txFlags.recipient == "" || *txFlags.amount == "" {
+		return nil, errp.New("Missing -to or -amount")
+	}
+	_, feeTargetCode := account.FeeTargets()
+	if *txFlags.customFee != "" {
+		feeTargetCode = accounts.FeeTargetCodeCustom
+	}
+	if *txFlags.feeTarget != "" {
+		var err error
+		feeTargetCode, err = accounts.NewFeeTargetCode(*txFlags.feeTarget)
+		if err != nil {
+			return nil, err
+		}
+	}
+	amount := coin.NewSendAmount(*txFlags.amount)
+	if *txFlags.amount == "all" {
+		amount = coin.NewSendAmountAll()
+	}
+	return &accounts.TxProposalArgs{
+		RecipientAddress: *txFlags.recipient,
+		Amount:           amount,
+		FeeTargetCode:    feeTargetCode,
+		CustomFee:        *txFlags.customFee,
+		Note:             *txFlags.note,
+		DryRun:           dryRun,
+		Fiat:             rates.Fiat(strings.ToUpper(*txFlags.fiat)),
+	}, nil
+}
+
+// txProposal validates the flags and creates the transaction proposal. Unless dryRun is set, the
+// proposal is stored in the account, so that it can be sent with SendTx().
+func (cli *cli) txProposal(txFlags *txProposalFlags, dryRun bool) (
+	accounts.Interface, *accounts.TxProposalResult, error) {
+	if *txFlags.recipient == "" || *txFlags.amount == "" {
+		return nil, nil, errp.New("Missing -to or -amount")
+	}
+	account, err := cli.account(*txFlags.accountCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	args, err := txFlags.args(account, dryRun)
+	if err != nil {
+		return nil, nil, err
+	}
+	txProposal, err := account.TxProposal(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, txProposal, nil
+}
+
+// txProposalJSON is the result of the propose and send commands.
+type txProposalJSON struct {
+	Amount    amountJSON                   `json:"amount"`
+	Fee       amountJSON                   `json:"fee"`
+	Total     amountJSON                   `json:"total"`
+	Warnings  []accounts.TxProposalWarning `json:"warnings"`
+	FiatQuote *accounts.FiatQuote          `json:"fiatQuote"`
+	// Sent is true if the transaction was signed and broadcast.
+	Sent bool `json:"sent"`
+}
+
+func newTxProposalJSON(account accounts.Interface, txProposal *accounts.TxProposalResult) *txProposalJSON {
+	warnings := txProposal.Warnings
+	if warnings == nil {
+		warnings = []accounts.TxProposalWarning{}
+	}
+	return &txProposalJSON{
+		Amount:    formatAmount(account.Coin(), txProposal.Amount, false),
+		Fee:       formatAmount(account.Coin(), txProposal.Fee, true),
+		Total:     formatAmount(account.Coin(), txProposal.Total, false),
+		Warnings:  warnings,
+		FiatQuote: txProposal.FiatQuote,
+	}
+}
+
+// rows returns the proposal as name/value pairs.
+func (result *txProposalJSON) rows() [][]string {
+	rows := [][]string{
+		{"Amount:", result.Amount.String()},
+		{"Fee:", result.Fee.String()},
+		{"Total:", result.Total.String()},
+	}
+	if result.FiatQuote != nil {
+		rows = append(rows, []string{"Rate:", fmt.Sprintf("%v %s/%s",
+			result.FiatQuote.Rate, result.FiatQuote.Fiat, result.Amount.Unit)})
+	}
+	for _, warning := range result.Warnings {
+		text := string(warning.Code)
+		if warning.Address != "" {
+			text += " " + warning.Address
+		}
+		if warning.NumAddresses != 0 {
+			text += fmt.Sprintf(" (%d addresses)", warning.NumAddresses)
+		}
+		rows = append(rows, []string{"Warning:", text})
+	}
+	return rows
+}
+
+func proposeCommand(flags *flag.FlagSet) func(cli *cli) error {
+	txFlags := newTxProposalFlags(flags)
+	return func(cli *cli) error {
+		account, txProposal, err := cli.txProposal(txFlags, true)
+		if err != nil {
+			return err
+		}
+		result := newTxProposalJSON(account, txProposal)
+		return cli.print(result, nil, result.rows())
+	}
+}
+
+// confirm asks the user to confirm on stdin.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func sendCommand(flags *flag.FlagSet) func(cli *cli) error {
+	txFlags := newTxProposalFlags(flags)
+	yes := flags.Bool("yes", false, "do not ask for confirmation before signing on the BitBox")
+	return func(cli *cli) error {
+		account, txProposal, err := cli.txProposal(txFlags, false)
+		if err != nil {
+			return err
+		}
+		result := newTxProposalJSON(account, txProposal)
+		if !*yes {
+			writer := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+			for _, row := range result.rows() {
+				fmt.Fprintln(writer, strings.Join(row, "\t"))
+			}
+			if err := writer.Flush(); err != nil {
+				return errp.WithStack(err)
+			}
+			if !confirm("Send this transaction?") {
+				return errp.ErrUserAbort
+			}
+		}
+		err = cli.withKeystore(func() error {
+			cli.notice("Please confirm the transaction on your BitBox.")
+			return account.SendTx(*txFlags.note)
+		})
+		if errp.Cause(err) == keystore.ErrSigningAborted {
+			return errp.ErrUserAbort
+		}
+		if err != nil {
+			return err
+		}
+		result.Sent = true
+		return cli.print(result, nil, append(result.rows(), []string{"Sent:", "true"}))
+	}
+}
+
+func exportNotesCommand(flags *flag.FlagSet) func(cli *cli) error {
+	output := flags.String("o", "", "the file to export to; a new file in the exports folder if empty")
+	return func(cli *cli) error {
+		if err := cli.waitForKeystore(false); err != nil {
+			return err
+		}
+		cli.environment.saveFilename = *output
+		if err := cli.backend.ExportNotes(); err != nil {
+			return err
+		}
+		path := cli.environment.savedFilename
+		return cli.print(map[string]string{"path": path}, nil, [][]string{{"Exported to:", path}})
+	}
+}
+
+func exportCSVCommand(flags *flag.FlagSet) func(cli *cli) error {
+	accountCode := accountFlag(flags)
+	output := flags.String("o", "", "the file to export to; stdout if empty")
+	return func(cli *cli) error {
+		account, err := cli.account(*accountCode)
+		if err != nil {
+			return err
+		}
+		transactions, err := account.Transactions()
+		if err != nil {
+			return err
+		}
+		if *output == "" {
+			return account.ExportCSV(os.Stdout, transactions)
+		}
+		file, err := os.Create(*output)
+		if err != nil {
+			return errp.WithStack(err)
+		}
+		if err := account.ExportCSV(file, transactions); err != nil {
+			_ = file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return errp.WithStack(err)
+		}
+		return cli.print(map[string]string{"path": *output}, nil, [][]string{{"Exported to:", *output}})
+	}
+}
+
+// apiTokenJSON is an API token without its hash.
+type apiTokenJSON struct {
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Scopes       []config.APITokenScope `json:"scopes"`
+	AccountCodes []accountsTypes.Code   `json:"accountCodes"`
+	Created      time.Time              `json:"created"`
+	Expires      *time.Time             `json:"expires"`
+}
+
+func newAPITokenJSON(token *config.APIToken) apiTokenJSON {
+	return apiTokenJSON{
+		ID:           token.ID,
+		Name:         token.Name,
+		Scopes:       token.Scopes,
+		AccountCodes: token.AccountCodes,
+		Created:      token.Created,
+		Expires:      token.Expires,
+	}
+}
+
+// splitList splits a comma separated list, ignoring empty elements.
+func splitList(list string) []string {
+	result := []string{}
+	for _, element := range strings.Split(list, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			result = append(result, element)
+		}
+	}
+	return result
+}
+
+func apiTokensCommand(flags *flag.FlagSet) func(cli *cli) error {
+	return func(cli *cli) error {
+		result := []apiTokenJSON{}
+		rows := [][]string{}
+		for _, token := range cli.backend.APITokens() {
+			entry := newAPITokenJSON(&token)
+			result = append(result, entry)
+			scopes := []string{}
+			for _, scope := range entry.Scopes {
+				scopes = append(scopes, string(scope))
+			}
+			accountCodes := "all"
+			if len(entry.AccountCodes) != 0 {
+				codes := []string{}
+				for _, code := range entry.AccountCodes {
+					codes = append(codes, string(code))
+				}
+				accountCodes = strings.Join(codes, ",")
+			}
+			expires := "never"
+			if entry.Expires != nil {
+				expires = entry.Expires.Format(time.RFC3339)
+				if token.Expired(time.Now()) {
+					expires += " (expired)"
+				}
+			}
+			rows = append(rows, []string{
+				entry.ID, entry.Name, strings.Join(scopes, ","), accountCodes, expires,
+			})
+		}
+		return cli.print(result, []string{"ID", "NAME", "SCOPES", "ACCOUNTS", "EXPIRES"}, rows)
+	}
+}
+
+func createAPITokenCommand(flags *flag.FlagSet) func(cli *cli) error {
+	knownScopes := []string{}
+	for _, scope := range config.APITokenScopes {
+		knownScopes = append(knownScopes, string(scope))
+	}
+	name := flags.String("name", "", "the name of the token, e.g. the app using it")
+	scopesFlag := flags.String("scopes", "",
+		"comma separated scopes of the token, out of: "+strings.Join(knownScopes, ", "))
+	accountsFlag := flags.String("accounts", "",
+		"comma separated codes of the accounts the token is restricted to; all accounts if empty")
+	expiresIn := flags.Duration("expires", 0, "how long the token is valid, e.g. 720h; no expiry if 0")
+	return func(cli *cli) error {
+		scopes := []config.APITokenScope{}
+		for _, scope := range splitList(*scopesFlag) {
+			scopes = append(scopes, config.APITokenScope(scope))
+		}
+		accountCodes := []accountsTypes.Code{}
+		for _, code := range splitList(*accountsFlag) {
+			accountCodes = append(accountCodes, accountsTypes.Code(code))
+		}
+		if len(accountCodes) != 0 {
+			// The accounts are loaded once the keystore is known.
+			if err := cli.waitForKeystore(false); err != nil {
+				return err
+			}
+		}
+		var expires *time.Time
+		if *expiresIn != 0 {
+			expiry := time.Now().Add(*expiresIn)
+			expires = &expiry
+		}
+		secret, token, err := cli.backend.CreateAPIToken(*name, scopes, accountCodes, expires)
+		if err != nil {
+			return err
+		}
+		cli.notice("Store the token now, it can't be shown again.")
+		type resultJSON struct {
+			Token    string       `json:"token"`
+			APIToken apiTokenJSON `json:"apiToken"`
+		}
+		return cli.print(
+			resultJSON{Token: secret, APIToken: newAPITokenJSON(token)},
+			nil,
+			[][]string{{"ID:", token.ID}, {"Token:", secret}})
+	}
+}
+
+func revokeAPITokenCommand(flags *flag.FlagSet) func(cli *cli) error {
+	id := flags.String("id", "", "the ID of the token, as listed by the api-tokens command")
+	return func(cli *cli) error {
+		if err := cli.backend.RevokeAPIToken(*id); err != nil {
+			return err
+		}
+		return cli.print(map[string]string{"id": *id}, nil, [][]string{{"Revoked:", *id}})
+	}
+}
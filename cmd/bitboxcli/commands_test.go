@@ -0,0 +1,81 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/mocks"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/coins/coin"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/rates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitList(t *testing.T) {
+	require.Equal(t, []string{}, splitList(""))
+	require.Equal(t, []string{}, splitList(" , ,"))
+	require.Equal(t, []string{"a"}, splitList("a"))
+	require.Equal(t, []string{"a", "b c", "d"}, splitList(" a,b c ,,d,"))
+}
+
+func TestTxProposalFlags(t *testing.T) {
+	account := &mocks.InterfaceMock{
+		FeeTargetsFunc: func() ([]accounts.FeeTarget, accounts.FeeTargetCode) {
+			return nil, accounts.FeeTargetCodeNormal
+		},
+	}
+	args := func(dryRun bool, arguments ...string) (*accounts.TxProposalArgs, error) {
+		flags := flag.NewFlagSet("propose", flag.ContinueOnError)
+		txFlags := newTxProposalFlags(flags)
+		require.NoError(t, flags.Parse(arguments))
+		return txFlags.args(account, dryRun)
+	}
+
+	result, err := args(true, "-to", "address", "-amount", "0.1", "-note", "rent")
+	require.NoError(t, err)
+	require.Equal(t, &accounts.TxProposalArgs{
+		RecipientAddress: "address",
+		Amount:           coin.NewSendAmount("0.1"),
+		FeeTargetCode:    accounts.FeeTargetCodeNormal,
+		Note:             "rent",
+		DryRun:           true,
+	}, result)
+
+	result, err = args(false, "-to", "address", "-amount", "all", "-fee-target", "low")
+	require.NoError(t, err)
+	require.True(t, result.Amount.SendAll())
+	require.Equal(t, accounts.FeeTargetCodeLow, result.FeeTargetCode)
+	require.False(t, result.DryRun)
+
+	result, err = args(false, "-to", "address", "-amount", "100", "-fiat", "chf")
+	require.NoError(t, err)
+	require.Equal(t, coin.NewSendAmount("100"), result.Amount)
+	require.Equal(t, rates.CHF, result.Fiat)
+
+	// A custom fee implies the custom fee target.
+	result, err = args(false, "-to", "address", "-amount", "0.1", "-custom-fee", "12.5")
+	require.NoError(t, err)
+	require.Equal(t, accounts.FeeTargetCodeCustom, result.FeeTargetCode)
+	require.Equal(t, "12.5", result.CustomFee)
+
+	_, err = args(false, "-to", "address", "-amount", "0.1", "-fee-target", "fastest")
+	require.Error(t, err)
+	_, err = args(false, "-amount", "0.1")
+	require.Error(t, err)
+	_, err = args(false, "-to", "address")
+	require.Error(t, err)
+}
@@ -0,0 +1,404 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/accounts/errors"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/arguments"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/devices/bitbox02"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/devices/device"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/backend/devices/usb"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/config"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/errp"
+	"github.com/BitBoxSwiss/bitbox-wallet-app/util/logging"
+	"github.com/BitBoxSwiss/bitbox02-api-go/api/firmware"
+	"github.com/sirupsen/logrus"
+	"go.etcd.io/bbolt"
+)
+
+// pollInterval is the interval in which the device and account status is checked while waiting.
+const pollInterval = 200 * time.Millisecond
+
+// usbEnumerationDelay is how long to wait for a plugged in BitBox to be detected before the
+// command runs without it.
+const usbEnumerationDelay = 2 * time.Second
+
+// terminalEnvironment implements backend.Environment for a terminal without a GUI.
+type terminalEnvironment struct {
+	// simulator, if not nil, makes a BitBox02 simulator available in addition to USB devices.
+	simulator *usb.Simulator
+	backend   *backend.Backend
+	// saveFilename, if not empty, is the path exported files are written to instead of the
+	// suggested path.
+	saveFilename string
+	// savedFilename is the path of the last exported file.
+	savedFilename string
+}
+
+// NotifyUser implements backend.Environment.
+func (env *terminalEnvironment) NotifyUser(text string) {
+	logging.Get().WithGroup("bitboxcli").Infof("NotifyUser: %s", text)
+}
+
+// DeviceInfos implements backend.Environment.
+func (env *terminalEnvironment) DeviceInfos() []usb.DeviceInfo {
+	if env.simulator != nil {
+		return append(usb.DeviceInfos(), env.simulator.DeviceInfos()...)
+	}
+	return usb.DeviceInfos()
+}
+
+// SystemOpen implements backend.Environment. There is no browser to open the URL in, so it is
+// printed instead.
+func (env *terminalEnvironment) SystemOpen(url string) error {
+	fmt.Fprintf(os.Stderr, "Open: %s\n", url)
+	return nil
+}
+
+// UsingMobileData implements backend.Environment.
+func (env *terminalEnvironment) UsingMobileData() bool {
+	return false
+}
+
+// Auth implements backend.Environment. The terminal has no means of authentication, so it always
+// succeeds.
+func (env *terminalEnvironment) Auth() {
+	if env.backend != nil {
+		env.backend.AuthResult(true)
+	}
+}
+
+// OnAuthSettingChanged implements backend.Environment.
+func (env *terminalEnvironment) OnAuthSettingChanged(enabled bool) {
+}
+
+// NativeLocale implements backend.Environment.
+func (env *terminalEnvironment) NativeLocale() string {
+	v := os.Getenv("LC_ALL")
+	if v == "" {
+		v = os.Getenv("LANG")
+	}
+	if v == "" || v == "C" || v == "POSIX" {
+		v = "en_US"
+	}
+	// Strip the charset, e.g. en_US.UTF-8.
+	return strings.Split(v, ".")[0]
+}
+
+// GetSaveFilename implements backend.Environment.
+func (env *terminalEnvironment) GetSaveFilename(suggestedFilename string) string {
+	filename := suggestedFilename
+	if env.saveFilename != "" {
+		filename = env.saveFilename
+	}
+	env.savedFilename = filename
+	return filename
+}
+
+// SetDarkTheme implements backend.Environment.
+func (env *terminalEnvironment) SetDarkTheme(isDark bool) {
+}
+
+// DetectDarkTheme implements backend.Environment.
+func (env *terminalEnvironment) DetectDarkTheme() bool {
+	return false
+}
+
+// cli runs a command against the backend and prints its result.
+type cli struct {
+	backend     *backend.Backend
+	environment *terminalEnvironment
+	jsonOutput  bool
+	// deadline is when waiting for the BitBox or for accounts to sync is given up.
+	deadline time.Time
+
+	devicesLock sync.Mutex
+	// devices are the registered BitBox02s.
+	devices map[string]*bitbox02.Device
+	// reportedStatus is the last device status the user was told about, to print each
+	// instruction only once.
+	reportedStatus map[string]firmware.Status
+
+	log *logrus.Entry
+}
+
+func newCLI(backend *backend.Backend, environment *terminalEnvironment, jsonOutput bool, timeout time.Duration) *cli {
+	cli := &cli{
+		backend:        backend,
+		environment:    environment,
+		jsonOutput:     jsonOutput,
+		deadline:       time.Now().Add(timeout),
+		devices:        map[string]*bitbox02.Device{},
+		reportedStatus: map[string]firmware.Status{},
+		log:            logging.Get().WithGroup("bitboxcli"),
+	}
+	backend.OnDeviceInit(func(theDevice device.Interface) {
+		if bitbox02Device, ok := theDevice.(*bitbox02.Device); ok {
+			cli.devicesLock.Lock()
+			cli.devices[theDevice.Identifier()] = bitbox02Device
+			cli.devicesLock.Unlock()
+		}
+	})
+	backend.OnDeviceUninit(func(deviceID string) {
+		cli.devicesLock.Lock()
+		delete(cli.devices, deviceID)
+		delete(cli.reportedStatus, deviceID)
+		cli.devicesLock.Unlock()
+	})
+	return cli
+}
+
+// start starts the backend. The events meant for the frontend are discarded.
+func (cli *cli) start() {
+	events := cli.backend.Start()
+	go func() {
+		for range events {
+		}
+	}()
+}
+
+// notice prints an instruction or progress message for the user. It goes to stderr so that it
+// does not interfere with the output of the command.
+func (cli *cli) notice(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// checkDevices guides the user through unlocking and pairing the registered BitBox02s. The pairing
+// is accepted once it was confirmed on the device, as the user compares the pairing code printed
+// here with the one shown on the device.
+func (cli *cli) checkDevices() (bool, error) {
+	cli.devicesLock.Lock()
+	defer cli.devicesLock.Unlock()
+	for deviceID, device := range cli.devices {
+		status := device.Status()
+		reported := cli.reportedStatus[deviceID] == status
+		cli.reportedStatus[deviceID] = status
+		switch status {
+		case firmware.StatusConnected:
+			if !reported {
+				cli.notice("Please unlock your BitBox02.")
+			}
+		case firmware.StatusUnpaired:
+			channelHash, deviceVerified := device.ChannelHash()
+			if !reported {
+				cli.notice("Pairing code:\n%s\nPlease confirm the pairing code on your BitBox02.", channelHash)
+			}
+			if deviceVerified {
+				device.ChannelHashVerify(true)
+			}
+		case firmware.StatusPairingFailed:
+			return false, errp.New("The pairing with the BitBox02 failed")
+		case firmware.StatusUninitialized, firmware.StatusSeeded:
+			return false, errp.New("The BitBox02 is not set up. Please set it up in the BitBoxApp first")
+		case firmware.StatusRequireFirmwareUpgrade:
+			return false, errp.New("The BitBox02 firmware is outdated. Please upgrade it in the BitBoxApp first")
+		case firmware.StatusRequireAppUpgrade:
+			return false, errp.New("The BitBox02 firmware is too new for this version of bitboxcli")
+		}
+	}
+	return len(cli.devices) > 0, nil
+}
+
+// waitForKeystore waits until the keystore of a connected BitBox is registered, so that its
+// accounts are loaded. If required is false and no BitBox is connected, it returns without
+// error, as the accounts of watch-only keystores are loaded anyway.
+func (cli *cli) waitForKeystore(required bool) error {
+	started := time.Now()
+	for cli.backend.Keystore() == nil {
+		hasDevices, err := cli.checkDevices()
+		if err != nil {
+			return err
+		}
+		if !hasDevices && !required && time.Since(started) > usbEnumerationDelay {
+			return nil
+		}
+		if time.Now().After(cli.deadline) {
+			if !hasDevices {
+				return errp.New("No BitBox connected")
+			}
+			return errp.New("Timed out waiting for the BitBox to be unlocked")
+		}
+		time.Sleep(pollInterval)
+	}
+	return nil
+}
+
+// waitSynced waits until the account is synced.
+func (cli *cli) waitSynced(account accounts.Interface) error {
+	for !account.Synced() {
+		if account.FatalError() {
+			return errp.Newf("Account %s could not be loaded", account.Config().Config.Code)
+		}
+		if err := account.Offline(); err != nil {
+			return errp.WithMessage(err, "Account is offline")
+		}
+		if time.Now().After(cli.deadline) {
+			return errp.Newf("Timed out waiting for account %s to sync", account.Config().Config.Code)
+		}
+		time.Sleep(pollInterval)
+	}
+	return nil
+}
+
+// withKeystore runs f, which uses the keystore of an account, e.g. to sign. The backend waits for
+// the right keystore to be connected, which is given up at the deadline.
+func (cli *cli) withKeystore(f func() error) error {
+	if err := cli.waitForKeystore(true); err != nil {
+		return err
+	}
+	timer := time.AfterFunc(time.Until(cli.deadline), cli.backend.CancelConnectKeystore)
+	defer timer.Stop()
+	return f()
+}
+
+// printError prints the error, as JSON in case of JSON output.
+func (cli *cli) printError(err error) {
+	if !cli.jsonOutput {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+	result := map[string]string{"error": err.Error()}
+	if validationErr, ok := errp.Cause(err).(errors.TxValidationError); ok {
+		result["errorCode"] = validationErr.Error()
+	}
+	if err := cli.printJSON(result); err != nil {
+		cli.log.WithError(err).Error("Failed to print the error")
+	}
+}
+
+// checkAppNotRunning returns an error if the BitBoxApp, or another bitboxcli, is using the app
+// folder. Both would write the same config and databases. The running app keeps notifier.db open
+// and locked.
+func checkAppNotRunning(appDir string) error {
+	filename := filepath.Join(appDir, "notifier.db")
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil
+	}
+	db, err := bbolt.Open(filename, 0600, &bbolt.Options{Timeout: time.Second})
+	if err == bbolt.ErrTimeout {
+		return errp.New("The BitBoxApp is running. Please close it first, or use -appdir")
+	}
+	if err != nil {
+		return errp.WithStack(err)
+	}
+	return errp.WithStack(db.Close())
+}
+
+func printUsage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: bitboxcli [flags] <command> [command flags]\n\n")
+	fmt.Fprintf(out, "Uses the wallet without the BitBoxApp. The accounts of a keystore are available while its\n")
+	fmt.Fprintf(out, "BitBox is connected, or if the keystore is remembered in the BitBoxApp.\n\n")
+	fmt.Fprintf(out, "Commands:\n")
+	for _, command := range commands {
+		fmt.Fprintf(out, "  %-14s %s\n", command.name, command.description)
+	}
+	fmt.Fprintf(out, "\nRun 'bitboxcli <command> -h' for the flags of a command.\n\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	appDir := flag.String("appdir", "", "use this app folder instead of the one of the BitBoxApp")
+	testnet := flag.Bool("testnet", false, "use testnet instead of mainnet coins")
+	regtest := flag.Bool("regtest", false, "use regtest instead of mainnet coins")
+	devservers := flag.Bool("devservers", false, "use the dev servers")
+	simulatorAddress := flag.String("simulator", os.Getenv("BITBOX02_SIMULATOR"),
+		"connect to a BitBox02 simulator at this address (host:port), e.g. localhost:15423")
+	jsonOutput := flag.Bool("json", false, "print the result as JSON")
+	timeout := flag.Duration("timeout", 2*time.Minute,
+		"how long to wait for the BitBox to be unlocked and for the accounts to sync")
+	verbose := flag.Bool("v", false, "log to stderr instead of the log file")
+	flag.Usage = printUsage
+	flag.Parse()
+
+	command := lookupCommand(flag.Arg(0))
+	if command == nil {
+		flag.Usage()
+		os.Exit(2)
+	}
+	commandFlags := flag.NewFlagSet(command.name, flag.ExitOnError)
+	commandFlags.Usage = func() {
+		fmt.Fprintf(commandFlags.Output(), "Usage: bitboxcli [flags] %s [command flags]\n\n%s\n\nCommand flags:\n",
+			command.name, command.description)
+		commandFlags.PrintDefaults()
+	}
+	run := command.setup(commandFlags)
+	_ = commandFlags.Parse(flag.Args()[1:])
+
+	if *appDir != "" {
+		config.SetAppDir(*appDir)
+	}
+	logConfiguration := &logging.Configuration{
+		Output: filepath.Join(config.AppDir(), "bitboxcli-log.txt"),
+		Level:  logrus.InfoLevel,
+	}
+	if *verbose {
+		logConfiguration = &logging.Configuration{Output: "STDERR", Level: logrus.DebugLevel}
+	}
+	logging.Set(logConfiguration)
+	log := logging.Get().WithGroup("bitboxcli")
+	log.WithField("command", command.name).Info("--------------- Started bitboxcli --------------")
+
+	if err := checkAppNotRunning(config.AppDir()); err != nil {
+		log.WithError(err).Error("The app folder is in use")
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	environment := &terminalEnvironment{}
+	if *simulatorAddress != "" {
+		environment.simulator = usb.NewSimulator(*simulatorAddress)
+	}
+	theBackend, err := backend.NewBackend(
+		arguments.NewArguments(config.AppDir(), *testnet || *regtest, *regtest, *devservers, nil),
+		environment)
+	if err != nil {
+		log.WithError(err).Error("Failed to create the backend")
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	environment.backend = theBackend
+	cli := newCLI(theBackend, environment, *jsonOutput, *timeout)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		theBackend.CancelConnectKeystore()
+		_ = theBackend.Close()
+		os.Exit(130)
+	}()
+
+	cli.start()
+	err = run(cli)
+	if closeErr := theBackend.Close(); closeErr != nil {
+		log.WithError(closeErr).Error("Failed to close the backend")
+	}
+	if err != nil {
+		log.WithError(err).Error("Command failed")
+		cli.printError(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 Shift Crypto AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestCheckAppNotRunning(t *testing.T) {
+	appDir := t.TempDir()
+	// The app never ran.
+	require.NoError(t, checkAppNotRunning(appDir))
+
+	// The app is running.
+	db, err := bbolt.Open(filepath.Join(appDir, "notifier.db"), 0600, nil)
+	require.NoError(t, err)
+	require.Error(t, checkAppNotRunning(appDir))
+
+	// The app was closed.
+	require.NoError(t, db.Close())
+	require.NoError(t, checkAppNotRunning(appDir))
+}
@@ -43,6 +43,8 @@ var backend *backendPkg.Backend
 
 // webdevEnvironment implements backend.Environment.
 type webdevEnvironment struct {
+	// simulator, if not nil, makes a BitBox02 simulator available in addition to USB devices.
+	simulator *usb.Simulator
 }
 
 // NotifyUser implements backend.Environment.
@@ -68,7 +70,10 @@ func (webdevEnvironment) NotifyUser(text string) {
 }
 
 // DeviceInfos implements backend.Environment.
-func (webdevEnvironment) DeviceInfos() []usb.DeviceInfo {
+func (env webdevEnvironment) DeviceInfos() []usb.DeviceInfo {
+	if env.simulator != nil {
+		return append(usb.DeviceInfos(), env.simulator.DeviceInfos()...)
+	}
 	return usb.DeviceInfos()
 }
 
@@ -146,6 +151,8 @@ func main() {
 	devservers := flag.Bool("devservers", true, "switch to dev servers")
 	gapLimitsReceive := flag.Uint("gapLimitReceive", 0, "gap limit for receive addresses")
 	gapLimitsChange := flag.Uint("gapLimitChange", 0, "gap limit for change addresses")
+	simulatorAddress := flag.String("simulator", os.Getenv("BITBOX02_SIMULATOR"),
+		"connect to a BitBox02 simulator at this address (host:port), e.g. localhost:15423")
 	flag.Parse()
 
 	var gapLimits *btctypes.GapLimits
@@ -167,6 +174,11 @@ func main() {
 		}
 	}(log)
 	log.Info("--------------- Started application --------------")
+	environment := webdevEnvironment{}
+	if *simulatorAddress != "" {
+		log.WithField("address", *simulatorAddress).Info("Using the BitBox02 simulator")
+		environment.simulator = usb.NewSimulator(*simulatorAddress)
+	}
 	// since we are in dev-mode, we can drop the authorization token
 	connectionData := backendHandlers.NewConnectionData(-1, "")
 	newBackend, err := backendPkg.NewBackend(
@@ -177,7 +189,7 @@ func main() {
 			*devservers,
 			gapLimits,
 		),
-		webdevEnvironment{})
+		environment)
 	if err != nil {
 		log.WithField("error", err).Panic(err)
 	}
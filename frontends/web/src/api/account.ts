@@ -325,6 +325,20 @@ export type TTxInput = {
   sendAll: 'yes' | 'no';
   selectedUTXOs: string[];
   paymentRequest: Slip24 | null;
+  // If set, `amount` is denominated in this fiat currency.
+  fiat?: Fiat;
+};
+
+export type TTxProposalWarning = {
+  code: 'addressReuse' | 'ownAddress' | 'consolidation' | 'changeScriptType';
+  address?: string;
+  numAddresses?: number;
+};
+
+export type TFiatQuote = {
+  fiat: Fiat;
+  rate: number;
+  timestamp: string;
 };
 
 export type TTxProposalResult = {
@@ -332,6 +346,8 @@ export type TTxProposalResult = {
   fee: IAmount;
   success: true;
   total: IAmount;
+  warnings: TTxProposalWarning[];
+  fiatQuote: TFiatQuote | null;
 } | {
   errorCode: string;
   success: false;
@@ -141,6 +141,50 @@ export const exportLogs = (): Promise<ISuccess> => {
   return apiPost('export-log');
 };
 
+export const testWebhooks = (): Promise<ISuccess> => {
+  return apiPost('webhooks/test');
+};
+
+export type TAPITokenScope = 'balances:read' | 'transactions:read' | 'proposals:create' | 'sign' | 'admin';
+
+export type TAPIToken = {
+  id: string;
+  name: string;
+  scopes: TAPITokenScope[];
+  accountCodes: AccountCode[];
+  created: string;
+  expires: string | null;
+  expired: boolean;
+};
+
+export const getAPITokens = (): Promise<TAPIToken[]> => {
+  return apiGet('api-tokens');
+};
+
+export type TCreateAPITokenArgs = {
+  name: string;
+  scopes: TAPITokenScope[];
+  accountCodes: AccountCode[];
+  expires: string | null;
+};
+
+export type TCreateAPITokenResponse = {
+  success: true;
+  token: string;
+  apiToken: TAPIToken;
+} | {
+  success: false;
+  errorMessage: string;
+};
+
+export const createAPIToken = (args: TCreateAPITokenArgs): Promise<TCreateAPITokenResponse> => {
+  return apiPost('api-tokens/create', args);
+};
+
+export const revokeAPIToken = (id: string): Promise<ISuccess> => {
+  return apiPost('api-tokens/revoke', { id });
+};
+
 export const exportNotes = (): Promise<(FailResponse & { aborted: boolean; }) | SuccessResponse> => {
   return apiPost('notes/export');
 };
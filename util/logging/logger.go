@@ -42,7 +42,7 @@ type Logger struct {
 // It is unsafe for concurrent use because NewLogger may rotate and truncate
 // an existing log file if it's too big.
 func NewLogger(configuration *Configuration) *Logger {
-	fmt.Printf("Logging into '%s' from '%s'.\n", configuration.Output, configuration.Level)
+	fmt.Fprintf(os.Stderr, "Logging into '%s' from '%s'.\n", configuration.Output, configuration.Level)
 	var logger = Logger{}
 	logger.Formatter = &logrus.TextFormatter{}
 	logger.Hooks = make(logrus.LevelHooks)